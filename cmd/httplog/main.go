@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"base-code-go-gin-clean/internal/config"
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/wire"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		showHelp()
+		os.Exit(2)
+	}
+
+	// Load .env from the working directory or the project root
+	_ = godotenv.Load()
+	if envPath, err := filepath.Abs("../../.env"); err == nil {
+		_ = godotenv.Load(envPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch os.Args[1] {
+	case "archive":
+		runArchive(ctx, os.Args[2:])
+	case "import":
+		runImport(ctx, os.Args[2:])
+	case "-h", "help":
+		showHelp()
+	default:
+		showHelp()
+		os.Exit(2)
+	}
+}
+
+func runArchive(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("archive", flag.ExitOnError)
+	from := flags.String("from", "", "Archive logs created at or after this RFC 3339 time or YYYY-MM-DD date (required)")
+	to := flags.String("to", "", "Archive logs created before this RFC 3339 time or YYYY-MM-DD date (required)")
+	dir := flags.String("dir", "", "Write to this directory instead of the configured archive")
+	compression := flags.String("compression", "", "gzip or zstd (defaults to HTTPLOG_ARCHIVE_COMPRESSION)")
+	_ = flags.Parse(args)
+
+	if *from == "" || *to == "" {
+		log.Fatal("❌ -from and -to are required")
+	}
+	fromTime, err := parseTime(*from)
+	if err != nil {
+		log.Fatalf("❌ Invalid -from: %v", err)
+	}
+	toTime, err := parseTime(*to)
+	if err != nil {
+		log.Fatalf("❌ Invalid -to: %v", err)
+	}
+
+	svc, closeDB := newService(*dir, *compression, "", 0)
+	defer closeDB()
+
+	manifest, err := svc.Archive(ctx, *fromTime, *toTime)
+	if err != nil {
+		log.Fatalf("❌ Archive failed: %v", err)
+	}
+
+	for _, file := range manifest.Files {
+		log.Printf("   %s: %d rows, %d bytes, sha256 %s", file.Name, file.Rows, file.Bytes, file.SHA256)
+	}
+	log.Printf("✅ Archived logs from %s to %s", manifest.From.Format(time.RFC3339), manifest.To.Format(time.RFC3339))
+}
+
+func runImport(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	archive := flags.String("archive", "", "Key prefix of the archive, e.g. httplog/20250801T000000Z_20250802T000000Z (required)")
+	dir := flags.String("dir", "", "Read the archive from this directory instead of the configured archive")
+	dbName := flags.String("db-name", "", "Import into this database instead of DB_NAME, e.g. a scratch database")
+	batchSize := flags.Int("batch-size", 500, "Rows per insert statement")
+	_ = flags.Parse(args)
+
+	if *archive == "" {
+		log.Fatal("❌ -archive is required")
+	}
+
+	svc, closeDB := newService(*dir, "", *dbName, *batchSize)
+	defer closeDB()
+
+	result, err := svc.Import(ctx, *archive)
+	if result != nil {
+		for _, table := range httplog.ArchivedTables {
+			log.Printf("   %s: %d rows read, %d inserted", table, result.Read[table], result.Inserted[table])
+		}
+	}
+	if err != nil {
+		log.Fatalf("❌ Import failed: %v", err)
+	}
+
+	log.Printf("✅ Imported logs from %s to %s", result.Manifest.From.Format(time.RFC3339), result.Manifest.To.Format(time.RFC3339))
+}
+
+// newService connects to the database and builds the archive service on the configured
+// archive, or on a local directory
+func newService(dir, compression, dbName string, batchSize int) (httplog.ArchiveService, func()) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+	if dbName != "" {
+		cfg.DB.Name = dbName
+	}
+	if compression != "" {
+		cfg.HTTPLog.ArchiveCompression = compression
+	}
+	if dir != "" {
+		cfg.HTTPLog.ArchiveDriver = "local"
+		cfg.HTTPLog.ArchiveDir = dir
+	}
+	if cfg.HTTPLog.ArchiveDriver == "" {
+		log.Fatal("❌ HTTPLOG_ARCHIVE_DRIVER is not set; pass -dir to use a local directory")
+	}
+	if !httplog.Compression(cfg.HTTPLog.ArchiveCompression).Valid() {
+		log.Fatalf("❌ Unknown compression %q", cfg.HTTPLog.ArchiveCompression)
+	}
+
+	db, err := config.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+
+	store, err := wire.ProvideHTTPLogArchiveStore(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to open the archive: %v", err)
+	}
+
+	svc := httplog.NewArchiveService(httplog.ArchiveConfig{
+		Repo:        httplog.NewArchiveRepository(db.DB),
+		Store:       store,
+		Prefix:      cfg.HTTPLog.ArchivePrefix,
+		Compression: httplog.Compression(cfg.HTTPLog.ArchiveCompression),
+		BatchSize:   batchSize,
+	})
+
+	return svc, func() {
+		if err := db.Close(); err != nil {
+			log.Printf("⚠️ Warning: Failed to close DB: %v", err)
+		}
+	}
+}
+
+// parseTime parses an optional RFC 3339 timestamp or date
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date, got %q", value)
+}
+
+func showHelp() {
+	fmt.Println("Usage:")
+	fmt.Println("  go run . archive -from 2025-08-01 -to 2025-08-02 [-dir ./archives] [-compression gzip|zstd]")
+	fmt.Println("  go run . import -archive httplog/20250801T000000Z_20250802T000000Z [-dir ./archives] [-db-name scratch] [-batch-size 500]")
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"base-code-go-gin-clean/internal/config"
+	"base-code-go-gin-clean/internal/domain/user"
+	userRepo "base-code-go-gin-clean/internal/repository/user"
+	"base-code-go-gin-clean/internal/service/userbulk"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		showHelp()
+		os.Exit(2)
+	}
+
+	// Load .env from the working directory or the project root
+	_ = godotenv.Load()
+	if envPath, err := filepath.Abs("../../.env"); err == nil {
+		_ = godotenv.Load(envPath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch os.Args[1] {
+	case "import":
+		runImport(ctx, os.Args[2:])
+	case "export":
+		runExport(ctx, os.Args[2:])
+	case "role":
+		runRole(ctx, os.Args[2:])
+	case "-h", "help":
+		showHelp()
+	default:
+		showHelp()
+		os.Exit(2)
+	}
+}
+
+func runImport(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "CSV or NDJSON file to import (required)")
+	format := flags.String("format", "", "csv or ndjson (defaults to the file extension)")
+	dryRun := flags.Bool("dry-run", false, "Validate without saving")
+	batchSize := flags.Int("batch-size", 500, "Rows per insert statement")
+	report := flags.String("report", "", "Write the JSON error report to this file instead of stdout")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		log.Fatal("❌ -file is required")
+	}
+	if *format == "" {
+		*format = filepath.Ext(*file)
+	}
+	f, err := userbulk.ParseFormat(*format)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("❌ Failed to open %s: %v", *file, err)
+	}
+	defer in.Close()
+
+	svc, closeDB := newService(*batchSize)
+	defer closeDB()
+
+	result, err := svc.Import(ctx, in, f, userbulk.ImportOptions{DryRun: *dryRun})
+	if err != nil {
+		log.Fatalf("❌ Import failed: %v", err)
+	}
+
+	out := os.Stdout
+	if *report != "" {
+		out, err = os.Create(*report)
+		if err != nil {
+			log.Fatalf("❌ Failed to create report: %v", err)
+		}
+		defer out.Close()
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("❌ Failed to write report: %v", err)
+	}
+
+	prefix := "✅"
+	if *dryRun {
+		prefix = "🔍 Dry run:"
+	}
+	log.Printf("%s %d rows, %d created, %d updated, %d failed", prefix, result.TotalRows, result.Created, result.Updated, result.Failed)
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func runExport(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("out", "", "Output file (defaults to stdout)")
+	format := flags.String("format", "csv", "csv or ndjson")
+	email := flags.String("email", "", "Only users whose email contains this text")
+	createdAfter := flags.String("created-after", "", "Only users created at or after this RFC 3339 time or YYYY-MM-DD date")
+	createdBefore := flags.String("created-before", "", "Only users created before this RFC 3339 time or YYYY-MM-DD date")
+	_ = flags.Parse(args)
+
+	f, err := userbulk.ParseFormat(*format)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	filter := user.ExportFilter{EmailContains: *email}
+	if filter.CreatedAfter, err = parseTime(*createdAfter); err != nil {
+		log.Fatalf("❌ Invalid -created-after: %v", err)
+	}
+	if filter.CreatedBefore, err = parseTime(*createdBefore); err != nil {
+		log.Fatalf("❌ Invalid -created-before: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			log.Fatalf("❌ Failed to create %s: %v", *output, err)
+		}
+		defer out.Close()
+	}
+
+	svc, closeDB := newService(0)
+	defer closeDB()
+
+	count, err := svc.Export(ctx, out, f, filter)
+	if err != nil {
+		log.Fatalf("❌ Export failed after %d users: %v", count, err)
+	}
+
+	log.Printf("✅ Exported %d users", count)
+}
+
+func runRole(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("role", flag.ExitOnError)
+	email := flags.String("email", "", "Email of the user (required)")
+	role := flags.String("role", "", "user or admin (required)")
+	_ = flags.Parse(args)
+
+	if *email == "" || *role == "" {
+		log.Fatal("❌ -email and -role are required")
+	}
+	if !user.Role(*role).IsValid() {
+		log.Fatalf("❌ Unknown role %q", *role)
+	}
+
+	db, closeDB := connectDB()
+	defer closeDB()
+
+	repo := userRepo.NewUserRepository(db.DB)
+	u, err := repo.GetByEmail(ctx, *email)
+	if err != nil {
+		log.Fatalf("❌ Failed to find %s: %v", *email, err)
+	}
+
+	u.Role = user.Role(*role)
+	u.UpdatedAt = time.Now()
+	if err := repo.UpdateRole(ctx, u); err != nil {
+		log.Fatalf("❌ Failed to update the role: %v", err)
+	}
+
+	// The API caches roles with the account status for a few minutes
+	log.Printf("✅ %s is now %s; signed-in sessions pick it up within a few minutes", *email, *role)
+}
+
+// newService connects to the database and builds the bulk service
+func newService(batchSize int) (userbulk.UserBulkService, func()) {
+	db, closeDB := connectDB()
+
+	svc := userbulk.NewUserBulkService(userbulk.UserBulkServiceConfig{
+		Repo:      userRepo.NewUserBulkRepository(db.DB),
+		BatchSize: batchSize,
+	})
+
+	return svc, closeDB
+}
+
+// connectDB loads the config and connects to the database
+func connectDB() (*config.DB, func()) {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to load config: %v", err)
+	}
+
+	db, err := config.NewDB(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+
+	return db, func() {
+		if err := db.Close(); err != nil {
+			log.Printf("⚠️ Warning: Failed to close DB: %v", err)
+		}
+	}
+}
+
+// parseTime parses an optional RFC 3339 timestamp or date
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("expected RFC 3339 timestamp or YYYY-MM-DD date, got %q", value)
+}
+
+func showHelp() {
+	fmt.Println("Usage:")
+	fmt.Println("  go run . import -file users.csv [-format csv|ndjson] [-dry-run] [-report report.json]")
+	fmt.Println("  go run . role -email admin@example.com -role admin|user")
+	fmt.Println("  go run . export [-format csv|ndjson] [-out users.csv] [-email text] [-created-after 2025-01-01] [-created-before 2025-02-01]")
+}
@@ -1,6 +1,9 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Config holds all configuration for the application
 type Config struct {
@@ -10,6 +13,9 @@ type Config struct {
 	Email   EmailConfig
 	Auth    AuthConfig
 	Redis   RedisConfig
+	Privacy PrivacyConfig
+	Storage StorageConfig
+	HTTPLog HTTPLogConfig
 }
 
 // AuthConfig holds authentication related configuration
@@ -24,6 +30,8 @@ type AuthConfig struct {
 type ServerConfig struct {
 	Port        string
 	Environment string
+	BaseURL     string // Public URL used to build links in emails
+	MaxUploadMB int    // Maximum size of multipart uploads
 }
 
 type DatabaseConfig struct {
@@ -43,11 +51,81 @@ type TracingConfig struct {
 }
 
 type EmailConfig struct {
+	Transport    string // "smtp", "file" (.eml files in FileDir), "log" or "memory"
 	SMTPServer   string
 	SMTPPort     string
 	SMTPUsername string
 	SMTPPassword string
+	SMTPSecurity string // "starttls", "tls" or "none"
+	SMTPAuth     string // "plain", "login", "cram-md5" or "none"
+	SMTPPoolSize int    // Maximum number of pooled SMTP connections
+	FileDir      string // Output directory of the file transport
 	From         string
+
+	OutboxWorkers        int // Messages delivered in parallel by the outbox worker
+	OutboxMaxAttempts    int // Delivery attempts before a message is dead-lettered
+	OutboxPollSeconds    int // Delay between outbox polls when it is empty
+	OutboxBackoffSeconds int // Delay before the first retry; doubled on every further failure
+	BatchDomainRate      int // Batch messages per minute to one recipient domain
+
+	UnsubscribeKey string // HMAC key for unsubscribe links; defaults to the access token secret
+	WebhookSecret  string // Shared secret of the bounce webhook; the webhook is disabled when empty
+
+	TrackOpens  bool   // Add an open-tracking pixel to HTML mail
+	TrackClicks bool   // Route links in HTML mail through the click-tracking endpoint
+	TrackingKey string // HMAC key for tracking links; defaults to the access token secret
+
+	DKIM []DKIMKey // Signing keys by From domain; mail from other domains is sent unsigned
+}
+
+// DKIMKey is a DKIM signing key for one domain
+type DKIMKey struct {
+	Domain     string // Signing domain (d=), e.g. example.com; also signs mail from its subdomains
+	Selector   string // Selector (s=) of the public key record at <selector>._domainkey.<domain>
+	PrivateKey string // PEM-encoded RSA or Ed25519 private key, or the path of a PEM file
+}
+
+// PrivacyConfig holds configuration for data export and account deletion
+type PrivacyConfig struct {
+	ExportDir           string // Directory where export archives are written
+	ExportLinkTTLHours  int    // How long an export download link stays valid
+	DeletionGracePeriod int    // Days before a requested deletion is carried out
+}
+
+// StorageConfig holds configuration for blob storage such as user avatars
+type StorageConfig struct {
+	Driver        string // "local" or "s3"
+	LocalDir      string // Root directory for the local driver
+	SigningKey    string // HMAC key used by the local driver to sign URLs
+	URLTTLMinutes int    // How long signed URLs stay valid
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3UseSSL      bool
+}
+
+type HTTPLogConfig struct {
+	QueueSize   int    // Logs buffered before the overflow policy applies
+	BatchSize   int    // Logs written per bulk insert
+	FlushMillis int    // Maximum time a log waits before it is written
+	Overflow    string // "drop" or "block" when the queue is full
+
+	RetentionDays     int    // Days logs are kept; 0 keeps them forever
+	PartitionInterval string // "day" or "month", the range of one log table partition
+	PartitionsAhead   int    // Partitions created ahead of the current one
+
+	TrackingSamplePercent int // Share of email open and click tracking requests logged, from 1 to 100 percent
+	SlowMillis            int // Requests taking longer are logged whatever their sampling; 0 disables
+	MaxRequestBodyKB      int // Kilobytes of request bodies logged
+	MaxResponseBodyKB     int // Kilobytes of response bodies logged
+
+	ArchiveDriver      string // "local" or "s3" to archive logs before they are dropped; not archived when empty
+	ArchiveDir         string // Directory of the archives with the local driver
+	ArchiveS3Bucket    string // Bucket of the archives with the s3 driver, reached with the S3_* settings
+	ArchivePrefix      string // Key prefix of the archives
+	ArchiveCompression string // "gzip" or "zstd"
 }
 
 type RedisConfig struct {
@@ -63,6 +141,8 @@ func Load() (*Config, error) {
 		Server: ServerConfig{
 			Port:        GetEnv("PORT", "8080"),
 			Environment: GetEnv("ENVIRONMENT", "development"),
+			BaseURL:     GetEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+			MaxUploadMB: GetEnvAsInt("MAX_UPLOAD_SIZE_MB", 10),
 		},
 		DB: DatabaseConfig{
 			Host:     GetEnv("DB_HOST", ""),
@@ -92,11 +172,67 @@ func Load() (*Config, error) {
 			DSN:         GetEnv("UPTRACE_DSN", ""),
 		},
 		Email: EmailConfig{
+			Transport:    GetEnv("EMAIL_TRANSPORT", "smtp"),
 			SMTPServer:   GetEnv("SMTP_SERVER", ""),
 			SMTPPort:     GetEnv("SMTP_PORT", ""),
 			SMTPUsername: GetEnv("SMTP_USERNAME", ""),
 			SMTPPassword: GetEnv("SMTP_PASSWORD", ""),
+			SMTPSecurity: GetEnv("SMTP_SECURITY", "starttls"),
+			SMTPAuth:     GetEnv("SMTP_AUTH", "plain"),
+			SMTPPoolSize: GetEnvAsInt("SMTP_POOL_SIZE", 4),
+			FileDir:      GetEnv("EMAIL_FILE_DIR", "./tmp/mail"),
 			From:         GetEnv("EMAIL_FROM", ""),
+
+			OutboxWorkers:        GetEnvAsInt("EMAIL_OUTBOX_WORKERS", 4),
+			OutboxMaxAttempts:    GetEnvAsInt("EMAIL_OUTBOX_MAX_ATTEMPTS", 8),
+			OutboxPollSeconds:    GetEnvAsInt("EMAIL_OUTBOX_POLL_SECONDS", 5),
+			OutboxBackoffSeconds: GetEnvAsInt("EMAIL_OUTBOX_BACKOFF_SECONDS", 30),
+			BatchDomainRate:      GetEnvAsInt("EMAIL_BATCH_DOMAIN_RATE", 60),
+
+			UnsubscribeKey: GetEnv("EMAIL_UNSUBSCRIBE_KEY", ""),
+			WebhookSecret:  GetEnv("EMAIL_WEBHOOK_SECRET", ""),
+
+			TrackOpens:  GetEnv("EMAIL_TRACK_OPENS", "false") == "true",
+			TrackClicks: GetEnv("EMAIL_TRACK_CLICKS", "false") == "true",
+			TrackingKey: GetEnv("EMAIL_TRACKING_KEY", ""),
+		},
+		Privacy: PrivacyConfig{
+			ExportDir:           GetEnv("PRIVACY_EXPORT_DIR", "./tmp/exports"),
+			ExportLinkTTLHours:  GetEnvAsInt("PRIVACY_EXPORT_LINK_TTL_HOURS", 48),
+			DeletionGracePeriod: GetEnvAsInt("PRIVACY_DELETION_GRACE_DAYS", 14),
+		},
+		Storage: StorageConfig{
+			Driver:        GetEnv("STORAGE_DRIVER", "local"),
+			LocalDir:      GetEnv("STORAGE_LOCAL_DIR", "./tmp/storage"),
+			SigningKey:    GetEnv("STORAGE_SIGNING_KEY", ""),
+			URLTTLMinutes: GetEnvAsInt("STORAGE_URL_TTL_MINUTES", 60),
+			S3Endpoint:    GetEnv("S3_ENDPOINT", ""),
+			S3Region:      GetEnv("S3_REGION", "us-east-1"),
+			S3Bucket:      GetEnv("S3_BUCKET", ""),
+			S3AccessKey:   GetEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey:   GetEnv("S3_SECRET_KEY", ""),
+			S3UseSSL:      GetEnv("S3_USE_SSL", "true") == "true",
+		},
+		HTTPLog: HTTPLogConfig{
+			QueueSize:   GetEnvAsInt("HTTPLOG_QUEUE_SIZE", 10000),
+			BatchSize:   GetEnvAsInt("HTTPLOG_BATCH_SIZE", 500),
+			FlushMillis: GetEnvAsInt("HTTPLOG_FLUSH_INTERVAL_MS", 1000),
+			Overflow:    GetEnv("HTTPLOG_OVERFLOW", "drop"),
+
+			RetentionDays:     GetEnvAsInt("HTTPLOG_RETENTION_DAYS", 30),
+			PartitionInterval: GetEnv("HTTPLOG_PARTITION_INTERVAL", "day"),
+			PartitionsAhead:   GetEnvAsInt("HTTPLOG_PARTITIONS_AHEAD", 3),
+
+			TrackingSamplePercent: GetEnvAsInt("HTTPLOG_TRACKING_SAMPLE_PERCENT", 10),
+			SlowMillis:            GetEnvAsInt("HTTPLOG_SLOW_MS", 1000),
+			MaxRequestBodyKB:      GetEnvAsInt("HTTPLOG_MAX_REQUEST_BODY_KB", 1024),
+			MaxResponseBodyKB:     GetEnvAsInt("HTTPLOG_MAX_RESPONSE_BODY_KB", 1024),
+
+			ArchiveDriver:      GetEnv("HTTPLOG_ARCHIVE_DRIVER", ""),
+			ArchiveDir:         GetEnv("HTTPLOG_ARCHIVE_DIR", "./tmp/httplog-archive"),
+			ArchiveS3Bucket:    GetEnv("HTTPLOG_ARCHIVE_S3_BUCKET", ""),
+			ArchivePrefix:      GetEnv("HTTPLOG_ARCHIVE_PREFIX", "httplog"),
+			ArchiveCompression: GetEnv("HTTPLOG_ARCHIVE_COMPRESSION", "gzip"),
 		},
 	}
 
@@ -112,13 +248,106 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("JWT secrets are not set")
 	}
 
-	if cfg.Email.SMTPServer == "" || cfg.Email.SMTPPort == "" || cfg.Email.SMTPUsername == "" || cfg.Email.SMTPPassword == "" || cfg.Email.From == "" {
-		return nil, fmt.Errorf("email credentials are not set")
+	if cfg.Email.From == "" {
+		return nil, fmt.Errorf("email sender address is not set")
+	}
+
+	switch cfg.Email.Transport {
+	case "smtp":
+		if cfg.Email.SMTPServer == "" || cfg.Email.SMTPPort == "" {
+			return nil, fmt.Errorf("email credentials are not set")
+		}
+		if cfg.Email.SMTPAuth != "none" && (cfg.Email.SMTPUsername == "" || cfg.Email.SMTPPassword == "") {
+			return nil, fmt.Errorf("email credentials are not set")
+		}
+	case "file", "log", "memory":
+	default:
+		return nil, fmt.Errorf("unknown email transport %q", cfg.Email.Transport)
+	}
+	if cfg.Email.UnsubscribeKey == "" {
+		cfg.Email.UnsubscribeKey = cfg.Auth.AccessTokenSecret
+	}
+	if cfg.Email.TrackingKey == "" {
+		cfg.Email.TrackingKey = cfg.Auth.AccessTokenSecret
+	}
+
+	dkim, err := loadDKIMKeys()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Email.DKIM = dkim
+
+	switch cfg.Storage.Driver {
+	case "local":
+		if cfg.Storage.SigningKey == "" {
+			cfg.Storage.SigningKey = cfg.Auth.AccessTokenSecret
+		}
+	case "s3":
+		if cfg.Storage.S3Endpoint == "" || cfg.Storage.S3Bucket == "" {
+			return nil, fmt.Errorf("s3 storage settings are not set")
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+
+	if cfg.HTTPLog.Overflow != "drop" && cfg.HTTPLog.Overflow != "block" {
+		return nil, fmt.Errorf("unknown http log overflow policy %q", cfg.HTTPLog.Overflow)
+	}
+	if cfg.HTTPLog.PartitionInterval != "day" && cfg.HTTPLog.PartitionInterval != "month" {
+		return nil, fmt.Errorf("unknown http log partition interval %q", cfg.HTTPLog.PartitionInterval)
+	}
+	if cfg.HTTPLog.TrackingSamplePercent < 1 || cfg.HTTPLog.TrackingSamplePercent > 100 {
+		return nil, fmt.Errorf("http log tracking sample percent %d is not between 1 and 100", cfg.HTTPLog.TrackingSamplePercent)
+	}
+	switch cfg.HTTPLog.ArchiveDriver {
+	case "", "local":
+	case "s3":
+		if cfg.Storage.S3Endpoint == "" || cfg.HTTPLog.ArchiveS3Bucket == "" {
+			return nil, fmt.Errorf("http log archive s3 settings are not set")
+		}
+	default:
+		return nil, fmt.Errorf("unknown http log archive driver %q", cfg.HTTPLog.ArchiveDriver)
+	}
+	if cfg.HTTPLog.ArchiveCompression != "gzip" && cfg.HTTPLog.ArchiveCompression != "zstd" {
+		return nil, fmt.Errorf("unknown http log archive compression %q", cfg.HTTPLog.ArchiveCompression)
 	}
 
 	return cfg, nil
 }
 
+// loadDKIMKeys reads the default DKIM key from EMAIL_DKIM_DOMAIN,
+// EMAIL_DKIM_SELECTOR and EMAIL_DKIM_PRIVATE_KEY, and keys for further From
+// domains from EMAIL_DKIM_KEYS as comma-separated domain:selector:keyfile entries.
+func loadDKIMKeys() ([]DKIMKey, error) {
+	var keys []DKIMKey
+	if domain := GetEnv("EMAIL_DKIM_DOMAIN", ""); domain != "" {
+		keys = append(keys, DKIMKey{
+			Domain:     domain,
+			Selector:   GetEnv("EMAIL_DKIM_SELECTOR", ""),
+			PrivateKey: GetEnv("EMAIL_DKIM_PRIVATE_KEY", ""),
+		})
+	}
+
+	for _, entry := range strings.Split(GetEnv("EMAIL_DKIM_KEYS", ""), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid EMAIL_DKIM_KEYS entry %q: want domain:selector:keyfile", entry)
+		}
+		keys = append(keys, DKIMKey{Domain: parts[0], Selector: parts[1], PrivateKey: parts[2]})
+	}
+
+	for _, key := range keys {
+		if key.Domain == "" || key.Selector == "" || key.PrivateKey == "" {
+			return nil, fmt.Errorf("DKIM key for %q needs a domain, selector and private key", key.Domain)
+		}
+	}
+	return keys, nil
+}
+
 // GetDSN returns the database connection string
 func (db *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
@@ -0,0 +1,100 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// BatchStatus represents the dispatch state of a batch
+type BatchStatus string
+
+const (
+	// BatchScheduled batches wait for SendAt and can still be cancelled
+	BatchScheduled BatchStatus = "scheduled"
+	// BatchDispatching batches are being fanned out into the outbox; the claim expires at LockedUntil
+	BatchDispatching BatchStatus = "dispatching"
+	// BatchDispatched batches have a queued message or a failure for every recipient
+	BatchDispatched BatchStatus = "dispatched"
+	BatchCancelled  BatchStatus = "cancelled"
+)
+
+// RecipientStatus represents the state of one recipient of a batch
+type RecipientStatus string
+
+const (
+	RecipientPending RecipientStatus = "pending"
+	// RecipientQueued recipients have a message in the outbox
+	RecipientQueued RecipientStatus = "queued"
+	// RecipientFailed recipients were not queued, e.g. because they are suppressed
+	RecipientFailed RecipientStatus = "failed"
+)
+
+// Batch is one template sent to many recipients. Data is shared by all
+// recipients; each recipient's own data takes precedence over it.
+type Batch struct {
+	bun.BaseModel `bun:"table:email_batches,alias:eb"`
+
+	ID                uuid.UUID                  `bun:"type:uuid,default:uuid_generate_v4(),pk" json:"id"`
+	Template          string                     `bun:"type:varchar(100),notnull" json:"template"`
+	ReplyTo           string                     `bun:"type:varchar(255),nullzero" json:"reply_to,omitempty"`
+	Locale            string                     `bun:"type:varchar(35),nullzero" json:"locale,omitempty"`
+	Data              map[string]json.RawMessage `bun:"type:jsonb" json:"-"`
+	Status            BatchStatus                `bun:"type:varchar(20),notnull" json:"status"`
+	SendAt            time.Time                  `bun:"type:timestamp,notnull" json:"send_at"`
+	Total             int                        `bun:"total,notnull" json:"total"`
+	Queued            int                        `bun:"queued,notnull" json:"queued"`
+	Failed            int                        `bun:"failed,notnull" json:"failed"`
+	LockedUntil       *time.Time                 `bun:"type:timestamp" json:"-"`
+	DispatchStartedAt *time.Time                 `bun:"type:timestamp" json:"dispatch_started_at,omitempty"`
+	DispatchedAt      *time.Time                 `bun:"type:timestamp" json:"dispatched_at,omitempty"`
+	CancelledAt       *time.Time                 `bun:"type:timestamp" json:"cancelled_at,omitempty"`
+	CreatedBy         *uuid.UUID                 `bun:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt         time.Time                  `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt         time.Time                  `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// BatchRecipient is one recipient of a batch with its own template data and
+// locale. Position keeps the order of the request.
+type BatchRecipient struct {
+	bun.BaseModel `bun:"table:email_batch_recipients,alias:ebr"`
+
+	BatchID   uuid.UUID                  `bun:"type:uuid,pk" json:"-"`
+	Position  int                        `bun:"position,pk" json:"position"`
+	Email     string                     `bun:"type:varchar(255),notnull" json:"email"`
+	Locale    string                     `bun:"type:varchar(35),nullzero" json:"locale,omitempty"`
+	Data      map[string]json.RawMessage `bun:"type:jsonb" json:"-"`
+	Status    RecipientStatus            `bun:"type:varchar(20),notnull" json:"status"`
+	MessageID *uuid.UUID                 `bun:"type:uuid" json:"message_id,omitempty"`
+	Error     string                     `bun:"type:text,nullzero" json:"error,omitempty"`
+}
+
+// BatchRepository defines storage operations for batches and their recipients
+type BatchRepository interface {
+	// Create stores a batch and its recipients in one transaction
+	Create(ctx context.Context, batch *Batch, recipients []*BatchRecipient) error
+
+	// GetByID returns a batch by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*Batch, error)
+
+	// Cancel marks a scheduled batch as cancelled. It returns sql.ErrNoRows
+	// when the batch does not exist or is no longer scheduled.
+	Cancel(ctx context.Context, id uuid.UUID, at time.Time) (*Batch, error)
+
+	// ClaimDue marks up to limit scheduled batches due at now as dispatching and
+	// leases them until leaseUntil. Batches whose lease expired mid-dispatch are
+	// claimed again.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*Batch, error)
+
+	// Update persists the status, counters and lease of a batch
+	Update(ctx context.Context, batch *Batch) error
+
+	// ListRecipients returns the recipients of a batch in request order
+	ListRecipients(ctx context.Context, batchID uuid.UUID) ([]*BatchRecipient, error)
+
+	// UpdateRecipient persists the status, message ID and error of a recipient
+	UpdateRecipient(ctx context.Context, recipient *BatchRecipient) error
+}
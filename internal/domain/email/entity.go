@@ -1,7 +1,141 @@
 package email
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// ErrInvalidEmail is wrapped by every error returned from Email.Validate
+var ErrInvalidEmail = errors.New("invalid email")
+
+// Email is an outgoing message. Body holds the HTML part; when Text is set
+// it is sent alongside as the text/plain alternative.
+//
+// SendAt schedules delivery; the message is held in the outbox until then.
+// Past or missing times mean as soon as possible.
+//
+// Bulk marks automated mail the recipient can opt out of, such as reports.
+// It is sent with one-click List-Unsubscribe headers and not delivered to
+// addresses that unsubscribed; transactional mail such as password resets
+// is only stopped by bounces and complaints.
 type Email struct {
-	To      []string
-	Subject string
-	Body    string
+	To          []string          `json:"to"`
+	Cc          []string          `json:"cc,omitempty"`
+	Bcc         []string          `json:"bcc,omitempty"`
+	ReplyTo     string            `json:"reply_to,omitempty"`
+	Subject     string            `json:"subject"`
+	Body        string            `json:"body"`
+	Text        string            `json:"text,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	Bulk        bool              `json:"bulk,omitempty"`
+	SendAt      *time.Time        `json:"send_at,omitempty"`
+
+	// Template is the catalog template the email was rendered from. It is
+	// only recorded in the delivery log.
+	Template string `json:"-"`
+}
+
+// TemplateEmail asks for a catalog template to be rendered with Data and sent to To.
+// Data is validated against the template's field schema. Locale overrides the
+// recipient's locale preference. The template is rendered when it is queued,
+// also when SendAt schedules delivery for later.
+type TemplateEmail struct {
+	Template string                     `json:"template"`
+	To       string                     `json:"to"`
+	ReplyTo  string                     `json:"reply_to,omitempty"`
+	Locale   string                     `json:"locale,omitempty" example:"de"`
+	Data     map[string]json.RawMessage `json:"data" swaggertype:"object"`
+	SendAt   *time.Time                 `json:"send_at,omitempty"`
+
+	// AcceptLanguage is the header of the API request, the last locale tried
+	AcceptLanguage string `json:"-"`
+}
+
+// Attachment is a file sent with an email. Attachments with a ContentID are
+// sent inline and can be referenced from the HTML body as cid:<ContentID>.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	Content     []byte `json:"content"` // base64 in JSON
+	ContentID   string `json:"content_id,omitempty"`
+}
+
+// Inline reports whether the attachment is embedded in the HTML body
+func (a Attachment) Inline() bool {
+	return a.ContentID != ""
+}
+
+// reservedHeaders are set by the message builder and cannot be overridden
+var reservedHeaders = map[string]bool{
+	"From": true, "To": true, "Cc": true, "Bcc": true, "Reply-To": true, "Subject": true,
+	"Date": true, "Message-Id": true, "Mime-Version": true, "Content-Type": true,
+	"Content-Transfer-Encoding": true, "List-Unsubscribe": true, "List-Unsubscribe-Post": true,
+}
+
+// Recipients returns every envelope recipient: To, Cc and Bcc
+func (e *Email) Recipients() []string {
+	rcpts := make([]string, 0, len(e.To)+len(e.Cc)+len(e.Bcc))
+	rcpts = append(rcpts, e.To...)
+	rcpts = append(rcpts, e.Cc...)
+	return append(rcpts, e.Bcc...)
+}
+
+// Validate checks addresses, custom headers and attachments
+func (e *Email) Validate() error {
+	if len(e.To) == 0 {
+		return fmt.Errorf("%w: at least one recipient is required", ErrInvalidEmail)
+	}
+	// The unsubscribe link identifies the recipient, so it cannot be shared
+	if e.Bulk && len(e.Recipients()) != 1 {
+		return fmt.Errorf("%w: bulk email must have exactly one recipient", ErrInvalidEmail)
+	}
+	for _, addr := range e.Recipients() {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("%w: invalid recipient %q", ErrInvalidEmail, addr)
+		}
+	}
+	if e.ReplyTo != "" {
+		if _, err := mail.ParseAddress(e.ReplyTo); err != nil {
+			return fmt.Errorf("%w: invalid reply-to %q", ErrInvalidEmail, e.ReplyTo)
+		}
+	}
+	for name, value := range e.Headers {
+		if !validHeaderName(name) {
+			return fmt.Errorf("%w: invalid header name %q", ErrInvalidEmail, name)
+		}
+		if reservedHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			return fmt.Errorf("%w: header %q cannot be overridden", ErrInvalidEmail, name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: header %q contains a line break", ErrInvalidEmail, name)
+		}
+	}
+	for _, a := range e.Attachments {
+		if a.Filename == "" || len(a.Content) == 0 {
+			return fmt.Errorf("%w: attachments need a filename and content", ErrInvalidEmail)
+		}
+		if strings.ContainsAny(a.ContentID, "<>\r\n ") {
+			return fmt.Errorf("%w: invalid content ID %q", ErrInvalidEmail, a.ContentID)
+		}
+	}
+	return nil
+}
+
+// validHeaderName reports whether name consists of printable ASCII without a colon (RFC 5322 section 2.2)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r <= ' ' || r > '~' || r == ':' {
+			return false
+		}
+	}
+	return true
 }
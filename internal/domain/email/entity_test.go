@@ -0,0 +1,50 @@
+package email
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmail_Validate(t *testing.T) {
+	valid := func() *Email {
+		return &Email{
+			To:      []string{"Jane <jane@example.com>"},
+			Cc:      []string{"cc@example.com"},
+			Subject: "Hello",
+			Body:    "<p>Hi</p>",
+			Headers: map[string]string{"X-Campaign": "welcome"},
+			Attachments: []Attachment{
+				{Filename: "logo.png", Content: []byte{1}, ContentID: "logo"},
+			},
+		}
+	}
+
+	assert.NoError(t, valid().Validate())
+
+	tests := map[string]func(e *Email){
+		"no recipients":            func(e *Email) { e.To = nil },
+		"invalid bcc":              func(e *Email) { e.Bcc = []string{"not an address"} },
+		"invalid reply-to":         func(e *Email) { e.ReplyTo = "@" },
+		"header injection":         func(e *Email) { e.Headers["X-Tag"] = "a\r\nBcc: victim@example.com" },
+		"reserved header":          func(e *Email) { e.Headers["content-type"] = "text/plain" },
+		"invalid header name":      func(e *Email) { e.Headers["X Tag"] = "a" },
+		"empty attachment":         func(e *Email) { e.Attachments[0].Content = nil },
+		"content ID with brackets": func(e *Email) { e.Attachments[0].ContentID = "<logo>" },
+		"list-unsubscribe header":  func(e *Email) { e.Headers["List-Unsubscribe"] = "<https://evil.example>" },
+		"bulk with two recipients": func(e *Email) { e.Bulk = true },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := valid()
+			mutate(e)
+			assert.ErrorIs(t, e.Validate(), ErrInvalidEmail)
+		})
+	}
+}
+
+func TestEmail_Recipients(t *testing.T) {
+	e := &Email{To: []string{"a@example.com"}, Cc: []string{"b@example.com"}, Bcc: []string{"c@example.com"}}
+
+	assert.Equal(t, []string{"a@example.com", "b@example.com", "c@example.com"}, e.Recipients())
+}
@@ -0,0 +1,96 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// TrackingEvent is a recipient interaction recorded by the tracking endpoints
+type TrackingEvent string
+
+const (
+	// TrackingOpen is recorded when the open-tracking pixel is loaded
+	TrackingOpen TrackingEvent = "open"
+	// TrackingClick is recorded when a rewritten link is followed
+	TrackingClick TrackingEvent = "click"
+)
+
+// MessageLog is the delivery record of an email. It shares its ID with the
+// outbox message and, unlike the outbox, keeps no message content beyond the
+// subject.
+type MessageLog struct {
+	bun.BaseModel `bun:"table:email_messages,alias:em"`
+
+	ID               uuid.UUID     `bun:"type:uuid,pk" json:"id"`
+	Template         string        `bun:"type:varchar(100),nullzero" json:"template,omitempty"`
+	Subject          string        `bun:"type:text,notnull" json:"subject"`
+	Recipients       []string      `bun:"type:text[],array,notnull" json:"recipients"`
+	Bulk             bool          `bun:"bulk,notnull" json:"bulk"`
+	Status           MessageStatus `bun:"type:varchar(20),notnull" json:"status"`
+	Attempts         int           `bun:"attempts,notnull" json:"attempts"`
+	ProviderResponse string        `bun:"type:text,nullzero" json:"provider_response,omitempty"`
+	LastError        string        `bun:"type:text,nullzero" json:"last_error,omitempty"`
+	TraceID          string        `bun:"type:varchar(64),nullzero" json:"trace_id,omitempty"`
+	Tracked          bool          `bun:"tracked,notnull" json:"tracked"`
+	Opens            int           `bun:"opens,notnull" json:"opens"`
+	Clicks           int           `bun:"clicks,notnull" json:"clicks"`
+	FirstOpenedAt    *time.Time    `bun:"type:timestamp" json:"first_opened_at,omitempty"`
+	LastOpenedAt     *time.Time    `bun:"type:timestamp" json:"last_opened_at,omitempty"`
+	FirstClickedAt   *time.Time    `bun:"type:timestamp" json:"first_clicked_at,omitempty"`
+	LastClickedAt    *time.Time    `bun:"type:timestamp" json:"last_clicked_at,omitempty"`
+	SentAt           *time.Time    `bun:"type:timestamp" json:"sent_at,omitempty"`
+	CreatedAt        time.Time     `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt        time.Time     `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// MessageLogFilter selects log entries; zero fields match everything
+type MessageLogFilter struct {
+	Template  string
+	Status    MessageStatus
+	Recipient string
+	TraceID   string
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+	Offset    int
+}
+
+// TemplateStats aggregates the log entries of one template. Messages sent
+// without a template are grouped under an empty name.
+type TemplateStats struct {
+	Template   string  `bun:"template" json:"template"`
+	Total      int     `bun:"total" json:"total"`
+	Sent       int     `bun:"sent" json:"sent"`
+	Pending    int     `bun:"pending" json:"pending"`
+	Dead       int     `bun:"dead" json:"dead"`
+	Suppressed int     `bun:"suppressed" json:"suppressed"`
+	Tracked    int     `bun:"tracked" json:"tracked"`
+	Opened     int     `bun:"opened" json:"opened"`
+	Clicked    int     `bun:"clicked" json:"clicked"`
+	OpenRate   float64 `bun:"-" json:"open_rate"`  // Share of sent tracked messages opened at least once
+	ClickRate  float64 `bun:"-" json:"click_rate"` // Share of sent tracked messages with at least one click
+}
+
+// MessageLogRepository defines storage operations for the delivery log
+type MessageLogRepository interface {
+	// Create stores the entry of a newly queued message
+	Create(ctx context.Context, entry *MessageLog) error
+
+	// UpdateDelivery persists the status, attempts, provider response, error and send time
+	UpdateDelivery(ctx context.Context, entry *MessageLog) error
+
+	// RecordEvent counts an open or click at the given time
+	RecordEvent(ctx context.Context, id uuid.UUID, event TrackingEvent, at time.Time) error
+
+	// GetByID returns an entry by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*MessageLog, error)
+
+	// List returns a page of entries matching filter, newest first, and the total count
+	List(ctx context.Context, filter MessageLogFilter) ([]*MessageLog, int, error)
+
+	// Stats aggregates entries created in the optional time window by template
+	Stats(ctx context.Context, since, until *time.Time) ([]*TemplateStats, error)
+}
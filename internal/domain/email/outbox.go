@@ -0,0 +1,66 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// MessageStatus represents the delivery state of a queued email
+type MessageStatus string
+
+const (
+	// MessageQueued messages are waiting for their next delivery attempt
+	MessageQueued MessageStatus = "queued"
+	// MessageSending messages are claimed by a worker; the claim expires at NextAttemptAt
+	MessageSending MessageStatus = "sending"
+	MessageSent    MessageStatus = "sent"
+	// MessageDead messages exhausted their attempts and will not be retried
+	MessageDead MessageStatus = "dead"
+	// MessageSuppressed messages were dropped because a recipient was suppressed after they were queued
+	MessageSuppressed MessageStatus = "suppressed"
+)
+
+// Valid reports whether s is a known status
+func (s MessageStatus) Valid() bool {
+	switch s {
+	case MessageQueued, MessageSending, MessageSent, MessageDead, MessageSuppressed:
+		return true
+	}
+	return false
+}
+
+// OutboxMessage is an email persisted for asynchronous delivery
+type OutboxMessage struct {
+	bun.BaseModel `bun:"table:email_outbox,alias:eo"`
+
+	ID            uuid.UUID     `bun:"type:uuid,default:uuid_generate_v4(),pk" json:"id"`
+	Payload       Email         `bun:"type:jsonb,notnull" json:"-"`
+	Status        MessageStatus `bun:"type:varchar(20),notnull" json:"status"`
+	Attempts      int           `bun:"attempts,notnull" json:"attempts"`
+	MaxAttempts   int           `bun:"max_attempts,notnull" json:"max_attempts"`
+	NextAttemptAt time.Time     `bun:"type:timestamp,notnull" json:"next_attempt_at"`
+	LastError     string        `bun:"type:text,nullzero" json:"last_error,omitempty"`
+	SentAt        *time.Time    `bun:"type:timestamp" json:"sent_at,omitempty"`
+	CreatedAt     time.Time     `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt     time.Time     `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// OutboxRepository defines storage operations for the email outbox
+type OutboxRepository interface {
+	// Create stores a new message
+	Create(ctx context.Context, msg *OutboxMessage) error
+
+	// GetByID returns a message by its ID
+	GetByID(ctx context.Context, id uuid.UUID) (*OutboxMessage, error)
+
+	// ClaimDue marks up to limit messages due at now as sending, increments their
+	// attempt counter and leases them until leaseUntil. Claimed rows are skipped by
+	// concurrent workers, and leases that expire are claimed again.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*OutboxMessage, error)
+
+	// Update persists the delivery outcome of a message
+	Update(ctx context.Context, msg *OutboxMessage) error
+}
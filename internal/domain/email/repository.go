@@ -1,5 +1,9 @@
 package email
 
+import "context"
+
 type EmailService interface {
-	SendEmail(email *Email) error
+	// SendEmail sends or queues a message. Implementations stop waiting on
+	// the mail server when ctx is cancelled.
+	SendEmail(ctx context.Context, email *Email) error
 }
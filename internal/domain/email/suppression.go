@@ -0,0 +1,67 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ErrRecipientSuppressed is returned when an email is addressed to a suppressed recipient
+var ErrRecipientSuppressed = errors.New("recipient is suppressed")
+
+// SuppressionReason records why mail to an address is suppressed
+type SuppressionReason string
+
+const (
+	// SuppressionUnsubscribed addresses opted out of bulk mail; transactional mail is still sent
+	SuppressionUnsubscribed SuppressionReason = "unsubscribed"
+	// SuppressionHardBounce addresses were rejected permanently by the receiving server
+	SuppressionHardBounce SuppressionReason = "hard_bounce"
+	// SuppressionComplaint addresses reported our mail as spam
+	SuppressionComplaint SuppressionReason = "complaint"
+)
+
+// Valid reports whether r is a known reason
+func (r SuppressionReason) Valid() bool {
+	switch r {
+	case SuppressionUnsubscribed, SuppressionHardBounce, SuppressionComplaint:
+		return true
+	}
+	return false
+}
+
+// Blocks reports whether the reason stops email of the given kind. Bounces
+// and complaints block everything; an unsubscribe only blocks bulk mail.
+func (r SuppressionReason) Blocks(bulk bool) bool {
+	return bulk || r != SuppressionUnsubscribed
+}
+
+// Suppression is an address that must not receive some or all mail
+type Suppression struct {
+	bun.BaseModel `bun:"table:email_suppressions,alias:es"`
+
+	ID        uuid.UUID         `bun:"type:uuid,default:uuid_generate_v4(),pk" json:"id"`
+	Email     string            `bun:"email,notnull" json:"email"` // Lower-cased bare address
+	Reason    SuppressionReason `bun:"type:varchar(20),notnull" json:"reason"`
+	Detail    string            `bun:"type:text,nullzero" json:"detail,omitempty"` // e.g. the bounce diagnostic
+	CreatedAt time.Time         `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt time.Time         `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// SuppressionRepository defines storage operations for the suppression list
+type SuppressionRepository interface {
+	// Upsert adds an address, or replaces the reason and detail of an existing entry
+	Upsert(ctx context.Context, s *Suppression) error
+
+	// FindByEmails returns the entries for the given lower-cased addresses
+	FindByEmails(ctx context.Context, emails []string) ([]*Suppression, error)
+
+	// List returns entries newest first, optionally only those with reason, and the total count
+	List(ctx context.Context, reason SuppressionReason, limit, offset int) ([]*Suppression, int, error)
+
+	// Delete removes an address; sql.ErrNoRows if it is not on the list
+	Delete(ctx context.Context, email string) error
+}
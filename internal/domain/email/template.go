@@ -0,0 +1,64 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// TemplateStatus is the lifecycle state of a stored template version
+type TemplateStatus string
+
+const (
+	// TemplateDraft versions can be edited and are never used for rendering
+	TemplateDraft TemplateStatus = "draft"
+	// TemplatePublished is the version used for rendering; at most one per template
+	TemplatePublished TemplateStatus = "published"
+	// TemplateArchived versions were published before and can be rolled back to
+	TemplateArchived TemplateStatus = "archived"
+)
+
+// TemplateVersion is a stored revision of a catalog template that overrides the
+// embedded file once published. Subject, HTMLBody and TextBody are Go template
+// source; an empty Subject or TextBody falls back to the catalog default.
+type TemplateVersion struct {
+	bun.BaseModel `bun:"table:email_template_versions,alias:etv"`
+
+	ID          uuid.UUID      `bun:"type:uuid,default:uuid_generate_v4(),pk" json:"id"`
+	Name        string         `bun:"type:varchar(100),notnull" json:"name"`
+	Version     int            `bun:"version,notnull" json:"version"`
+	Status      TemplateStatus `bun:"type:varchar(20),notnull" json:"status"`
+	Subject     string         `bun:"type:text,nullzero" json:"subject,omitempty"`
+	HTMLBody    string         `bun:"html_body,type:text,notnull" json:"html_body"`
+	TextBody    string         `bun:"text_body,type:text,nullzero" json:"text_body,omitempty"`
+	CreatedBy   *uuid.UUID     `bun:"type:uuid" json:"created_by,omitempty"`
+	PublishedAt *time.Time     `bun:"type:timestamp" json:"published_at,omitempty"`
+	CreatedAt   time.Time      `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt   time.Time      `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// TemplateRepository defines storage operations for template versions
+type TemplateRepository interface {
+	// CreateVersion stores a new version, numbering it after the latest version of its template
+	CreateVersion(ctx context.Context, v *TemplateVersion) error
+
+	// GetVersion returns one version of a template
+	GetVersion(ctx context.Context, name string, version int) (*TemplateVersion, error)
+
+	// GetPublished returns the published version of a template
+	GetPublished(ctx context.Context, name string) (*TemplateVersion, error)
+
+	// ListVersions returns all versions of a template, newest first
+	ListVersions(ctx context.Context, name string) ([]*TemplateVersion, error)
+
+	// UpdateDraft saves the content of a draft version
+	UpdateDraft(ctx context.Context, v *TemplateVersion) error
+
+	// Publish makes version the published one and archives the previously published version
+	Publish(ctx context.Context, name string, version int, at time.Time) error
+
+	// Unpublish archives the published version so rendering falls back to the embedded file
+	Unpublish(ctx context.Context, name string, at time.Time) error
+}
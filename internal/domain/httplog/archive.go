@@ -0,0 +1,179 @@
+package httplog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/uptrace/bun"
+)
+
+// ArchiveVersion is the version of the archive layout written in manifests
+const ArchiveVersion = 1
+
+// ArchivedTables are the HTTP log tables in an archive, in the order they are imported:
+// requests before the responses and errors referencing them
+var ArchivedTables = []string{"log_incoming_requests", "log_outgoing_requests", "log_errors"}
+
+// Compression is the compression of the NDJSON files of an archive
+type Compression string
+
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// Valid reports whether c is a supported compression
+func (c Compression) Valid() bool {
+	return c == CompressionGzip || c == CompressionZstd
+}
+
+// Extension returns the file extension of NDJSON files compressed with c
+func (c Compression) Extension() string {
+	if c == CompressionZstd {
+		return ".ndjson.zst"
+	}
+	return ".ndjson.gz"
+}
+
+// ContentType returns the content type of files compressed with c
+func (c Compression) ContentType() string {
+	if c == CompressionZstd {
+		return "application/zstd"
+	}
+	return "application/gzip"
+}
+
+// NewWriter returns a writer compressing into w
+func (c Compression) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("unknown compression %q", c)
+}
+
+// NewReader returns a reader decompressing r
+func (c Compression) NewReader(r io.Reader) (io.ReadCloser, error) {
+	switch c {
+	case CompressionGzip:
+		return gzip.NewReader(r)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unknown compression %q", c)
+}
+
+// ArchiveManifest describes an archive of the HTTP logs created in [From, To). It is
+// written once all the files it lists are, so an archive without one is incomplete.
+type ArchiveManifest struct {
+	Version     int           `json:"version"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Compression Compression   `json:"compression"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Files       []ArchiveFile `json:"files"`
+}
+
+// ArchiveFile is the compressed NDJSON file of one table, one row per line
+type ArchiveFile struct {
+	Table  string `json:"table"`
+	Name   string `json:"name"`   // File name, relative to the archive
+	Rows   int64  `json:"rows"`   // Rows in the file
+	Bytes  int64  `json:"bytes"`  // Size of the compressed file
+	SHA256 string `json:"sha256"` // Hex SHA-256 of the compressed file
+}
+
+// ArchiveRepository reads and writes the rows of the HTTP log tables as JSON documents
+type ArchiveRepository interface {
+	// StreamRows calls fn with each row of table created in [from, to) as a JSON document,
+	// oldest first, without loading them all
+	StreamRows(ctx context.Context, table string, from, to time.Time, fn func(row []byte) error) error
+
+	// InsertRows inserts JSON documents of rows of table, skipping rows already there
+	InsertRows(ctx context.Context, table string, rows [][]byte) (int64, error)
+}
+
+type archiveRepository struct {
+	db *bun.DB
+}
+
+// NewArchiveRepository creates a new instance of the HTTP log archive repository
+func NewArchiveRepository(db *bun.DB) ArchiveRepository {
+	return &archiveRepository{db: db}
+}
+
+// StreamRows calls fn with each row of table created in [from, to) as a JSON document
+func (r *archiveRepository) StreamRows(ctx context.Context, table string, from, to time.Time, fn func(row []byte) error) error {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT row_to_json(t)::text FROM ? AS t WHERE created_at >= ? AND created_at < ? ORDER BY created_at, id",
+		bun.Ident(qualified(table)), from, to,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// InsertRows inserts JSON documents of rows of table, skipping rows already there.
+// Responses whose request is not in the database, as it was logged in an earlier
+// archive, lose the reference instead of failing the foreign key.
+func (r *archiveRepository) InsertRows(ctx context.Context, table string, rows [][]byte) (int64, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	document := append(append([]byte("["), bytes.Join(rows, []byte(","))...), ']')
+	target := bun.Ident(qualified(table))
+
+	var inserted int64
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, "CREATE TEMPORARY TABLE archived_rows (LIKE ?) ON COMMIT DROP", target); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO archived_rows SELECT * FROM json_populate_recordset(NULL::archived_rows, ?::json)",
+			string(document),
+		); err != nil {
+			return err
+		}
+
+		if table == "log_outgoing_requests" {
+			if _, err := tx.ExecContext(ctx, `UPDATE archived_rows AS a
+				SET incoming_request_id = NULL, incoming_request_created_at = NULL
+				WHERE a.incoming_request_id IS NOT NULL AND NOT EXISTS (
+					SELECT 1 FROM ? AS r WHERE r.id = a.incoming_request_id AND r.created_at = a.incoming_request_created_at
+				)`, bun.Ident(qualified("log_incoming_requests"))); err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, "INSERT INTO ? SELECT * FROM archived_rows ON CONFLICT DO NOTHING", target)
+		if err != nil {
+			return err
+		}
+		inserted, err = result.RowsAffected()
+		return err
+	})
+	return inserted, err
+}
@@ -0,0 +1,354 @@
+package httplog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"time"
+
+	"base-code-go-gin-clean/internal/pkg/storage"
+)
+
+const (
+	defaultArchivePrefix   = "httplog"
+	defaultImportBatchSize = 500
+
+	manifestName   = "manifest.json"
+	archiveTimeKey = "20060102T150405Z"
+)
+
+// ArchiveService archives the HTTP logs to compressed NDJSON files with a manifest
+type ArchiveService interface {
+	// Archive writes the logs created in [from, to) to an archive, replacing an existing one
+	Archive(ctx context.Context, from, to time.Time) (*ArchiveManifest, error)
+
+	// EnsureArchived archives the logs created in [from, to) unless they already are
+	EnsureArchived(ctx context.Context, from, to time.Time) error
+
+	// Import loads the archive with the given key prefix into the database, after
+	// verifying the checksums of its files
+	Import(ctx context.Context, prefix string) (*ImportResult, error)
+
+	// ArchiveCompletedPeriods archives the ended partition periods of the retention
+	// period that are not archived yet, as a scheduled job
+	ArchiveCompletedPeriods()
+}
+
+// ImportResult reports the rows of each table read from an archive and inserted
+type ImportResult struct {
+	Manifest *ArchiveManifest
+	Read     map[string]int64
+	Inserted map[string]int64
+}
+
+// ArchiveConfig holds the dependencies and settings of the archive service
+type ArchiveConfig struct {
+	Repo          ArchiveRepository
+	Store         storage.BlobStore
+	Prefix        string            // Key prefix of the archives; "httplog" by default
+	Compression   Compression       // gzip by default
+	Interval      PartitionInterval // Periods archived by the scheduled job; daily by default
+	RetentionDays int               // Days looked back by the scheduled job; the last period when 0
+	BatchSize     int               // Rows per insert when importing
+}
+
+type archiveService struct {
+	repo          ArchiveRepository
+	store         storage.BlobStore
+	prefix        string
+	compression   Compression
+	interval      PartitionInterval
+	retentionDays int
+	batchSize     int
+	now           func() time.Time
+}
+
+// NewArchiveService creates a new HTTP log archive service
+func NewArchiveService(cfg ArchiveConfig) ArchiveService {
+	s := &archiveService{
+		repo:          cfg.Repo,
+		store:         cfg.Store,
+		prefix:        cfg.Prefix,
+		compression:   cfg.Compression,
+		interval:      cfg.Interval,
+		retentionDays: max(cfg.RetentionDays, 0),
+		batchSize:     cfg.BatchSize,
+		now:           time.Now,
+	}
+	if s.prefix == "" {
+		s.prefix = defaultArchivePrefix
+	}
+	if !s.compression.Valid() {
+		s.compression = CompressionGzip
+	}
+	if !s.interval.Valid() {
+		s.interval = PartitionDaily
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultImportBatchSize
+	}
+	return s
+}
+
+// ArchiveKey returns the key prefix of the archive of the logs created in [from, to)
+func ArchiveKey(prefix string, from, to time.Time) string {
+	return path.Join(prefix, from.UTC().Format(archiveTimeKey)+"_"+to.UTC().Format(archiveTimeKey))
+}
+
+// Archive streams each table into a compressed NDJSON file, then writes the manifest
+func (s *archiveService) Archive(ctx context.Context, from, to time.Time) (*ArchiveManifest, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("archive range [%s, %s) is empty", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	key := ArchiveKey(s.prefix, from, to)
+	manifest := &ArchiveManifest{
+		Version:     ArchiveVersion,
+		From:        from.UTC(),
+		To:          to.UTC(),
+		Compression: s.compression,
+	}
+	for _, table := range ArchivedTables {
+		file, err := s.archiveTable(ctx, key, table, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive %s: %w", table, err)
+		}
+		manifest.Files = append(manifest.Files, *file)
+	}
+
+	manifest.CreatedAt = s.now().UTC()
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, path.Join(key, manifestName), bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// archiveTable streams the rows of table into a compressed NDJSON file of the store,
+// hashing and counting what is written on the way
+func (s *archiveService) archiveTable(ctx context.Context, key, table string, from, to time.Time) (*ArchiveFile, error) {
+	file := &ArchiveFile{Table: table, Name: table + s.compression.Extension()}
+	hash := sha256.New()
+	counter := &countingWriter{}
+
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := s.writeRows(ctx, io.MultiWriter(writer, hash, counter), table, from, to, &file.Rows)
+		writer.CloseWithError(err)
+		done <- err
+	}()
+
+	err := s.store.Put(ctx, path.Join(key, file.Name), reader, -1, s.compression.ContentType())
+	// Stop the writer if the store gave up reading
+	reader.CloseWithError(err)
+	if writeErr := <-done; writeErr != nil && err == nil {
+		err = writeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file.Bytes = counter.n
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return file, nil
+}
+
+// writeRows writes the rows of table as compressed NDJSON to w
+func (s *archiveService) writeRows(ctx context.Context, w io.Writer, table string, from, to time.Time, rows *int64) error {
+	compressed, err := s.compression.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	buffered := bufio.NewWriterSize(compressed, 64*1024)
+
+	err = s.repo.StreamRows(ctx, table, from, to, func(row []byte) error {
+		*rows++
+		if _, err := buffered.Write(row); err != nil {
+			return err
+		}
+		return buffered.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+// EnsureArchived archives the logs created in [from, to) unless their manifest exists
+func (s *archiveService) EnsureArchived(ctx context.Context, from, to time.Time) error {
+	manifest, err := s.store.Get(ctx, path.Join(ArchiveKey(s.prefix, from, to), manifestName))
+	if err == nil {
+		return manifest.Close()
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	_, err = s.Archive(ctx, from, to)
+	return err
+}
+
+// Import verifies the checksums of the files of an archive, then inserts their rows
+// table by table in batches. Rows already in the database are skipped, so an
+// interrupted import can be run again.
+func (s *archiveService) Import(ctx context.Context, prefix string) (*ImportResult, error) {
+	manifest, err := s.readManifest(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !manifest.Compression.Valid() {
+		return nil, fmt.Errorf("archive %s has an unknown compression %q", prefix, manifest.Compression)
+	}
+
+	for _, file := range manifest.Files {
+		if err := s.verify(ctx, prefix, file); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &ImportResult{Manifest: manifest, Read: map[string]int64{}, Inserted: map[string]int64{}}
+	for _, table := range ArchivedTables {
+		for _, file := range manifest.Files {
+			if file.Table != table {
+				continue
+			}
+			if err := s.importFile(ctx, prefix, manifest.Compression, file, result); err != nil {
+				return result, fmt.Errorf("failed to import %s: %w", file.Name, err)
+			}
+		}
+	}
+	return result, nil
+}
+
+// readManifest reads the manifest of the archive with the given key prefix
+func (s *archiveService) readManifest(ctx context.Context, prefix string) (*ArchiveManifest, error) {
+	blob, err := s.store.Get(ctx, path.Join(prefix, manifestName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the manifest of %s: %w", prefix, err)
+	}
+	defer blob.Close()
+
+	var manifest ArchiveManifest
+	if err := json.NewDecoder(blob).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest of %s: %w", prefix, err)
+	}
+	if manifest.Version != ArchiveVersion {
+		return nil, fmt.Errorf("archive %s has version %d, expected %d", prefix, manifest.Version, ArchiveVersion)
+	}
+	return &manifest, nil
+}
+
+// verify checks the size and checksum of an archived file
+func (s *archiveService) verify(ctx context.Context, prefix string, file ArchiveFile) error {
+	blob, err := s.store.Get(ctx, path.Join(prefix, file.Name))
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file.Name, err)
+	}
+	defer blob.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, blob)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	if n != file.Bytes || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return fmt.Errorf("%s does not match the checksum of the manifest", file.Name)
+	}
+	return nil
+}
+
+// importFile inserts the rows of an archived file in batches
+func (s *archiveService) importFile(ctx context.Context, prefix string, compression Compression, file ArchiveFile, result *ImportResult) error {
+	blob, err := s.store.Get(ctx, path.Join(prefix, file.Name))
+	if err != nil {
+		return err
+	}
+	defer blob.Close()
+
+	decompressed, err := compression.NewReader(blob)
+	if err != nil {
+		return err
+	}
+	defer decompressed.Close()
+
+	var batch [][]byte
+	flush := func() error {
+		inserted, err := s.repo.InsertRows(ctx, file.Table, batch)
+		result.Inserted[file.Table] += inserted
+		batch = batch[:0]
+		return err
+	}
+
+	scanner := bufio.NewScanner(decompressed)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return fmt.Errorf("line %d is not a JSON document", result.Read[file.Table]+1)
+		}
+		result.Read[file.Table]++
+		batch = append(batch, bytes.Clone(line))
+		if len(batch) == s.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if result.Read[file.Table] != file.Rows {
+		return fmt.Errorf("read %d rows, the manifest lists %d", result.Read[file.Table], file.Rows)
+	}
+	return nil
+}
+
+// ArchiveCompletedPeriods archives the ended periods of the retention period that
+// are not archived yet, oldest first, as a scheduled job. Older logs, such as those
+// of a default partition, are archived by the retention job before it deletes them.
+func (s *archiveService) ArchiveCompletedPeriods() {
+	ctx := context.Background()
+	now := s.now().UTC()
+	end := s.interval.Start(now)
+	start := s.interval.Start(now.AddDate(0, 0, -max(s.retentionDays, 1)))
+
+	for from := start; from.Before(end); from = s.interval.Add(from, 1) {
+		to := s.interval.Add(from, 1)
+		if err := s.EnsureArchived(ctx, from, to); err != nil {
+			log.Printf("httplog: archive: failed to archive %s: %v", ArchiveKey(s.prefix, from, to), err)
+		}
+	}
+}
+
+// countingWriter counts the bytes written to it
+type countingWriter struct {
+	n int64
+}
+
+// Write counts p
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
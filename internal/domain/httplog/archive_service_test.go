@@ -0,0 +1,122 @@
+package httplog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"base-code-go-gin-clean/internal/pkg/storage"
+)
+
+// memoryArchive is an in-memory ArchiveRepository
+type memoryArchive struct {
+	rows     map[string][]string
+	inserted map[string][]string
+	streams  int
+}
+
+func (m *memoryArchive) StreamRows(ctx context.Context, table string, from, to time.Time, fn func(row []byte) error) error {
+	m.streams++
+	for _, row := range m.rows[table] {
+		if err := fn([]byte(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryArchive) InsertRows(ctx context.Context, table string, rows [][]byte) (int64, error) {
+	for _, row := range rows {
+		m.inserted[table] = append(m.inserted[table], string(row))
+	}
+	return int64(len(rows)), nil
+}
+
+func newTestArchive(t *testing.T, compression Compression) (*archiveService, *memoryArchive, string) {
+	dir := t.TempDir()
+	store, err := storage.NewLocalStore(dir, "", "test-key")
+	if err != nil {
+		t.Fatalf("local store: %v", err)
+	}
+
+	repo := &memoryArchive{rows: map[string][]string{}, inserted: map[string][]string{}}
+	for i := 0; i < 1200; i++ {
+		repo.rows["log_incoming_requests"] = append(repo.rows["log_incoming_requests"], fmt.Sprintf(`{"id":"req-%d","endpoint":"/api/v1/users"}`, i))
+	}
+	repo.rows["log_errors"] = []string{`{"id":"err-1","error":"boom"}`}
+
+	s := NewArchiveService(ArchiveConfig{Repo: repo, Store: store, Compression: compression, RetentionDays: 3}).(*archiveService)
+	s.now = func() time.Time { return time.Date(2025, 8, 25, 0, 5, 0, 0, time.UTC) }
+	return s, repo, dir
+}
+
+func TestArchiveService_RoundTrip(t *testing.T) {
+	from := time.Date(2025, 8, 24, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	for _, compression := range []Compression{CompressionGzip, CompressionZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			s, repo, dir := newTestArchive(t, compression)
+
+			manifest, err := s.Archive(context.Background(), from, to)
+			if !assert.NoError(t, err) {
+				return
+			}
+			key := ArchiveKey("httplog", from, to)
+			assert.Equal(t, "httplog/20250824T000000Z_20250825T000000Z", key)
+			assert.FileExists(t, filepath.Join(dir, key, "manifest.json"))
+			if !assert.Len(t, manifest.Files, 3) {
+				return
+			}
+			assert.Equal(t, "log_incoming_requests"+compression.Extension(), manifest.Files[0].Name)
+			assert.EqualValues(t, 1200, manifest.Files[0].Rows)
+			assert.EqualValues(t, 0, manifest.Files[1].Rows)
+			assert.Len(t, manifest.Files[0].SHA256, 64)
+
+			result, err := s.Import(context.Background(), key)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, repo.rows["log_incoming_requests"], repo.inserted["log_incoming_requests"])
+			assert.Equal(t, repo.rows["log_errors"], repo.inserted["log_errors"])
+			assert.EqualValues(t, 1200, result.Inserted["log_incoming_requests"])
+		})
+	}
+}
+
+func TestArchiveService_ImportVerifiesChecksums(t *testing.T) {
+	from := time.Date(2025, 8, 24, 0, 0, 0, 0, time.UTC)
+	s, repo, dir := newTestArchive(t, CompressionGzip)
+	if _, err := s.Archive(context.Background(), from, from.AddDate(0, 0, 1)); !assert.NoError(t, err) {
+		return
+	}
+
+	key := ArchiveKey("httplog", from, from.AddDate(0, 0, 1))
+	file := filepath.Join(dir, key, "log_errors.ndjson.gz")
+	data, _ := os.ReadFile(file)
+	data[len(data)-1] ^= 0xff
+	_ = os.WriteFile(file, data, 0o644)
+
+	_, err := s.Import(context.Background(), key)
+
+	assert.ErrorContains(t, err, "log_errors.ndjson.gz does not match the checksum")
+	assert.Empty(t, repo.inserted, "nothing is imported from a damaged archive")
+}
+
+func TestArchiveService_ArchiveCompletedPeriods(t *testing.T) {
+	s, repo, dir := newTestArchive(t, CompressionGzip)
+
+	s.ArchiveCompletedPeriods()
+	assert.Equal(t, 3*len(ArchivedTables), repo.streams, "the ended days of the retention period")
+	for _, day := range []string{"20250822T000000Z_20250823T000000Z", "20250823T000000Z_20250824T000000Z", "20250824T000000Z_20250825T000000Z"} {
+		assert.FileExists(t, filepath.Join(dir, "httplog", day, "manifest.json"))
+	}
+
+	s.ArchiveCompletedPeriods()
+	assert.Equal(t, 3*len(ArchivedTables), repo.streams, "archived periods are not archived again")
+}
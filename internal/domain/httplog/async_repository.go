@@ -0,0 +1,347 @@
+package httplog
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OverflowPolicy decides what happens to a log when the queue of an AsyncRepository is full
+type OverflowPolicy string
+
+const (
+	// OverflowDrop discards the log and counts it as dropped
+	OverflowDrop OverflowPolicy = "drop"
+	// OverflowBlock makes the caller wait for room in the queue until its context is done
+	OverflowBlock OverflowPolicy = "block"
+)
+
+var (
+	// ErrQueueFull is returned when a log is dropped because the queue is full
+	ErrQueueFull = errors.New("httplog: log queue is full")
+	// ErrRepositoryClosed is returned when a log is written after Close
+	ErrRepositoryClosed = errors.New("httplog: log repository is closed")
+)
+
+const (
+	defaultQueueSize     = 10000
+	defaultBatchSize     = 500
+	defaultFlushInterval = time.Second
+	batchWriteTimeout    = 10 * time.Second
+	batchWriteAttempts   = 3
+	batchRetryDelay      = 200 * time.Millisecond
+)
+
+// AsyncConfig configures an AsyncRepository
+type AsyncConfig struct {
+	QueueSize     int            // Logs buffered before the overflow policy applies
+	BatchSize     int            // Logs written per bulk insert
+	FlushInterval time.Duration  // Maximum time a log waits in the queue
+	Overflow      OverflowPolicy // Drop or block when the queue is full
+}
+
+// AsyncStats are the counters of an AsyncRepository
+type AsyncStats struct {
+	Queued  int    `json:"queued"`
+	Written uint64 `json:"written"`
+	Dropped uint64 `json:"dropped"`
+	Failed  uint64 `json:"failed"`
+}
+
+// AsyncRepository takes logs off the request path. Writes are put on a bounded
+// queue and bulk inserted by a background goroutine in batches; reads go straight
+// to the wrapped repository. Logs are written in the order they were queued, so
+// a response or an error is never written before the incoming request it references.
+type AsyncRepository struct {
+	Repository
+	cfg   AsyncConfig
+	queue chan any
+	done  chan struct{}
+
+	// lost holds the IDs of incoming requests that failed to be written; responses
+	// and errors queued after them are written without the reference
+	lost map[string]struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	written  atomic.Uint64
+	dropped  atomic.Uint64
+	failed   atomic.Uint64
+	reported uint64
+}
+
+// NewAsyncRepository wraps repo in an AsyncRepository and starts its writer.
+// Call Close to write the logs still queued on shutdown.
+func NewAsyncRepository(repo Repository, cfg AsyncConfig) *AsyncRepository {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = OverflowDrop
+	}
+
+	r := &AsyncRepository{
+		Repository: repo,
+		cfg:        cfg,
+		queue:      make(chan any, cfg.QueueSize),
+		done:       make(chan struct{}),
+		lost:       make(map[string]struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// LogOutgoingRequest queues an outgoing HTTP request
+func (r *AsyncRepository) LogOutgoingRequest(ctx context.Context, log *LogOutgoingRequest) error {
+	return r.enqueue(ctx, log)
+}
+
+// LogIncomingRequest queues an incoming HTTP request and returns its log ID,
+// which is assigned up front as the row is not inserted yet
+func (r *AsyncRepository) LogIncomingRequest(ctx context.Context, log *LogIncomingRequest) (string, error) {
+	if log.ID == "" {
+		log.ID = uuid.New().String()
+	}
+	if err := r.enqueue(ctx, log); err != nil {
+		return "", err
+	}
+	return log.ID, nil
+}
+
+// LogError queues an error that occurred during request processing
+func (r *AsyncRepository) LogError(ctx context.Context, log *LogError) error {
+	if log.ID == "" {
+		log.ID = uuid.New().String()
+	}
+	return r.enqueue(ctx, log)
+}
+
+// Stats returns the number of queued logs and the counters since start
+func (r *AsyncRepository) Stats() AsyncStats {
+	return AsyncStats{
+		Queued:  len(r.queue),
+		Written: r.written.Load(),
+		Dropped: r.dropped.Load(),
+		Failed:  r.failed.Load(),
+	}
+}
+
+// Close stops accepting logs and waits until the queued ones are written or ctx is done
+func (r *AsyncRepository) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue puts a log on the queue, applying the overflow policy when it is full
+func (r *AsyncRepository) enqueue(ctx context.Context, entry any) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		r.dropped.Add(1)
+		return ErrRepositoryClosed
+	}
+
+	select {
+	case r.queue <- entry:
+		return nil
+	default:
+	}
+
+	if r.cfg.Overflow == OverflowBlock {
+		select {
+		case r.queue <- entry:
+			return nil
+		case <-ctx.Done():
+		}
+	}
+
+	r.dropped.Add(1)
+	return ErrQueueFull
+}
+
+// run collects queued logs into batches and writes them when a batch is full,
+// on every flush interval and once more when the queue is closed
+func (r *AsyncRepository) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := &Batch{}
+	for {
+		select {
+		case entry, ok := <-r.queue:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			switch e := entry.(type) {
+			case *LogIncomingRequest:
+				batch.Incoming = append(batch.Incoming, e)
+			case *LogOutgoingRequest:
+				r.unlinkLost(e)
+				batch.Outgoing = append(batch.Outgoing, e)
+			case *LogError:
+				r.unlinkLost(e)
+				batch.Errors = append(batch.Errors, e)
+			}
+			if batch.Len() >= r.cfg.BatchSize {
+				r.flush(batch)
+				batch = &Batch{}
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch = &Batch{}
+		}
+	}
+}
+
+// flush writes a batch and reports logs dropped since the last flush. A batch
+// rejected by the database for the data of a row is written row by row, so that
+// only the offending logs are lost.
+func (r *AsyncRepository) flush(batch *Batch) {
+	if dropped := r.dropped.Load(); dropped > r.reported {
+		log.Printf("httplog: dropped %d logs (queue size %d)", dropped-r.reported, r.cfg.QueueSize)
+		r.reported = dropped
+	}
+
+	n := batch.Len()
+	if n == 0 {
+		return
+	}
+
+	err := r.writeBatch(batch)
+	switch {
+	case err == nil:
+		r.written.Add(uint64(n))
+	case rejected(err):
+		log.Printf("httplog: batch of %d logs rejected, writing them one by one: %v", n, err)
+		r.writeRows(batch)
+	default:
+		r.failed.Add(uint64(n))
+		log.Printf("httplog: failed to write %d logs: %v", n, err)
+		r.markLost(batch.Incoming...)
+	}
+}
+
+// writeBatch writes a batch, retrying with a growing delay when the failure may be
+// transient, like a lost connection or a timeout
+func (r *AsyncRepository) writeBatch(batch *Batch) error {
+	var err error
+	for attempt := 1; attempt <= batchWriteAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * batchRetryDelay)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), batchWriteTimeout)
+		err = r.Repository.WriteBatch(ctx, batch)
+		cancel()
+		if err == nil || rejected(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// writeRows writes the logs of a rejected batch one per batch, requests first, and
+// drops the references to requests that failed to be written
+func (r *AsyncRepository) writeRows(batch *Batch) {
+	var failed int
+	write := func(row *Batch) bool {
+		if err := r.writeBatch(row); err != nil {
+			failed++
+			log.Printf("httplog: failed to write log: %v", err)
+			return false
+		}
+		r.written.Add(1)
+		return true
+	}
+
+	for _, req := range batch.Incoming {
+		if !write(&Batch{Incoming: []*LogIncomingRequest{req}}) {
+			r.markLost(req)
+		}
+	}
+	for _, res := range batch.Outgoing {
+		r.unlinkLost(res)
+		write(&Batch{Outgoing: []*LogOutgoingRequest{res}})
+	}
+	for _, e := range batch.Errors {
+		r.unlinkLost(e)
+		write(&Batch{Errors: []*LogError{e}})
+	}
+
+	if failed > 0 {
+		r.failed.Add(uint64(failed))
+		log.Printf("httplog: failed to write %d of %d logs", failed, batch.Len())
+	}
+}
+
+// markLost remembers incoming requests that failed to be written
+func (r *AsyncRepository) markLost(reqs ...*LogIncomingRequest) {
+	// Forget about requests lost long ago rather than grow without bounds
+	if len(r.lost)+len(reqs) > r.cfg.QueueSize {
+		clear(r.lost)
+	}
+	for _, req := range reqs {
+		r.lost[req.ID] = struct{}{}
+	}
+}
+
+// unlinkLost drops the reference of a response or an error to an incoming request
+// that failed to be written, which would otherwise fail its batch too or point
+// at nothing
+func (r *AsyncRepository) unlinkLost(entry any) {
+	switch e := entry.(type) {
+	case *LogOutgoingRequest:
+		if e.IncomingRequestID == nil {
+			return
+		}
+		if _, ok := r.lost[*e.IncomingRequestID]; ok {
+			e.IncomingRequestID = nil
+			e.IncomingRequestCreatedAt = nil
+		}
+	case *LogError:
+		if e.RequestID == nil {
+			return
+		}
+		if _, ok := r.lost[*e.RequestID]; ok {
+			e.RequestID = nil
+		}
+	}
+}
+
+// rejected reports whether err is a PostgreSQL error caused by the data written,
+// such as a constraint violation or an invalid value, which a retry cannot fix
+func rejected(err error) bool {
+	var pgErr interface{ Field(byte) string }
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	code := pgErr.Field('C')
+	return strings.HasPrefix(code, "22") || strings.HasPrefix(code, "23")
+}
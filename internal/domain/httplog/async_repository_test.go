@@ -0,0 +1,230 @@
+package httplog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecorder is a Repository that records the batches written to it
+type batchRecorder struct {
+	Repository
+	mu       sync.Mutex
+	batches  []*Batch
+	block    chan struct{}
+	err      error
+	failures int                // Batches failing with err before the writes succeed
+	reject   func(*Batch) error // Fails the batches it returns an error for, before err applies
+}
+
+func (r *batchRecorder) WriteBatch(ctx context.Context, batch *Batch) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, batch)
+	if r.reject != nil {
+		if err := r.reject(batch); err != nil {
+			return err
+		}
+	}
+	if r.failures > 0 && len(r.batches) > r.failures {
+		return nil
+	}
+	return r.err
+}
+
+func (r *batchRecorder) written() []*Batch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Batch(nil), r.batches...)
+}
+
+func TestAsyncRepository_WritesInBatches(t *testing.T) {
+	recorder := &batchRecorder{}
+	repo := NewAsyncRepository(recorder, AsyncConfig{BatchSize: 3, FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	id, err := repo.LogIncomingRequest(ctx, &LogIncomingRequest{TraceID: "t1"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, id)
+	assert.NoError(t, repo.LogError(ctx, &LogError{TraceID: "t1", RequestID: &id}))
+	assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{TraceID: "t1"}))
+	assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{TraceID: "t2"}))
+
+	// The first three logs fill a batch; the fourth is written on Close
+	if !assert.NoError(t, repo.Close(ctx)) {
+		return
+	}
+	batches := recorder.written()
+	if !assert.Len(t, batches, 2) {
+		return
+	}
+	assert.Equal(t, id, batches[0].Incoming[0].ID)
+	assert.Equal(t, &id, batches[0].Errors[0].RequestID)
+	assert.NotEmpty(t, batches[0].Errors[0].ID)
+	assert.Equal(t, "t2", batches[1].Outgoing[0].TraceID)
+	assert.Equal(t, AsyncStats{Written: 4}, repo.Stats())
+
+	_, err = repo.LogIncomingRequest(ctx, &LogIncomingRequest{})
+	assert.ErrorIs(t, err, ErrRepositoryClosed)
+}
+
+func TestAsyncRepository_FlushInterval(t *testing.T) {
+	recorder := &batchRecorder{}
+	repo := NewAsyncRepository(recorder, AsyncConfig{FlushInterval: 10 * time.Millisecond})
+	defer repo.Close(context.Background())
+
+	assert.NoError(t, repo.LogOutgoingRequest(context.Background(), &LogOutgoingRequest{TraceID: "t1"}))
+	assert.Eventually(t, func() bool { return len(recorder.written()) == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestAsyncRepository_Overflow(t *testing.T) {
+	t.Run("drop counts logs that do not fit", func(t *testing.T) {
+		recorder := &batchRecorder{block: make(chan struct{})}
+		repo := NewAsyncRepository(recorder, AsyncConfig{QueueSize: 1, BatchSize: 1, Overflow: OverflowDrop})
+		ctx := context.Background()
+
+		// The writer holds the first log while the second fills the queue
+		assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}))
+		assert.Eventually(t, func() bool { return repo.Stats().Queued == 0 }, time.Second, time.Millisecond)
+		assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}))
+
+		_, err := repo.LogIncomingRequest(ctx, &LogIncomingRequest{})
+		assert.ErrorIs(t, err, ErrQueueFull)
+		assert.ErrorIs(t, repo.LogError(ctx, &LogError{}), ErrQueueFull)
+		assert.Equal(t, uint64(2), repo.Stats().Dropped)
+
+		close(recorder.block)
+		assert.NoError(t, repo.Close(ctx))
+		assert.Equal(t, AsyncStats{Written: 2, Dropped: 2}, repo.Stats())
+	})
+
+	t.Run("block waits for room until the context is done", func(t *testing.T) {
+		recorder := &batchRecorder{block: make(chan struct{})}
+		repo := NewAsyncRepository(recorder, AsyncConfig{QueueSize: 1, BatchSize: 1, Overflow: OverflowBlock})
+		ctx := context.Background()
+
+		assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}))
+		assert.Eventually(t, func() bool { return repo.Stats().Queued == 0 }, time.Second, time.Millisecond)
+		assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}))
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, repo.LogOutgoingRequest(timeoutCtx, &LogOutgoingRequest{}), ErrQueueFull)
+
+		blocked := make(chan error, 1)
+		go func() { blocked <- repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}) }()
+		close(recorder.block)
+		assert.NoError(t, <-blocked)
+
+		assert.NoError(t, repo.Close(ctx))
+		assert.Equal(t, AsyncStats{Written: 3, Dropped: 1}, repo.Stats())
+	})
+}
+
+func TestAsyncRepository_FailedBatch(t *testing.T) {
+	recorder := &batchRecorder{err: errors.New("connection refused")}
+	repo := NewAsyncRepository(recorder, AsyncConfig{})
+	ctx := context.Background()
+
+	_, err := repo.LogIncomingRequest(ctx, &LogIncomingRequest{})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}))
+
+	assert.NoError(t, repo.Close(ctx))
+	assert.Equal(t, AsyncStats{Failed: 2}, repo.Stats())
+}
+
+func TestAsyncRepository_UnlinksResponsesOfLostRequests(t *testing.T) {
+	recorder := &batchRecorder{err: errors.New("connection refused"), failures: batchWriteAttempts}
+	repo := NewAsyncRepository(recorder, AsyncConfig{BatchSize: 1})
+	ctx := context.Background()
+
+	lost := &LogIncomingRequest{CreatedAt: time.Now()}
+	_, err := repo.LogIncomingRequest(ctx, lost)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{IncomingRequestID: &lost.ID, IncomingRequestCreatedAt: &lost.CreatedAt}))
+	assert.NoError(t, repo.LogError(ctx, &LogError{RequestID: &lost.ID}))
+
+	kept := &LogIncomingRequest{CreatedAt: time.Now()}
+	_, err = repo.LogIncomingRequest(ctx, kept)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{IncomingRequestID: &kept.ID, IncomingRequestCreatedAt: &kept.CreatedAt}))
+
+	assert.NoError(t, repo.Close(ctx))
+	batches := recorder.written()[batchWriteAttempts:]
+	if !assert.Len(t, batches, 4) {
+		return
+	}
+	assert.Nil(t, batches[0].Outgoing[0].IncomingRequestID, "the response of the lost request is written without the reference")
+	assert.Nil(t, batches[0].Outgoing[0].IncomingRequestCreatedAt)
+	assert.Nil(t, batches[1].Errors[0].RequestID, "the error of the lost request is written without the reference")
+	assert.Equal(t, &kept.ID, batches[3].Outgoing[0].IncomingRequestID)
+	assert.Equal(t, AsyncStats{Written: 4, Failed: 1}, repo.Stats())
+}
+
+func TestAsyncRepository_RetriesFailedBatches(t *testing.T) {
+	recorder := &batchRecorder{err: errors.New("connection reset by peer"), failures: 1}
+	repo := NewAsyncRepository(recorder, AsyncConfig{})
+	ctx := context.Background()
+
+	assert.NoError(t, repo.LogOutgoingRequest(ctx, &LogOutgoingRequest{}))
+
+	assert.NoError(t, repo.Close(ctx))
+	assert.Len(t, recorder.written(), 2)
+	assert.Equal(t, AsyncStats{Written: 1}, repo.Stats())
+}
+
+// pgError is a PostgreSQL error with an SQLSTATE code
+type pgError string
+
+func (e pgError) Error() string { return "ERROR: SQLSTATE " + string(e) }
+
+func (e pgError) Field(k byte) string {
+	if k == 'C' {
+		return string(e)
+	}
+	return ""
+}
+
+func TestAsyncRepository_WritesRejectedBatchesRowByRow(t *testing.T) {
+	recorder := &batchRecorder{reject: func(batch *Batch) error {
+		for _, req := range batch.Incoming {
+			if req.TraceID == "bad" {
+				return fmt.Errorf("insert failed: %w", pgError("23505"))
+			}
+		}
+		return nil
+	}}
+	repo := NewAsyncRepository(recorder, AsyncConfig{BatchSize: 4, FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	bad := &LogIncomingRequest{TraceID: "bad"}
+	_, err := repo.LogIncomingRequest(ctx, bad)
+	assert.NoError(t, err)
+	good := &LogIncomingRequest{TraceID: "good"}
+	_, err = repo.LogIncomingRequest(ctx, good)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.LogError(ctx, &LogError{RequestID: &bad.ID}))
+	assert.NoError(t, repo.LogError(ctx, &LogError{RequestID: &good.ID}))
+
+	assert.NoError(t, repo.Close(ctx))
+	batches := recorder.written()
+	// The rejected batch is not retried, then each log is written on its own
+	if !assert.Len(t, batches, 5) {
+		return
+	}
+	assert.Equal(t, "good", batches[2].Incoming[0].TraceID)
+	assert.Nil(t, batches[3].Errors[0].RequestID, "the error of the rejected request is written without the reference")
+	assert.Equal(t, &good.ID, batches[4].Errors[0].RequestID)
+	assert.Equal(t, AsyncStats{Written: 3, Failed: 1}, repo.Stats())
+}
@@ -18,9 +18,18 @@ const (
 	TRACE   HTTPMethod = "TRACE"
 )
 
+// Valid reports whether m is a known HTTP method
+func (m HTTPMethod) Valid() bool {
+	switch m {
+	case GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, TRACE:
+		return true
+	}
+	return false
+}
+
 // LogOutgoingRequest represents an outgoing HTTP request made by the application
 type LogOutgoingRequest struct {
-	ID         int64       `bun:",pk,autoincrement"` // Gunakan autoincrement
+	ID         int64       `bun:",pk,autoincrement" json:"id"` // Gunakan autoincrement
 	TraceID    string      `pg:",notnull" json:"trace_id"`
 	EventName  string      `pg:",notnull" json:"event_name"`
 	Endpoint   string      `pg:",notnull" json:"endpoint"`
@@ -28,13 +37,21 @@ type LogOutgoingRequest struct {
 	Request    interface{} `pg:",type:jsonb,notnull" json:"request"`
 	StatusCode int         `pg:",notnull" json:"status_code"`
 	Response   interface{} `pg:",type:jsonb" json:"response,omitempty"`
-	CreatedAt  time.Time   `pg:",notnull,default:now()" json:"created_at"`
-	UpdatedAt  time.Time   `pg:",notnull,default:now()" json:"updated_at"`
+
+	// IncomingRequestID and IncomingRequestCreatedAt reference the request this is the
+	// response to; both are nil for calls made to other services
+	IncomingRequestID        *string    `bun:",type:uuid" json:"incoming_request_id,omitempty"`
+	IncomingRequestCreatedAt *time.Time `json:"-"`
+	DurationMs               int64      `json:"duration_ms"`
+	ResponseSize             int64      `json:"response_size"` // Bytes of the response body
+
+	CreatedAt time.Time `pg:",notnull,default:now()" json:"created_at"`
+	UpdatedAt time.Time `pg:",notnull,default:now()" json:"updated_at"`
 }
 
 // LogIncomingRequest represents an incoming HTTP request to the application
 type LogIncomingRequest struct {
-	ID        string      `bun:",pk,type:uuid,default:uuid_generate_v4()"`
+	ID        string      `bun:",pk,type:uuid,default:uuid_generate_v4()" json:"id"`
 	TraceID   string      `pg:",notnull" json:"trace_id"`
 	EventName string      `pg:",notnull" json:"event_name"`
 	Endpoint  string      `pg:",notnull" json:"endpoint"`
@@ -42,13 +59,18 @@ type LogIncomingRequest struct {
 	Request   interface{} `pg:",type:jsonb,notnull" json:"request"`
 	IPAddress string      `pg:"ip_address" json:"ip_address,omitempty"`
 	UserAgent string      `pg:"user_agent" json:"user_agent,omitempty"`
-	CreatedAt time.Time   `pg:",notnull,default:now()" json:"created_at"`
-	UpdatedAt time.Time   `pg:",notnull,default:now()" json:"updated_at"`
+
+	UserID      *string `bun:",type:uuid" json:"user_id,omitempty"` // Nil for anonymous requests
+	XRequestID  string  `bun:",nullzero" json:"x_request_id,omitempty"`
+	RequestSize int64   `json:"request_size"` // Bytes of the request body
+
+	CreatedAt time.Time `pg:",notnull,default:now()" json:"created_at"`
+	UpdatedAt time.Time `pg:",notnull,default:now()" json:"updated_at"`
 }
 
 // LogError represents an error that occurred during request processing
 type LogError struct {
-	ID         string      `bun:",pk,type:uuid,default:uuid_generate_v4()"`
+	ID         string      `bun:",pk,type:uuid,default:uuid_generate_v4()" json:"id"`
 	TraceID    string      `pg:",notnull" json:"trace_id"`
 	StatusCode int         `pg:",notnull" json:"status_code"`
 	Error      string      `pg:",notnull" json:"error"`
@@ -57,3 +79,15 @@ type LogError struct {
 	CreatedAt  time.Time   `pg:",notnull,default:now()" json:"created_at"`
 	UpdatedAt  time.Time   `pg:",notnull,default:now()" json:"updated_at"`
 }
+
+// Batch is a set of logs written together by Repository.WriteBatch
+type Batch struct {
+	Incoming []*LogIncomingRequest
+	Outgoing []*LogOutgoingRequest
+	Errors   []*LogError
+}
+
+// Len returns the number of logs in the batch
+func (b *Batch) Len() int {
+	return len(b.Incoming) + len(b.Outgoing) + len(b.Errors)
+}
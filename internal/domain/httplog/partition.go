@@ -0,0 +1,198 @@
+package httplog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Schema is the database schema of the HTTP log tables
+const Schema = "httplog"
+
+// PartitionedTables are the HTTP log tables partitioned by created_at, responses
+// first so that their partitions are dropped before those of the requests they reference
+var PartitionedTables = []string{"log_outgoing_requests", "log_errors", "log_incoming_requests"}
+
+// PartitionInterval is the time range covered by one partition
+type PartitionInterval string
+
+const (
+	PartitionDaily   PartitionInterval = "day"
+	PartitionMonthly PartitionInterval = "month"
+)
+
+// Valid reports whether i is a known interval
+func (i PartitionInterval) Valid() bool {
+	return i == PartitionDaily || i == PartitionMonthly
+}
+
+// Start returns the start of the partition range containing t
+func (i PartitionInterval) Start(t time.Time) time.Time {
+	t = t.UTC()
+	if i == PartitionMonthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Add moves the start of a partition range n ranges ahead
+func (i PartitionInterval) Add(start time.Time, n int) time.Time {
+	if i == PartitionMonthly {
+		return start.AddDate(0, n, 0)
+	}
+	return start.AddDate(0, 0, n)
+}
+
+// Partition is one range partition of an HTTP log table
+type Partition struct {
+	Table string
+	Name  string
+	From  time.Time // Inclusive
+	To    time.Time // Exclusive
+}
+
+// NewPartition returns the partition of table covering the range of interval that starts at from.
+// Partitions are named after their table and start, e.g. log_errors_p20250825 or log_errors_p202508.
+func NewPartition(table string, interval PartitionInterval, from time.Time) Partition {
+	layout := "20060102"
+	if interval == PartitionMonthly {
+		layout = "200601"
+	}
+	return Partition{
+		Table: table,
+		Name:  table + "_p" + from.Format(layout),
+		From:  from,
+		To:    interval.Add(from, 1),
+	}
+}
+
+// ParsePartition recovers the range of a partition from its name. It reports
+// false for partitions not named by NewPartition, such as the default partition.
+func ParsePartition(table, name string) (Partition, bool) {
+	suffix, ok := strings.CutPrefix(name, table+"_p")
+	if !ok {
+		return Partition{}, false
+	}
+	for interval, layout := range map[PartitionInterval]string{PartitionDaily: "20060102", PartitionMonthly: "200601"} {
+		if len(suffix) != len(layout) {
+			continue
+		}
+		from, err := time.Parse(layout, suffix)
+		if err != nil {
+			return Partition{}, false
+		}
+		return NewPartition(table, interval, from), true
+	}
+	return Partition{}, false
+}
+
+// PartitionRepository manages the partitions of the HTTP log tables
+type PartitionRepository interface {
+	// IsPartitioned reports whether table is a partitioned table
+	IsPartitioned(ctx context.Context, table string) (bool, error)
+
+	// ListPartitions returns the names of the partitions of table
+	ListPartitions(ctx context.Context, table string) ([]string, error)
+
+	// CreatePartition creates and attaches a partition, moving the rows of its range out of
+	// the default partition
+	CreatePartition(ctx context.Context, partition Partition) error
+
+	// DropPartition drops a partition with all its rows
+	DropPartition(ctx context.Context, partition Partition) error
+}
+
+type partitionRepository struct {
+	db *bun.DB
+}
+
+// NewPartitionRepository creates a new instance of the HTTP log partition repository
+func NewPartitionRepository(db *bun.DB) PartitionRepository {
+	return &partitionRepository{db: db}
+}
+
+// IsPartitioned reports whether table is a partitioned table
+func (r *partitionRepository) IsPartitioned(ctx context.Context, table string) (bool, error) {
+	var partitioned bool
+	err := r.db.NewRaw(
+		"SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = to_regclass(?))",
+		qualified(table),
+	).Scan(ctx, &partitioned)
+	return partitioned, err
+}
+
+// ListPartitions returns the names of the partitions of table
+func (r *partitionRepository) ListPartitions(ctx context.Context, table string) ([]string, error) {
+	var names []string
+	err := r.db.NewRaw(
+		"SELECT c.relname FROM pg_inherits AS i JOIN pg_class AS c ON c.oid = i.inhrelid WHERE i.inhparent = to_regclass(?) ORDER BY c.relname",
+		qualified(table),
+	).Scan(ctx, &names)
+	return names, err
+}
+
+// CreatePartition creates and attaches a partition. Rows of its range that were written
+// to the default partition before it existed are moved into it, as attaching the
+// partition fails while the default partition holds any of them.
+func (r *partitionRepository) CreatePartition(ctx context.Context, partition Partition) error {
+	table := bun.Ident(qualified(partition.Table))
+	name := bun.Ident(qualified(partition.Name))
+	defaultPartition := qualified(partition.Table + "_default")
+
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, "CREATE TABLE ? (LIKE ? INCLUDING DEFAULTS INCLUDING CONSTRAINTS)", name, table); err != nil {
+			return err
+		}
+
+		var hasDefault bool
+		if err := tx.NewRaw("SELECT to_regclass(?) IS NOT NULL", defaultPartition).Scan(ctx, &hasDefault); err != nil {
+			return err
+		}
+		if hasDefault {
+			if _, err := tx.ExecContext(ctx,
+				"WITH moved AS (DELETE FROM ? WHERE created_at >= ? AND created_at < ? RETURNING *) INSERT INTO ? SELECT * FROM moved",
+				bun.Ident(defaultPartition), partition.From, partition.To, name,
+			); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, "ALTER TABLE ? ATTACH PARTITION ? FOR VALUES FROM (?) TO (?)",
+			table, name, partition.From, partition.To)
+		return err
+	})
+}
+
+// DropPartition drops a partition with all its rows. Responses logged after the end
+// of a partition of incoming requests may still reference its requests; they lose
+// the reference first, as a referenced partition cannot be dropped.
+func (r *partitionRepository) DropPartition(ctx context.Context, partition Partition) error {
+	if !strings.HasPrefix(partition.Name, partition.Table+"_") {
+		return fmt.Errorf("%s is not a partition of %s", partition.Name, partition.Table)
+	}
+
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if partition.Table == "log_incoming_requests" {
+			if _, err := tx.NewUpdate().
+				Model((*LogOutgoingRequest)(nil)).
+				Set("incoming_request_id = NULL").
+				Set("incoming_request_created_at = NULL").
+				Where("incoming_request_created_at >= ?", partition.From).
+				Where("incoming_request_created_at < ?", partition.To).
+				Exec(ctx); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS ?", bun.Ident(qualified(partition.Name)))
+		return err
+	})
+}
+
+// qualified prefixes a table name with the HTTP log schema
+func qualified(table string) string {
+	return Schema + "." + table
+}
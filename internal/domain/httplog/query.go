@@ -0,0 +1,94 @@
+package httplog
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRequestNotFound is returned when no incoming request has the given ID
+	ErrRequestNotFound = errors.New("request log not found")
+	// ErrInvalidFilter is returned for malformed search filters and cursors
+	ErrInvalidFilter = errors.New("invalid log filter")
+)
+
+// RequestFilter selects incoming requests; zero fields match everything
+type RequestFilter struct {
+	TraceID       string
+	Endpoint      string // URL path or gin route, or a path prefix ending in *
+	Method        HTTPMethod
+	StatusMin     int    // Lowest response status code, inclusive
+	StatusMax     int    // Highest response status code, inclusive
+	IPAddress     string // Client IP address or CIDR range
+	UserID        string // Authenticated user ID
+	MinDurationMs int    // Slowest requests only: lowest time to respond, inclusive
+	Since         *time.Time
+	Until         *time.Time
+	After         *Cursor // Continue after this request
+	Limit         int
+}
+
+// RequestSummary is an incoming request with the status code and duration of its response
+type RequestSummary struct {
+	ID         string     `bun:"id" json:"id"`
+	TraceID    string     `bun:"trace_id" json:"trace_id"`
+	EventName  string     `bun:"event_name" json:"event_name"`
+	Endpoint   string     `bun:"endpoint" json:"endpoint"`
+	Method     HTTPMethod `bun:"method" json:"method"`
+	StatusCode *int       `bun:"status_code" json:"status_code,omitempty"` // Nil while the response is not logged
+	DurationMs *int64     `bun:"duration_ms" json:"duration_ms,omitempty"` // Nil while the response is not logged
+	IPAddress  string     `bun:"ip_address" json:"ip_address,omitempty"`
+	UserAgent  string     `bun:"user_agent" json:"user_agent,omitempty"`
+	UserID     *string    `bun:"user_id" json:"user_id,omitempty"`
+	XRequestID *string    `bun:"x_request_id" json:"x_request_id,omitempty"`
+	CreatedAt  time.Time  `bun:"created_at" json:"created_at"`
+}
+
+// RequestPage is one page of search results, newest first
+type RequestPage struct {
+	Requests   []*RequestSummary `json:"requests"`
+	NextCursor string            `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// RequestDetail stitches together an incoming request, its response and its errors
+type RequestDetail struct {
+	Request  *LogIncomingRequest `json:"request"`
+	Response *LogOutgoingRequest `json:"response,omitempty"`
+	Errors   []*LogError         `json:"errors"`
+}
+
+// Cursor is the position of a request in the newest-first search order
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// String encodes the cursor for use in URLs
+func (c Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID))
+}
+
+// ParseCursor decodes a cursor returned as RequestPage.NextCursor
+func ParseCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidFilter)
+	}
+	return &Cursor{CreatedAt: t, ID: id}, nil
+}
@@ -1,6 +1,9 @@
 package httplog
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository defines the interface for HTTP log storage operations
 type Repository interface {
@@ -13,6 +16,10 @@ type Repository interface {
 	// LogError logs an error that occurred during request processing
 	LogError(ctx context.Context, log *LogError) error
 
+	// WriteBatch bulk inserts a batch of logs in one transaction. Incoming requests
+	// are written first so that errors in the same batch can reference them.
+	WriteBatch(ctx context.Context, batch *Batch) error
+
 	// FindOutgoingRequestByTraceID finds outgoing requests by trace ID
 	FindOutgoingRequestByTraceID(ctx context.Context, traceID string) ([]*LogOutgoingRequest, error)
 
@@ -25,6 +32,27 @@ type Repository interface {
 	// FindRequestWithErrors finds a request and its associated errors
 	FindRequestWithErrors(ctx context.Context, requestID string) (*LogIncomingRequest, []*LogError, error)
 
+	// SearchIncomingRequests returns the requests matching filter with the status codes of
+	// their responses, newest first
+	SearchIncomingRequests(ctx context.Context, filter RequestFilter) ([]*RequestSummary, error)
+
+	// FindResponse finds the logged response of an incoming request, or nil if there is none
+	FindResponse(ctx context.Context, req *LogIncomingRequest) (*LogOutgoingRequest, error)
+
+	// FindIncomingRequestsByUserID finds incoming requests made by or referencing the given user ID
+	FindIncomingRequestsByUserID(ctx context.Context, userID string, limit int) ([]*LogIncomingRequest, error)
+
+	// ScrubValues replaces every occurrence of each key of replacements with its value in
+	// stored request and response documents. Keys are matched against the JSON text of the
+	// documents, so both keys and values must be valid JSON fragments.
+	ScrubValues(ctx context.Context, replacements map[string]string) (int64, error)
+
 	// CleanupOldLogs removes logs older than the specified duration
 	CleanupOldLogs(ctx context.Context, olderThanDays int) (int64, error)
+
+	// DeleteLogsBefore removes logs created before the given time
+	DeleteLogsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// OldestLogTime returns the creation time of the oldest log, or nil when there is none
+	OldestLogTime(ctx context.Context) (*time.Time, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/uptrace/bun"
@@ -48,6 +49,28 @@ func (r *repository) LogError(ctx context.Context, log *LogError) error {
 	return err
 }
 
+// WriteBatch bulk inserts a batch of logs in one transaction
+func (r *repository) WriteBatch(ctx context.Context, batch *Batch) error {
+	return r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(batch.Incoming) > 0 {
+			if _, err := tx.NewInsert().Model(&batch.Incoming).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		if len(batch.Outgoing) > 0 {
+			if _, err := tx.NewInsert().Model(&batch.Outgoing).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		if len(batch.Errors) > 0 {
+			if _, err := tx.NewInsert().Model(&batch.Errors).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // FindOutgoingRequestByTraceID finds outgoing requests by trace ID
 func (r *repository) FindOutgoingRequestByTraceID(ctx context.Context, traceID string) ([]*LogOutgoingRequest, error) {
 	var logs []*LogOutgoingRequest
@@ -122,14 +145,159 @@ func (r *repository) FindRequestWithErrors(ctx context.Context, requestID string
 	return &req, errors, tx.Commit()
 }
 
+// SearchIncomingRequests returns the requests matching filter with the status codes of
+// their responses, newest first
+func (r *repository) SearchIncomingRequests(ctx context.Context, filter RequestFilter) ([]*RequestSummary, error) {
+	q := r.db.NewSelect().
+		TableExpr("log_incoming_requests AS r").
+		ColumnExpr("r.id, r.trace_id, r.event_name, r.endpoint, r.method, r.ip_address, r.user_agent, r.user_id, r.x_request_id, r.created_at").
+		ColumnExpr("res.status_code, res.duration_ms").
+		Join(`LEFT JOIN LATERAL (
+			SELECT o.status_code, o.duration_ms FROM log_outgoing_requests AS o
+			WHERE o.incoming_request_id = r.id AND o.incoming_request_created_at = r.created_at
+			LIMIT 1
+		) AS res ON TRUE`)
+
+	if filter.TraceID != "" {
+		q = q.Where("r.trace_id = ?", filter.TraceID)
+	}
+	if prefix, ok := strings.CutSuffix(filter.Endpoint, "*"); ok {
+		q = q.Where("r.endpoint LIKE ?", escapeLike(prefix)+"%")
+	} else if filter.Endpoint != "" {
+		q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("r.endpoint = ?", filter.Endpoint).
+				WhereOr("r.event_name = ?", filter.Endpoint)
+		})
+	}
+	if filter.Method != "" {
+		q = q.Where("r.method = ?", filter.Method)
+	}
+	if filter.StatusMin > 0 {
+		q = q.Where("res.status_code >= ?", filter.StatusMin)
+	}
+	if filter.StatusMax > 0 {
+		q = q.Where("res.status_code <= ?", filter.StatusMax)
+	}
+	if filter.IPAddress != "" {
+		q = q.Where("r.ip_address <<= ?::inet", filter.IPAddress)
+	}
+	if filter.UserID != "" {
+		q = q.Where("r.user_id = ?", filter.UserID)
+	}
+	if filter.MinDurationMs > 0 {
+		q = q.Where("res.duration_ms >= ?", filter.MinDurationMs)
+	}
+	if filter.Since != nil {
+		q = q.Where("r.created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		q = q.Where("r.created_at < ?", *filter.Until)
+	}
+	if filter.After != nil {
+		q = q.Where("(r.created_at, r.id) < (?, ?::uuid)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	var summaries []*RequestSummary
+	err := q.OrderExpr("r.created_at DESC, r.id DESC").
+		Limit(filter.Limit).
+		Scan(ctx, &summaries)
+
+	return summaries, err
+}
+
+// FindResponse finds the logged response of an incoming request, or nil if there is none
+func (r *repository) FindResponse(ctx context.Context, req *LogIncomingRequest) (*LogOutgoingRequest, error) {
+	var res LogOutgoingRequest
+	err := r.db.NewSelect().
+		Model(&res).
+		Where("incoming_request_id = ?", req.ID).
+		Where("incoming_request_created_at = ?", req.CreatedAt).
+		Limit(1).
+		Scan(ctx)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// FindIncomingRequestsByUserID finds incoming requests made by the given user or
+// referencing the user ID in their endpoint or request document
+func (r *repository) FindIncomingRequestsByUserID(ctx context.Context, userID string, limit int) ([]*LogIncomingRequest, error) {
+	pattern := "%" + escapeLike(userID) + "%"
+
+	var logs []*LogIncomingRequest
+	err := r.db.NewSelect().
+		Model(&logs).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("user_id = ?", userID).
+				WhereOr("endpoint LIKE ?", pattern).
+				WhereOr("request::text LIKE ?", pattern)
+		}).
+		Order("created_at DESC").
+		Limit(limit).
+		Scan(ctx)
+
+	return logs, err
+}
+
+// ScrubValues replaces every occurrence of each key of replacements with its value
+// in stored request and response documents
+func (r *repository) ScrubValues(ctx context.Context, replacements map[string]string) (int64, error) {
+	var total int64
+
+	for value, replacement := range replacements {
+		if value == "" {
+			continue
+		}
+		pattern := "%" + escapeLike(value) + "%"
+
+		incomingResult, err := r.db.NewUpdate().
+			Model((*LogIncomingRequest)(nil)).
+			Set("request = replace(request::text, ?, ?)::jsonb", value, replacement).
+			Set("updated_at = now()").
+			Where("request::text LIKE ?", pattern).
+			Exec(ctx)
+		if err != nil {
+			return total, err
+		}
+
+		outgoingResult, err := r.db.NewUpdate().
+			Model((*LogOutgoingRequest)(nil)).
+			Set("request = replace(request::text, ?, ?)::jsonb", value, replacement).
+			Set("response = replace(response::text, ?, ?)::jsonb", value, replacement).
+			Set("updated_at = now()").
+			WhereGroup(" AND ", func(q *bun.UpdateQuery) *bun.UpdateQuery {
+				return q.Where("request::text LIKE ?", pattern).
+					WhereOr("response::text LIKE ?", pattern)
+			}).
+			Exec(ctx)
+		if err != nil {
+			return total, err
+		}
+
+		incomingCount, _ := incomingResult.RowsAffected()
+		outgoingCount, _ := outgoingResult.RowsAffected()
+		total += incomingCount + outgoingCount
+	}
+
+	return total, nil
+}
+
 // CleanupOldLogs removes logs older than the specified number of days
 func (r *repository) CleanupOldLogs(ctx context.Context, olderThanDays int) (int64, error) {
 	if olderThanDays <= 0 {
 		return 0, errors.New("olderThanDays must be greater than 0")
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -olderThanDays)
+	return r.DeleteLogsBefore(ctx, time.Now().AddDate(0, 0, -olderThanDays))
+}
 
+// DeleteLogsBefore removes logs created before the given time
+func (r *repository) DeleteLogsBefore(ctx context.Context, cutoffTime time.Time) (int64, error) {
 	// Delete old outgoing requests
 	outgoingResult, err := r.db.NewDelete().
 		Model((*LogOutgoingRequest)(nil)).
@@ -163,3 +331,26 @@ func (r *repository) CleanupOldLogs(ctx context.Context, olderThanDays int) (int
 
 	return outgoingCount + incomingCount + errorCount, nil
 }
+
+// OldestLogTime returns the creation time of the oldest log, or nil when there is none
+func (r *repository) OldestLogTime(ctx context.Context) (*time.Time, error) {
+	var oldest sql.NullTime
+	err := r.db.NewRaw(`SELECT min(created_at) FROM (
+			SELECT min(created_at) AS created_at FROM ?
+			UNION ALL SELECT min(created_at) FROM ?
+			UNION ALL SELECT min(created_at) FROM ?
+		) AS t`,
+		bun.Ident(qualified("log_incoming_requests")),
+		bun.Ident(qualified("log_outgoing_requests")),
+		bun.Ident(qualified("log_errors")),
+	).Scan(ctx, &oldest)
+	if err != nil || !oldest.Valid {
+		return nil, err
+	}
+	return &oldest.Time, nil
+}
+
+// escapeLike escapes the LIKE wildcard characters in s
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
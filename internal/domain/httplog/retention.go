@@ -0,0 +1,192 @@
+package httplog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const defaultPartitionsAhead = 3
+
+// RetentionService keeps the HTTP logs within their retention period
+type RetentionService interface {
+	// Enforce creates the partitions of the current and coming periods, drops partitions
+	// past the retention period and deletes the remaining expired rows
+	Enforce(ctx context.Context) (*RetentionResult, error)
+
+	// EnforceRetention runs Enforce as a scheduled job
+	EnforceRetention()
+}
+
+// RetentionResult reports what a retention run changed
+type RetentionResult struct {
+	Created []string
+	Dropped []string
+	Deleted int64 // Expired rows deleted from unpartitioned tables, default partitions and partly expired partitions
+}
+
+// RetentionConfig holds the dependencies and settings of the retention service
+type RetentionConfig struct {
+	Repo            Repository
+	Partitions      PartitionRepository
+	RetentionDays   int               // Logs are kept forever when 0
+	Interval        PartitionInterval // Range of one partition; daily by default
+	PartitionsAhead int               // Partitions created beyond the current one
+	Archive         ArchiveService    // Archives the logs before partitions are dropped or rows deleted; not archived when nil
+}
+
+type retentionService struct {
+	repo          Repository
+	partitions    PartitionRepository
+	retentionDays int
+	interval      PartitionInterval
+	ahead         int
+	archive       ArchiveService
+	now           func() time.Time
+}
+
+// NewRetentionService creates a new HTTP log retention service
+func NewRetentionService(cfg RetentionConfig) RetentionService {
+	s := &retentionService{
+		repo:          cfg.Repo,
+		partitions:    cfg.Partitions,
+		retentionDays: max(cfg.RetentionDays, 0),
+		interval:      cfg.Interval,
+		ahead:         cfg.PartitionsAhead,
+		archive:       cfg.Archive,
+		now:           time.Now,
+	}
+	if !s.interval.Valid() {
+		s.interval = PartitionDaily
+	}
+	if s.ahead <= 0 {
+		s.ahead = defaultPartitionsAhead
+	}
+	return s
+}
+
+// Enforce creates upcoming partitions, drops expired ones and deletes the remaining expired
+// rows. Tables that are not partitioned only get the row-by-row cleanup. With an archive,
+// a partition is only dropped once the logs of its range are archived, and rows are only
+// deleted by whole periods once those are archived, so they may outlive the retention
+// period by up to one partition interval.
+func (s *retentionService) Enforce(ctx context.Context) (*RetentionResult, error) {
+	now := s.now().UTC()
+	cutoff := now.AddDate(0, 0, -s.retentionDays)
+	result := &RetentionResult{}
+	var errs []error
+
+	for _, table := range PartitionedTables {
+		partitioned, err := s.partitions.IsPartitioned(ctx, table)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to inspect %s: %w", table, err))
+			continue
+		}
+		if !partitioned {
+			continue
+		}
+
+		names, err := s.partitions.ListPartitions(ctx, table)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to list partitions of %s: %w", table, err))
+			continue
+		}
+
+		existing := make(map[string]bool, len(names))
+		for _, name := range names {
+			existing[name] = true
+			partition, ok := ParsePartition(table, name)
+			if !ok || s.retentionDays == 0 || partition.To.After(cutoff) {
+				continue
+			}
+			if s.archive != nil {
+				if err := s.archive.EnsureArchived(ctx, partition.From, partition.To); err != nil {
+					errs = append(errs, fmt.Errorf("kept %s, which failed to be archived: %w", name, err))
+					continue
+				}
+			}
+			if err := s.partitions.DropPartition(ctx, partition); err != nil {
+				errs = append(errs, fmt.Errorf("failed to drop %s: %w", name, err))
+				continue
+			}
+			result.Dropped = append(result.Dropped, name)
+		}
+
+		start := s.interval.Start(now)
+		for i := 0; i <= s.ahead; i++ {
+			partition := NewPartition(table, s.interval, s.interval.Add(start, i))
+			if existing[partition.Name] {
+				continue
+			}
+			if err := s.partitions.CreatePartition(ctx, partition); err != nil {
+				errs = append(errs, fmt.Errorf("failed to create %s: %w", partition.Name, err))
+				continue
+			}
+			result.Created = append(result.Created, partition.Name)
+		}
+	}
+
+	if s.retentionDays > 0 {
+		deleted, err := s.deleteExpired(ctx, cutoff)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		result.Deleted = deleted
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// deleteExpired deletes the rows left past the retention period. With an archive, the
+// periods from the oldest log to the cutoff are archived first, which also covers logs
+// in default partitions and unpartitioned tables, and rows are only deleted up to the
+// first period that failed to be archived.
+func (s *retentionService) deleteExpired(ctx context.Context, cutoff time.Time) (int64, error) {
+	if s.archive == nil {
+		deleted, err := s.repo.CleanupOldLogs(ctx, s.retentionDays)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete expired logs: %w", err)
+		}
+		return deleted, nil
+	}
+
+	oldest, err := s.repo.OldestLogTime(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the oldest log: %w", err)
+	}
+	end := s.interval.Start(cutoff)
+	if oldest == nil || !oldest.Before(end) {
+		return 0, nil
+	}
+
+	var archiveErr error
+	for from := s.interval.Start(*oldest); from.Before(end); from = s.interval.Add(from, 1) {
+		if err := s.archive.EnsureArchived(ctx, from, s.interval.Add(from, 1)); err != nil {
+			archiveErr = fmt.Errorf("kept expired logs from %s, which failed to be archived: %w", from.Format(time.RFC3339), err)
+			end = from
+			break
+		}
+	}
+
+	var deleted int64
+	if end.After(*oldest) {
+		deleted, err = s.repo.DeleteLogsBefore(ctx, end)
+		if err != nil {
+			err = fmt.Errorf("failed to delete expired logs: %w", err)
+		}
+	}
+	return deleted, errors.Join(archiveErr, err)
+}
+
+// EnforceRetention runs Enforce as a scheduled job
+func (s *retentionService) EnforceRetention() {
+	result, err := s.Enforce(context.Background())
+	if err != nil {
+		log.Printf("httplog: retention: %v", err)
+	}
+	if len(result.Created) > 0 || len(result.Dropped) > 0 || result.Deleted > 0 {
+		log.Printf("httplog: retention: created %v, dropped %v, deleted %d expired logs", result.Created, result.Dropped, result.Deleted)
+	}
+}
@@ -0,0 +1,259 @@
+package httplog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cleanupRepository is a Repository recording row-by-row cleanups
+type cleanupRepository struct {
+	Repository
+	cleanups      []int
+	oldest        *time.Time
+	deletedBefore []time.Time
+}
+
+func (r *cleanupRepository) CleanupOldLogs(ctx context.Context, olderThanDays int) (int64, error) {
+	r.cleanups = append(r.cleanups, olderThanDays)
+	return 7, nil
+}
+
+func (r *cleanupRepository) DeleteLogsBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.deletedBefore = append(r.deletedBefore, before)
+	return 5, nil
+}
+
+func (r *cleanupRepository) OldestLogTime(ctx context.Context) (*time.Time, error) {
+	return r.oldest, nil
+}
+
+// fakePartitions is an in-memory PartitionRepository
+type fakePartitions struct {
+	unpartitioned map[string]bool
+	partitions    map[string][]string
+	createErr     error
+	dropped       []string // Tables in the order their partitions were dropped
+}
+
+func (f *fakePartitions) IsPartitioned(ctx context.Context, table string) (bool, error) {
+	return !f.unpartitioned[table], nil
+}
+
+func (f *fakePartitions) ListPartitions(ctx context.Context, table string) ([]string, error) {
+	return f.partitions[table], nil
+}
+
+func (f *fakePartitions) CreatePartition(ctx context.Context, partition Partition) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	f.partitions[partition.Table] = append(f.partitions[partition.Table], partition.Name)
+	return nil
+}
+
+func (f *fakePartitions) DropPartition(ctx context.Context, partition Partition) error {
+	f.dropped = append(f.dropped, partition.Table)
+	var kept []string
+	for _, p := range f.partitions[partition.Table] {
+		if p != partition.Name {
+			kept = append(kept, p)
+		}
+	}
+	f.partitions[partition.Table] = kept
+	return nil
+}
+
+// recordingArchive is an ArchiveService recording the ranges it archives, which fails
+// to archive the ranges starting at or after failFrom
+type recordingArchive struct {
+	ArchiveService
+	ranges   []time.Time
+	failFrom *time.Time
+}
+
+func (a *recordingArchive) EnsureArchived(ctx context.Context, from, to time.Time) error {
+	a.ranges = append(a.ranges, from)
+	if a.failFrom != nil && !from.Before(*a.failFrom) {
+		return errors.New("bucket unreachable")
+	}
+	return nil
+}
+
+func TestPartition_Naming(t *testing.T) {
+	from := time.Date(2025, 8, 25, 0, 0, 0, 0, time.UTC)
+
+	daily := NewPartition("log_errors", PartitionDaily, from)
+	assert.Equal(t, "log_errors_p20250825", daily.Name)
+	assert.Equal(t, from.AddDate(0, 0, 1), daily.To)
+
+	monthly := NewPartition("log_errors", PartitionMonthly, PartitionMonthly.Start(from))
+	assert.Equal(t, "log_errors_p202508", monthly.Name)
+	assert.Equal(t, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), monthly.To)
+
+	parsed, ok := ParsePartition("log_errors", "log_errors_p202508")
+	assert.True(t, ok)
+	assert.Equal(t, monthly, parsed)
+
+	parsed, ok = ParsePartition("log_errors", "log_errors_p20250825")
+	assert.True(t, ok)
+	assert.Equal(t, daily, parsed)
+
+	for _, name := range []string{"log_errors_default", "log_incoming_requests_p20250825", "log_errors_p2025"} {
+		_, ok := ParsePartition("log_errors", name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestRetentionService_Enforce(t *testing.T) {
+	now := time.Date(2025, 8, 25, 9, 30, 0, 0, time.UTC)
+
+	newService := func(days int, partitions *fakePartitions) (*retentionService, *cleanupRepository) {
+		repo := &cleanupRepository{}
+		s := NewRetentionService(RetentionConfig{
+			Repo:            repo,
+			Partitions:      partitions,
+			RetentionDays:   days,
+			PartitionsAhead: 2,
+		}).(*retentionService)
+		s.now = func() time.Time { return now }
+		return s, repo
+	}
+
+	t.Run("creates upcoming partitions and drops expired ones", func(t *testing.T) {
+		partitions := &fakePartitions{partitions: map[string][]string{
+			"log_incoming_requests": {"log_incoming_requests_p20250724"},
+			"log_outgoing_requests": {"log_outgoing_requests_p20250724"},
+			"log_errors":            {"log_errors_default", "log_errors_p20250724", "log_errors_p20250726", "log_errors_p20250825"},
+		}}
+		svc, repo := newService(30, partitions)
+
+		result, err := svc.Enforce(context.Background())
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Contains(t, result.Dropped, "log_errors_p20250724")
+		assert.Equal(t, []string{"log_outgoing_requests", "log_errors", "log_incoming_requests"}, partitions.dropped,
+			"responses are dropped before the requests they reference")
+		assert.NotContains(t, result.Dropped, "log_errors_p20250726")
+		assert.Equal(t, []string{"log_errors_default", "log_errors_p20250726", "log_errors_p20250825", "log_errors_p20250826", "log_errors_p20250827"},
+			partitions.partitions["log_errors"])
+		assert.Contains(t, result.Created, "log_incoming_requests_p20250825")
+		assert.Len(t, result.Created, 3+3+2)
+		assert.Equal(t, []int{30}, repo.cleanups)
+		assert.EqualValues(t, 7, result.Deleted)
+	})
+
+	t.Run("keeps logs forever without a retention period", func(t *testing.T) {
+		partitions := &fakePartitions{partitions: map[string][]string{
+			"log_errors": {"log_errors_p20200101"},
+		}}
+		svc, repo := newService(0, partitions)
+
+		result, err := svc.Enforce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Dropped)
+		assert.Contains(t, partitions.partitions["log_errors"], "log_errors_p20200101")
+		assert.Empty(t, repo.cleanups)
+	})
+
+	t.Run("unpartitioned tables fall back to deleting rows", func(t *testing.T) {
+		partitions := &fakePartitions{
+			unpartitioned: map[string]bool{"log_incoming_requests": true, "log_outgoing_requests": true, "log_errors": true},
+			partitions:    map[string][]string{},
+		}
+		svc, repo := newService(14, partitions)
+
+		result, err := svc.Enforce(context.Background())
+
+		assert.NoError(t, err)
+		assert.Empty(t, result.Created)
+		assert.Equal(t, []int{14}, repo.cleanups)
+	})
+
+	t.Run("keeps partitions that failed to be archived", func(t *testing.T) {
+		partitions := &fakePartitions{partitions: map[string][]string{
+			"log_errors": {"log_errors_p20250724", "log_errors_p20250825"},
+		}}
+		svc, _ := newService(30, partitions)
+		archive := &recordingArchive{failFrom: &time.Time{}}
+		svc.archive = archive
+
+		result, err := svc.Enforce(context.Background())
+
+		assert.ErrorContains(t, err, "kept log_errors_p20250724, which failed to be archived: bucket unreachable")
+		assert.Empty(t, result.Dropped)
+		assert.Contains(t, partitions.partitions["log_errors"], "log_errors_p20250724")
+		assert.Equal(t, []time.Time{time.Date(2025, 7, 24, 0, 0, 0, 0, time.UTC)}, archive.ranges)
+	})
+
+	t.Run("archives expired rows before deleting them", func(t *testing.T) {
+		partitions := &fakePartitions{
+			unpartitioned: map[string]bool{"log_incoming_requests": true, "log_outgoing_requests": true, "log_errors": true},
+			partitions:    map[string][]string{},
+		}
+		svc, repo := newService(30, partitions)
+		oldest := time.Date(2025, 7, 20, 10, 0, 0, 0, time.UTC)
+		repo.oldest = &oldest
+		archive := &recordingArchive{}
+		svc.archive = archive
+
+		result, err := svc.Enforce(context.Background())
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, archive.ranges, 6)
+		assert.Equal(t, time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC), archive.ranges[0])
+		assert.Equal(t, time.Date(2025, 7, 25, 0, 0, 0, 0, time.UTC), archive.ranges[5])
+		assert.Equal(t, []time.Time{time.Date(2025, 7, 26, 0, 0, 0, 0, time.UTC)}, repo.deletedBefore,
+			"rows of the partly expired day are kept until it is archived")
+		assert.Empty(t, repo.cleanups)
+		assert.EqualValues(t, 5, result.Deleted)
+	})
+
+	t.Run("keeps expired rows that failed to be archived", func(t *testing.T) {
+		partitions := &fakePartitions{
+			unpartitioned: map[string]bool{"log_incoming_requests": true, "log_outgoing_requests": true, "log_errors": true},
+			partitions:    map[string][]string{},
+		}
+		svc, repo := newService(30, partitions)
+		oldest := time.Date(2025, 7, 20, 10, 0, 0, 0, time.UTC)
+		repo.oldest = &oldest
+		failFrom := time.Date(2025, 7, 23, 0, 0, 0, 0, time.UTC)
+		archive := &recordingArchive{failFrom: &failFrom}
+		svc.archive = archive
+
+		_, err := svc.Enforce(context.Background())
+
+		assert.ErrorContains(t, err, "kept expired logs from 2025-07-23T00:00:00Z, which failed to be archived: bucket unreachable")
+		assert.Len(t, archive.ranges, 4)
+		assert.Equal(t, []time.Time{failFrom}, repo.deletedBefore)
+
+		// Nothing is deleted when the oldest period fails
+		repo.deletedBefore = nil
+		archive.ranges = nil
+		archive.failFrom = &time.Time{}
+
+		_, err = svc.Enforce(context.Background())
+
+		assert.Error(t, err)
+		assert.Len(t, archive.ranges, 1)
+		assert.Empty(t, repo.deletedBefore)
+	})
+
+	t.Run("failures do not stop the run", func(t *testing.T) {
+		partitions := &fakePartitions{partitions: map[string][]string{}, createErr: errors.New("permission denied")}
+		svc, repo := newService(30, partitions)
+
+		_, err := svc.Enforce(context.Background())
+
+		assert.ErrorContains(t, err, "failed to create log_errors_p20250825")
+		assert.Equal(t, []int{30}, repo.cleanups)
+	})
+}
@@ -24,6 +24,12 @@ type Service interface {
 	// GetErrorLogs retrieves error logs for a specific trace ID
 	GetErrorLogs(ctx context.Context, traceID string) ([]*LogError, error)
 
+	// SearchRequests returns a page of incoming requests matching filter, newest first
+	SearchRequests(ctx context.Context, filter RequestFilter) (*RequestPage, error)
+
+	// GetRequest returns an incoming request with its response and errors
+	GetRequest(ctx context.Context, requestID string) (*RequestDetail, error)
+
 	// CleanupOldLogs removes logs older than the specified number of days
 	CleanupOldLogs(ctx context.Context, olderThanDays int) (int64, error)
 }
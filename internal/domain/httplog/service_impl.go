@@ -4,9 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/google/uuid"
+
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 200
 )
 
 type service struct {
@@ -78,10 +86,10 @@ func (s *service) LogErrorWithRequest(ctx context.Context, requestID string, sta
 		return errors.New("error cannot be nil")
 	}
 
-	// Try to get the trace ID from context or generate a new one
-	traceID, _ := ctx.Value("trace_id").(string)
+	// Errors outside of a request get a trace of their own
+	traceID := telemetry.TraceID(ctx)
 	if traceID == "" {
-		traceID = generateTraceID()
+		traceID = telemetry.NewTraceID().String()
 	}
 
 	log := &LogError{
@@ -143,6 +151,80 @@ func (s *service) GetErrorLogs(ctx context.Context, traceID string) ([]*LogError
 	return s.repo.FindErrorsByTraceID(ctx, traceID)
 }
 
+// SearchRequests returns a page of incoming requests matching filter, newest first
+func (s *service) SearchRequests(ctx context.Context, filter RequestFilter) (*RequestPage, error) {
+	if filter.Method != "" && !filter.Method.Valid() {
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidFilter, filter.Method)
+	}
+	if filter.StatusMin < 0 || filter.StatusMax < 0 || (filter.StatusMax > 0 && filter.StatusMin > filter.StatusMax) {
+		return nil, fmt.Errorf("%w: invalid status range", ErrInvalidFilter)
+	}
+	if filter.IPAddress != "" && net.ParseIP(filter.IPAddress) == nil {
+		if _, _, err := net.ParseCIDR(filter.IPAddress); err != nil {
+			return nil, fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidFilter, filter.IPAddress)
+		}
+	}
+	if filter.UserID != "" {
+		if _, err := uuid.Parse(filter.UserID); err != nil {
+			return nil, fmt.Errorf("%w: invalid user ID", ErrInvalidFilter)
+		}
+	}
+	if filter.MinDurationMs < 0 {
+		return nil, fmt.Errorf("%w: invalid minimum duration", ErrInvalidFilter)
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidFilter)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	filter.Limit = min(filter.Limit, maxSearchLimit)
+
+	// Fetch one more request than asked for to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	requests, err := s.repo.SearchIncomingRequests(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search requests: %w", err)
+	}
+
+	page := &RequestPage{Requests: requests}
+	if len(requests) > limit {
+		page.Requests = requests[:limit]
+		last := page.Requests[limit-1]
+		page.NextCursor = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.String()
+	}
+	if page.Requests == nil {
+		page.Requests = []*RequestSummary{}
+	}
+	return page, nil
+}
+
+// GetRequest returns an incoming request with its response and errors
+func (s *service) GetRequest(ctx context.Context, requestID string) (*RequestDetail, error) {
+	if _, err := uuid.Parse(requestID); err != nil {
+		return nil, errors.New("invalid request ID format")
+	}
+
+	req, errs, err := s.repo.FindRequestWithErrors(ctx, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get request: %w", err)
+	}
+	if req == nil {
+		return nil, ErrRequestNotFound
+	}
+
+	res, err := s.repo.FindResponse(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get response: %w", err)
+	}
+
+	if errs == nil {
+		errs = []*LogError{}
+	}
+	return &RequestDetail{Request: req, Response: res, Errors: errs}, nil
+}
+
 // CleanupOldLogs removes logs older than the specified number of days
 func (s *service) CleanupOldLogs(ctx context.Context, olderThanDays int) (int64, error) {
 	if olderThanDays <= 0 {
@@ -151,8 +233,3 @@ func (s *service) CleanupOldLogs(ctx context.Context, olderThanDays int) (int64,
 
 	return s.repo.CleanupOldLogs(ctx, olderThanDays)
 }
-
-// generateTraceID generates a new trace ID
-func generateTraceID() string {
-	return "trace_" + time.Now().Format("20060102150405") + "_" + uuid.New().String()
-}
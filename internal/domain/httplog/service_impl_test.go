@@ -0,0 +1,151 @@
+package httplog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// searchRepository is a Repository serving canned search results
+type searchRepository struct {
+	Repository
+	requests []*RequestSummary
+	filters  []RequestFilter
+	request  *LogIncomingRequest
+	errors   []*LogError
+	response *LogOutgoingRequest
+}
+
+func (r *searchRepository) SearchIncomingRequests(ctx context.Context, filter RequestFilter) ([]*RequestSummary, error) {
+	r.filters = append(r.filters, filter)
+	var page []*RequestSummary
+	for _, req := range r.requests {
+		if filter.After != nil && !req.CreatedAt.Before(filter.After.CreatedAt) {
+			continue
+		}
+		if len(page) == filter.Limit {
+			break
+		}
+		page = append(page, req)
+	}
+	return page, nil
+}
+
+func (r *searchRepository) FindRequestWithErrors(ctx context.Context, requestID string) (*LogIncomingRequest, []*LogError, error) {
+	if r.request == nil || r.request.ID != requestID {
+		return nil, nil, nil
+	}
+	return r.request, r.errors, nil
+}
+
+func (r *searchRepository) FindResponse(ctx context.Context, req *LogIncomingRequest) (*LogOutgoingRequest, error) {
+	return r.response, nil
+}
+
+func TestService_SearchRequests(t *testing.T) {
+	start := time.Date(2025, 8, 25, 9, 0, 0, 0, time.UTC)
+	repo := &searchRepository{}
+	for i := 0; i < 5; i++ {
+		repo.requests = append(repo.requests, &RequestSummary{ID: uuid.New().String(), CreatedAt: start.Add(-time.Duration(i) * time.Minute)})
+	}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	var seen []string
+	filter := RequestFilter{Limit: 2}
+	for pages := 1; ; pages++ {
+		page, err := svc.SearchRequests(ctx, filter)
+		if !assert.NoError(t, err) {
+			return
+		}
+		for _, req := range page.Requests {
+			seen = append(seen, req.ID)
+		}
+		if page.NextCursor == "" {
+			assert.Equal(t, 3, pages)
+			break
+		}
+		filter.After, err = ParseCursor(page.NextCursor)
+		if !assert.NoError(t, err) {
+			return
+		}
+	}
+	assert.Len(t, seen, 5)
+	assert.Equal(t, repo.requests[4].ID, seen[4])
+	assert.Equal(t, 3, repo.filters[0].Limit, "one extra request is fetched to detect the next page")
+
+	t.Run("default and maximum page size", func(t *testing.T) {
+		_, err := svc.SearchRequests(ctx, RequestFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, defaultSearchLimit+1, repo.filters[len(repo.filters)-1].Limit)
+
+		page, err := svc.SearchRequests(ctx, RequestFilter{Limit: 5000, After: &Cursor{CreatedAt: start.Add(-time.Hour)}})
+		assert.NoError(t, err)
+		assert.Equal(t, maxSearchLimit+1, repo.filters[len(repo.filters)-1].Limit)
+		assert.NotNil(t, page.Requests)
+		assert.Empty(t, page.Requests)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		since, until := start, start.Add(-time.Hour)
+		for _, filter := range []RequestFilter{
+			{Method: "FETCH"},
+			{StatusMin: 500, StatusMax: 400},
+			{StatusMin: -1},
+			{IPAddress: "10.0.0"},
+			{UserID: "jane@example.com"},
+			{MinDurationMs: -1},
+			{Since: &since, Until: &until},
+		} {
+			_, err := svc.SearchRequests(ctx, filter)
+			assert.ErrorIs(t, err, ErrInvalidFilter, fmt.Sprintf("%+v", filter))
+		}
+
+		_, err := svc.SearchRequests(ctx, RequestFilter{IPAddress: "10.0.0.0/8", StatusMin: 500, StatusMax: 599, Method: POST})
+		assert.NoError(t, err)
+	})
+}
+
+func TestService_GetRequest(t *testing.T) {
+	id := uuid.New().String()
+	repo := &searchRepository{
+		request:  &LogIncomingRequest{ID: id, TraceID: "t1"},
+		response: &LogOutgoingRequest{TraceID: "t1", StatusCode: 500},
+		errors:   []*LogError{{TraceID: "t1", Error: "boom"}},
+	}
+	svc := NewService(repo)
+
+	detail, err := svc.GetRequest(context.Background(), id)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, id, detail.Request.ID)
+	assert.Equal(t, 500, detail.Response.StatusCode)
+	assert.Len(t, detail.Errors, 1)
+
+	_, err = svc.GetRequest(context.Background(), uuid.New().String())
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+
+	_, err = svc.GetRequest(context.Background(), "not-a-uuid")
+	assert.ErrorContains(t, err, "invalid request ID format")
+}
+
+func TestParseCursor(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2025, 8, 25, 9, 0, 0, 123456000, time.UTC), ID: uuid.New().String()}
+
+	parsed, err := ParseCursor(cursor.String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+	assert.Equal(t, cursor.ID, parsed.ID)
+
+	for _, s := range []string{"%%%", "bm9waXBl", Cursor{CreatedAt: cursor.CreatedAt, ID: "x"}.String()} {
+		_, err := ParseCursor(s)
+		assert.ErrorIs(t, err, ErrInvalidFilter)
+	}
+}
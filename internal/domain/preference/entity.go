@@ -0,0 +1,56 @@
+package preference
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// UserPreferences stores the explicitly set preferences of a user.
+// Keys that are not present fall back to the registry defaults.
+type UserPreferences struct {
+	bun.BaseModel `bun:"table:user_preferences,alias:up"`
+
+	UserID    uuid.UUID                  `bun:"type:uuid,pk" json:"user_id"`
+	Settings  map[string]json.RawMessage `bun:"type:jsonb,notnull" json:"settings"`
+	CreatedAt time.Time                  `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt time.Time                  `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// Preferences is the resolved view of a user's preferences: every registered
+// key is present, holding either the user's value or the default
+type Preferences map[string]any
+
+// String returns a string preference, or "" if the key is not a string
+func (p Preferences) String(key string) string {
+	s, _ := p[key].(string)
+	return s
+}
+
+// Bool returns a boolean preference, or false if the key is not a boolean
+func (p Preferences) Bool(key string) bool {
+	b, _ := p[key].(bool)
+	return b
+}
+
+// Int returns an integer preference, or 0 if the key is not an integer
+func (p Preferences) Int(key string) int {
+	i, _ := p[key].(int)
+	return i
+}
+
+// Locale returns the user's preferred locale
+func (p Preferences) Locale() string {
+	return p.String(KeyLocale)
+}
+
+// Location returns the user's preferred time zone, falling back to UTC
+func (p Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.String(KeyTimezone))
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,289 @@
+package preference
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Kind is the value type of a preference
+type Kind string
+
+const (
+	KindString Kind = "string"
+	KindBool   Kind = "bool"
+	KindInt    Kind = "int"
+	KindEnum   Kind = "enum"
+)
+
+// Well-known preference keys
+const (
+	KeyLocale                 = "locale"
+	KeyTimezone               = "timezone"
+	KeyNotificationsEmail     = "notifications.email"
+	KeyNotificationsMarketing = "notifications.marketing"
+	KeyNotificationsSecurity  = "notifications.security"
+	KeyUITheme                = "ui.theme"
+	KeyUICompactMode          = "ui.compact_mode"
+)
+
+// Definition describes an allowed preference key
+type Definition struct {
+	Key         string   `json:"key"`
+	Kind        Kind     `json:"kind"`
+	Default     any      `json:"default"`
+	Options     []string `json:"options,omitempty"` // Allowed values for KindEnum
+	Description string   `json:"description,omitempty"`
+
+	// Validate optionally checks a decoded value and may return a normalized one
+	Validate func(value any) (any, error) `json:"-"`
+}
+
+// ValidationErrors maps preference keys to the reason their value was rejected
+type ValidationErrors map[string]string
+
+func (e ValidationErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + e[key]
+	}
+	return "invalid preferences: " + strings.Join(parts, "; ")
+}
+
+// Registry holds the set of allowed preference keys.
+// New keys can be added without a database migration.
+type Registry struct {
+	mu          sync.RWMutex
+	definitions map[string]Definition
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{definitions: make(map[string]Definition)}
+}
+
+// Register adds a definition, panicking on duplicate keys or invalid defaults
+// since both are programming errors
+func (r *Registry) Register(def Definition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.definitions[def.Key]; exists {
+		panic(fmt.Sprintf("preference: key %q registered twice", def.Key))
+	}
+	raw, err := json.Marshal(def.Default)
+	if err != nil {
+		panic(fmt.Sprintf("preference: default for %q is not JSON: %v", def.Key, err))
+	}
+	if _, err := decode(def, raw); err != nil {
+		panic(fmt.Sprintf("preference: default for %q is invalid: %v", def.Key, err))
+	}
+
+	r.definitions[def.Key] = def
+}
+
+// Lookup returns the definition of key
+func (r *Registry) Lookup(key string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.definitions[key]
+	return def, ok
+}
+
+// Definitions returns all definitions ordered by key
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(r.definitions))
+	for _, def := range r.definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Key < defs[j].Key })
+	return defs
+}
+
+// Validate checks a set of changes and returns the normalized JSON to store.
+// A JSON null value resets the key to its default and is returned as nil.
+func (r *Registry) Validate(changes map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	errs := ValidationErrors{}
+	normalized := make(map[string]json.RawMessage, len(changes))
+
+	for key, raw := range changes {
+		def, ok := r.definitions[key]
+		if !ok {
+			errs[key] = "unknown preference"
+			continue
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			normalized[key] = nil
+			continue
+		}
+
+		value, err := decode(def, raw)
+		if err != nil {
+			errs[key] = err.Error()
+			continue
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			errs[key] = err.Error()
+			continue
+		}
+		normalized[key] = encoded
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return normalized, nil
+}
+
+// Resolve merges stored settings with the defaults. Stored values for keys that
+// are no longer registered, or that no longer validate, are ignored.
+func (r *Registry) Resolve(settings map[string]json.RawMessage) Preferences {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs := make(Preferences, len(r.definitions))
+	for key, def := range r.definitions {
+		prefs[key] = def.Default
+		if raw, ok := settings[key]; ok {
+			if value, err := decode(def, raw); err == nil {
+				prefs[key] = value
+			}
+		}
+	}
+	return prefs
+}
+
+// decode parses raw according to the definition kind and runs its validator
+func decode(def Definition, raw json.RawMessage) (any, error) {
+	var value any
+
+	switch def.Kind {
+	case KindString, KindEnum:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("must be a string")
+		}
+		if def.Kind == KindEnum && !contains(def.Options, s) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(def.Options, ", "))
+		}
+		value = s
+	case KindBool:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		value = b
+	case KindInt:
+		var i int
+		if err := json.Unmarshal(raw, &i); err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		value = i
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", def.Kind)
+	}
+
+	if def.Validate != nil {
+		return def.Validate(value)
+	}
+	return value, nil
+}
+
+func contains(options []string, s string) bool {
+	for _, option := range options {
+		if option == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRegistry returns a registry with the application's preferences
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(Definition{
+		Key:         KeyLocale,
+		Kind:        KindString,
+		Default:     "en",
+		Description: "BCP 47 language tag used for emails and formatting",
+		Validate: func(value any) (any, error) {
+			tag, err := language.Parse(value.(string))
+			if err != nil {
+				return nil, fmt.Errorf("must be a valid language tag")
+			}
+			return tag.String(), nil
+		},
+	})
+	r.Register(Definition{
+		Key:         KeyTimezone,
+		Kind:        KindString,
+		Default:     "UTC",
+		Description: "IANA time zone name",
+		Validate: func(value any) (any, error) {
+			name := value.(string)
+			if name == "" || strings.EqualFold(name, "local") {
+				return nil, fmt.Errorf("must be an IANA time zone name")
+			}
+			if _, err := time.LoadLocation(name); err != nil {
+				return nil, fmt.Errorf("must be an IANA time zone name")
+			}
+			return name, nil
+		},
+	})
+	r.Register(Definition{
+		Key:         KeyNotificationsEmail,
+		Kind:        KindBool,
+		Default:     true,
+		Description: "Receive account notifications by email",
+	})
+	r.Register(Definition{
+		Key:         KeyNotificationsMarketing,
+		Kind:        KindBool,
+		Default:     false,
+		Description: "Receive product news and offers",
+	})
+	r.Register(Definition{
+		Key:         KeyNotificationsSecurity,
+		Kind:        KindBool,
+		Default:     true,
+		Description: "Receive security alerts such as new sign-ins",
+	})
+	r.Register(Definition{
+		Key:         KeyUITheme,
+		Kind:        KindEnum,
+		Default:     "system",
+		Options:     []string{"light", "dark", "system"},
+		Description: "Color theme of the web interface",
+	})
+	r.Register(Definition{
+		Key:         KeyUICompactMode,
+		Kind:        KindBool,
+		Default:     false,
+		Description: "Use denser layouts in the web interface",
+	})
+
+	return r
+}
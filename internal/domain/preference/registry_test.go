@@ -0,0 +1,67 @@
+package preference
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Validate(t *testing.T) {
+	r := DefaultRegistry()
+
+	t.Run("normalizes valid values", func(t *testing.T) {
+		normalized, err := r.Validate(map[string]json.RawMessage{
+			KeyLocale:   json.RawMessage(`"en-us"`),
+			KeyUITheme:  json.RawMessage(`"dark"`),
+			KeyTimezone: json.RawMessage(`null`),
+		})
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `"en-US"`, string(normalized[KeyLocale]))
+		assert.JSONEq(t, `"dark"`, string(normalized[KeyUITheme]))
+		assert.Nil(t, normalized[KeyTimezone])
+	})
+
+	t.Run("reports every invalid key", func(t *testing.T) {
+		_, err := r.Validate(map[string]json.RawMessage{
+			"unknown":             json.RawMessage(`1`),
+			KeyTimezone:           json.RawMessage(`"Mars/Olympus"`),
+			KeyUITheme:            json.RawMessage(`"neon"`),
+			KeyNotificationsEmail: json.RawMessage(`"yes"`),
+		})
+
+		var errs ValidationErrors
+		assert.ErrorAs(t, err, &errs)
+		assert.Len(t, errs, 4)
+		assert.Equal(t, "unknown preference", errs["unknown"])
+		assert.Equal(t, "must be one of light, dark, system", errs[KeyUITheme])
+		assert.Equal(t, "must be a boolean", errs[KeyNotificationsEmail])
+	})
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := DefaultRegistry()
+
+	prefs := r.Resolve(map[string]json.RawMessage{
+		KeyLocale:             json.RawMessage(`"de"`),
+		KeyNotificationsEmail: json.RawMessage(`false`),
+		"removed.key":         json.RawMessage(`true`),
+		KeyUICompactMode:      json.RawMessage(`"not a bool"`),
+	})
+
+	assert.Equal(t, "de", prefs.Locale())
+	assert.False(t, prefs.Bool(KeyNotificationsEmail))
+	assert.Equal(t, "UTC", prefs.Location().String())
+	assert.False(t, prefs.Bool(KeyUICompactMode), "invalid stored values fall back to the default")
+	assert.NotContains(t, prefs, "removed.key")
+	assert.Len(t, prefs, len(r.Definitions()))
+}
+
+func TestRegistry_RegisterRejectsInvalidDefault(t *testing.T) {
+	r := NewRegistry()
+
+	assert.Panics(t, func() {
+		r.Register(Definition{Key: "ui.density", Kind: KindEnum, Default: "huge", Options: []string{"small", "large"}})
+	})
+}
@@ -0,0 +1,22 @@
+package preference
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines storage operations for user preferences
+type Repository interface {
+	// GetByUserID returns the stored preferences of a user
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*UserPreferences, error)
+
+	// Merge sets and resets keys of the stored preferences of a user in one statement,
+	// keeping the other keys, and returns the resulting settings
+	Merge(ctx context.Context, userID uuid.UUID, set map[string]json.RawMessage, reset []string, now time.Time) (map[string]json.RawMessage, error)
+
+	// Delete removes the stored preferences of a user
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
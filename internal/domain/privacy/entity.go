@@ -0,0 +1,41 @@
+package privacy
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// DeletionStatus represents the state of an account deletion request
+type DeletionStatus string
+
+const (
+	DeletionPending   DeletionStatus = "pending"
+	DeletionCancelled DeletionStatus = "cancelled"
+	DeletionCompleted DeletionStatus = "completed"
+)
+
+// DeletionRequest records a user's request to erase their account.
+// The deletion is carried out once ScheduledFor has passed unless it is cancelled first.
+type DeletionRequest struct {
+	bun.BaseModel `bun:"table:user_deletion_requests,alias:udr"`
+
+	ID           uuid.UUID      `bun:"type:uuid,default:uuid_generate_v4(),pk" json:"id"`
+	UserID       uuid.UUID      `bun:"type:uuid,notnull" json:"user_id"`
+	Status       DeletionStatus `bun:"type:varchar(20),notnull" json:"status"`
+	ScheduledFor time.Time      `bun:"type:timestamp,notnull" json:"scheduled_for"`
+	CancelledAt  *time.Time     `bun:"type:timestamp" json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time     `bun:"type:timestamp" json:"completed_at,omitempty"`
+	CreatedAt    time.Time      `bun:"type:timestamp,default:now(),notnull" json:"created_at"`
+	UpdatedAt    time.Time      `bun:"type:timestamp,default:now(),notnull" json:"updated_at"`
+}
+
+// ExportArchive describes the contents of a subject access export
+type ExportArchive struct {
+	GeneratedAt   time.Time   `json:"generated_at"`
+	Profile       interface{} `json:"profile"`
+	Preferences   interface{} `json:"preferences"`
+	RefreshTokens interface{} `json:"refresh_tokens"`
+	HTTPLogs      interface{} `json:"http_logs"`
+}
@@ -0,0 +1,29 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeletionRequestRepository defines storage operations for account deletion requests
+type DeletionRequestRepository interface {
+	// Create stores a new deletion request
+	Create(ctx context.Context, req *DeletionRequest) error
+
+	// GetPendingByUserID returns the pending deletion request for a user, if any
+	GetPendingByUserID(ctx context.Context, userID uuid.UUID) (*DeletionRequest, error)
+
+	// ListDue returns pending requests scheduled at or before the given time
+	ListDue(ctx context.Context, before time.Time, limit int) ([]*DeletionRequest, error)
+
+	// Update persists status changes of a pending deletion request. It returns
+	// sql.ErrNoRows when the request is no longer pending.
+	Update(ctx context.Context, req *DeletionRequest) error
+
+	// Process locks a pending request while fn carries it out, then persists the status
+	// fn leaves it in, so that it cannot be cancelled halfway. It reports false without
+	// calling fn when the request is no longer pending or another worker holds it.
+	Process(ctx context.Context, id uuid.UUID, fn func(ctx context.Context, req *DeletionRequest) error) (bool, error)
+}
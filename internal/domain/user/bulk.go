@@ -0,0 +1,33 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+// ExportFilter narrows the users returned by a bulk export
+type ExportFilter struct {
+	EmailContains string     // Case-insensitive substring match on email
+	CreatedAfter  *time.Time // Inclusive lower bound on created_at
+	CreatedBefore *time.Time // Exclusive upper bound on created_at
+}
+
+// BulkRepository defines storage operations used by bulk import and export
+type BulkRepository interface {
+	// ExistingUsers returns the users, deleted ones included, owning the given emails, keyed by email
+	ExistingUsers(ctx context.Context, emails []string) (map[string]*User, error)
+
+	// UpsertBatch inserts the users in a single statement, updating existing users matched by email.
+	// A password of UnsetPassword keeps the stored password of an existing user, and so does a
+	// zero StatusChangedAt its status. A status change clears the suspension details. Deleted
+	// users are left untouched.
+	UpsertBatch(ctx context.Context, users []*User) error
+
+	// Stream calls fn for every user matching the filter, ordered by creation date,
+	// without loading the whole result set into memory
+	Stream(ctx context.Context, filter ExportFilter, fn func(*User) error) error
+}
+
+// UnsetPassword is stored for imported users without a password. It is not a valid
+// bcrypt hash, so such accounts cannot log in until a password is set.
+const UnsetPassword = "!"
@@ -11,30 +11,43 @@ import (
 type User struct {
 	bun.BaseModel `bun:"table:users,alias:u"`
 
-	ID        uuid.UUID `bun:"type:uuid,default:uuid_generate_v4(),pk"`
-	Name      string    `bun:"type:varchar(100),notnull"`
-	Email     string    `bun:"type:varchar(100),unique,notnull"`
-	Password  string    `bun:"type:varchar(255),notnull" json:"-"`
-	CreatedAt time.Time `bun:"type:timestamp,default:now(),notnull"`
-	UpdatedAt time.Time `bun:"type:timestamp,default:now(),notnull"`
-	DeletedAt time.Time `bun:"type:timestamp,soft_delete,nullzero" json:"-"`
+	ID               uuid.UUID  `bun:"type:uuid,default:uuid_generate_v4(),pk"`
+	Name             string     `bun:"type:varchar(100),notnull"`
+	Email            string     `bun:"type:varchar(100),unique,notnull"`
+	Password         string     `bun:"type:varchar(255),notnull" json:"-"`
+	Status           Status     `bun:"type:varchar(20),notnull,default:'active'"`
+	Role             Role       `bun:"type:varchar(20),notnull,default:'user'"`
+	SuspensionReason string     `bun:"type:text,nullzero"`
+	SuspendedUntil   *time.Time `bun:"type:timestamp"`
+	StatusChangedAt  time.Time  `bun:"type:timestamp,nullzero"`
+	AvatarKey        string     `bun:"type:varchar(255),nullzero"`
+	CreatedAt        time.Time  `bun:"type:timestamp,default:now(),notnull"`
+	UpdatedAt        time.Time  `bun:"type:timestamp,default:now(),notnull"`
+	DeletedAt        time.Time  `bun:"type:timestamp,soft_delete,nullzero" json:"-"`
 }
 
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID         `json:"id"`
+	Name             string            `json:"name"`
+	Email            string            `json:"email"`
+	Status           Status            `json:"status,omitempty"`
+	SuspensionReason string            `json:"suspension_reason,omitempty"`
+	SuspendedUntil   *time.Time        `json:"suspended_until,omitempty"`
+	AvatarURLs       map[string]string `json:"avatar_urls,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
 }
 
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:               u.ID,
+		Name:             u.Name,
+		Email:            u.Email,
+		Status:           u.Status,
+		SuspensionReason: u.SuspensionReason,
+		SuspendedUntil:   u.SuspendedUntil,
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
 	}
 }
 
@@ -57,3 +70,61 @@ func (u *User) HashPassword(password string) error {
 func (u *User) CheckPassword(password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 }
+
+// StatusSnapshot returns the status information used for authorization checks
+func (u *User) StatusSnapshot() StatusSnapshot {
+	status := u.Status
+	if status == "" {
+		status = StatusActive
+	}
+	role := u.Role
+	if role == "" {
+		role = RoleUser
+	}
+	return StatusSnapshot{
+		Status:         status,
+		SuspendedUntil: u.SuspendedUntil,
+		Role:           role,
+	}
+}
+
+// ChangeStatus moves the user to a new status if the transition is allowed
+func (u *User) ChangeStatus(next Status, reason string, until *time.Time, now time.Time) error {
+	if !next.IsValid() {
+		return ErrInvalidStatus
+	}
+
+	current := u.StatusSnapshot().Status
+	if !current.CanTransitionTo(next) {
+		return ErrInvalidStatusTransition
+	}
+
+	u.Status = next
+	u.StatusChangedAt = now
+	u.UpdatedAt = now
+
+	if next == StatusSuspended {
+		u.SuspensionReason = reason
+		u.SuspendedUntil = until
+	} else {
+		u.SuspensionReason = ""
+		u.SuspendedUntil = nil
+	}
+
+	return nil
+}
+
+// Anonymize replaces personal data with placeholders and marks the user deleted.
+// The row is kept so that references from other tables remain valid.
+func (u *User) Anonymize(now time.Time) {
+	u.Name = "Deleted User"
+	u.Email = "deleted-" + u.ID.String() + "@invalid.local"
+	u.Password = "!"
+	u.Status = StatusLocked
+	u.SuspensionReason = ""
+	u.SuspendedUntil = nil
+	u.AvatarKey = ""
+	u.StatusChangedAt = now
+	u.UpdatedAt = now
+	u.DeletedAt = now
+}
@@ -0,0 +1,18 @@
+package user
+
+import "errors"
+
+var (
+	// ErrUserNotFound is returned when no user matches the lookup
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidStatus is returned when a status value is not recognised
+	ErrInvalidStatus = errors.New("invalid user status")
+	// ErrInvalidStatusTransition is returned when a status change is not allowed
+	ErrInvalidStatusTransition = errors.New("invalid user status transition")
+	// ErrAccountInactive is returned when a non-active account tries to authenticate
+	ErrAccountInactive = errors.New("user account is not active")
+	// ErrInsufficientRole is returned when a user lacks the role a route requires
+	ErrInsufficientRole = errors.New("user does not have the required role")
+	// ErrInvalidRole is returned when a role value is not recognised
+	ErrInvalidRole = errors.New("invalid user role")
+)
@@ -10,4 +10,8 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Create(ctx context.Context, user *User) error
+	UpdateStatus(ctx context.Context, user *User) error
+	UpdateAvatar(ctx context.Context, user *User) error
+	UpdateRole(ctx context.Context, user *User) error
+	Anonymize(ctx context.Context, user *User) error
 }
@@ -0,0 +1,14 @@
+package user
+
+// Role is the authorization role of a user account
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// IsValid reports whether the role is one of the known roles
+func (r Role) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
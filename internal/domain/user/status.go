@@ -0,0 +1,62 @@
+package user
+
+import "time"
+
+// Status represents the lifecycle state of a user account
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+	StatusLocked    Status = "locked"
+)
+
+// statusTransitions lists the statuses each status may move to
+var statusTransitions = map[Status][]Status{
+	StatusPending:   {StatusActive, StatusSuspended, StatusLocked},
+	StatusActive:    {StatusSuspended, StatusLocked},
+	StatusSuspended: {StatusActive, StatusLocked},
+	StatusLocked:    {StatusActive, StatusSuspended},
+}
+
+// IsValid reports whether the status is one of the known statuses
+func (s Status) IsValid() bool {
+	_, ok := statusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a user in status s may be moved to next
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusSnapshot is the minimal status information needed for authorization checks
+type StatusSnapshot struct {
+	Status         Status     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Role           Role       `json:"role,omitempty"`
+}
+
+// IsActiveAt reports whether the account may authenticate at the given time.
+// A suspension with an expired SuspendedUntil is treated as lifted.
+func (s StatusSnapshot) IsActiveAt(now time.Time) bool {
+	switch s.Status {
+	case StatusActive:
+		return true
+	case StatusSuspended:
+		return s.SuspendedUntil != nil && !now.Before(*s.SuspendedUntil)
+	default:
+		return false
+	}
+}
+
+// HasRole reports whether the account has the given role
+func (s StatusSnapshot) HasRole(role Role) bool {
+	return s.Role == role
+}
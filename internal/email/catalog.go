@@ -0,0 +1,398 @@
+package email
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"base-code-go-gin-clean/internal/pkg/i18n"
+)
+
+// ErrUnknownTemplate is returned when a template name is not in the catalog
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// FieldType is the type of a template data field
+type FieldType string
+
+const (
+	FieldString   FieldType = "string"
+	FieldURL      FieldType = "url"      // Absolute http or https URL
+	FieldInt      FieldType = "int"      // Whole number
+	FieldDateTime FieldType = "datetime" // RFC 3339 timestamp
+)
+
+// Field describes one value a template expects
+type Field struct {
+	Name        string    `json:"name"`
+	Type        FieldType `json:"type"`
+	Required    bool      `json:"required"`
+	Description string    `json:"description,omitempty"`
+}
+
+// Definition describes a template that can be rendered from untyped data,
+// e.g. by the send-template API
+type Definition struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Localized   bool    `json:"localized"` // Rendered in the recipient's locale; otherwise always English
+	Bulk        bool    `json:"bulk"`      // Sent as bulk mail, which recipients can unsubscribe from
+	Fields      []Field `json:"fields"`
+
+	file  string
+	build func(l *i18n.Localizer, v Values) TemplateData
+}
+
+// Rendered is a template rendered for sending
+type Rendered struct {
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// ValidationErrors maps data fields to the reason their value was rejected
+type ValidationErrors map[string]string
+
+func (e ValidationErrors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ": " + e[key]
+	}
+	return "invalid template data: " + strings.Join(parts, "; ")
+}
+
+// Values holds template data decoded according to a definition. Getters
+// return the zero value for optional fields that were not provided.
+type Values map[string]any
+
+func (v Values) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+func (v Values) Int(name string) int {
+	i, _ := v[name].(int)
+	return i
+}
+
+func (v Values) Time(name string) *time.Time {
+	t, ok := v[name].(time.Time)
+	if !ok {
+		return nil
+	}
+	return &t
+}
+
+var catalog = []Definition{
+	{
+		Name:        "welcome",
+		Localized:   true,
+		Description: "Greets a new user and links to the dashboard",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "login_url", Type: FieldURL, Required: true, Description: "Dashboard link"},
+		},
+		file: "welcome.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			return welcomeData(l, v.String("name"), v.String("login_url"))
+		},
+	},
+	{
+		Name:        "password_reset",
+		Localized:   true,
+		Description: "Sends a link to set a new password",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "reset_url", Type: FieldURL, Required: true, Description: "Password reset link"},
+		},
+		file: "password_reset.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			return passwordResetData(l, v.String("name"), v.String("reset_url"))
+		},
+	},
+	{
+		Name:        "verification",
+		Localized:   true,
+		Description: "Asks the user to confirm their email address",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "verification_url", Type: FieldURL, Required: true, Description: "Verification link"},
+		},
+		file: "verification_email.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			return verificationData(l, v.String("name"), v.String("verification_url"))
+		},
+	},
+	{
+		Name:        "daily_report",
+		Localized:   true,
+		Bulk:        true,
+		Description: "Daily activity summary",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "new_users", Type: FieldInt, Description: "Users registered in the last day; defaults to 0"},
+			{Name: "date", Type: FieldDateTime, Description: "Day the report covers; defaults to today"},
+		},
+		file: "daily_report.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			day := time.Now()
+			if d := v.Time("date"); d != nil {
+				day = *d
+			}
+			return dailyReportData(l, v.String("name"), v.Int("new_users"), day)
+		},
+	},
+	{
+		Name:        "account_suspended",
+		Localized:   true,
+		Description: "Tells a user their account was suspended",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "reason", Type: FieldString, Description: "Reason shown to the user"},
+			{Name: "suspended_until", Type: FieldDateTime, Description: "End of the suspension; omit for an indefinite one"},
+		},
+		file: "account_suspended.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			return accountSuspendedData(l, v.String("name"), v.String("reason"), v.Time("suspended_until"))
+		},
+	},
+	{
+		Name:        "data_export_ready",
+		Localized:   true,
+		Description: "Links to a finished personal data export",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "download_url", Type: FieldURL, Required: true, Description: "Export download link"},
+			{Name: "expires_at", Type: FieldDateTime, Required: true, Description: "When the link stops working"},
+		},
+		file: "data_export_ready.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			return dataExportReadyData(l, v.String("name"), v.String("download_url"), *v.Time("expires_at"))
+		},
+	},
+	{
+		Name:        "account_deletion_scheduled",
+		Localized:   true,
+		Description: "Confirms that an account deletion was scheduled",
+		Fields: []Field{
+			{Name: "name", Type: FieldString, Required: true, Description: "Recipient name"},
+			{Name: "scheduled_for", Type: FieldDateTime, Required: true, Description: "When the account is deleted"},
+		},
+		file: "account_deletion_scheduled.html",
+		build: func(l *i18n.Localizer, v Values) TemplateData {
+			return accountDeletionScheduledData(l, v.String("name"), *v.Time("scheduled_for"))
+		},
+	},
+}
+
+// Catalog returns the templates that can be rendered by name, ordered by name
+func Catalog() []Definition {
+	defs := make([]Definition, len(catalog))
+	copy(defs, catalog)
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// LookupTemplate returns the definition of the named template
+func LookupTemplate(name string) (Definition, bool) {
+	for _, def := range catalog {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Render validates data against the named template's fields and renders the
+// embedded template in the best supported match of locales (see i18n.Bundle.Match).
+// It returns ErrUnknownTemplate or ValidationErrors for bad input.
+func Render(name string, data map[string]json.RawMessage, locales ...string) (*Rendered, error) {
+	def, ok := LookupTemplate(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	values, err := def.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return def.Render(Messages.Localizer(locales...), values, nil)
+}
+
+// Render renders decoded values with src, e.g. a published database version,
+// or with the embedded template when src is nil. Localized templates use l
+// for their copy and dates.
+func (d Definition) Render(l *i18n.Localizer, values Values, src *Source) (*Rendered, error) {
+	ctx := Context{TemplateData: d.build(l, values), Data: values}
+
+	if src != nil {
+		compiled, err := compile(*src)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := compiled.execute(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render template %s: %w", d.Name, err)
+		}
+		rendered.Locale = ctx.Lang
+		return rendered, nil
+	}
+
+	body, err := generateEmailFromTemplate(d.file, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", d.Name, err)
+	}
+
+	return &Rendered{
+		Locale:  ctx.Lang,
+		Subject: ctx.Subject,
+		HTML:    body,
+		Text:    PlainText(ctx.TemplateData),
+	}, nil
+}
+
+// Decode checks data against the definition's fields and returns the typed values.
+// Missing required fields, unknown fields and values of the wrong type are all reported.
+// Optional fields that were not provided are set to their zero value (nil for datetime).
+func (d Definition) Decode(data map[string]json.RawMessage) (Values, error) {
+	errs := ValidationErrors{}
+	values := make(Values, len(d.Fields))
+
+	known := make(map[string]bool, len(d.Fields))
+	for _, field := range d.Fields {
+		known[field.Name] = true
+
+		raw, ok := data[field.Name]
+		if !ok || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if field.Required {
+				errs[field.Name] = "is required"
+			}
+			values[field.Name] = zeroValue(field.Type)
+			continue
+		}
+
+		value, err := decodeField(field, raw)
+		if err != nil {
+			errs[field.Name] = err.Error()
+			continue
+		}
+		values[field.Name] = value
+	}
+
+	for name := range data {
+		if !known[name] {
+			errs[name] = "unknown field"
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return values, nil
+}
+
+func zeroValue(t FieldType) any {
+	switch t {
+	case FieldInt:
+		return 0
+	case FieldDateTime:
+		return nil
+	default:
+		return ""
+	}
+}
+
+func decodeField(field Field, raw json.RawMessage) (any, error) {
+	if field.Type == FieldInt {
+		var i int
+		if err := json.Unmarshal(raw, &i); err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return i, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, errors.New("must be a string")
+	}
+	s = strings.TrimSpace(s)
+	if field.Required && s == "" {
+		return nil, errors.New("is required")
+	}
+
+	switch field.Type {
+	case FieldURL:
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("must be an absolute http or https URL")
+		}
+	case FieldDateTime:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, errors.New("must be an RFC 3339 timestamp")
+		}
+		return t, nil
+	}
+	return s, nil
+}
+
+var (
+	headElement = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+	blockEndTag = regexp.MustCompile(`(?i)</(p|div|li|ul|ol|h[1-6]|tr)>|<br\s*/?>`)
+	listItemTag = regexp.MustCompile(`(?i)<li[^>]*>`)
+	anyTag      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// PlainText renders the text/plain alternative of an email built from data
+func PlainText(data TemplateData) string {
+	var b strings.Builder
+	if data.Greeting != "" {
+		b.WriteString(data.Greeting + ",\n\n")
+	}
+
+	b.WriteString(HTMLToText(data.Content))
+
+	if data.ButtonURL != "" {
+		b.WriteString("\n" + data.ButtonText + ": " + data.ButtonURL + "\n")
+	}
+	if data.Footer != "" {
+		b.WriteString("\n" + data.Footer + "\n")
+	}
+	if data.Copyright != "" {
+		b.WriteString("\n" + data.Copyright + "\n")
+	} else {
+		fmt.Fprintf(&b, "\n© %d Your Company. All rights reserved.\n", data.CurrentYear)
+	}
+	return b.String()
+}
+
+// HTMLToText reduces HTML to its text, one line per block element. It is
+// meant for the simple markup of email templates, not arbitrary documents.
+func HTMLToText(s string) string {
+	s = headElement.ReplaceAllString(s, "")
+	s = blockEndTag.ReplaceAllString(s, "\n")
+	s = listItemTag.ReplaceAllString(s, "- ")
+	s = html.UnescapeString(anyTag.ReplaceAllString(s, ""))
+
+	var b strings.Builder
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
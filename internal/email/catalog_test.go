@@ -0,0 +1,89 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rawData(t *testing.T, data map[string]any) map[string]json.RawMessage {
+	t.Helper()
+	raw := make(map[string]json.RawMessage, len(data))
+	for key, value := range data {
+		b, err := json.Marshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw[key] = b
+	}
+	return raw
+}
+
+func TestCatalog_TemplatesExist(t *testing.T) {
+	for _, def := range Catalog() {
+		assert.NotNil(t, Templates.Lookup(def.file), def.Name)
+		assert.NotEmpty(t, def.Fields, def.Name)
+	}
+}
+
+func TestRender(t *testing.T) {
+	rendered, err := Render("data_export_ready", rawData(t, map[string]any{
+		"name":         "Jane <admin>",
+		"download_url": "https://example.com/exports/abc",
+		"expires_at":   "2025-08-20T10:00:00Z",
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Your Data Export Is Ready", rendered.Subject)
+	assert.Contains(t, rendered.HTML, `href="https://example.com/exports/abc"`)
+	assert.Contains(t, rendered.HTML, "Jane &lt;admin&gt;")
+	assert.Contains(t, rendered.HTML, "August 20, 2025 at 10:00 UTC")
+
+	assert.Contains(t, rendered.Text, "Hello Jane <admin>,")
+	assert.Contains(t, rendered.Text, "The link expires on August 20, 2025 at 10:00 UTC.")
+	assert.Contains(t, rendered.Text, "Download My Data: https://example.com/exports/abc")
+	assert.NotContains(t, rendered.Text, "<p>")
+}
+
+func TestRender_OptionalFields(t *testing.T) {
+	rendered, err := Render("daily_report", rawData(t, map[string]any{"name": "Jane"}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, rendered.Text, "- No new users")
+
+	rendered, err = Render("daily_report", rawData(t, map[string]any{"name": "Jane", "new_users": 12}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, rendered.HTML, "<li>12 new users</li>")
+}
+
+func TestRender_Validation(t *testing.T) {
+	_, err := Render("missing", nil)
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+
+	_, err = Render("account_deletion_scheduled", rawData(t, map[string]any{
+		"scheduled_for": "tomorrow",
+		"extra":         true,
+	}))
+	var validationErrs ValidationErrors
+	if !assert.True(t, errors.As(err, &validationErrs)) {
+		return
+	}
+	assert.Equal(t, ValidationErrors{
+		"name":          "is required",
+		"scheduled_for": "must be an RFC 3339 timestamp",
+		"extra":         "unknown field",
+	}, validationErrs)
+
+	_, err = Render("welcome", rawData(t, map[string]any{"name": "Jane", "login_url": "/relative"}))
+	assert.EqualError(t, err, "invalid template data: login_url: must be an absolute http or https URL")
+
+	_, err = Render("daily_report", rawData(t, map[string]any{"name": "Jane", "new_users": "12"}))
+	assert.EqualError(t, err, "invalid template data: new_users: must be an integer")
+}
@@ -0,0 +1,102 @@
+package email
+
+import (
+	"encoding/json"
+	"io/fs"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Every locale must translate every message of the default locale, otherwise
+// recipients get a mix of languages
+func TestLocales_Complete(t *testing.T) {
+	keys := func(path string) []string {
+		data, err := fs.ReadFile(localeFS, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var f struct {
+			Messages map[string]json.RawMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(data, &f); err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for key := range f.Messages {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	want := keys("locales/" + DefaultLocale + ".json")
+	for _, locale := range Messages.Locales()[1:] {
+		assert.Equal(t, want, keys("locales/"+locale+".json"), locale)
+	}
+}
+
+func TestRender_Localized(t *testing.T) {
+	data := rawData(t, map[string]any{
+		"name":      "Jane",
+		"new_users": 1,
+		"date":      time.Date(2025, 8, 16, 9, 0, 0, 0, time.UTC),
+	})
+
+	rendered, err := Render("daily_report", data, "de-AT,de;q=0.9,en;q=0.5")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "de", rendered.Locale)
+	assert.Equal(t, "Ihr Tagesbericht", rendered.Subject)
+	assert.Contains(t, rendered.HTML, `<html lang="de">`)
+	assert.Contains(t, rendered.HTML, "16. August 2025")
+	assert.Contains(t, rendered.HTML, "1 neuer Benutzer")
+	assert.Contains(t, rendered.Text, "Alle Rechte vorbehalten.")
+
+	// Unsupported locales fall back to English
+	rendered, err = Render("daily_report", data, "ja")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "en", rendered.Locale)
+	assert.Contains(t, rendered.Text, "Here's your activity summary for August 16, 2025:")
+	assert.Contains(t, rendered.Text, "- 1 new user")
+}
+
+func TestPasswordResetEmail_Locale(t *testing.T) {
+	subject, body, err := PasswordResetEmail("fr-CA", "Jane", "https://example.com/r/1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, Messages.Localizer("fr").T("password_reset.subject"), subject)
+	assert.Contains(t, body, `<html lang="fr">`)
+	assert.Contains(t, body, `href="https://example.com/r/1"`)
+
+	subject, _, err = PasswordResetEmail("", "Jane", "https://example.com/r/1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Password Reset Request", subject)
+	}
+}
+
+func TestAccountSuspendedEmail_Locale(t *testing.T) {
+	until := time.Date(2025, 9, 1, 12, 30, 0, 0, time.UTC)
+
+	subject, body, err := AccountSuspendedEmail("de-DE", "Jane", "Spam", &until)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Ihr Konto wurde gesperrt", subject)
+	assert.Contains(t, body, `<html lang="de">`)
+	assert.Contains(t, body, "Grund: Spam")
+	assert.Contains(t, body, "Die Sperre endet am 1. September 2025 um 12:30 UTC.")
+
+	subject, body, err = AccountSuspendedEmail("", "Jane", "", nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Your Account Has Been Suspended", subject)
+	assert.NotContains(t, body, "Reason:")
+}
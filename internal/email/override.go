@@ -0,0 +1,155 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Source is template markup stored outside the binary, such as a published
+// database version. HTML is parsed alongside the embedded files and may use
+// {{template "base.html" .}}. An empty Subject keeps the catalog subject and
+// an empty Text is derived from the rendered HTML.
+type Source struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Context is what templates execute against: the TemplateData fields built
+// by the catalog, plus the decoded request values under .Data, e.g. {{.Data.reset_url}}.
+type Context struct {
+	TemplateData
+	Data Values
+}
+
+type compiledSource struct {
+	subject *texttemplate.Template
+	html    *template.Template
+	text    *texttemplate.Template
+}
+
+// compile parses src, reporting syntax errors per part
+func compile(src Source) (*compiledSource, error) {
+	errs := ValidationErrors{}
+	compiled := &compiledSource{}
+
+	if strings.TrimSpace(src.HTML) == "" {
+		errs["html_body"] = "is required"
+	} else {
+		set, err := baseTemplates.Clone()
+		if err != nil {
+			return nil, err
+		}
+		compiled.html, err = set.New("source").Option("missingkey=error").Parse(src.HTML)
+		if err != nil {
+			errs["html_body"] = err.Error()
+		}
+	}
+
+	if src.Subject != "" {
+		var err error
+		compiled.subject, err = parseText("subject", src.Subject)
+		if err != nil {
+			errs["subject"] = err.Error()
+		}
+	}
+	if src.Text != "" {
+		var err error
+		compiled.text, err = parseText("text", src.Text)
+		if err != nil {
+			errs["text_body"] = err.Error()
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return compiled, nil
+}
+
+func parseText(name, text string) (*texttemplate.Template, error) {
+	return texttemplate.New(name).
+		Funcs(texttemplate.FuncMap{"formatDate": formatDate}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+func (c *compiledSource) execute(ctx Context) (*Rendered, error) {
+	var buf bytes.Buffer
+
+	if c.subject != nil {
+		if err := c.subject.Execute(&buf, ctx); err != nil {
+			return nil, err
+		}
+		// A subject is a single header line
+		ctx.Subject = strings.Join(strings.Fields(buf.String()), " ")
+		buf.Reset()
+	}
+
+	if err := c.html.Execute(&buf, ctx); err != nil {
+		return nil, err
+	}
+	rendered := &Rendered{Subject: ctx.Subject, HTML: buf.String()}
+
+	if c.text != nil {
+		buf.Reset()
+		if err := c.text.Execute(&buf, ctx); err != nil {
+			return nil, err
+		}
+		rendered.Text = buf.String()
+	} else {
+		rendered.Text = HTMLToText(rendered.HTML)
+	}
+
+	return rendered, nil
+}
+
+// ValidateSource checks that src parses and renders for the named template,
+// both with every field set and with only the required ones. Errors are
+// returned as ValidationErrors keyed by subject, html_body and text_body.
+func ValidateSource(name string, src Source) error {
+	def, ok := LookupTemplate(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	compiled, err := compile(src)
+	if err != nil {
+		return err
+	}
+
+	for _, values := range []Values{def.sampleValues(true), def.sampleValues(false)} {
+		ctx := Context{TemplateData: def.build(Messages.Localizer(), values), Data: values}
+		if _, err := compiled.execute(ctx); err != nil {
+			return ValidationErrors{"template": err.Error()}
+		}
+	}
+	return nil
+}
+
+// sampleValues returns placeholder values for the definition's fields
+func (d Definition) sampleValues(optional bool) Values {
+	values := make(Values, len(d.Fields))
+	for _, field := range d.Fields {
+		if !field.Required && !optional {
+			values[field.Name] = zeroValue(field.Type)
+			continue
+		}
+		switch field.Type {
+		case FieldInt:
+			values[field.Name] = 1
+		case FieldURL:
+			values[field.Name] = "https://example.com/" + url.PathEscape(field.Name)
+		case FieldDateTime:
+			values[field.Name] = time.Now()
+		default:
+			values[field.Name] = "Sample " + field.Name
+		}
+	}
+	return values
+}
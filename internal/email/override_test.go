@@ -0,0 +1,81 @@
+package email
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderSource(t *testing.T) {
+	def, _ := LookupTemplate("password_reset")
+	values, err := def.Decode(rawData(t, map[string]any{"name": "Jane", "reset_url": "https://example.com/r/1"}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rendered, err := def.Render(Messages.Localizer(), values, &Source{
+		Subject: "Reset for {{.Data.name}}",
+		HTML: `<h2>Hi {{.Data.name}}</h2>` +
+			`<p>Use <a href="{{.Data.reset_url}}">this link</a>.</p><p>{{.Footer}}</p>`,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Reset for Jane", rendered.Subject)
+	assert.Contains(t, rendered.HTML, `<a href="https://example.com/r/1">`)
+	assert.Equal(t, "Hi Jane\nUse this link.\nThis password reset link will expire in 24 hours.\n", rendered.Text)
+}
+
+func TestRenderSource_BaseLayout(t *testing.T) {
+	def, _ := LookupTemplate("account_suspended")
+	values, err := def.Decode(rawData(t, map[string]any{"name": "Jane <x>"}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	rendered, err := def.Render(Messages.Localizer(), values, &Source{
+		HTML: `{{template "base.html" .}}`,
+		Text: "Hello {{.Data.name}}{{with .Data.suspended_until}} until {{formatDate .}}{{end}}",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Your Account Has Been Suspended", rendered.Subject)
+	assert.Contains(t, rendered.HTML, "<title>Your Account Has Been Suspended</title>")
+	assert.Contains(t, rendered.HTML, "Hello Jane &lt;x&gt;")
+	assert.Equal(t, "Hello Jane <x>", rendered.Text)
+}
+
+func TestValidateSource(t *testing.T) {
+	assert.NoError(t, ValidateSource("welcome", Source{
+		Subject: "Welcome, {{.Data.name}}",
+		HTML:    `{{template "base.html" .}}`,
+	}))
+
+	err := ValidateSource("missing", Source{HTML: "x"})
+	assert.True(t, errors.Is(err, ErrUnknownTemplate))
+
+	err = ValidateSource("welcome", Source{Subject: "{{.Data.name", HTML: " ", Text: "{{end}}"})
+	var validationErrs ValidationErrors
+	if assert.True(t, errors.As(err, &validationErrs)) {
+		assert.Contains(t, validationErrs, "subject")
+		assert.Equal(t, "is required", validationErrs["html_body"])
+		assert.Contains(t, validationErrs, "text_body")
+	}
+
+	// Parses, but refers to a field the template does not have
+	err = ValidateSource("welcome", Source{HTML: "<p>{{.Data.reset_url}}</p>"})
+	if assert.True(t, errors.As(err, &validationErrs)) {
+		assert.Contains(t, validationErrs["template"], "reset_url")
+	}
+
+	err = ValidateSource("welcome", Source{HTML: "<p>{{.Missing}}</p>"})
+	assert.True(t, errors.As(err, &validationErrs))
+
+	// Only fails when the optional value is absent
+	err = ValidateSource("account_suspended", Source{HTML: "<p>{{.Data.suspended_until.Year}}</p>"})
+	assert.True(t, errors.As(err, &validationErrs))
+}
@@ -3,27 +3,73 @@ package email
 import (
 	"embed"
 	"html/template"
+	"time"
+
+	"base-code-go-gin-clean/internal/pkg/i18n"
 )
 
 //go:embed templates/*.html
 var templateFS embed.FS
 
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// DefaultLocale is used when no requested locale is supported
+const DefaultLocale = "en"
+
 var (
 	// Templates holds all parsed email templates
 	Templates *template.Template
+
+	// Messages holds the localized email copy, one catalog per locale
+	Messages *i18n.Bundle
+
+	// baseTemplates is an unexecuted copy of Templates that stored overrides
+	// are parsed into, so they can use {{template "base.html" .}}. html/template
+	// cannot clone a set once it has been executed.
+	baseTemplates *template.Template
 )
 
+// templateFuncs are available to embedded and stored templates
+var templateFuncs = template.FuncMap{
+	"safeHTML": func(html string) template.HTML {
+		return template.HTML(html)
+	},
+	"formatDate": formatDate,
+}
+
 func init() {
 	// Parse all template files
-	tmpl, err := template.New("emails").Funcs(template.FuncMap{
-		"safeHTML": func(html string) template.HTML {
-			return template.HTML(html)
-		},
-	}).ParseFS(templateFS, "templates/*.html")
+	tmpl, err := template.New("emails").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html")
 
 	if err != nil {
 		panic("failed to parse email templates: " + err.Error())
 	}
 
+	base, err := tmpl.Clone()
+	if err != nil {
+		panic("failed to clone email templates: " + err.Error())
+	}
+
+	messages, err := i18n.Load(localeFS, "locales", DefaultLocale)
+	if err != nil {
+		panic("failed to load email messages: " + err.Error())
+	}
+
 	Templates = tmpl
+	baseTemplates = base
+	Messages = messages
+}
+
+// formatDate formats a datetime field the way the built-in templates do; nil prints nothing
+func formatDate(t any) string {
+	switch v := t.(type) {
+	case time.Time:
+		return v.UTC().Format("January 2, 2006 at 15:04 MST")
+	case *time.Time:
+		if v != nil {
+			return v.UTC().Format("January 2, 2006 at 15:04 MST")
+		}
+	}
+	return ""
 }
@@ -9,4 +9,6 @@ type TemplateData struct {
 	ButtonText  string
 	Footer      string
 	CurrentYear int
+	Lang        string // BCP 47 tag for the html lang attribute; omitted when empty
+	Copyright   string // Localized copyright line; defaults to the English one
 }
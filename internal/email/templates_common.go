@@ -2,109 +2,188 @@ package email
 
 import (
 	"bytes"
-	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
 	"time"
+
+	"base-code-go-gin-clean/internal/pkg/i18n"
 )
 
-// WelcomeEmail creates a welcome email template
-func WelcomeEmail(recipientName, loginURL string) (subject, body string, err error) {
-	templateData := TemplateData{
-		Subject:  "Welcome to Our Platform!",
-		Greeting: "Hello " + recipientName,
-		Content: "<p>Thank you for joining our platform! We're excited to have you on board.</p>" +
-			"<p>Start exploring all the features we have to offer and make the most of your experience.</p>",
-		ButtonURL:   loginURL,
-		ButtonText:  "Go to Dashboard",
-		Footer:      "If you did not create an account, please contact our support team immediately.",
-		CurrentYear: time.Now().Year(),
-	}
+// Validity of the links sent in password reset and verification emails
+const (
+	passwordResetLinkHours = 24
+	verificationLinkHours  = 24
+)
 
-	body, err = generateEmailFromTemplate("welcome.html", templateData)
-	if err != nil {
-		return "", "", err
-	}
+// WelcomeEmail creates a welcome email in the best supported match for locale,
+// a language tag or Accept-Language value, falling back to English
+func WelcomeEmail(locale, recipientName, loginURL string) (subject, body string, err error) {
+	return renderEmail("welcome.html", welcomeData(Messages.Localizer(locale), recipientName, loginURL))
+}
 
-	return templateData.Subject, body, nil
+func welcomeData(l *i18n.Localizer, recipientName, loginURL string) TemplateData {
+	data := localizedData(l)
+	data.Subject = l.T("welcome.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(l.T("welcome.intro"), l.T("welcome.explore"))
+	data.ButtonURL = loginURL
+	data.ButtonText = l.T("welcome.button")
+	data.Footer = l.T("welcome.footer")
+	return data
 }
 
-// PasswordResetEmail creates a password reset email template
-func PasswordResetEmail(recipientName, resetURL string) (subject, body string, err error) {
-	templateData := TemplateData{
-		Subject:  "Password Reset Request",
-		Greeting: "Hello " + recipientName,
-		Content: "<p>We received a request to reset your password. Click the button below to set a new password.</p>" +
-			"<p>If you didn't request this, you can safely ignore this email.</p>",
-		ButtonURL:   resetURL,
-		ButtonText:  "Reset Password",
-		Footer:      "This password reset link will expire in 24 hours.",
-		CurrentYear: time.Now().Year(),
-	}
+// PasswordResetEmail creates a password reset email in the best supported match
+// for locale, a language tag or Accept-Language value, falling back to English
+func PasswordResetEmail(locale, recipientName, resetURL string) (subject, body string, err error) {
+	return renderEmail("password_reset.html", passwordResetData(Messages.Localizer(locale), recipientName, resetURL))
+}
 
-	body, err = generateEmailFromTemplate("password_reset.html", templateData)
-	if err != nil {
-		return "", "", err
+func passwordResetData(l *i18n.Localizer, recipientName, resetURL string) TemplateData {
+	data := localizedData(l)
+	data.Subject = l.T("password_reset.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(l.T("password_reset.intro"), l.T("password_reset.ignore"))
+	data.ButtonURL = resetURL
+	data.ButtonText = l.T("password_reset.button")
+	data.Footer = l.Plural("password_reset.expiry", passwordResetLinkHours)
+	return data
+}
+
+// DailyReportEmail creates a daily report email in the best supported match for
+// locale, a language tag or Accept-Language value, falling back to English
+func DailyReportEmail(locale, recipientName string, reportData map[string]interface{}) (subject, body string, err error) {
+	// Example report data usage
+	newUsers := 0
+	switch val := reportData["newUsers"].(type) {
+	case int:
+		newUsers = val
+	case string:
+		newUsers, _ = strconv.Atoi(val)
 	}
 
-	return templateData.Subject, body, nil
+	return renderEmail("daily_report.html", dailyReportData(Messages.Localizer(locale), recipientName, newUsers, time.Now()))
 }
 
-// DailyReportEmail creates a daily report email template
-func DailyReportEmail(recipientName string, reportData map[string]interface{}) (subject, body string, err error) {
-	// Example report data usage
-	newUsers := "0"
-	if val, ok := reportData["newUsers"]; ok {
-		newUsers = val.(string)
+func dailyReportData(l *i18n.Localizer, recipientName string, newUsers int, day time.Time) TemplateData {
+	data := localizedData(l)
+	data.Subject = l.T("daily_report.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(l.T("daily_report.intro", "date", l.FormatDate(day))) +
+		"<ul>" +
+		"<li>" + template.HTMLEscapeString(l.Plural("daily_report.new_users", newUsers)) + "</li>" +
+		"</ul>"
+	data.Footer = l.T("daily_report.footer")
+	return data
+}
+
+// VerifyEmail creates an email verification email in the best supported match
+// for locale, a language tag or Accept-Language value, falling back to English
+func VerifyEmail(locale, recipientName, verificationURL string) (subject, body string, err error) {
+	return renderEmail("verification_email.html", verificationData(Messages.Localizer(locale), recipientName, verificationURL))
+}
+
+func verificationData(l *i18n.Localizer, recipientName, verificationURL string) TemplateData {
+	data := localizedData(l)
+	data.Subject = l.T("verification.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(l.T("verification.intro"), l.Plural("verification.expiry", verificationLinkHours))
+	data.ButtonURL = verificationURL
+	data.ButtonText = l.T("verification.button")
+	data.Footer = l.T("verification.footer")
+	return data
+}
+
+// localizedData returns TemplateData with the locale-dependent layout fields set
+func localizedData(l *i18n.Localizer) TemplateData {
+	year := time.Now().Year()
+	return TemplateData{
+		CurrentYear: year,
+		Lang:        l.Locale(),
+		Copyright:   l.T("copyright", "year", strconv.Itoa(year)),
 	}
+}
 
-	templateData := TemplateData{
-		Subject:  "Your Daily Report",
-		Greeting: "Hello " + recipientName,
-		Content: "<p>Here's your daily activity summary:</p>" +
-			"<ul>" +
-			"<li>New Users: " + newUsers + "</li>" +
-			"</ul>",
-		Footer:      "This is an automated message, please do not reply to this email.",
-		CurrentYear: time.Now().Year(),
+// paragraphs escapes plain-text messages and wraps each in <p>
+func paragraphs(texts ...string) string {
+	var b strings.Builder
+	for _, text := range texts {
+		b.WriteString("<p>" + template.HTMLEscapeString(text) + "</p>")
 	}
+	return b.String()
+}
 
-	body, err = generateEmailFromTemplate("daily_report.html", templateData)
-	if err != nil {
-		return "", "", err
+// AccountSuspendedEmail creates an account suspension notice in the best supported
+// match for locale, falling back to English
+func AccountSuspendedEmail(locale, recipientName, reason string, suspendedUntil *time.Time) (subject, body string, err error) {
+	return renderEmail("account_suspended.html", accountSuspendedData(Messages.Localizer(locale), recipientName, reason, suspendedUntil))
+}
+
+func accountSuspendedData(l *i18n.Localizer, recipientName, reason string, suspendedUntil *time.Time) TemplateData {
+	texts := []string{l.T("account_suspended.intro")}
+	if reason != "" {
+		texts = append(texts, l.T("account_suspended.reason", "reason", reason))
 	}
-	return templateData.Subject, body, nil
-}
-
-// VerifyEmail creates an email verification template
-func VerifyEmail(recipientName, verificationURL string) (subject, body string, err error) {
-	templateData := TemplateData{
-		Subject:  "Verify Your Email Address",
-		Greeting: "Hello " + recipientName,
-		Content: "<p>Thank you for signing up! Please verify your email address by clicking the button below.</p>" +
-			"<p>This link will expire in 24 hours.</p>",
-		ButtonURL:   verificationURL,
-		ButtonText:  "Verify Email",
-		Footer:      "If you didn't create an account, you can safely ignore this email.",
-		CurrentYear: time.Now().Year(),
+	if suspendedUntil != nil {
+		texts = append(texts, l.T("account_suspended.until", "date", l.FormatDateTime(*suspendedUntil)))
 	}
 
-	body, err = generateEmailFromTemplate("verification_email.html", templateData)
+	data := localizedData(l)
+	data.Subject = l.T("account_suspended.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(texts...)
+	data.Footer = l.T("account_suspended.footer")
+	return data
+}
+
+// DataExportReadyEmail creates a notice with the download link for a personal data
+// export in the best supported match for locale, falling back to English
+func DataExportReadyEmail(locale, recipientName, downloadURL string, expiresAt time.Time) (subject, body string, err error) {
+	return renderEmail("data_export_ready.html", dataExportReadyData(Messages.Localizer(locale), recipientName, downloadURL, expiresAt))
+}
+
+func dataExportReadyData(l *i18n.Localizer, recipientName, downloadURL string, expiresAt time.Time) TemplateData {
+	data := localizedData(l)
+	data.Subject = l.T("data_export.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(l.T("data_export.intro"), l.T("data_export.expiry", "date", l.FormatDateTime(expiresAt)))
+	data.ButtonURL = downloadURL
+	data.ButtonText = l.T("data_export.button")
+	data.Footer = l.T("data_export.footer")
+	return data
+}
+
+// AccountDeletionScheduledEmail creates a confirmation that an account deletion was
+// scheduled in the best supported match for locale, falling back to English
+func AccountDeletionScheduledEmail(locale, recipientName string, scheduledFor time.Time) (subject, body string, err error) {
+	return renderEmail("account_deletion_scheduled.html", accountDeletionScheduledData(Messages.Localizer(locale), recipientName, scheduledFor))
+}
+
+func accountDeletionScheduledData(l *i18n.Localizer, recipientName string, scheduledFor time.Time) TemplateData {
+	data := localizedData(l)
+	data.Subject = l.T("account_deletion.subject")
+	data.Greeting = l.T("greeting", "name", recipientName)
+	data.Content = paragraphs(l.T("account_deletion.intro"), l.T("account_deletion.scheduled", "date", l.FormatDateTime(scheduledFor)))
+	data.Footer = l.T("account_deletion.footer")
+	return data
+}
+
+// renderEmail renders a template file and returns the subject with the HTML body
+func renderEmail(templateName string, data TemplateData) (subject, body string, err error) {
+	body, err = generateEmailFromTemplate(templateName, Context{TemplateData: data})
 	if err != nil {
 		return "", "", err
 	}
-
-	return templateData.Subject, body, nil
+	return data.Subject, body, nil
 }
 
 // generateEmailFromTemplate is a helper function to render email templates
-func generateEmailFromTemplate(templateName string, data TemplateData) (string, error) {
+func generateEmailFromTemplate(templateName string, data Context) (string, error) {
 	var buf bytes.Buffer
 	err := Templates.ExecuteTemplate(&buf, templateName, data)
 	if err != nil {
 		return "", err
 	}
 
-	fmt.Println("==== BUFFER ====")
-	fmt.Println(buf.String())
-
 	return buf.String(), nil
 }
@@ -1,9 +1,11 @@
 package auth
 
 import (
+	domainUser "base-code-go-gin-clean/internal/domain/user"
 	"base-code-go-gin-clean/internal/handler/auth/dto"
 	httpPkg "base-code-go-gin-clean/internal/pkg/http"
 	"base-code-go-gin-clean/internal/service"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -159,6 +161,7 @@ func clearAuthCookies(c *gin.Context) {
 // @Success 200 {object} handler.SuccessResponse{data=dto.LoginResponse} "Login successful"
 // @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid input format"
 // @Failure 401 {object} handler.ErrorResponse "Unauthorized: Invalid email or password"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Account suspended, locked or pending"
 // @Failure 500 {object} handler.ErrorResponse "Internal Server Error: Failed to process login"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -170,6 +173,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	loginResponse, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
+		if errors.Is(err, domainUser.ErrAccountInactive) {
+			httpPkg.Forbidden(c, "User account is not active")
+			return
+		}
 		httpPkg.Unauthorized(c, "Invalid email or password")
 		return
 	}
@@ -217,6 +224,11 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Call service to refresh token
 	tokenResponse, err := h.authService.RefreshToken(c.Request.Context(), refreshToken)
 	if err != nil {
+		if errors.Is(err, domainUser.ErrAccountInactive) {
+			clearAuthCookies(c)
+			httpPkg.Forbidden(c, "User account is not active")
+			return
+		}
 		httpPkg.Unauthorized(c, "Invalid or expired refresh token")
 		return
 	}
@@ -0,0 +1,94 @@
+package avatar
+
+import (
+	"errors"
+	"net/http"
+
+	domainUser "base-code-go-gin-clean/internal/domain/user"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	avatarService "base-code-go-gin-clean/internal/service/avatar"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys for storing values in the request context
+const (
+	userIDKey = "userID"
+)
+
+// formField is the multipart field that carries the image
+const formField = "avatar"
+
+type AvatarHandler struct {
+	avatarService avatarService.AvatarService
+}
+
+func NewAvatarHandler(avatarService avatarService.AvatarService) *AvatarHandler {
+	return &AvatarHandler{
+		avatarService: avatarService,
+	}
+}
+
+// UploadAvatar handles profile picture uploads
+// @Summary Upload avatar
+// @Description Upload a JPEG, PNG, GIF or WebP image as the authenticated user's avatar. The image is cropped to a square and resized to 64, 128 and 256 pixels.
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} handler.SuccessResponse{data=dto.UserResponse} "Avatar updated"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Missing or unreadable file"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 413 {object} handler.ErrorResponse "Request Entity Too Large"
+// @Failure 415 {object} handler.ErrorResponse "Unsupported Media Type"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /users/me/avatar [put]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	userID := c.GetString(userIDKey)
+	if userID == "" {
+		httpPkg.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	fileHeader, err := c.FormFile(formField)
+	if err != nil {
+		span.RecordError(err)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			httpPkg.ErrorResponse(c, httpPkg.StatusRequestEntityTooLarge, "Avatar image is too large", nil)
+			return
+		}
+		httpPkg.BadRequest(c, "An image file is required in the 'avatar' field", nil)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Failed to read uploaded file", nil)
+		return
+	}
+	defer file.Close()
+
+	userResponse, err := h.avatarService.Upload(ctx, userID, file)
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case errors.Is(err, avatarService.ErrUnsupportedFormat):
+			httpPkg.ErrorResponse(c, httpPkg.StatusUnsupportedMediaType, "Avatar must be a JPEG, PNG, GIF or WebP image", nil)
+		case errors.Is(err, avatarService.ErrImageTooLarge):
+			httpPkg.ErrorResponse(c, httpPkg.StatusRequestEntityTooLarge, "Avatar image dimensions are too large", nil)
+		case errors.Is(err, domainUser.ErrUserNotFound):
+			httpPkg.NotFound(c, "User not found")
+		default:
+			httpPkg.InternalServerError(c, "Failed to upload avatar")
+		}
+		return
+	}
+
+	httpPkg.Success(c, userResponse)
+}
@@ -0,0 +1,117 @@
+package avatar_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	avatarHandler "base-code-go-gin-clean/internal/handler/avatar"
+	"base-code-go-gin-clean/internal/middleware"
+	avatarService "base-code-go-gin-clean/internal/service/avatar"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockAvatarService struct {
+	mock.Mock
+}
+
+func (m *mockAvatarService) Upload(ctx context.Context, userID string, r io.Reader) (*user.UserResponse, error) {
+	args := m.Called(ctx, userID, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.UserResponse), args.Error(1)
+}
+
+func setupRouter(h *avatarHandler.AvatarHandler, userID string, maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/users/me/avatar", func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	}, middleware.BodyLimitMiddleware(maxBytes), h.UploadAvatar)
+	return r
+}
+
+func multipartBody(t *testing.T, field string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, "avatar.png")
+	assert.NoError(t, err)
+	_, _ = part.Write(content)
+	assert.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestAvatarHandler_UploadAvatar(t *testing.T) {
+	userID := uuid.New().String()
+
+	t.Run("success", func(t *testing.T) {
+		svc := new(mockAvatarService)
+		svc.On("Upload", mock.Anything, userID, mock.Anything).Return(&user.UserResponse{
+			AvatarURLs: map[string]string{"64": "http://localhost/a_64.png"},
+		}, nil)
+
+		body, contentType := multipartBody(t, "avatar", []byte("image"))
+		req := httptest.NewRequest(http.MethodPut, "/users/me/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		setupRouter(avatarHandler.NewAvatarHandler(svc), userID, 1<<20).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "avatar_urls")
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		svc := new(mockAvatarService)
+
+		body, contentType := multipartBody(t, "other", []byte("image"))
+		req := httptest.NewRequest(http.MethodPut, "/users/me/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		setupRouter(avatarHandler.NewAvatarHandler(svc), userID, 1<<20).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		svc := new(mockAvatarService)
+
+		body, contentType := multipartBody(t, "avatar", bytes.Repeat([]byte("x"), 4096))
+		req := httptest.NewRequest(http.MethodPut, "/users/me/avatar", io.NopCloser(body))
+		req.ContentLength = -1 // force the streaming limit rather than the Content-Length check
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		setupRouter(avatarHandler.NewAvatarHandler(svc), userID, 1024).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		svc.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		svc := new(mockAvatarService)
+		svc.On("Upload", mock.Anything, userID, mock.Anything).Return(nil, avatarService.ErrUnsupportedFormat)
+
+		body, contentType := multipartBody(t, "avatar", []byte("not an image"))
+		req := httptest.NewRequest(http.MethodPut, "/users/me/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+
+		setupRouter(avatarHandler.NewAvatarHandler(svc), userID, 1<<20).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
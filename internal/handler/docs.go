@@ -15,6 +15,7 @@ type ErrorResponse struct {
 	Code    int         `json:"code" example:"400"`
 	Message string      `json:"message" example:"Bad Request"`
 	Errors  interface{} `json:"errors,omitempty"`
+	TraceID string      `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
 }
 
 // ValidationError represents a validation error response
@@ -23,4 +24,5 @@ type ValidationError struct {
 	Code    int               `json:"code" example:"422"`
 	Message string            `json:"message" example:"Validation failed"`
 	Errors  map[string]string `json:"errors"`
+	TraceID string            `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
 }
@@ -0,0 +1,146 @@
+package email
+
+import (
+	"errors"
+	"strings"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	emailService "base-code-go-gin-clean/internal/service/email"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BatchHandler serves the admin API of scheduled batches of templated mail
+type BatchHandler struct {
+	batches emailService.BatchService
+}
+
+func NewBatchHandler(batches emailService.BatchService) *BatchHandler {
+	return &BatchHandler{
+		batches: batches,
+	}
+}
+
+// CreateBatch godoc
+// @Summary Schedule a batch of templated emails
+// @Description Send one catalog template to up to 10000 recipients, each with their own data merged over the shared data. The batch is dispatched at send_at, or right away when it is not set, and can be cancelled until then. Mail to the same recipient domain is spread out over time. The data of every recipient is validated up front; errors are keyed by recipient, e.g. "recipients[3].reset_url".
+// @Tags email-batches
+// @Accept  json
+// @Produce  json
+// @Param   batch  body      emailService.BatchRequest  true  "Template, recipients and data"
+// @Success 202 {object} handler.SuccessResponse{data=domain.Batch} "Batch scheduled"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid payload, recipient count or reply-to"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template not found"
+// @Failure 422 {object} handler.ErrorResponse "Invalid recipient address or template data"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/batches [post]
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var req emailService.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request payload", nil)
+		return
+	}
+	if req.Template == "" {
+		httpPkg.BadRequest(c, "Template is required", nil)
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if id, err := uuid.Parse(c.GetString(userIDKey)); err == nil {
+		createdBy = &id
+	}
+
+	batch, err := h.batches.Create(ctx, &req, createdBy)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to schedule batch")
+		return
+	}
+
+	httpPkg.SuccessResponse(c, httpPkg.StatusAccepted, batch)
+}
+
+// GetBatch godoc
+// @Summary Get a batch
+// @Description Return the status of a batch (scheduled, dispatching, dispatched or cancelled) with the number of recipients queued and failed so far. Failed recipients are suppressed or were rejected when their email was rendered.
+// @Tags email-batches
+// @Produce  json
+// @Param   id  path  string  true  "Batch ID"
+// @Success 200 {object} handler.SuccessResponse{data=domain.Batch} "Batch"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid batch ID"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Batch not found"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/batches/{id} [get]
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	batch, err := h.batches.Get(ctx, c.Param("id"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to get batch")
+		return
+	}
+
+	httpPkg.Success(c, batch)
+}
+
+// CancelBatch godoc
+// @Summary Cancel a batch
+// @Description Cancel a scheduled batch before it is dispatched. Once dispatching has started, its messages are already in the outbox and the batch cannot be cancelled.
+// @Tags email-batches
+// @Produce  json
+// @Param   id  path  string  true  "Batch ID"
+// @Success 200 {object} handler.SuccessResponse{data=domain.Batch} "Batch cancelled"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid batch ID"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Batch not found"
+// @Failure 409 {object} handler.ErrorResponse "Conflict: Batch is already dispatching or dispatched"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/batches/{id}/cancel [post]
+func (h *BatchHandler) CancelBatch(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	batch, err := h.batches.Cancel(ctx, c.Param("id"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to cancel batch")
+		return
+	}
+
+	httpPkg.Success(c, batch)
+}
+
+func (h *BatchHandler) handleError(c *gin.Context, err error, message string) {
+	var validationErrs templates.ValidationErrors
+	switch {
+	case strings.Contains(err.Error(), "invalid batch ID format"):
+		httpPkg.BadRequest(c, "Invalid batch ID", nil)
+	case errors.Is(err, emailService.ErrBatchNotFound):
+		httpPkg.NotFound(c, "Batch not found")
+	case errors.Is(err, emailService.ErrBatchNotCancellable):
+		httpPkg.ErrorResponse(c, httpPkg.StatusConflict, "Only scheduled batches can be cancelled", nil)
+	case errors.Is(err, templates.ErrUnknownTemplate):
+		httpPkg.NotFound(c, "Template not found")
+	case errors.As(err, &validationErrs):
+		httpPkg.ValidationError(c, "Invalid recipients", validationErrs)
+	case errors.Is(err, domain.ErrInvalidEmail):
+		httpPkg.BadRequest(c, err.Error(), nil)
+	default:
+		httpPkg.InternalServerError(c, message)
+	}
+}
@@ -0,0 +1,101 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+	emailService "base-code-go-gin-clean/internal/service/email"
+	"base-code-go-gin-clean/test"
+)
+
+type MockBatchService struct {
+	mock.Mock
+}
+
+func (m *MockBatchService) Create(ctx context.Context, req *emailService.BatchRequest, createdBy *uuid.UUID) (*email.Batch, error) {
+	args := m.Called(ctx, req, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.Batch), args.Error(1)
+}
+
+func (m *MockBatchService) Get(ctx context.Context, id string) (*email.Batch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.Batch), args.Error(1)
+}
+
+func (m *MockBatchService) Cancel(ctx context.Context, id string) (*email.Batch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.Batch), args.Error(1)
+}
+
+func TestBatchHandler_CreateBatch(t *testing.T) {
+	mockBatches := new(MockBatchService)
+	adminID := uuid.New()
+	router := test.SetupTestRouter()
+	router.POST("/admin/email/batches", func(c *gin.Context) {
+		c.Set(userIDKey, adminID.String())
+	}, NewBatchHandler(mockBatches).CreateBatch)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/email/batches", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	mockBatches.On("Create", mock.Anything, mock.MatchedBy(func(req *emailService.BatchRequest) bool {
+		return req.Template == "welcome" && len(req.Recipients) == 2 && req.SendAt != nil
+	}), &adminID).Return(&email.Batch{ID: uuid.New(), Status: email.BatchScheduled, Total: 2}, nil).Once()
+	resp := post(`{"template": "welcome", "send_at": "2025-08-22T11:00:00Z", "data": {"login_url": "https://app.example.com"},
+		"recipients": [{"to": "jane@example.com", "data": {"name": "Jane"}}, {"to": "max@example.de", "locale": "de", "data": {"name": "Max"}}]}`)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":"scheduled"`)
+
+	mockBatches.On("Create", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, templates.ValidationErrors{"recipients[1].name": "is required"}).Once()
+	resp = post(`{"template": "welcome", "recipients": [{"to": "jane@example.com"}]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "recipients[1].name")
+
+	assert.Equal(t, http.StatusBadRequest, post(`{"recipients": []}`).Code)
+	mockBatches.AssertExpectations(t)
+}
+
+func TestBatchHandler_CancelBatch(t *testing.T) {
+	mockBatches := new(MockBatchService)
+	router := test.SetupTestRouter()
+	router.POST("/admin/email/batches/:id/cancel", NewBatchHandler(mockBatches).CancelBatch)
+
+	id := uuid.New().String()
+	mockBatches.On("Cancel", mock.Anything, id).Return(&email.Batch{Status: email.BatchCancelled}, nil).Once()
+	resp := test.MakeTestRequest(router, "POST", "/admin/email/batches/"+id+"/cancel")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":"cancelled"`)
+
+	mockBatches.On("Cancel", mock.Anything, id).Return(nil, emailService.ErrBatchNotCancellable).Once()
+	resp = test.MakeTestRequest(router, "POST", "/admin/email/batches/"+id+"/cancel")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+
+	mockBatches.On("Cancel", mock.Anything, id).Return(nil, emailService.ErrBatchNotFound).Once()
+	resp = test.MakeTestRequest(router, "POST", "/admin/email/batches/"+id+"/cancel")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -1,39 +1,46 @@
 package email
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 
 	domain "base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
 	httpPkg "base-code-go-gin-clean/internal/pkg/http"
 	"base-code-go-gin-clean/internal/pkg/telemetry"
+	emailService "base-code-go-gin-clean/internal/service/email"
 
 	"github.com/gin-gonic/gin"
 )
 
 type EmailHandler struct {
-	emailService domain.EmailService
+	outbox    emailService.OutboxService
+	templates emailService.TemplateService
 }
 
-func NewEmailHandler(emailService domain.EmailService) *EmailHandler {
+func NewEmailHandler(outbox emailService.OutboxService, templates emailService.TemplateService) *EmailHandler {
 	return &EmailHandler{
-		emailService: emailService,
+		outbox:    outbox,
+		templates: templates,
 	}
 }
 
 // SendEmail godoc
-// @Summary Send an email
-// @Description Send an email using the configured SMTP server
+// @Summary Queue an email
+// @Description Persist an email in the outbox and return its message ID. Body is the HTML part; text, cc, bcc, reply_to, custom headers and base64 attachments (inline when content_id is set) are optional. Delivery happens in the background with retries; poll GET /email/messages/{id} for the status.
 // @Tags email
 // @Accept  json
 // @Produce  json
 // @Param   email  body      domain.Email  true  "Email details"
-// @Success 200 {object} map[string]string "Email sent successfully"
-// @Failure 400 {object} map[string]string "Bad request"
+// @Success 202 {object} domain.OutboxMessage "Email queued"
+// @Failure 400 {object} map[string]string "Bad request: invalid payload, address or header"
+// @Failure 422 {object} map[string]string "A recipient is on the suppression list"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /email/send [post]
 func (h *EmailHandler) SendEmail(c *gin.Context) {
 	// Start a new span for the request
-	_, span := telemetry.Start(c.Request.Context())
+	ctx, span := telemetry.Start(c.Request.Context())
 	defer span.End()
 
 	var email domain.Email
@@ -50,13 +57,169 @@ func (h *EmailHandler) SendEmail(c *gin.Context) {
 		return
 	}
 
-	if err := h.emailService.SendEmail(&email); err != nil {
+	msg, err := h.outbox.Enqueue(ctx, &email)
+	if err != nil {
 		span.RecordError(err)
-		httpPkg.InternalServerError(c, "Failed to send email")
+		switch {
+		case errors.Is(err, domain.ErrInvalidEmail):
+			httpPkg.BadRequest(c, err.Error(), nil)
+		case errors.Is(err, domain.ErrRecipientSuppressed):
+			httpPkg.ValidationError(c, err.Error(), nil)
+		default:
+			httpPkg.InternalServerError(c, "Failed to queue email")
+		}
 		return
 	}
 
-	httpPkg.Success(c, map[string]string{
-		"message": "Email sent successfully",
-	})
+	httpPkg.SuccessResponse(c, httpPkg.StatusAccepted, msg)
+}
+
+// GetMessage godoc
+// @Summary Get email delivery status
+// @Description Return the delivery status of a queued email: queued, sending, sent, dead (failed permanently) or suppressed (a recipient was suppressed before delivery)
+// @Tags email
+// @Produce  json
+// @Param   id  path  string  true  "Message ID"
+// @Success 200 {object} domain.OutboxMessage "Message status"
+// @Failure 400 {object} map[string]string "Invalid message ID"
+// @Failure 404 {object} map[string]string "Message not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /email/messages/{id} [get]
+func (h *EmailHandler) GetMessage(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	msg, err := h.outbox.GetMessage(ctx, c.Param("id"))
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case strings.Contains(err.Error(), "invalid message ID format"):
+			httpPkg.BadRequest(c, "Invalid message ID", nil)
+		case errors.Is(err, emailService.ErrMessageNotFound):
+			httpPkg.NotFound(c, "Message not found")
+		default:
+			httpPkg.InternalServerError(c, "Failed to get message")
+		}
+		return
+	}
+
+	httpPkg.Success(c, msg)
+}
+
+// SendTemplate godoc
+// @Summary Queue a templated email
+// @Description Render a catalog template with the given data and queue it for one recipient. Data is validated against the template's fields; see GET /email/templates. Localized templates use the locale field, else the recipient's locale preference, else Accept-Language, else English.
+// @Tags email
+// @Accept  json
+// @Produce  json
+// @Param   email  body      domain.TemplateEmail  true  "Template, recipient and data"
+// @Success 202 {object} domain.OutboxMessage "Email queued"
+// @Failure 400 {object} map[string]string "Bad request: invalid payload or address"
+// @Failure 404 {object} map[string]string "Template not found"
+// @Failure 422 {object} map[string]string "Template data failed validation or the recipient is suppressed"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /email/send-template [post]
+func (h *EmailHandler) SendTemplate(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var req domain.TemplateEmail
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request payload", nil)
+		return
+	}
+	if req.Template == "" || req.To == "" {
+		httpPkg.BadRequest(c, "Template and recipient are required", nil)
+		return
+	}
+	req.AcceptLanguage = c.GetHeader("Accept-Language")
+
+	msg, err := h.templates.SendTemplate(ctx, &req)
+	if err != nil {
+		span.RecordError(err)
+		if !h.handleTemplateError(c, err) {
+			httpPkg.InternalServerError(c, "Failed to queue email")
+		}
+		return
+	}
+
+	httpPkg.SuccessResponse(c, httpPkg.StatusAccepted, msg)
+}
+
+// ListTemplates godoc
+// @Summary List email templates
+// @Description List the templates accepted by /email/send-template with the data fields each one expects
+// @Tags email
+// @Produce  json
+// @Success 200 {array} templates.Definition "Templates"
+// @Router /email/templates [get]
+func (h *EmailHandler) ListTemplates(c *gin.Context) {
+	httpPkg.Success(c, h.templates.ListTemplates())
+}
+
+// PreviewTemplate godoc
+// @Summary Preview an email template
+// @Description Render a template with the given data and return the subject, HTML and plain-text parts without sending anything
+// @Tags email
+// @Accept  json
+// @Produce  json
+// @Param   name    path   string  true   "Template name"
+// @Param   locale  query  string  false  "Locale; defaults to the Accept-Language header"
+// @Param   data    body   object  true   "Template data"
+// @Success 200 {object} templates.Rendered "Rendered email"
+// @Failure 400 {object} map[string]string "Invalid request payload"
+// @Failure 404 {object} map[string]string "Template not found"
+// @Failure 422 {object} map[string]string "Template data failed validation"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /email/templates/{name}/preview [post]
+func (h *EmailHandler) PreviewTemplate(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var data map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&data); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request payload", nil)
+		return
+	}
+
+	rendered, err := h.templates.Preview(ctx, c.Param("name"), previewLocale(c), data)
+	if err != nil {
+		span.RecordError(err)
+		if !h.handleTemplateError(c, err) {
+			httpPkg.InternalServerError(c, "Failed to render template")
+		}
+		return
+	}
+
+	httpPkg.Success(c, rendered)
+}
+
+// handleTemplateError writes the response for client errors from rendering and
+// reports whether it did so
+func (h *EmailHandler) handleTemplateError(c *gin.Context, err error) bool {
+	var validationErrs templates.ValidationErrors
+	switch {
+	case errors.Is(err, templates.ErrUnknownTemplate):
+		httpPkg.NotFound(c, "Template not found")
+	case errors.As(err, &validationErrs):
+		httpPkg.ValidationError(c, "Invalid template data", validationErrs)
+	case errors.Is(err, domain.ErrInvalidEmail):
+		httpPkg.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, domain.ErrRecipientSuppressed):
+		httpPkg.ValidationError(c, err.Error(), nil)
+	default:
+		return false
+	}
+	return true
+}
+
+// previewLocale returns the locale asked for by the locale query parameter or
+// the Accept-Language header
+func previewLocale(c *gin.Context) string {
+	if locale := c.Query("locale"); locale != "" {
+		return locale
+	}
+	return c.GetHeader("Accept-Language")
 }
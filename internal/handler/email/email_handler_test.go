@@ -2,31 +2,131 @@ package email
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+	emailService "base-code-go-gin-clean/internal/service/email"
 	"base-code-go-gin-clean/test"
 )
 
-// MockEmailService is a mock implementation of EmailService
-type MockEmailService struct {
+// MockOutboxService is a mock implementation of emailService.OutboxService
+type MockOutboxService struct {
 	mock.Mock
 }
 
-func (m *MockEmailService) SendEmail(e *email.Email) error {
-	args := m.Called(e)
+func (m *MockOutboxService) SendEmail(ctx context.Context, e *email.Email) error {
+	args := m.Called(ctx, e)
+	return args.Error(0)
+}
+
+func (m *MockOutboxService) Enqueue(ctx context.Context, e *email.Email) (*email.OutboxMessage, error) {
+	args := m.Called(ctx, e)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxService) GetMessage(ctx context.Context, id string) (*email.OutboxMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.OutboxMessage), args.Error(1)
+}
+
+// MockTemplateService is a mock implementation of emailService.TemplateService
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) ListTemplates() []templates.Definition {
+	args := m.Called()
+	return args.Get(0).([]templates.Definition)
+}
+
+func (m *MockTemplateService) Preview(ctx context.Context, name, locale string, data map[string]json.RawMessage) (*templates.Rendered, error) {
+	args := m.Called(ctx, name, locale, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*templates.Rendered), args.Error(1)
+}
+
+func (m *MockTemplateService) SendTemplate(ctx context.Context, req *email.TemplateEmail) (*email.OutboxMessage, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.OutboxMessage), args.Error(1)
+}
+
+func (m *MockTemplateService) ListVersions(ctx context.Context, name string) ([]*email.TemplateVersion, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*email.TemplateVersion), args.Error(1)
+}
+
+func (m *MockTemplateService) CreateDraft(ctx context.Context, name string, src emailService.TemplateSource, createdBy *uuid.UUID) (*email.TemplateVersion, error) {
+	args := m.Called(ctx, name, src, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.TemplateVersion), args.Error(1)
+}
+
+func (m *MockTemplateService) UpdateDraft(ctx context.Context, name, version string, src emailService.TemplateSource) (*email.TemplateVersion, error) {
+	args := m.Called(ctx, name, version, src)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.TemplateVersion), args.Error(1)
+}
+
+func (m *MockTemplateService) PreviewVersion(ctx context.Context, name, version, locale string, data map[string]json.RawMessage) (*templates.Rendered, error) {
+	args := m.Called(ctx, name, version, locale, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*templates.Rendered), args.Error(1)
+}
+
+func (m *MockTemplateService) Publish(ctx context.Context, name, version string) (*email.TemplateVersion, error) {
+	args := m.Called(ctx, name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.TemplateVersion), args.Error(1)
+}
+
+func (m *MockTemplateService) Rollback(ctx context.Context, name string) (*email.TemplateVersion, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.TemplateVersion), args.Error(1)
+}
+
+func (m *MockTemplateService) Unpublish(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
 	return args.Error(0)
 }
 
 func TestEmailHandler_SendEmail(t *testing.T) {
 	// Setup
-	mockService := new(MockEmailService)
-	handler := NewEmailHandler(mockService)
+	mockService := new(MockOutboxService)
+	handler := NewEmailHandler(mockService, new(MockTemplateService))
 	router := test.SetupTestRouter()
 	router.POST("/email", handler.SendEmail)
 
@@ -39,15 +139,118 @@ func TestEmailHandler_SendEmail(t *testing.T) {
 	jsonBody, _ := json.Marshal(emailPayload)
 
 	// Expectation
-	mockService.On("SendEmail", mock.MatchedBy(func(e *email.Email) bool {
+	queued := &email.OutboxMessage{ID: uuid.New(), Status: email.MessageQueued}
+	mockService.On("Enqueue", mock.Anything, mock.MatchedBy(func(e *email.Email) bool {
 		return e.Subject == "Test" && e.Body == "Test body" && len(e.To) == 1 && e.To[0] == "test@example.com"
-	})).Return(nil)
+	})).Return(queued, nil)
 
 	// Execute
 	testReq := test.MakeTestRequestWithBody(router, "POST", "/email", bytes.NewReader(jsonBody))
 	testReq.Request.Header.Set("Content-Type", "application/json")
 
 	// Assert
-	assert.Equal(t, http.StatusOK, testReq.Response.Code)
+	assert.Equal(t, http.StatusAccepted, testReq.Response.Code)
+	assert.Contains(t, testReq.Response.Body.String(), queued.ID.String())
 	mockService.AssertExpectations(t)
 }
+
+func TestEmailHandler_GetMessage(t *testing.T) {
+	mockService := new(MockOutboxService)
+	handler := NewEmailHandler(mockService, new(MockTemplateService))
+	router := test.SetupTestRouter()
+	router.GET("/email/messages/:id", handler.GetMessage)
+
+	t.Run("dead-lettered message", func(t *testing.T) {
+		msg := &email.OutboxMessage{ID: uuid.New(), Status: email.MessageDead, Attempts: 8, LastError: "550 mailbox unavailable"}
+		mockService.On("GetMessage", mock.Anything, msg.ID.String()).Return(msg, nil)
+
+		resp := test.MakeTestRequest(router, "GET", "/email/messages/"+msg.ID.String())
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"status":"dead"`)
+		assert.Contains(t, resp.Body.String(), "550 mailbox unavailable")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		id := uuid.NewString()
+		mockService.On("GetMessage", mock.Anything, id).Return(nil, emailService.ErrMessageNotFound)
+
+		resp := test.MakeTestRequest(router, "GET", "/email/messages/"+id)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		mockService.On("GetMessage", mock.Anything, "nope").Return(nil, errors.New("invalid message ID format: invalid UUID length: 4"))
+
+		resp := test.MakeTestRequest(router, "GET", "/email/messages/nope")
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestEmailHandler_SendTemplate(t *testing.T) {
+	mockTemplates := new(MockTemplateService)
+	handler := NewEmailHandler(new(MockOutboxService), mockTemplates)
+	router := test.SetupTestRouter()
+	router.POST("/email/send-template", handler.SendTemplate)
+
+	send := func(req email.TemplateEmail) int {
+		jsonBody, _ := json.Marshal(req)
+		return test.MakeTestRequestWithBody(router, "POST", "/email/send-template", bytes.NewReader(jsonBody)).Response.Code
+	}
+
+	t.Run("queued", func(t *testing.T) {
+		req := email.TemplateEmail{Template: "welcome", To: "a@example.com"}
+		mockTemplates.On("SendTemplate", mock.Anything, &req).Return(&email.OutboxMessage{ID: uuid.New()}, nil).Once()
+
+		assert.Equal(t, http.StatusAccepted, send(req))
+	})
+
+	t.Run("missing recipient", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(email.TemplateEmail{Template: "welcome"}))
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		req := email.TemplateEmail{Template: "nope", To: "a@example.com"}
+		mockTemplates.On("SendTemplate", mock.Anything, &req).Return(nil, templates.ErrUnknownTemplate).Once()
+
+		assert.Equal(t, http.StatusNotFound, send(req))
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		req := email.TemplateEmail{Template: "welcome", To: "a@example.com"}
+		mockTemplates.On("SendTemplate", mock.Anything, &req).
+			Return(nil, templates.ValidationErrors{"name": "is required"}).Once()
+
+		assert.Equal(t, http.StatusUnprocessableEntity, send(req))
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		req := email.TemplateEmail{Template: "welcome", To: "not-an-address"}
+		mockTemplates.On("SendTemplate", mock.Anything, &req).Return(nil, email.ErrInvalidEmail).Once()
+
+		assert.Equal(t, http.StatusBadRequest, send(req))
+	})
+}
+
+func TestEmailHandler_PreviewTemplate(t *testing.T) {
+	mockTemplates := new(MockTemplateService)
+	handler := NewEmailHandler(new(MockOutboxService), mockTemplates)
+	router := test.SetupTestRouter()
+	router.POST("/email/templates/:name/preview", handler.PreviewTemplate)
+
+	rendered := &templates.Rendered{Locale: "de", Subject: "Hallo", HTML: "<p>Hallo</p>", Text: "Hallo"}
+	mockTemplates.On("Preview", mock.Anything, "welcome", "de", mock.Anything).Return(rendered, nil)
+
+	resp := test.MakeTestRequestWithBody(router, "POST", "/email/templates/welcome/preview?locale=de", bytes.NewReader([]byte(`{"name":"Jane"}`))).Response
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var body struct {
+		Data templates.Rendered `json:"data"`
+	}
+	if !assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body)) {
+		return
+	}
+	assert.Equal(t, *rendered, body.Data)
+}
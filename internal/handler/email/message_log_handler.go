@@ -0,0 +1,227 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	emailService "base-code-go-gin-clean/internal/service/email"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trackingPixel is a transparent 1x1 GIF
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// MessageLogHandler serves the open and click tracking endpoints and the
+// admin API of the delivery log
+type MessageLogHandler struct {
+	messages emailService.MessageLogService
+}
+
+func NewMessageLogHandler(messages emailService.MessageLogService) *MessageLogHandler {
+	return &MessageLogHandler{
+		messages: messages,
+	}
+}
+
+// TrackOpen godoc
+// @Summary Open-tracking pixel
+// @Description Transparent 1x1 GIF embedded in tracked HTML mail. Loading it counts an open; the image is returned even for unknown tokens.
+// @Tags email
+// @Produce gif
+// @Param token path string true "Signed tracking token"
+// @Success 200 {file} file "Tracking pixel"
+// @Router /email/track/open/{token} [get]
+func (h *MessageLogHandler) TrackOpen(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	if err := h.messages.TrackOpen(ctx, c.Param("token")); err != nil {
+		span.RecordError(err)
+	}
+
+	// Every load must reach us to be counted
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+	c.Data(http.StatusOK, "image/gif", trackingPixel)
+}
+
+// TrackClick godoc
+// @Summary Follow a tracked link
+// @Description Counts a click on a link in tracked HTML mail and redirects to the original URL. Only links signed by the server are followed.
+// @Tags email
+// @Param token path string true "Signed tracking token"
+// @Success 302 "Redirect to the original link"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Invalid link"
+// @Router /email/track/click/{token} [get]
+func (h *MessageLogHandler) TrackClick(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	link, err := h.messages.TrackClick(ctx, c.Param("token"))
+	if err != nil {
+		span.RecordError(err)
+		httpPkg.NotFound(c, "Invalid link")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, link)
+}
+
+// ListMessages godoc
+// @Summary Query the email delivery log
+// @Description List logged emails, newest first
+// @Tags email-messages
+// @Produce json
+// @Param template query string false "Template name"
+// @Param status query string false "Delivery status" Enums(queued, sending, sent, dead, suppressed)
+// @Param recipient query string false "Recipient address"
+// @Param trace_id query string false "Trace ID of the API request that queued the email"
+// @Param since query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param until query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Entries to skip"
+// @Success 200 {object} handler.SuccessResponse{data=emailService.MessageLogList} "Messages"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid filter"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/messages [get]
+func (h *MessageLogHandler) ListMessages(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	since, until, err := parseWindow(c)
+	if err != nil {
+		httpPkg.BadRequest(c, err.Error(), nil)
+		return
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	list, err := h.messages.List(ctx, domain.MessageLogFilter{
+		Template:  c.Query("template"),
+		Status:    domain.MessageStatus(c.Query("status")),
+		Recipient: c.Query("recipient"),
+		TraceID:   c.Query("trace_id"),
+		Since:     since,
+		Until:     until,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to list messages")
+		return
+	}
+
+	httpPkg.Success(c, list)
+}
+
+// GetMessage godoc
+// @Summary Get a delivery log entry
+// @Description Return the delivery log entry of an email: recipients, status, provider response, trace ID and tracked opens and clicks
+// @Tags email-messages
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} handler.SuccessResponse{data=domain.MessageLog} "Message"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid message ID"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Message not found"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/messages/{id} [get]
+func (h *MessageLogHandler) GetMessage(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	entry, err := h.messages.Get(ctx, c.Param("id"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to get message")
+		return
+	}
+
+	httpPkg.Success(c, entry)
+}
+
+// Stats godoc
+// @Summary Email statistics per template
+// @Description Delivery outcomes and unique opens and clicks per template. Rates are relative to sent messages with tracking.
+// @Tags email-messages
+// @Produce json
+// @Param since query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param until query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Success 200 {object} handler.SuccessResponse{data=[]domain.TemplateStats} "Statistics"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid time window"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/stats [get]
+func (h *MessageLogHandler) Stats(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	since, until, err := parseWindow(c)
+	if err != nil {
+		httpPkg.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	stats, err := h.messages.Stats(ctx, since, until)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to compute statistics")
+		return
+	}
+	if stats == nil {
+		stats = []*domain.TemplateStats{}
+	}
+
+	httpPkg.Success(c, stats)
+}
+
+func (h *MessageLogHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "invalid message ID format"):
+		httpPkg.BadRequest(c, "Invalid message ID", nil)
+	case strings.Contains(err.Error(), "invalid message status"):
+		httpPkg.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, emailService.ErrMessageNotFound):
+		httpPkg.NotFound(c, "Message not found")
+	default:
+		httpPkg.InternalServerError(c, message)
+	}
+}
+
+// parseWindow parses the optional since and until query parameters
+func parseWindow(c *gin.Context) (*time.Time, *time.Time, error) {
+	var window [2]*time.Time
+	for i, name := range []string{"since", "until"} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				window[i] = &t
+				break
+			}
+		}
+		if window[i] == nil {
+			return nil, nil, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name)
+		}
+	}
+	return window[0], window[1], nil
+}
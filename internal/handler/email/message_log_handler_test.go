@@ -0,0 +1,113 @@
+package email
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	emailService "base-code-go-gin-clean/internal/service/email"
+	"base-code-go-gin-clean/test"
+)
+
+type MockMessageLogService struct {
+	mock.Mock
+}
+
+func (m *MockMessageLogService) Instrument(id uuid.UUID, e *email.Email) *email.Email {
+	return m.Called(id, e).Get(0).(*email.Email)
+}
+
+func (m *MockMessageLogService) Record(ctx context.Context, msg *email.OutboxMessage) error {
+	return m.Called(ctx, msg).Error(0)
+}
+
+func (m *MockMessageLogService) RecordDelivery(ctx context.Context, msg *email.OutboxMessage, response string) error {
+	return m.Called(ctx, msg, response).Error(0)
+}
+
+func (m *MockMessageLogService) TrackOpen(ctx context.Context, token string) error {
+	return m.Called(ctx, token).Error(0)
+}
+
+func (m *MockMessageLogService) TrackClick(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMessageLogService) Get(ctx context.Context, id string) (*email.MessageLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.MessageLog), args.Error(1)
+}
+
+func (m *MockMessageLogService) List(ctx context.Context, filter email.MessageLogFilter) (*emailService.MessageLogList, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emailService.MessageLogList), args.Error(1)
+}
+
+func (m *MockMessageLogService) Stats(ctx context.Context, since, until *time.Time) ([]*email.TemplateStats, error) {
+	args := m.Called(ctx, since, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*email.TemplateStats), args.Error(1)
+}
+
+func TestMessageLogHandler_Tracking(t *testing.T) {
+	mockMessages := new(MockMessageLogService)
+	handler := NewMessageLogHandler(mockMessages)
+	router := test.SetupTestRouter()
+	router.GET("/email/track/open/:token", handler.TrackOpen)
+	router.GET("/email/track/click/:token", handler.TrackClick)
+
+	// The pixel is served even for unknown tokens
+	mockMessages.On("TrackOpen", mock.Anything, "forged").Return(emailService.ErrInvalidTrackingToken).Once()
+	resp := test.MakeTestRequest(router, "GET", "/email/track/open/forged")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "image/gif", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Header().Get("Cache-Control"), "no-store")
+
+	mockMessages.On("TrackClick", mock.Anything, "abc").Return("https://example.org/reset", nil).Once()
+	resp = test.MakeTestRequest(router, "GET", "/email/track/click/abc")
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://example.org/reset", resp.Header().Get("Location"))
+
+	mockMessages.On("TrackClick", mock.Anything, "forged").Return("", emailService.ErrInvalidTrackingToken).Once()
+	resp = test.MakeTestRequest(router, "GET", "/email/track/click/forged")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Empty(t, resp.Header().Get("Location"))
+	mockMessages.AssertExpectations(t)
+}
+
+func TestMessageLogHandler_ListMessages(t *testing.T) {
+	mockMessages := new(MockMessageLogService)
+	router := test.SetupTestRouter()
+	router.GET("/admin/email/messages", NewMessageLogHandler(mockMessages).ListMessages)
+
+	since := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	mockMessages.On("List", mock.Anything, email.MessageLogFilter{
+		Template: "welcome",
+		Status:   email.MessageSent,
+		Since:    &since,
+		Limit:    10,
+	}).Return(&emailService.MessageLogList{Messages: []*email.MessageLog{{Subject: "Welcome"}}, Total: 1}, nil).Once()
+
+	resp := test.MakeTestRequest(router, "GET", "/admin/email/messages?template=welcome&status=sent&since=2025-08-01&limit=10")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"total":1`)
+
+	resp = test.MakeTestRequest(router, "GET", "/admin/email/messages?until=yesterday")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	mockMessages.AssertExpectations(t)
+}
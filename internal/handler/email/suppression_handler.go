@@ -0,0 +1,252 @@
+package email
+
+import (
+	"crypto/subtle"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/mailer"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	emailService "base-code-go-gin-clean/internal/service/email"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxReportBytes bounds the size of a bounce report accepted by the webhook
+const maxReportBytes = 10 << 20
+
+// unsubscribePage is shown to people who follow an unsubscribe link. Opening
+// the link only asks for confirmation, so link scanners cannot unsubscribe anyone.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+  <head><meta charset="utf-8" /><title>Unsubscribe</title></head>
+  <body style="font-family: sans-serif; max-width: 480px; margin: 40px auto; text-align: center">
+    <p>{{.Message}}</p>
+    {{if .Confirm}}<form method="post"><button type="submit">Unsubscribe</button></form>{{end}}
+  </body>
+</html>
+`))
+
+// SuppressionHandler serves unsubscribe links, the bounce webhook and the
+// admin API of the suppression list
+type SuppressionHandler struct {
+	suppressions  emailService.SuppressionService
+	webhookSecret string
+}
+
+// NewSuppressionHandler creates the handler. Bounce reports are only accepted
+// with webhookSecret; an empty secret disables the webhook.
+func NewSuppressionHandler(suppressions emailService.SuppressionService, webhookSecret string) *SuppressionHandler {
+	return &SuppressionHandler{
+		suppressions:  suppressions,
+		webhookSecret: webhookSecret,
+	}
+}
+
+// ConfirmUnsubscribe godoc
+// @Summary Unsubscribe confirmation page
+// @Description HTML page linked from the List-Unsubscribe header and email footers. It asks for confirmation and posts back to unsubscribe.
+// @Tags email
+// @Produce html
+// @Param token query string true "Signed unsubscribe token"
+// @Success 200 {string} string "Confirmation page"
+// @Router /email/unsubscribe [get]
+func (h *SuppressionHandler) ConfirmUnsubscribe(c *gin.Context) {
+	renderUnsubscribePage(c, http.StatusOK, "Do you want to stop receiving these emails?", true)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from bulk email
+// @Description RFC 8058 one-click unsubscribe. Mail clients post List-Unsubscribe=One-Click; the confirmation page posts an empty form. The address keeps receiving transactional mail such as password resets.
+// @Tags email
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param token query string true "Signed unsubscribe token"
+// @Success 200 {string} string "Unsubscribed"
+// @Failure 400 {string} string "Invalid or missing token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /email/unsubscribe [post]
+func (h *SuppressionHandler) Unsubscribe(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+
+	if _, err := h.suppressions.Unsubscribe(ctx, token); err != nil {
+		span.RecordError(err)
+		if errors.Is(err, emailService.ErrInvalidUnsubscribeToken) {
+			renderUnsubscribePage(c, http.StatusBadRequest, "This unsubscribe link is invalid.", false)
+			return
+		}
+		renderUnsubscribePage(c, http.StatusInternalServerError, "Something went wrong. Please try again later.", false)
+		return
+	}
+
+	renderUnsubscribePage(c, http.StatusOK, "You have been unsubscribed and will no longer receive these emails.", false)
+}
+
+// BounceWebhook godoc
+// @Summary Process a bounce or complaint report
+// @Description Inbound webhook for the mail server or provider. The body is a complete message: a delivery status notification (RFC 3464) or an abuse feedback report (RFC 5965). Hard-bounced and complaining recipients are added to the suppression list; soft bounces are ignored.
+// @Tags email
+// @Accept plain
+// @Produce json
+// @Param X-Webhook-Secret header string false "Webhook secret; may be passed as the secret query parameter instead"
+// @Success 200 {object} handler.SuccessResponse{data=[]domain.Suppression} "Suppressed addresses"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Invalid webhook secret"
+// @Failure 422 {object} handler.ErrorResponse "Unprocessable Entity: Not a bounce or complaint report"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /email/webhooks/bounces [post]
+func (h *SuppressionHandler) BounceWebhook(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	secret := c.GetHeader("X-Webhook-Secret")
+	if secret == "" {
+		secret = c.Query("secret")
+	}
+	if h.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(h.webhookSecret)) != 1 {
+		httpPkg.Unauthorized(c, "Invalid webhook secret")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxReportBytes)
+	suppressed, err := h.suppressions.ProcessReport(ctx, body)
+	if err != nil {
+		span.RecordError(err)
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, mailer.ErrNotReport), errors.As(err, &maxBytesErr), strings.HasPrefix(err.Error(), "mailer: invalid"):
+			httpPkg.ValidationError(c, err.Error(), nil)
+		default:
+			httpPkg.InternalServerError(c, "Failed to process report")
+		}
+		return
+	}
+
+	if suppressed == nil {
+		suppressed = []*domain.Suppression{}
+	}
+	httpPkg.Success(c, suppressed)
+}
+
+// ListSuppressions godoc
+// @Summary List suppressed addresses
+// @Description List the suppression list, newest first
+// @Tags email-suppressions
+// @Produce json
+// @Param reason query string false "Filter by reason" Enums(unsubscribed, hard_bounce, complaint)
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Entries to skip"
+// @Success 200 {object} handler.SuccessResponse{data=emailService.SuppressionList} "Suppressions"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid reason"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/suppressions [get]
+func (h *SuppressionHandler) ListSuppressions(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	list, err := h.suppressions.List(ctx, c.Query("reason"), limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to list suppressions")
+		return
+	}
+
+	httpPkg.Success(c, list)
+}
+
+// AddSuppression godoc
+// @Summary Suppress an address
+// @Description Add an address to the suppression list, or change the reason of an existing entry. An unsubscribe does not replace a bounce or complaint.
+// @Tags email-suppressions
+// @Accept json
+// @Produce json
+// @Param request body emailService.SuppressAddressRequest true "Address and reason"
+// @Success 201 {object} handler.SuccessResponse{data=domain.Suppression} "Address suppressed"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid address or reason"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/suppressions [post]
+func (h *SuppressionHandler) AddSuppression(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var req emailService.SuppressAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+
+	sup, err := h.suppressions.Suppress(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to suppress address")
+		return
+	}
+
+	httpPkg.Created(c, sup)
+}
+
+// RemoveSuppression godoc
+// @Summary Remove an address from the suppression list
+// @Description Allow mail to an address again, e.g. after a bounced mailbox was fixed
+// @Tags email-suppressions
+// @Produce json
+// @Param email path string true "Email address"
+// @Success 200 {object} handler.SuccessResponse "Address removed"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Address is not suppressed"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/suppressions/{email} [delete]
+func (h *SuppressionHandler) RemoveSuppression(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	if err := h.suppressions.Remove(ctx, c.Param("email")); err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to remove suppression")
+		return
+	}
+
+	httpPkg.Success(c, nil)
+}
+
+func (h *SuppressionHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidEmail), strings.Contains(err.Error(), "invalid suppression reason"):
+		httpPkg.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, emailService.ErrSuppressionNotFound):
+		httpPkg.NotFound(c, "Address is not suppressed")
+	default:
+		httpPkg.InternalServerError(c, message)
+	}
+}
+
+func renderUnsubscribePage(c *gin.Context, code int, message string, confirm bool) {
+	var b strings.Builder
+	if err := unsubscribePage.Execute(&b, struct {
+		Message string
+		Confirm bool
+	}{message, confirm}); err != nil {
+		c.String(http.StatusInternalServerError, message)
+		return
+	}
+	c.Data(code, "text/html; charset=utf-8", []byte(b.String()))
+}
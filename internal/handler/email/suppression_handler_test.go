@@ -0,0 +1,121 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/mailer"
+	emailService "base-code-go-gin-clean/internal/service/email"
+	"base-code-go-gin-clean/test"
+)
+
+type MockSuppressionService struct {
+	mock.Mock
+}
+
+func (m *MockSuppressionService) Check(ctx context.Context, e *email.Email) error {
+	return m.Called(ctx, e).Error(0)
+}
+
+func (m *MockSuppressionService) UnsubscribeURL(address string) string {
+	return m.Called(address).String(0)
+}
+
+func (m *MockSuppressionService) Unsubscribe(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSuppressionService) ProcessReport(ctx context.Context, report io.Reader) ([]*email.Suppression, error) {
+	body, _ := io.ReadAll(report)
+	args := m.Called(ctx, string(body))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*email.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionService) List(ctx context.Context, reason string, limit, offset int) (*emailService.SuppressionList, error) {
+	args := m.Called(ctx, reason, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emailService.SuppressionList), args.Error(1)
+}
+
+func (m *MockSuppressionService) Suppress(ctx context.Context, req emailService.SuppressAddressRequest) (*email.Suppression, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.Suppression), args.Error(1)
+}
+
+func (m *MockSuppressionService) Remove(ctx context.Context, address string) error {
+	return m.Called(ctx, address).Error(0)
+}
+
+func TestSuppressionHandler_Unsubscribe(t *testing.T) {
+	mockSuppressions := new(MockSuppressionService)
+	handler := NewSuppressionHandler(mockSuppressions, "")
+	router := test.SetupTestRouter()
+	router.GET("/email/unsubscribe", handler.ConfirmUnsubscribe)
+	router.POST("/email/unsubscribe", handler.Unsubscribe)
+
+	// Opening the link only asks for confirmation
+	resp := test.MakeTestRequest(router, "GET", "/email/unsubscribe?token=abc")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `<form method="post">`)
+	mockSuppressions.AssertNotCalled(t, "Unsubscribe", mock.Anything, mock.Anything)
+
+	// RFC 8058 one-click POST from a mail client
+	mockSuppressions.On("Unsubscribe", mock.Anything, "abc").Return("jane@example.com", nil).Once()
+	req := httptest.NewRequest("POST", "/email/unsubscribe?token=abc", strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "You have been unsubscribed")
+
+	mockSuppressions.On("Unsubscribe", mock.Anything, "forged").Return("", emailService.ErrInvalidUnsubscribeToken).Once()
+	resp = test.MakeTestRequest(router, "POST", "/email/unsubscribe?token=forged")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestSuppressionHandler_BounceWebhook(t *testing.T) {
+	mockSuppressions := new(MockSuppressionService)
+	router := test.SetupTestRouter()
+	router.POST("/webhook", NewSuppressionHandler(mockSuppressions, "s3cret").BounceWebhook)
+	router.POST("/disabled", NewSuppressionHandler(mockSuppressions, "").BounceWebhook)
+
+	post := func(path, secret, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, bytes.NewReader([]byte(body)))
+		if secret != "" {
+			req.Header.Set("X-Webhook-Secret", secret)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("/webhook", "wrong", "report").Code)
+	assert.Equal(t, http.StatusUnauthorized, post("/disabled", "", "report").Code)
+
+	mockSuppressions.On("ProcessReport", mock.Anything, "report").
+		Return([]*email.Suppression{{Email: "gone@example.org", Reason: email.SuppressionHardBounce}}, nil).Once()
+	resp := post("/webhook", "s3cret", "report")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "gone@example.org")
+
+	mockSuppressions.On("ProcessReport", mock.Anything, "hello").Return(nil, mailer.ErrNotReport).Once()
+	assert.Equal(t, http.StatusUnprocessableEntity, post("/webhook?secret=s3cret", "", "hello").Code)
+}
@@ -0,0 +1,277 @@
+package email
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	templates "base-code-go-gin-clean/internal/email"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	emailService "base-code-go-gin-clean/internal/service/email"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Context keys for storing values in the request context
+const (
+	userIDKey = "userID"
+)
+
+// TemplateAdminHandler manages the stored versions that override the embedded email templates
+type TemplateAdminHandler struct {
+	templates emailService.TemplateService
+}
+
+func NewTemplateAdminHandler(templates emailService.TemplateService) *TemplateAdminHandler {
+	return &TemplateAdminHandler{
+		templates: templates,
+	}
+}
+
+// ListVersions godoc
+// @Summary List template versions
+// @Description List the stored versions of an email template, newest first. At most one is published; without one the embedded template is used.
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} handler.SuccessResponse{data=[]domain.TemplateVersion} "Versions"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template not found"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/versions [get]
+func (h *TemplateAdminHandler) ListVersions(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	versions, err := h.templates.ListVersions(ctx, c.Param("name"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to list template versions")
+		return
+	}
+
+	httpPkg.Success(c, versions)
+}
+
+// CreateDraft godoc
+// @Summary Create a template draft
+// @Description Store a new draft version of an email template. Bodies are Go templates executed with the catalog fields (.Subject, .Greeting, .Content, ...) and the request data under .Data; the HTML body may use {{template "base.html" .}}. Drafts are validated when published.
+// @Tags email-templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param request body emailService.TemplateSource true "Template source"
+// @Success 201 {object} handler.SuccessResponse{data=domain.TemplateVersion} "Draft created"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid input"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template not found"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/versions [post]
+func (h *TemplateAdminHandler) CreateDraft(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var src emailService.TemplateSource
+	if err := c.ShouldBindJSON(&src); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if id, err := uuid.Parse(c.GetString(userIDKey)); err == nil {
+		createdBy = &id
+	}
+
+	version, err := h.templates.CreateDraft(ctx, c.Param("name"), src, createdBy)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to create template draft")
+		return
+	}
+
+	httpPkg.Created(c, version)
+}
+
+// UpdateDraft godoc
+// @Summary Update a template draft
+// @Description Replace the subject and bodies of a draft version. Published and archived versions are read-only.
+// @Tags email-templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param version path int true "Version number"
+// @Param request body emailService.TemplateSource true "Template source"
+// @Success 200 {object} handler.SuccessResponse{data=domain.TemplateVersion} "Draft updated"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid input"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template or version not found"
+// @Failure 409 {object} handler.ErrorResponse "Conflict: Version is not a draft"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/versions/{version} [put]
+func (h *TemplateAdminHandler) UpdateDraft(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var src emailService.TemplateSource
+	if err := c.ShouldBindJSON(&src); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+
+	version, err := h.templates.UpdateDraft(ctx, c.Param("name"), c.Param("version"), src)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to update template draft")
+		return
+	}
+
+	httpPkg.Success(c, version)
+}
+
+// PreviewVersion godoc
+// @Summary Preview a template version
+// @Description Render a stored version, including drafts, with the given data without sending anything
+// @Tags email-templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param version path int true "Version number"
+// @Param locale query string false "Locale; defaults to the Accept-Language header"
+// @Param data body object true "Template data"
+// @Success 200 {object} handler.SuccessResponse{data=templates.Rendered} "Rendered email"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid input"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template or version not found"
+// @Failure 422 {object} handler.ErrorResponse "Unprocessable Entity: Invalid data or template syntax"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/versions/{version}/preview [post]
+func (h *TemplateAdminHandler) PreviewVersion(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var data map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&data); err != nil {
+		span.RecordError(err)
+		httpPkg.BadRequest(c, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+
+	rendered, err := h.templates.PreviewVersion(ctx, c.Param("name"), c.Param("version"), previewLocale(c), data)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to render template version")
+		return
+	}
+
+	httpPkg.Success(c, rendered)
+}
+
+// Publish godoc
+// @Summary Publish a template version
+// @Description Check that a version parses and renders with sample data, then make it the version used for sending. The previously published version is archived.
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Param version path int true "Version number"
+// @Success 200 {object} handler.SuccessResponse{data=domain.TemplateVersion} "Version published"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid version"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template or version not found"
+// @Failure 422 {object} handler.ErrorResponse "Unprocessable Entity: Template syntax errors"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/versions/{version}/publish [post]
+func (h *TemplateAdminHandler) Publish(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	version, err := h.templates.Publish(ctx, c.Param("name"), c.Param("version"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to publish template version")
+		return
+	}
+
+	httpPkg.Success(c, version)
+}
+
+// Rollback godoc
+// @Summary Roll back a template
+// @Description Republish the version that was published before the current one
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} handler.SuccessResponse{data=domain.TemplateVersion} "Version published"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template not found"
+// @Failure 409 {object} handler.ErrorResponse "Conflict: No previously published version"
+// @Failure 422 {object} handler.ErrorResponse "Unprocessable Entity: Previous version no longer renders"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/rollback [post]
+func (h *TemplateAdminHandler) Rollback(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	version, err := h.templates.Rollback(ctx, c.Param("name"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to roll back template")
+		return
+	}
+
+	httpPkg.Success(c, version)
+}
+
+// Unpublish godoc
+// @Summary Revert a template to the embedded file
+// @Description Archive the published version so the template built into the application is used again
+// @Tags email-templates
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} handler.SuccessResponse "Template reverted"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Template not found"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/email/templates/{name}/published [delete]
+func (h *TemplateAdminHandler) Unpublish(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	if err := h.templates.Unpublish(ctx, c.Param("name")); err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to unpublish template")
+		return
+	}
+
+	httpPkg.Success(c, nil)
+}
+
+func (h *TemplateAdminHandler) handleError(c *gin.Context, err error, message string) {
+	var validationErrs templates.ValidationErrors
+	switch {
+	case strings.Contains(err.Error(), "invalid template version format"):
+		httpPkg.BadRequest(c, "Invalid template version", nil)
+	case errors.Is(err, templates.ErrUnknownTemplate):
+		httpPkg.NotFound(c, "Template not found")
+	case errors.Is(err, emailService.ErrTemplateVersionNotFound):
+		httpPkg.NotFound(c, "Template version not found")
+	case errors.Is(err, emailService.ErrTemplateNotDraft):
+		httpPkg.ErrorResponse(c, httpPkg.StatusConflict, "Only draft versions can be edited", nil)
+	case errors.Is(err, emailService.ErrNoRollbackTarget):
+		httpPkg.ErrorResponse(c, httpPkg.StatusConflict, "No previously published version to roll back to", nil)
+	case errors.As(err, &validationErrs):
+		httpPkg.ValidationError(c, "Invalid template", validationErrs)
+	default:
+		httpPkg.InternalServerError(c, message)
+	}
+}
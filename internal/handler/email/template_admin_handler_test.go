@@ -0,0 +1,91 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+	emailService "base-code-go-gin-clean/internal/service/email"
+	"base-code-go-gin-clean/test"
+)
+
+func TestTemplateAdminHandler_CreateDraft(t *testing.T) {
+	mockTemplates := new(MockTemplateService)
+	handler := NewTemplateAdminHandler(mockTemplates)
+	adminID := uuid.New()
+	router := test.SetupTestRouter()
+	router.POST("/admin/email/templates/:name/versions", func(c *gin.Context) {
+		c.Set(userIDKey, adminID.String())
+		handler.CreateDraft(c)
+	})
+
+	src := emailService.TemplateSource{Subject: "Hi", HTMLBody: "<p>Hi</p>"}
+	mockTemplates.On("CreateDraft", mock.Anything, "welcome", src, &adminID).
+		Return(&email.TemplateVersion{Name: "welcome", Version: 1, Status: email.TemplateDraft}, nil)
+
+	resp := test.MakeTestRequestWithBody(router, "POST", "/admin/email/templates/welcome/versions",
+		bytes.NewReader([]byte(`{"subject":"Hi","html_body":"<p>Hi</p>"}`))).Response
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	resp = test.MakeTestRequestWithBody(router, "POST", "/admin/email/templates/welcome/versions",
+		bytes.NewReader([]byte(`{"subject":"Hi"}`))).Response
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestTemplateAdminHandler_Publish(t *testing.T) {
+	mockTemplates := new(MockTemplateService)
+	handler := NewTemplateAdminHandler(mockTemplates)
+	router := test.SetupTestRouter()
+	router.POST("/admin/email/templates/:name/versions/:version/publish", handler.Publish)
+
+	publish := func(name, version string) *http.Response {
+		return test.MakeTestRequest(router, "POST", "/admin/email/templates/"+name+"/versions/"+version+"/publish").Result()
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		err     error
+		want    int
+	}{
+		{"published", "1", nil, http.StatusOK},
+		{"syntax error", "2", templates.ValidationErrors{"html_body": "unexpected EOF"}, http.StatusUnprocessableEntity},
+		{"invalid version", "x", errors.New(`invalid template version format: "x"`), http.StatusBadRequest},
+		{"unknown version", "3", emailService.ErrTemplateVersionNotFound, http.StatusNotFound},
+		{"database error", "4", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				mockTemplates.On("Publish", mock.Anything, "welcome", tt.version).Return(nil, tt.err).Once()
+			} else {
+				mockTemplates.On("Publish", mock.Anything, "welcome", tt.version).
+					Return(&email.TemplateVersion{Name: "welcome", Version: 1, Status: email.TemplatePublished}, nil).Once()
+			}
+
+			assert.Equal(t, tt.want, publish("welcome", tt.version).StatusCode)
+		})
+	}
+}
+
+func TestTemplateAdminHandler_Rollback(t *testing.T) {
+	mockTemplates := new(MockTemplateService)
+	handler := NewTemplateAdminHandler(mockTemplates)
+	router := test.SetupTestRouter()
+	router.POST("/admin/email/templates/:name/rollback", handler.Rollback)
+
+	mockTemplates.On("Rollback", mock.Anything, "welcome").Return(nil, emailService.ErrNoRollbackTarget)
+
+	resp := test.MakeTestRequest(router, "POST", "/admin/email/templates/welcome/rollback")
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,78 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/storage"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler serves blobs from the local store through signed URLs.
+// It is only needed when blobs are not served by an S3-compatible backend.
+type FileHandler struct {
+	store *storage.LocalStore
+}
+
+func NewFileHandler(store *storage.LocalStore) *FileHandler {
+	return &FileHandler{
+		store: store,
+	}
+}
+
+// ServeFile serves a stored blob if the URL signature is valid
+// @Summary Download a stored file
+// @Description Serve a file from local storage using a signed URL
+// @Tags files
+// @Produce octet-stream
+// @Param key path string true "Object key"
+// @Param expires query string true "Expiry timestamp"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file "File content"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Invalid or expired signature"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: File not found"
+// @Router /files/{key} [get]
+func (h *FileHandler) ServeFile(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := h.store.VerifySignature(key, c.Query("expires"), c.Query("signature")); err != nil {
+		span.RecordError(err)
+		httpPkg.Forbidden(c, "Invalid or expired signature")
+		return
+	}
+
+	blob, err := h.store.Get(ctx, key)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, storage.ErrNotFound) {
+			httpPkg.NotFound(c, "File not found")
+			return
+		}
+		httpPkg.InternalServerError(c, "Failed to read file")
+		return
+	}
+	defer blob.Close()
+
+	// Keys are immutable, so the file may be cached until the URL expires
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	if rs, ok := blob.(io.ReadSeeker); ok {
+		http.ServeContent(c.Writer, c.Request, path.Base(key), time.Time{}, rs)
+		return
+	}
+
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, blob); err != nil {
+		span.RecordError(err)
+	}
+}
@@ -3,13 +3,25 @@
 package handler
 
 import (
-	emailDomain "base-code-go-gin-clean/internal/domain/email"
+	domainhttplog "base-code-go-gin-clean/internal/domain/httplog"
 	"base-code-go-gin-clean/internal/handler/auth"
+	"base-code-go-gin-clean/internal/handler/avatar"
 	email "base-code-go-gin-clean/internal/handler/email"
+	"base-code-go-gin-clean/internal/handler/files"
 	"base-code-go-gin-clean/internal/handler/health"
+	"base-code-go-gin-clean/internal/handler/httplog"
+	"base-code-go-gin-clean/internal/handler/preference"
+	"base-code-go-gin-clean/internal/handler/privacy"
 	"base-code-go-gin-clean/internal/handler/roles"
 	"base-code-go-gin-clean/internal/handler/user"
+	"base-code-go-gin-clean/internal/handler/userbulk"
+	"base-code-go-gin-clean/internal/pkg/storage"
 	"base-code-go-gin-clean/internal/service"
+	avatarService "base-code-go-gin-clean/internal/service/avatar"
+	emailService "base-code-go-gin-clean/internal/service/email"
+	preferenceService "base-code-go-gin-clean/internal/service/preference"
+	privacyService "base-code-go-gin-clean/internal/service/privacy"
+	userbulkService "base-code-go-gin-clean/internal/service/userbulk"
 )
 
 // UserHandler is an alias for user.UserHandler
@@ -20,6 +32,14 @@ func NewUserHandler(userService service.UserService) *UserHandler {
 	return user.NewUserHandler(userService)
 }
 
+// UserBulkHandler is an alias for userbulk.UserBulkHandler
+type UserBulkHandler = userbulk.UserBulkHandler
+
+// NewUserBulkHandler creates a new UserBulkHandler
+func NewUserBulkHandler(bulkSvc userbulkService.UserBulkService) *UserBulkHandler {
+	return userbulk.NewUserBulkHandler(bulkSvc)
+}
+
 // RolesHandler is an alias for roles.RolesHandler
 type RolesHandler = roles.RolesHandler
 
@@ -40,8 +60,40 @@ func NewHealthHandler(healthService service.HealthService) *HealthHandler {
 type EmailHandler = email.EmailHandler
 
 // NewEmailHandler creates a new EmailHandler
-func NewEmailHandler(emailService emailDomain.EmailService) *EmailHandler {
-	return email.NewEmailHandler(emailService)
+func NewEmailHandler(outbox emailService.OutboxService, templates emailService.TemplateService) *EmailHandler {
+	return email.NewEmailHandler(outbox, templates)
+}
+
+// EmailTemplateHandler is an alias for email.TemplateAdminHandler
+type EmailTemplateHandler = email.TemplateAdminHandler
+
+// NewEmailTemplateHandler creates a new EmailTemplateHandler
+func NewEmailTemplateHandler(templates emailService.TemplateService) *EmailTemplateHandler {
+	return email.NewTemplateAdminHandler(templates)
+}
+
+// EmailSuppressionHandler is an alias for email.SuppressionHandler
+type EmailSuppressionHandler = email.SuppressionHandler
+
+// NewEmailSuppressionHandler creates a new EmailSuppressionHandler
+func NewEmailSuppressionHandler(suppressions emailService.SuppressionService, webhookSecret string) *EmailSuppressionHandler {
+	return email.NewSuppressionHandler(suppressions, webhookSecret)
+}
+
+// EmailMessageLogHandler is an alias for email.MessageLogHandler
+type EmailMessageLogHandler = email.MessageLogHandler
+
+// NewEmailMessageLogHandler creates a new EmailMessageLogHandler
+func NewEmailMessageLogHandler(messages emailService.MessageLogService) *EmailMessageLogHandler {
+	return email.NewMessageLogHandler(messages)
+}
+
+// EmailBatchHandler is an alias for email.BatchHandler
+type EmailBatchHandler = email.BatchHandler
+
+// NewEmailBatchHandler creates a new EmailBatchHandler
+func NewEmailBatchHandler(batches emailService.BatchService) *EmailBatchHandler {
+	return email.NewBatchHandler(batches)
 }
 
 // AuthHandler is an alias for auth.AuthHandler
@@ -51,3 +103,43 @@ type AuthHandler = auth.AuthHandler
 func NewAuthHandler(authService service.AuthService) *AuthHandler {
 	return auth.NewAuthHandler(authService)
 }
+
+// PrivacyHandler is an alias for privacy.PrivacyHandler
+type PrivacyHandler = privacy.PrivacyHandler
+
+// NewPrivacyHandler creates a new PrivacyHandler
+func NewPrivacyHandler(privacySvc privacyService.PrivacyService) *PrivacyHandler {
+	return privacy.NewPrivacyHandler(privacySvc)
+}
+
+// AvatarHandler is an alias for avatar.AvatarHandler
+type AvatarHandler = avatar.AvatarHandler
+
+// NewAvatarHandler creates a new AvatarHandler
+func NewAvatarHandler(avatarSvc avatarService.AvatarService) *AvatarHandler {
+	return avatar.NewAvatarHandler(avatarSvc)
+}
+
+// FileHandler is an alias for files.FileHandler
+type FileHandler = files.FileHandler
+
+// NewFileHandler creates a new FileHandler
+func NewFileHandler(store *storage.LocalStore) *FileHandler {
+	return files.NewFileHandler(store)
+}
+
+// PreferenceHandler is an alias for preference.PreferenceHandler
+type PreferenceHandler = preference.PreferenceHandler
+
+// NewPreferenceHandler creates a new PreferenceHandler
+func NewPreferenceHandler(preferenceSvc preferenceService.PreferenceService) *PreferenceHandler {
+	return preference.NewPreferenceHandler(preferenceSvc)
+}
+
+// HTTPLogHandler is an alias for httplog.HTTPLogHandler
+type HTTPLogHandler = httplog.HTTPLogHandler
+
+// NewHTTPLogHandler creates a new HTTPLogHandler
+func NewHTTPLogHandler(logs domainhttplog.Service) *HTTPLogHandler {
+	return httplog.NewHTTPLogHandler(logs)
+}
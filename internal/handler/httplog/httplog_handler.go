@@ -0,0 +1,191 @@
+package httplog
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/httplog"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPLogHandler serves the admin API for searching the HTTP request logs
+type HTTPLogHandler struct {
+	logs domain.Service
+}
+
+func NewHTTPLogHandler(logs domain.Service) *HTTPLogHandler {
+	return &HTTPLogHandler{
+		logs: logs,
+	}
+}
+
+// SearchRequests godoc
+// @Summary Search the HTTP request logs
+// @Description List logged incoming requests with the status codes and durations of their responses, newest first. Pass next_cursor of a page as cursor to get the next one.
+// @Tags http-logs
+// @Produce json
+// @Param trace_id query string false "Trace ID"
+// @Param endpoint query string false "URL path or route (e.g. /api/v1/users/:id), or a path prefix ending in *"
+// @Param method query string false "HTTP method" Enums(GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS, TRACE)
+// @Param status_min query int false "Lowest response status code"
+// @Param status_max query int false "Highest response status code"
+// @Param ip query string false "Client IP address or CIDR range"
+// @Param user_id query string false "ID of the authenticated user"
+// @Param min_duration_ms query int false "Slow requests only: lowest time to respond in milliseconds"
+// @Param since query string false "Received at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param until query string false "Received before (RFC 3339 or YYYY-MM-DD)"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Success 200 {object} handler.SuccessResponse{data=domain.RequestPage} "Requests"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid filter or cursor"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/http-logs/requests [get]
+func (h *HTTPLogHandler) SearchRequests(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	filter, err := parseFilter(c)
+	if err != nil {
+		httpPkg.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	page, err := h.logs.SearchRequests(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to search request logs")
+		return
+	}
+
+	httpPkg.Success(c, page)
+}
+
+// GetRequest godoc
+// @Summary Get a logged HTTP request
+// @Description Return a logged incoming request together with its response and the errors raised while handling it
+// @Tags http-logs
+// @Produce json
+// @Param id path string true "Request log ID"
+// @Success 200 {object} handler.SuccessResponse{data=domain.RequestDetail} "Request, response and errors"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid request ID"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Request not found"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/http-logs/requests/{id} [get]
+func (h *HTTPLogHandler) GetRequest(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	detail, err := h.logs.GetRequest(ctx, c.Param("id"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to get request log")
+		return
+	}
+
+	httpPkg.Success(c, detail)
+}
+
+// GetTrace godoc
+// @Summary Get the HTTP logs of a trace
+// @Description Return the latest incoming request, the outgoing requests and the errors logged under a trace ID
+// @Tags http-logs
+// @Produce json
+// @Param trace_id path string true "Trace ID"
+// @Success 200 {object} handler.SuccessResponse{data=domain.RequestLogs} "Logs of the trace"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: No logs for the trace"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/http-logs/traces/{trace_id} [get]
+func (h *HTTPLogHandler) GetTrace(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	logs, err := h.logs.GetRequestLogs(ctx, c.Param("trace_id"))
+	if err != nil {
+		span.RecordError(err)
+		h.handleError(c, err, "Failed to get trace logs")
+		return
+	}
+	if logs.IncomingRequest == nil {
+		httpPkg.NotFound(c, "No logs for this trace")
+		return
+	}
+
+	httpPkg.Success(c, logs)
+}
+
+func (h *HTTPLogHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case strings.Contains(err.Error(), "invalid request ID format"):
+		httpPkg.BadRequest(c, "Invalid request ID", nil)
+	case errors.Is(err, domain.ErrInvalidFilter):
+		httpPkg.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, domain.ErrRequestNotFound):
+		httpPkg.NotFound(c, "Request not found")
+	default:
+		httpPkg.InternalServerError(c, message)
+	}
+}
+
+// parseFilter parses the search query parameters
+func parseFilter(c *gin.Context) (domain.RequestFilter, error) {
+	filter := domain.RequestFilter{
+		TraceID:   c.Query("trace_id"),
+		Endpoint:  c.Query("endpoint"),
+		Method:    domain.HTTPMethod(strings.ToUpper(c.Query("method"))),
+		IPAddress: c.Query("ip"),
+		UserID:    c.Query("user_id"),
+	}
+
+	for name, dst := range map[string]*int{
+		"status_min":      &filter.StatusMin,
+		"status_max":      &filter.StatusMax,
+		"min_duration_ms": &filter.MinDurationMs,
+		"limit":           &filter.Limit,
+	} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return filter, fmt.Errorf("%s must be a number", name)
+			}
+			*dst = n
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				*dst = &t
+				break
+			}
+		}
+		if *dst == nil {
+			return filter, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name)
+		}
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := domain.ParseCursor(cursor)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = after
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,100 @@
+package httplog
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	domain "base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/test"
+)
+
+type MockService struct {
+	domain.Service
+	mock.Mock
+}
+
+func (m *MockService) SearchRequests(ctx context.Context, filter domain.RequestFilter) (*domain.RequestPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RequestPage), args.Error(1)
+}
+
+func (m *MockService) GetRequest(ctx context.Context, requestID string) (*domain.RequestDetail, error) {
+	args := m.Called(ctx, requestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RequestDetail), args.Error(1)
+}
+
+func (m *MockService) GetRequestLogs(ctx context.Context, traceID string) (*domain.RequestLogs, error) {
+	args := m.Called(ctx, traceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RequestLogs), args.Error(1)
+}
+
+func TestHTTPLogHandler_SearchRequests(t *testing.T) {
+	mockLogs := new(MockService)
+	router := test.SetupTestRouter()
+	router.GET("/admin/http-logs/requests", NewHTTPLogHandler(mockLogs).SearchRequests)
+
+	cursor := domain.Cursor{CreatedAt: time.Date(2025, 8, 25, 9, 0, 0, 0, time.UTC), ID: uuid.New().String()}
+	status := 502
+	mockLogs.On("SearchRequests", mock.Anything, mock.MatchedBy(func(f domain.RequestFilter) bool {
+		return f.Method == domain.POST && f.StatusMin == 500 && f.StatusMax == 599 && f.IPAddress == "10.0.0.0/8" &&
+			f.Endpoint == "/api/v1/admin/*" && f.Since != nil && f.Until == nil && f.Limit == 20 &&
+			f.After != nil && f.After.ID == cursor.ID
+	})).Return(&domain.RequestPage{
+		Requests:   []*domain.RequestSummary{{ID: uuid.New().String(), StatusCode: &status}},
+		NextCursor: "next",
+	}, nil).Once()
+
+	resp := test.MakeTestRequest(router, "GET", "/admin/http-logs/requests?method=post&status_min=500&status_max=599&ip=10.0.0.0/8"+
+		"&endpoint=/api/v1/admin/*&since=2025-08-25&limit=20&cursor="+cursor.String())
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status_code":502`)
+	assert.Contains(t, resp.Body.String(), `"next_cursor":"next"`)
+
+	assert.Equal(t, http.StatusBadRequest, test.MakeTestRequest(router, "GET", "/admin/http-logs/requests?status_min=5xx").Code)
+	assert.Equal(t, http.StatusBadRequest, test.MakeTestRequest(router, "GET", "/admin/http-logs/requests?since=yesterday").Code)
+	assert.Equal(t, http.StatusBadRequest, test.MakeTestRequest(router, "GET", "/admin/http-logs/requests?cursor=garbage").Code)
+
+	mockLogs.On("SearchRequests", mock.Anything, mock.Anything).Return(nil, domain.ErrInvalidFilter).Once()
+	assert.Equal(t, http.StatusBadRequest, test.MakeTestRequest(router, "GET", "/admin/http-logs/requests?method=FETCH").Code)
+	mockLogs.AssertExpectations(t)
+}
+
+func TestHTTPLogHandler_GetRequest(t *testing.T) {
+	mockLogs := new(MockService)
+	router := test.SetupTestRouter()
+	h := NewHTTPLogHandler(mockLogs)
+	router.GET("/admin/http-logs/requests/:id", h.GetRequest)
+	router.GET("/admin/http-logs/traces/:trace_id", h.GetTrace)
+
+	id := uuid.New().String()
+	mockLogs.On("GetRequest", mock.Anything, id).Return(&domain.RequestDetail{
+		Request:  &domain.LogIncomingRequest{ID: id, TraceID: "t1"},
+		Response: &domain.LogOutgoingRequest{TraceID: "t1", StatusCode: 500},
+		Errors:   []*domain.LogError{{Error: "boom"}},
+	}, nil).Once()
+	resp := test.MakeTestRequest(router, "GET", "/admin/http-logs/requests/"+id)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"error":"boom"`)
+	assert.Contains(t, resp.Body.String(), `"id":"`+id+`"`)
+
+	mockLogs.On("GetRequest", mock.Anything, id).Return(nil, domain.ErrRequestNotFound).Once()
+	assert.Equal(t, http.StatusNotFound, test.MakeTestRequest(router, "GET", "/admin/http-logs/requests/"+id).Code)
+
+	mockLogs.On("GetRequestLogs", mock.Anything, "t2").Return(&domain.RequestLogs{}, nil).Once()
+	assert.Equal(t, http.StatusNotFound, test.MakeTestRequest(router, "GET", "/admin/http-logs/traces/t2").Code)
+}
@@ -0,0 +1,116 @@
+package preference
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"base-code-go-gin-clean/internal/domain/preference"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	preferenceService "base-code-go-gin-clean/internal/service/preference"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys for storing values in the request context
+const (
+	userIDKey = "userID"
+)
+
+type PreferenceHandler struct {
+	preferenceService preferenceService.PreferenceService
+}
+
+func NewPreferenceHandler(preferenceService preferenceService.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferenceService: preferenceService,
+	}
+}
+
+// GetPreferences returns the authenticated user's preferences
+// @Summary Get preferences
+// @Description Get all preferences of the authenticated user. Keys the user has not set hold their default value.
+// @Tags preferences
+// @Produce json
+// @Success 200 {object} handler.SuccessResponse{data=map[string]interface{}} "Preferences"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /users/me/preferences [get]
+func (h *PreferenceHandler) GetPreferences(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	userID := c.GetString(userIDKey)
+	if userID == "" {
+		httpPkg.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	prefs, err := h.preferenceService.Get(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		httpPkg.InternalServerError(c, "Failed to load preferences")
+		return
+	}
+
+	httpPkg.Success(c, prefs)
+}
+
+// UpdatePreferences applies a partial update to the authenticated user's preferences
+// @Summary Update preferences
+// @Description Set one or more preferences. Keys not in the request are left unchanged; a null value resets a key to its default.
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} true "Preferences to change"
+// @Success 200 {object} handler.SuccessResponse{data=map[string]interface{}} "Updated preferences"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Body is not a JSON object"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 422 {object} handler.ErrorResponse "Validation error per key"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /users/me/preferences [put]
+func (h *PreferenceHandler) UpdatePreferences(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	userID := c.GetString(userIDKey)
+	if userID == "" {
+		httpPkg.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var changes map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&changes); err != nil || changes == nil {
+		httpPkg.BadRequest(c, "Request body must be a JSON object of preference keys", nil)
+		return
+	}
+
+	prefs, err := h.preferenceService.Update(ctx, userID, changes)
+	if err != nil {
+		span.RecordError(err)
+		var validationErrs preference.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrs):
+			httpPkg.ValidationError(c, "Invalid preferences", validationErrs)
+		case strings.Contains(err.Error(), "invalid user ID format"):
+			httpPkg.BadRequest(c, "Invalid user ID format", nil)
+		default:
+			httpPkg.InternalServerError(c, "Failed to update preferences")
+		}
+		return
+	}
+
+	httpPkg.Success(c, prefs)
+}
+
+// GetSchema lists the allowed preference keys
+// @Summary Preference schema
+// @Description List every preference key with its type, default value and allowed options
+// @Tags preferences
+// @Produce json
+// @Success 200 {object} handler.SuccessResponse{data=[]preference.Definition} "Preference definitions"
+// @Router /preferences/schema [get]
+func (h *PreferenceHandler) GetSchema(c *gin.Context) {
+	httpPkg.Success(c, h.preferenceService.Schema())
+}
@@ -0,0 +1,161 @@
+package privacy
+
+import (
+	"errors"
+	"path/filepath"
+
+	domainUser "base-code-go-gin-clean/internal/domain/user"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	privacyService "base-code-go-gin-clean/internal/service/privacy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys for storing values in the request context
+const (
+	userIDKey = "userID"
+)
+
+type PrivacyHandler struct {
+	privacyService privacyService.PrivacyService
+}
+
+func NewPrivacyHandler(privacyService privacyService.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// RequestExport handles subject access export requests
+// @Summary Request a personal data export
+// @Description Assemble an archive of the authenticated user's profile, sessions and HTTP logs in the background. A download link is emailed when it is ready.
+// @Tags privacy
+// @Produce json
+// @Success 202 {object} handler.SuccessResponse "Export started"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 409 {object} handler.ErrorResponse "Conflict: Export already in progress"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /users/me/export [post]
+func (h *PrivacyHandler) RequestExport(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	userID := c.GetString(userIDKey)
+	if userID == "" {
+		httpPkg.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.privacyService.RequestExport(ctx, userID); err != nil {
+		span.RecordError(err)
+		switch {
+		case errors.Is(err, privacyService.ErrExportInProgress):
+			httpPkg.ErrorResponse(c, httpPkg.StatusConflict, "A data export is already in progress", nil)
+		case errors.Is(err, domainUser.ErrUserNotFound):
+			httpPkg.NotFound(c, "User not found")
+		default:
+			httpPkg.InternalServerError(c, "Failed to start data export")
+		}
+		return
+	}
+
+	httpPkg.SuccessResponse(c, httpPkg.StatusAccepted, map[string]string{
+		"message": "Your data export is being prepared. A download link will be emailed to you.",
+	})
+}
+
+// DownloadExport serves a previously generated export archive
+// @Summary Download a personal data export
+// @Description Download the archive referenced by the emailed link
+// @Tags privacy
+// @Produce application/zip
+// @Param token path string true "Download token"
+// @Success 200 {file} file "Export archive"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: Export not found or expired"
+// @Router /privacy/exports/{token} [get]
+func (h *PrivacyHandler) DownloadExport(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	path, err := h.privacyService.OpenExport(ctx, c.Param("token"))
+	if err != nil {
+		span.RecordError(err)
+		httpPkg.NotFound(c, "Export not found or expired")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, filepath.Base(path))
+}
+
+// RequestDeletion handles right-to-erasure requests
+// @Summary Request account deletion
+// @Description Schedule the authenticated user's account for deletion. The deletion can be cancelled until the grace period ends.
+// @Tags privacy
+// @Produce json
+// @Success 202 {object} handler.SuccessResponse{data=privacy.DeletionRequest} "Deletion scheduled"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 409 {object} handler.ErrorResponse "Conflict: Deletion already requested"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /users/me/deletion [post]
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	userID := c.GetString(userIDKey)
+	if userID == "" {
+		httpPkg.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	req, err := h.privacyService.RequestDeletion(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case errors.Is(err, privacyService.ErrDeletionAlreadyRequested):
+			httpPkg.ErrorResponse(c, httpPkg.StatusConflict, "Account deletion already requested", nil)
+		case errors.Is(err, domainUser.ErrUserNotFound):
+			httpPkg.NotFound(c, "User not found")
+		default:
+			httpPkg.InternalServerError(c, "Failed to schedule account deletion")
+		}
+		return
+	}
+
+	httpPkg.SuccessResponse(c, httpPkg.StatusAccepted, req)
+}
+
+// CancelDeletion cancels a pending account deletion
+// @Summary Cancel account deletion
+// @Description Cancel a pending account deletion during the grace period
+// @Tags privacy
+// @Produce json
+// @Success 200 {object} handler.SuccessResponse{data=privacy.DeletionRequest} "Deletion cancelled"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: No pending deletion"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /users/me/deletion [delete]
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	userID := c.GetString(userIDKey)
+	if userID == "" {
+		httpPkg.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	req, err := h.privacyService.CancelDeletion(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		if errors.Is(err, privacyService.ErrNoPendingDeletion) {
+			httpPkg.NotFound(c, "No pending account deletion")
+			return
+		}
+		httpPkg.InternalServerError(c, "Failed to cancel account deletion")
+		return
+	}
+
+	httpPkg.Success(c, req)
+}
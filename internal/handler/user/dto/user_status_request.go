@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+// ChangeUserStatusRequest represents the request body for changing a user's account status
+type ChangeUserStatusRequest struct {
+	Status         string     `json:"status" binding:"required,oneof=pending active suspended locked" example:"suspended"`
+	Reason         string     `json:"reason,omitempty" binding:"max=500" example:"Violation of terms of service"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty" example:"2025-12-31T00:00:00Z"`
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 
+	domainUser "base-code-go-gin-clean/internal/domain/user"
 	"base-code-go-gin-clean/internal/handler/user/dto"
 	"base-code-go-gin-clean/internal/pkg/http"
 	"base-code-go-gin-clean/internal/pkg/telemetry"
@@ -67,3 +68,55 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	// Map domain model to DTO and return success response
 	http.Success(c, dto.NewUserResponse(userResponse))
 }
+
+// ChangeUserStatus handles administrative account status changes
+// @Summary Change user account status
+// @Description Activate, suspend or lock a user account. Suspended users are notified by email and lose access immediately.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ChangeUserStatusRequest true "Status change"
+// @Success 200 {object} handler.SuccessResponse{data=dto.UserResponse} "Status updated"
+// @Failure 400 {object} handler.ErrorResponse "Bad Request: Invalid input"
+// @Failure 401 {object} handler.ErrorResponse "Unauthorized: Authentication required"
+// @Failure 403 {object} handler.ErrorResponse "Forbidden: Insufficient permissions"
+// @Failure 404 {object} handler.ErrorResponse "Not Found: User not found"
+// @Failure 409 {object} handler.ErrorResponse "Conflict: Status transition not allowed"
+// @Failure 500 {object} handler.ErrorResponse "Internal Server Error"
+// @Router /admin/users/{id}/status [put]
+func (h *UserHandler) ChangeUserStatus(c *gin.Context) {
+	ctx, span := telemetry.Start(c.Request.Context())
+	defer span.End()
+
+	var req dto.ChangeUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		span.RecordError(err)
+		http.BadRequest(c, "Invalid request body: "+err.Error(), nil)
+		return
+	}
+
+	userResponse, err := h.userService.ChangeUserStatus(ctx, c.Param("id"), service.ChangeUserStatusRequest{
+		Status:         domainUser.Status(req.Status),
+		Reason:         req.Reason,
+		SuspendedUntil: req.SuspendedUntil,
+	})
+	if err != nil {
+		span.RecordError(err)
+		switch {
+		case strings.Contains(err.Error(), "invalid user ID format"):
+			http.BadRequest(c, "Invalid user ID format", nil)
+		case errors.Is(err, domainUser.ErrInvalidStatus):
+			http.BadRequest(c, err.Error(), nil)
+		case errors.Is(err, domainUser.ErrUserNotFound):
+			http.NotFound(c, "User not found")
+		case errors.Is(err, domainUser.ErrInvalidStatusTransition):
+			http.ErrorResponse(c, http.StatusConflict, "Status transition not allowed", nil)
+		default:
+			http.InternalServerError(c, "Failed to change user status")
+		}
+		return
+	}
+
+	http.Success(c, dto.NewUserResponse(userResponse))
+}
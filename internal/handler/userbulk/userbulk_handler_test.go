@@ -0,0 +1,128 @@
+package userbulk_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	userbulkHandler "base-code-go-gin-clean/internal/handler/userbulk"
+	userbulkService "base-code-go-gin-clean/internal/service/userbulk"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockUserBulkService struct {
+	mock.Mock
+}
+
+func (m *mockUserBulkService) Import(ctx context.Context, r io.Reader, format userbulkService.Format, opts userbulkService.ImportOptions) (*userbulkService.ImportReport, error) {
+	args := m.Called(ctx, r, format, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userbulkService.ImportReport), args.Error(1)
+}
+
+func (m *mockUserBulkService) Export(ctx context.Context, w io.Writer, format userbulkService.Format, filter user.ExportFilter) (int, error) {
+	args := m.Called(ctx, w, format, filter)
+	_, _ = w.Write([]byte("id,email\n"))
+	return args.Int(0), args.Error(1)
+}
+
+func setupRouter(h *userbulkHandler.UserBulkHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/admin/users/import", h.ImportUsers)
+	r.GET("/admin/users/export", h.ExportUsers)
+	return r
+}
+
+func TestUserBulkHandler_ImportUsers(t *testing.T) {
+	t.Run("dry run with format from extension", func(t *testing.T) {
+		svc := new(mockUserBulkService)
+		svc.On("Import", mock.Anything, mock.Anything, userbulkService.FormatNDJSON, userbulkService.ImportOptions{DryRun: true}).
+			Return(&userbulkService.ImportReport{DryRun: true, TotalRows: 1, Created: 1, Errors: []userbulkService.RowError{}}, nil)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "users.ndjson")
+		_, _ = part.Write([]byte(`{"email":"a@example.com","name":"A"}`))
+		_ = writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/import?dry_run=true", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+
+		setupRouter(userbulkHandler.NewUserBulkHandler(svc)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data userbulkService.ImportReport `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Data.DryRun)
+		assert.Equal(t, 1, response.Data.Created)
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		svc := new(mockUserBulkService)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "users.xlsx")
+		_, _ = part.Write([]byte("data"))
+		_ = writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/import", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+
+		setupRouter(userbulkHandler.NewUserBulkHandler(svc)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		svc.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserBulkHandler_ExportUsers(t *testing.T) {
+	t.Run("filters are passed to the service", func(t *testing.T) {
+		svc := new(mockUserBulkService)
+		after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		svc.On("Export", mock.Anything, mock.Anything, userbulkService.FormatCSV, user.ExportFilter{
+			EmailContains: "@example.com",
+			CreatedAfter:  &after,
+		}).Return(1, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/users/export?email=@example.com&created_after=2025-01-01", nil)
+		w := httptest.NewRecorder()
+
+		setupRouter(userbulkHandler.NewUserBulkHandler(svc)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+		assert.Equal(t, "id,email\n", w.Body.String())
+		svc.AssertExpectations(t)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		svc := new(mockUserBulkService)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/users/export?created_before=yesterday", nil)
+		w := httptest.NewRecorder()
+
+		setupRouter(userbulkHandler.NewUserBulkHandler(svc)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
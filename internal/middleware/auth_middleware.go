@@ -1,15 +1,29 @@
 package middleware
 
 import (
+	"base-code-go-gin-clean/internal/domain/user"
 	"base-code-go-gin-clean/internal/pkg/token"
-	"fmt"
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware is a middleware that checks for a valid access token
-func AuthMiddleware(tokenService token.TokenService) gin.HandlerFunc {
+// StatusChecker verifies that an authenticated user is still allowed to access the API
+type StatusChecker interface {
+	EnsureUserActive(ctx context.Context, userID string) error
+}
+
+// RoleChecker verifies that an authenticated user has the role a route requires
+type RoleChecker interface {
+	EnsureUserRole(ctx context.Context, userID string, role user.Role) error
+}
+
+// AuthMiddleware is a middleware that checks for a valid access token.
+// When statusChecker is not nil, requests from suspended, locked or pending
+// accounts are rejected even if their access token has not expired yet.
+func AuthMiddleware(tokenService token.TokenService, statusChecker StatusChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the access token from the cookie
 		accessToken, err := c.Cookie("access_token")
@@ -29,31 +43,66 @@ func AuthMiddleware(tokenService token.TokenService) gin.HandlerFunc {
 			return
 		}
 
+		// Reject accounts that are no longer active
+		if statusChecker != nil {
+			if err := statusChecker.EnsureUserActive(c.Request.Context(), userID); err != nil {
+				abortAccountCheck(c, err, user.ErrAccountInactive, "User account is not active")
+				return
+			}
+		}
+
 		// Set the user ID in the context for use in subsequent handlers
 		c.Set("userID", userID)
 		c.Next()
 	}
 }
 
-// RoleMiddleware is a middleware that checks if the user has the required role
-func RoleMiddleware(requiredRole string) gin.HandlerFunc {
+// RoleMiddleware is a middleware that checks if the user has the required role.
+// It must run after AuthMiddleware. Without a role checker every request is
+// rejected, so that a misconfigured server does not expose admin routes.
+func RoleMiddleware(roleChecker RoleChecker, requiredRole user.Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// In a real application, you would check the user's roles here
-		// For this example, we'll just check if the user has the required role
-		// You would typically get the user's roles from the database or token claims
-		userID, exists := c.Get("userID")
-		if !exists {
+		userID := c.GetString("userID")
+		if userID == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "User not authenticated",
 			})
 			return
 		}
 
-		// In a real application, you would check the user's roles here
-		// For now, we'll just log the user ID and required role
-		fmt.Printf("User %s is trying to access a route that requires role: %s\n", userID, requiredRole)
+		if roleChecker == nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Insufficient permissions",
+			})
+			return
+		}
+
+		if err := roleChecker.EnsureUserRole(c.Request.Context(), userID, requiredRole); err != nil {
+			abortAccountCheck(c, err, user.ErrInsufficientRole, "Insufficient permissions")
+			return
+		}
 
-		// Continue to the next handler if the user has the required role
 		c.Next()
 	}
 }
+
+// abortAccountCheck answers a failed account check: 403 when the account is denied
+// with the denied error, 401 when it no longer exists, and 503 when it could not
+// be checked
+func abortAccountCheck(c *gin.Context, err, denied error, message string) {
+	switch {
+	case errors.Is(err, denied):
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": message,
+		})
+	case errors.Is(err, user.ErrUserNotFound):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "User not found",
+		})
+	default:
+		_ = c.Error(err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Failed to verify user account",
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/config"
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/middleware"
+	"base-code-go-gin-clean/internal/pkg/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// accountChecker fails the status and role checks with err
+type accountChecker struct {
+	err error
+}
+
+func (c accountChecker) EnsureUserActive(ctx context.Context, userID string) error {
+	return c.err
+}
+
+func (c accountChecker) EnsureUserRole(ctx context.Context, userID string, role user.Role) error {
+	return c.err
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenService := token.NewTokenService(&config.TokenConfig{
+		AccessSecret:      "test-secret",
+		AccessTokenExpiry: time.Minute,
+	})
+	accessToken, err := tokenService.GenerateAccessToken("user-1")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name       string
+		checker    accountChecker
+		wantStatus int
+	}{
+		{"active user", accountChecker{}, http.StatusOK},
+		{"inactive user", accountChecker{err: user.ErrAccountInactive}, http.StatusForbidden},
+		{"deleted user", accountChecker{err: user.ErrUserNotFound}, http.StatusUnauthorized},
+		{"database down", accountChecker{err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", middleware.AuthMiddleware(tokenService, tt.checker), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: "access_token", Value: accessToken})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRoleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		userID     string
+		checker    middleware.RoleChecker
+		wantStatus int
+	}{
+		{"admin", "user-1", accountChecker{}, http.StatusOK},
+		{"missing role", "user-1", accountChecker{err: user.ErrInsufficientRole}, http.StatusForbidden},
+		{"deleted user", "user-1", accountChecker{err: user.ErrUserNotFound}, http.StatusUnauthorized},
+		{"database down", "user-1", accountChecker{err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+		{"no role checker", "user-1", nil, http.StatusForbidden},
+		{"not authenticated", "", accountChecker{}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if tt.userID != "" {
+					c.Set("userID", tt.userID)
+				}
+			}, middleware.RoleMiddleware(tt.checker, user.RoleAdmin), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
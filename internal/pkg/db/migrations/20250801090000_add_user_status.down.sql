@@ -0,0 +1,12 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_status;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_status;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS suspended_until,
+    DROP COLUMN IF EXISTS suspension_reason,
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS suspension_reason TEXT,
+    ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE users
+    ADD CONSTRAINT chk_users_status CHECK (status IN ('pending', 'active', 'suspended', 'locked'));
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users (status)
+WHERE
+    deleted_at IS NULL;
+-- +goose StatementEnd
@@ -0,0 +1,4 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_deletion_requests;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_deletion_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'cancelled', 'completed')),
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one pending deletion per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_deletion_requests_pending ON user_deletion_requests (user_id)
+WHERE
+    status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_user_deletion_requests_due ON user_deletion_requests (scheduled_for)
+WHERE
+    status = 'pending';
+-- +goose StatementEnd
@@ -0,0 +1,4 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_key;
+-- +goose StatementEnd
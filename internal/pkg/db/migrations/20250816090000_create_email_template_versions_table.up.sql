@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_template_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    subject TEXT,
+    html_body TEXT NOT NULL,
+    text_body TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_email_template_versions_status CHECK (status IN ('draft', 'published', 'archived')),
+    CONSTRAINT uq_email_template_versions_name_version UNIQUE (name, version)
+);
+
+-- Rendering looks up the published version by name; only one may exist per template
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_template_versions_published ON email_template_versions (name)
+    WHERE status = 'published';
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    detail TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_email_suppressions_reason CHECK (reason IN ('unsubscribed', 'hard_bounce', 'complaint')),
+    -- Addresses are stored lower-cased, so this also makes lookups case-insensitive
+    CONSTRAINT uq_email_suppressions_email UNIQUE (email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_suppressions_created_at ON email_suppressions (created_at DESC);
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_messages (
+    -- Same ID as the email_outbox row the message was queued as
+    id UUID PRIMARY KEY,
+    template VARCHAR(100),
+    subject TEXT NOT NULL,
+    recipients TEXT[] NOT NULL,
+    bulk BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    provider_response TEXT,
+    last_error TEXT,
+    trace_id VARCHAR(64),
+    tracked BOOLEAN NOT NULL DEFAULT FALSE,
+    opens INTEGER NOT NULL DEFAULT 0,
+    clicks INTEGER NOT NULL DEFAULT 0,
+    first_opened_at TIMESTAMP WITH TIME ZONE,
+    last_opened_at TIMESTAMP WITH TIME ZONE,
+    first_clicked_at TIMESTAMP WITH TIME ZONE,
+    last_clicked_at TIMESTAMP WITH TIME ZONE,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_email_messages_status CHECK (status IN ('queued', 'sending', 'sent', 'dead', 'suppressed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_messages_created_at ON email_messages (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_messages_template_created_at ON email_messages (template, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_email_messages_trace_id ON email_messages (trace_id) WHERE trace_id IS NOT NULL;
+-- Serves recipient lookups with "recipients @> ARRAY[...]"
+CREATE INDEX IF NOT EXISTS idx_email_messages_recipients ON email_messages USING GIN (recipients);
+-- +goose StatementEnd
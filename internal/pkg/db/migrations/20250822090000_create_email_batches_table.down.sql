@@ -0,0 +1,5 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_batch_recipients;
+DROP TABLE IF EXISTS email_batches;
+-- +goose StatementEnd
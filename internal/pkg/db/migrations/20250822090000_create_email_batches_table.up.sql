@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template VARCHAR(100) NOT NULL,
+    reply_to VARCHAR(255),
+    locale VARCHAR(35),
+    -- Template data shared by all recipients
+    data JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+    send_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    total INTEGER NOT NULL DEFAULT 0,
+    queued INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    dispatch_started_at TIMESTAMP WITH TIME ZONE,
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_email_batches_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_email_batches_status CHECK (status IN ('scheduled', 'dispatching', 'dispatched', 'cancelled'))
+);
+
+-- Serves the batch worker's poll for due and abandoned batches
+CREATE INDEX IF NOT EXISTS idx_email_batches_due ON email_batches (send_at) WHERE status IN ('scheduled', 'dispatching');
+
+CREATE TABLE IF NOT EXISTS email_batch_recipients (
+    batch_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    locale VARCHAR(35),
+    -- Per-recipient template data, merged over the batch data
+    data JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    message_id UUID,
+    error TEXT,
+    CONSTRAINT pk_email_batch_recipients PRIMARY KEY (batch_id, position),
+    CONSTRAINT fk_email_batch_recipients_batch FOREIGN KEY (batch_id) REFERENCES email_batches(id) ON DELETE CASCADE,
+    CONSTRAINT chk_email_batch_recipients_status CHECK (status IN ('pending', 'queued', 'failed'))
+);
+-- +goose StatementEnd
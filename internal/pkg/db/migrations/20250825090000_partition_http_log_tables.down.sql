@@ -0,0 +1,78 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS httplog.idx_log_outgoing_requests_trace_id;
+DROP INDEX IF EXISTS httplog.idx_log_outgoing_requests_created_at;
+DROP INDEX IF EXISTS httplog.idx_log_incoming_requests_trace_id;
+DROP INDEX IF EXISTS httplog.idx_log_incoming_requests_created_at;
+DROP INDEX IF EXISTS httplog.idx_log_errors_trace_id;
+DROP INDEX IF EXISTS httplog.idx_log_errors_created_at;
+DROP INDEX IF EXISTS httplog.idx_log_errors_request_id;
+
+ALTER TABLE httplog.log_outgoing_requests RENAME TO log_outgoing_requests_partitioned;
+ALTER TABLE httplog.log_incoming_requests RENAME TO log_incoming_requests_partitioned;
+ALTER TABLE httplog.log_errors RENAME TO log_errors_partitioned;
+
+CREATE TABLE httplog.log_outgoing_requests (
+    id INTEGER PRIMARY KEY DEFAULT nextval('httplog.log_outgoing_requests_id_seq'),
+    trace_id TEXT NOT NULL,
+    event_name TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    method http_method NOT NULL,
+    request JSONB NOT NULL,
+    status_code INTEGER NOT NULL,
+    response JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE httplog.log_incoming_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trace_id TEXT NOT NULL,
+    event_name TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    method http_method NOT NULL,
+    request JSONB NOT NULL,
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE httplog.log_errors (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trace_id TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    error TEXT NOT NULL,
+    request_id UUID REFERENCES httplog.log_incoming_requests (id) ON DELETE SET NULL,
+    traces JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO httplog.log_outgoing_requests
+SELECT id, trace_id, event_name, endpoint, method, request, status_code, response, created_at, updated_at
+FROM httplog.log_outgoing_requests_partitioned;
+
+INSERT INTO httplog.log_incoming_requests
+SELECT id, trace_id, event_name, endpoint, method, request, ip_address, user_agent, created_at, updated_at
+FROM httplog.log_incoming_requests_partitioned;
+
+-- Requests of dropped partitions may be gone; their errors lose the reference
+INSERT INTO httplog.log_errors
+SELECT e.id, e.trace_id, e.status_code, e.error, r.id, e.traces, e.created_at, e.updated_at
+FROM httplog.log_errors_partitioned e
+LEFT JOIN httplog.log_incoming_requests r ON r.id = e.request_id;
+
+ALTER SEQUENCE httplog.log_outgoing_requests_id_seq OWNED BY httplog.log_outgoing_requests.id;
+
+DROP TABLE httplog.log_errors_partitioned;
+DROP TABLE httplog.log_incoming_requests_partitioned;
+DROP TABLE httplog.log_outgoing_requests_partitioned;
+
+CREATE INDEX IF NOT EXISTS idx_log_outgoing_requests_trace_id ON httplog.log_outgoing_requests (trace_id);
+CREATE INDEX IF NOT EXISTS idx_log_outgoing_requests_created_at ON httplog.log_outgoing_requests (created_at);
+CREATE INDEX IF NOT EXISTS idx_log_incoming_requests_trace_id ON httplog.log_incoming_requests (trace_id);
+CREATE INDEX IF NOT EXISTS idx_log_incoming_requests_created_at ON httplog.log_incoming_requests (created_at);
+CREATE INDEX IF NOT EXISTS idx_log_errors_trace_id ON httplog.log_errors (trace_id);
+CREATE INDEX IF NOT EXISTS idx_log_errors_created_at ON httplog.log_errors (created_at);
+-- +goose StatementEnd
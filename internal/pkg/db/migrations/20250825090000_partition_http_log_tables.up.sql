@@ -0,0 +1,101 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Range-partition the HTTP log tables by created_at so that expired logs can be
+-- dropped a partition at a time. The retention job creates the partitions of
+-- the coming days or months; rows outside of every partition, including all
+-- existing rows, are kept in a default partition.
+
+-- A partitioned table can only be referenced through a key that includes the
+-- partition column, so errors keep the request ID without a foreign key
+ALTER TABLE httplog.log_errors DROP CONSTRAINT IF EXISTS log_errors_request_id_fkey;
+
+DROP INDEX IF EXISTS httplog.idx_log_outgoing_requests_trace_id;
+DROP INDEX IF EXISTS httplog.idx_log_outgoing_requests_created_at;
+DROP INDEX IF EXISTS httplog.idx_log_incoming_requests_trace_id;
+DROP INDEX IF EXISTS httplog.idx_log_incoming_requests_created_at;
+DROP INDEX IF EXISTS httplog.idx_log_errors_trace_id;
+DROP INDEX IF EXISTS httplog.idx_log_errors_created_at;
+
+ALTER TABLE httplog.log_outgoing_requests RENAME TO log_outgoing_requests_unpartitioned;
+ALTER TABLE httplog.log_incoming_requests RENAME TO log_incoming_requests_unpartitioned;
+ALTER TABLE httplog.log_errors RENAME TO log_errors_unpartitioned;
+
+CREATE TABLE httplog.log_outgoing_requests (
+    id INTEGER NOT NULL DEFAULT nextval('httplog.log_outgoing_requests_id_seq'),
+    trace_id TEXT NOT NULL,
+    event_name TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    method http_method NOT NULL,
+    request JSONB NOT NULL,
+    status_code INTEGER NOT NULL,
+    response JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_log_outgoing_requests PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE httplog.log_incoming_requests (
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    trace_id TEXT NOT NULL,
+    event_name TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    method http_method NOT NULL,
+    request JSONB NOT NULL,
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_log_incoming_requests PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE httplog.log_errors (
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    trace_id TEXT NOT NULL,
+    status_code INTEGER NOT NULL,
+    error TEXT NOT NULL,
+    request_id UUID,
+    traces JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_log_errors PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE httplog.log_outgoing_requests_default PARTITION OF httplog.log_outgoing_requests DEFAULT;
+CREATE TABLE httplog.log_incoming_requests_default PARTITION OF httplog.log_incoming_requests DEFAULT;
+CREATE TABLE httplog.log_errors_default PARTITION OF httplog.log_errors DEFAULT;
+
+CREATE INDEX idx_log_outgoing_requests_trace_id ON httplog.log_outgoing_requests (trace_id);
+CREATE INDEX idx_log_outgoing_requests_created_at ON httplog.log_outgoing_requests (created_at);
+CREATE INDEX idx_log_incoming_requests_trace_id ON httplog.log_incoming_requests (trace_id);
+CREATE INDEX idx_log_incoming_requests_created_at ON httplog.log_incoming_requests (created_at);
+CREATE INDEX idx_log_errors_trace_id ON httplog.log_errors (trace_id);
+CREATE INDEX idx_log_errors_created_at ON httplog.log_errors (created_at);
+CREATE INDEX idx_log_errors_request_id ON httplog.log_errors (request_id);
+
+INSERT INTO httplog.log_outgoing_requests (id, trace_id, event_name, endpoint, method, request, status_code, response, created_at, updated_at)
+SELECT id, trace_id, event_name, endpoint, method, request, status_code, response, created_at, updated_at
+FROM httplog.log_outgoing_requests_unpartitioned;
+
+INSERT INTO httplog.log_incoming_requests (id, trace_id, event_name, endpoint, method, request, ip_address, user_agent, created_at, updated_at)
+SELECT id, trace_id, event_name, endpoint, method, request, ip_address, user_agent, created_at, updated_at
+FROM httplog.log_incoming_requests_unpartitioned;
+
+INSERT INTO httplog.log_errors (id, trace_id, status_code, error, request_id, traces, created_at, updated_at)
+SELECT id, trace_id, status_code, error, request_id, traces, created_at, updated_at
+FROM httplog.log_errors_unpartitioned;
+
+-- Keep the ID sequence of outgoing requests when the old table is dropped
+ALTER SEQUENCE httplog.log_outgoing_requests_id_seq OWNED BY httplog.log_outgoing_requests.id;
+
+DROP TABLE httplog.log_errors_unpartitioned;
+DROP TABLE httplog.log_incoming_requests_unpartitioned;
+DROP TABLE httplog.log_outgoing_requests_unpartitioned;
+
+COMMENT ON TABLE httplog.log_outgoing_requests IS 'Stores logs for outgoing HTTP requests made by the application, partitioned by created_at';
+COMMENT ON TABLE httplog.log_incoming_requests IS 'Stores logs for incoming HTTP requests to the application, partitioned by created_at';
+COMMENT ON TABLE httplog.log_errors IS 'Stores error logs with references to the original requests, partitioned by created_at';
+COMMENT ON COLUMN httplog.log_outgoing_requests.trace_id IS 'Distributed tracing ID for correlating logs';
+COMMENT ON COLUMN httplog.log_incoming_requests.trace_id IS 'Distributed tracing ID for correlating logs';
+COMMENT ON COLUMN httplog.log_errors.trace_id IS 'Distributed tracing ID for correlating logs';
+COMMENT ON COLUMN httplog.log_errors.request_id IS 'Reference to the original request that caused the error';
+-- +goose StatementEnd
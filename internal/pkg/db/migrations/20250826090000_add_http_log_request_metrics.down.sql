@@ -0,0 +1,20 @@
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS httplog.idx_log_incoming_requests_x_request_id;
+DROP INDEX IF EXISTS httplog.idx_log_incoming_requests_user_id;
+DROP INDEX IF EXISTS httplog.idx_log_outgoing_requests_duration_ms;
+DROP INDEX IF EXISTS httplog.idx_log_outgoing_requests_incoming_request;
+
+ALTER TABLE httplog.log_outgoing_requests DROP CONSTRAINT IF EXISTS fk_log_outgoing_requests_incoming_request;
+
+ALTER TABLE httplog.log_outgoing_requests
+    DROP COLUMN IF EXISTS response_size,
+    DROP COLUMN IF EXISTS duration_ms,
+    DROP COLUMN IF EXISTS incoming_request_created_at,
+    DROP COLUMN IF EXISTS incoming_request_id;
+
+ALTER TABLE httplog.log_incoming_requests
+    DROP COLUMN IF EXISTS request_size,
+    DROP COLUMN IF EXISTS x_request_id,
+    DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE httplog.log_incoming_requests
+    ADD COLUMN user_id UUID,
+    ADD COLUMN x_request_id TEXT,
+    ADD COLUMN request_size BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE httplog.log_outgoing_requests
+    ADD COLUMN incoming_request_id UUID,
+    ADD COLUMN incoming_request_created_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN duration_ms INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN response_size BIGINT NOT NULL DEFAULT 0;
+
+-- Link the responses logged so far to their requests, which they were only
+-- matched with by trace ID, endpoint and method
+UPDATE httplog.log_outgoing_requests AS o
+SET incoming_request_id = r.id,
+    incoming_request_created_at = r.created_at
+FROM httplog.log_incoming_requests AS r
+WHERE o.trace_id = r.trace_id
+  AND o.endpoint = r.endpoint
+  AND o.method = r.method
+  AND o.created_at >= r.created_at;
+
+-- The incoming requests are partitioned by created_at, which therefore is part
+-- of the reference
+ALTER TABLE httplog.log_outgoing_requests
+    ADD CONSTRAINT fk_log_outgoing_requests_incoming_request
+    FOREIGN KEY (incoming_request_id, incoming_request_created_at)
+    REFERENCES httplog.log_incoming_requests (id, created_at)
+    ON DELETE SET NULL;
+
+CREATE INDEX idx_log_outgoing_requests_incoming_request ON httplog.log_outgoing_requests (incoming_request_created_at, incoming_request_id);
+CREATE INDEX idx_log_outgoing_requests_duration_ms ON httplog.log_outgoing_requests (duration_ms DESC, created_at DESC);
+CREATE INDEX idx_log_incoming_requests_user_id ON httplog.log_incoming_requests (user_id, created_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_log_incoming_requests_x_request_id ON httplog.log_incoming_requests (x_request_id) WHERE x_request_id IS NOT NULL;
+
+COMMENT ON COLUMN httplog.log_incoming_requests.user_id IS 'Authenticated user who made the request';
+COMMENT ON COLUMN httplog.log_incoming_requests.x_request_id IS 'X-Request-ID header of the request';
+COMMENT ON COLUMN httplog.log_incoming_requests.request_size IS 'Size of the request body in bytes';
+COMMENT ON COLUMN httplog.log_outgoing_requests.incoming_request_id IS 'Request this is the response to; NULL for calls to other services';
+COMMENT ON COLUMN httplog.log_outgoing_requests.duration_ms IS 'Time taken to handle the request or to get the response, in milliseconds';
+COMMENT ON COLUMN httplog.log_outgoing_requests.response_size IS 'Size of the response body in bytes';
+-- +goose StatementEnd
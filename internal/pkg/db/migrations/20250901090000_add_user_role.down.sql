@@ -0,0 +1,6 @@
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_users_role;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
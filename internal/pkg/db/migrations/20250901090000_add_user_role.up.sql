@@ -0,0 +1,8 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE users
+    ADD CONSTRAINT chk_users_role CHECK (role IN ('user', 'admin'));
+-- +goose StatementEnd
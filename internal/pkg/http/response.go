@@ -1,6 +1,10 @@
 package http
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+)
 
 // HTTP status code constants
 const (
@@ -16,6 +20,7 @@ type Response struct {
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Errors  interface{} `json:"errors,omitempty"`
+	TraceID string      `json:"trace_id,omitempty"` // Set on errors to correlate them with the logs
 }
 
 // SuccessResponse creates a success response
@@ -39,6 +44,7 @@ func ErrorResponse(c *gin.Context, code int, message string, errors interface{})
 		Code:    code,
 		Message: message,
 		Errors:  errors,
+		TraceID: telemetry.TraceID(c.Request.Context()),
 	})
 }
 
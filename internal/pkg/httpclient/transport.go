@@ -0,0 +1,298 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"base-code-go-gin-clean/internal/domain/httplog"
+	pkghttplog "base-code-go-gin-clean/internal/pkg/httplog"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+)
+
+const (
+	tracerName = "base-code-go-gin-clean/httpclient"
+
+	defaultMaxBodySize = 64 * 1024
+	defaultBaseBackoff = 200 * time.Millisecond
+	defaultMaxBackoff  = 5 * time.Second
+)
+
+// Config configures the instrumented transport
+type Config struct {
+	Service      httplog.Service          // Receives a log of every attempt; nothing is logged when nil
+	Redactor     *pkghttplog.Redactor     // Masks secrets and personal data; the default redaction when nil
+	Base         http.RoundTripper        // Performs the requests; http.DefaultTransport when nil
+	Timeout      time.Duration            // Timeout of one attempt; none when 0
+	HostTimeouts map[string]time.Duration // Timeouts of one attempt by host, e.g. "api.example.com" or "api.example.com:8443"
+	MaxRetries   int                      // Retries after the first attempt
+	BaseBackoff  time.Duration            // Wait before the first retry, doubled on every retry
+	MaxBackoff   time.Duration            // Longest wait before a retry, including Retry-After
+	MaxBodySize  int64                    // Bytes of request and response bodies kept in the logs
+}
+
+// Transport is an http.RoundTripper that logs requests to log_outgoing_requests, propagates
+// the trace ID and traceparent, retries failed idempotent requests and applies per-host timeouts
+type Transport struct {
+	service      httplog.Service
+	redactor     *pkghttplog.Redactor
+	base         http.RoundTripper
+	timeout      time.Duration
+	hostTimeouts map[string]time.Duration
+	maxRetries   int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	maxBodySize  int64
+	sleep        func(ctx context.Context, d time.Duration) error
+}
+
+// NewTransport creates a new instrumented transport
+func NewTransport(cfg Config) *Transport {
+	t := &Transport{
+		service:      cfg.Service,
+		redactor:     cfg.Redactor,
+		base:         cfg.Base,
+		timeout:      cfg.Timeout,
+		hostTimeouts: cfg.HostTimeouts,
+		maxRetries:   max(cfg.MaxRetries, 0),
+		baseBackoff:  cfg.BaseBackoff,
+		maxBackoff:   cfg.MaxBackoff,
+		maxBodySize:  cfg.MaxBodySize,
+		sleep:        sleep,
+	}
+	if t.redactor == nil {
+		redactor, err := pkghttplog.NewRedactor(pkghttplog.DefaultRedaction())
+		if err != nil {
+			panic(err)
+		}
+		t.redactor = redactor
+	}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if t.baseBackoff <= 0 {
+		t.baseBackoff = defaultBaseBackoff
+	}
+	if t.maxBackoff <= 0 {
+		t.maxBackoff = defaultMaxBackoff
+	}
+	if t.maxBodySize <= 0 {
+		t.maxBodySize = defaultMaxBodySize
+	}
+	return t
+}
+
+// New creates an http.Client using the instrumented transport
+func New(cfg Config) *http.Client {
+	return &http.Client{Transport: NewTransport(cfg)}
+}
+
+// eventNameKey is the context key of the event name of outbound requests
+type eventNameKey struct{}
+
+// WithEventName names the outbound requests made with ctx in the logs, e.g. "stripe.create_charge".
+// Requests are named after their host otherwise.
+func WithEventName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, eventNameKey{}, name)
+}
+
+// RoundTrip sends the request, retrying it while it fails and may be retried
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("httpclient: failed to read request body: %w", err)
+		}
+	}
+
+	// Requests made outside of a trace start one, so that the logs and the traceparent agree
+	ctx := telemetry.EnsureTraceID(req.Context())
+	req = req.WithContext(ctx)
+	traceID := telemetry.TraceID(ctx)
+	for attempt := 1; ; attempt++ {
+		resp, err := t.send(req, body, traceID, attempt)
+		if attempt > t.maxRetries || !retryable(req, resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			// Drain a little of the body so that the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes one attempt of the request. The attempt is logged and its span ended once
+// the response body is closed, or right away when the request fails.
+func (t *Transport) send(orig *http.Request, body []byte, traceID string, attempt int) (*http.Response, error) {
+	ctx, cancel := orig.Context(), context.CancelFunc(func() {})
+	if timeout := t.timeoutFor(orig.URL); timeout > 0 {
+		ctx, cancel = context.WithTimeout(orig.Context(), timeout)
+	}
+
+	endpoint := t.redactor.RedactURL(&url.URL{Scheme: orig.URL.Scheme, Host: orig.URL.Host, Path: orig.URL.Path})
+	ctx, span := otel.Tracer(tracerName).Start(ctx, orig.Method+" "+orig.URL.Host,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", orig.Method),
+			attribute.String("http.url", endpoint),
+			attribute.Int("http.resend_count", attempt-1),
+		),
+	)
+
+	req := orig.Clone(ctx)
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		req.ContentLength = int64(len(body))
+	}
+	if req.Header.Get(telemetry.TraceIDHeader) == "" {
+		req.Header.Set(telemetry.TraceIDHeader, traceID)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if req.Header.Get("traceparent") == "" {
+		// Without a configured propagator the W3C trace context is still sent
+		propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+
+	entry := &httplog.LogOutgoingRequest{
+		TraceID:   traceID,
+		EventName: eventName(orig),
+		Endpoint:  endpoint,
+		Method:    httplog.HTTPMethod(req.Method),
+		Request: map[string]interface{}{
+			"method":  req.Method,
+			"url":     t.redactor.RedactURL(req.URL),
+			"headers": t.redactor.RedactHeaders(req.Header),
+			"body":    t.logBody(req.Header.Get("Content-Type"), body),
+			"attempt": attempt,
+		},
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		cancel()
+		t.log(orig.Context(), entry, nil, nil, 0, time.Since(start), err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	elapsed := time.Since(start)
+	resp.Body = &loggedBody{
+		ReadCloser: resp.Body,
+		limit:      t.maxBodySize,
+		done: func(captured []byte, size int64) {
+			span.End()
+			cancel()
+			t.log(orig.Context(), entry, resp, captured, size, elapsed, nil)
+		},
+	}
+	return resp, nil
+}
+
+// log writes the log of one attempt
+func (t *Transport) log(ctx context.Context, entry *httplog.LogOutgoingRequest, resp *http.Response, body []byte, size int64, elapsed time.Duration, err error) {
+	if t.service == nil {
+		return
+	}
+
+	entry.DurationMs = elapsed.Milliseconds()
+	response := map[string]interface{}{}
+	if err != nil {
+		response["error"] = t.redactor.RedactBody("text/plain", []byte(err.Error()))
+	}
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+		entry.ResponseSize = size
+		response["status_code"] = resp.StatusCode
+		response["headers"] = t.redactor.RedactHeaders(resp.Header)
+		response["body"] = t.logBody(resp.Header.Get("Content-Type"), body)
+	}
+	entry.Response = response
+
+	if err := t.service.LogOutgoingRequest(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("httpclient: failed to log outgoing request: %v", err)
+	}
+}
+
+// logBody redacts the logged part of a body
+func (t *Transport) logBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	return t.redactor.RedactBody(contentType, body[:min(int64(len(body)), t.maxBodySize)])
+}
+
+// timeoutFor returns the timeout of one attempt on the host of u
+func (t *Transport) timeoutFor(u *url.URL) time.Duration {
+	if timeout, ok := t.hostTimeouts[u.Host]; ok {
+		return timeout
+	}
+	if timeout, ok := t.hostTimeouts[u.Hostname()]; ok {
+		return timeout
+	}
+	return t.timeout
+}
+
+// eventName returns the name of a request in the logs
+func eventName(req *http.Request) string {
+	if name, ok := req.Context().Value(eventNameKey{}).(string); ok && name != "" {
+		return name
+	}
+	return req.URL.Host
+}
+
+// loggedBody keeps the start of a response body and reports it with the number of
+// bytes read once the body is closed
+type loggedBody struct {
+	io.ReadCloser
+	limit    int64
+	captured bytes.Buffer
+	size     int64
+	once     sync.Once
+	done     func(captured []byte, size int64)
+}
+
+// Read reads from the body, keeping up to limit bytes
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if room := b.limit - int64(b.captured.Len()); room > 0 && n > 0 {
+		b.captured.Write(p[:min(int64(n), room)])
+	}
+	return n, err
+}
+
+// Close closes the body and reports it
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.captured.Bytes(), b.size) })
+	return err
+}
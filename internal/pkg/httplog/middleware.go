@@ -2,23 +2,25 @@ package httplog
 
 import (
 	"bytes"
-	"context"
-	"encoding/json"
 	"io"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
 )
 
-// contextKey is a custom type for context keys
-type contextKey string
+const (
+	// userIDKey is the gin context key of the user set by the auth middleware
+	userIDKey = "userID"
 
-// TraceIDKey is the key used to store the trace ID in the context
-const TraceIDKey contextKey = "traceID"
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
 
 // Config holds the configuration for the HTTP logger middleware
 type Config struct {
@@ -28,13 +30,20 @@ type Config struct {
 	SkipBodyMethods     map[string]bool
 	MaxBodySize         int64
 	IncludeResponseBody bool
+	Redactor            *Redactor // Masks secrets and personal data; the default redaction when nil
+
+	Policies            []Policy      // The first policy matching the route of a request applies; requests matching none are logged in full
+	AlwaysLogErrors     bool          // Log requests answered with a 5xx status or with gin errors whatever their sampling
+	SlowThreshold       time.Duration // Log requests taking longer whatever their sampling; 0 disables
+	MaxRequestBodySize  int64         // Bytes of request bodies logged; MaxBodySize when 0
+	MaxResponseBodySize int64         // Bytes of response bodies logged; MaxBodySize when 0
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig(service httplog.Service) Config {
 	return Config{
 		Service:     service,
-		SkipPaths:   []string{"/health", "/metrics"},
+		SkipPaths:   []string{"/health", "/metrics", "/api/*/health"},
 		SkipHeaders: []string{"Authorization", "Cookie"},
 		SkipBodyMethods: map[string]bool{
 			"GET":     true,
@@ -43,7 +52,35 @@ func DefaultConfig(service httplog.Service) Config {
 		},
 		MaxBodySize:         1024 * 1024,
 		IncludeResponseBody: true,
+		Redactor:            defaultRedactor(),
+		AlwaysLogErrors:     true,
+		SlowThreshold:       time.Second,
+	}
+}
+
+// requestBodyLimit returns the bytes of request bodies logged
+func (c Config) requestBodyLimit() int64 {
+	if c.MaxRequestBodySize > 0 {
+		return c.MaxRequestBodySize
+	}
+	return c.MaxBodySize
+}
+
+// responseBodyLimit returns the bytes of response bodies logged
+func (c Config) responseBodyLimit() int64 {
+	if c.MaxResponseBodySize > 0 {
+		return c.MaxResponseBodySize
 	}
+	return c.MaxBodySize
+}
+
+// defaultRedactor returns a Redactor of DefaultRedaction
+func defaultRedactor() *Redactor {
+	redactor, err := NewRedactor(DefaultRedaction())
+	if err != nil {
+		panic(err)
+	}
+	return redactor
 }
 
 // Middleware returns a new HTTP logger middleware
@@ -51,10 +88,11 @@ func Middleware(config Config) gin.HandlerFunc {
 	if config.Service == nil {
 		panic("httplog: Service is required")
 	}
-
-	skipPaths := make(map[string]struct{}, len(config.SkipPaths))
-	for _, path := range config.SkipPaths {
-		skipPaths[path] = struct{}{}
+	if config.Redactor == nil {
+		config.Redactor = defaultRedactor()
+	}
+	if err := compilePolicies(config); err != nil {
+		panic(err)
 	}
 
 	skipHeaders := make(map[string]struct{}, len(config.SkipHeaders))
@@ -63,138 +101,147 @@ func Middleware(config Config) gin.HandlerFunc {
 	}
 
 	return func(c *gin.Context) {
-		if _, ok := skipPaths[c.Request.URL.Path]; ok {
+		if skipped(config, c) {
 			c.Next()
 			return
 		}
 
-		traceID := c.GetHeader("X-Trace-ID")
-		if traceID == "" {
-			traceID = uuid.New().String()
+		// Logs are correlated by the trace ID of the request, which the trace
+		// context middleware has set unless the logger runs without it
+		if telemetry.TraceID(c.Request.Context()) == "" {
+			c.Request = c.Request.WithContext(telemetry.EnsureTraceID(c.Request.Context()))
+			c.Header(telemetry.TraceIDHeader, telemetry.TraceID(c.Request.Context()))
 		}
-		ctx := context.WithValue(c.Request.Context(), TraceIDKey, traceID)
-		c.Request = c.Request.WithContext(ctx)
-		c.Writer.Header().Set("X-Trace-ID", traceID)
+		traceID := telemetry.TraceID(c.Request.Context())
 
-		reqLog, err := logIncomingRequest(c, config, traceID)
-		if err != nil {
-			c.Next()
-			return
-		}
+		// Requests out of the sample are still handled as if logged, so that failed
+		// and slow ones can be logged in full once they turn out to be
+		policy := policyFor(config, c)
+		sampled := policy.sampled(traceID)
+
+		start := time.Now()
+		red := config.Redactor.forRequest(c)
+		reqLog := newIncomingRequest(c, config, policy, red, traceID, start)
+		c.Set(requestLogIDKey, reqLog.ID)
 
 		var blw *bodyLogWriter
-		if !config.SkipBodyMethods[c.Request.Method] && config.IncludeResponseBody {
-			blw = &bodyLogWriter{body: bytes.NewBufferString(""), ResponseWriter: c.Writer}
+		if !config.SkipBodyMethods[c.Request.Method] && config.IncludeResponseBody && !policy.SkipBodies {
+			blw = &bodyLogWriter{body: bytes.NewBufferString(""), limit: config.responseBodyLimit(), ResponseWriter: c.Writer}
 			c.Writer = blw
 		}
 
+		// The request is logged once it is handled, when the authenticated user and
+		// the duration are known; the response and errors reference it
 		defer func() {
-			logResponse(c, blw, reqLog, config, traceID)
+			if !sampled && !mustLog(config, c, time.Since(start)) {
+				return
+			}
+			if !logIncomingRequest(c, reqLog, config) {
+				return
+			}
+			logResponse(c, blw, reqLog, config, policy, red, start)
+			logErrors(c, reqLog, config, red)
 		}()
 
 		c.Next()
-
-		if len(c.Errors) > 0 {
-			errMsg := ""
-			for _, e := range c.Errors {
-				errMsg += e.Error() + "; "
-			}
-
-			logErr := &httplog.LogError{
-				TraceID:    traceID,
-				RequestID:  &reqLog.ID,
-				StatusCode: c.Writer.Status(),
-				Error:      strings.TrimSuffix(errMsg, "; "),
-			}
-			if err := config.Service.LogError(c.Request.Context(), logErr); err != nil {
-				log.Printf("httplog: failed to log error: %v", err)
-			}
-		}
 	}
 }
 
-// logIncomingRequest logs the incoming HTTP request
-func logIncomingRequest(c *gin.Context, config Config, traceID string) (*httplog.LogIncomingRequest, error) {
+// newIncomingRequest builds the redacted log of the incoming HTTP request. Its ID is
+// assigned up front so that logs written while the request is handled can refer to it.
+func newIncomingRequest(c *gin.Context, config Config, policy Policy, red *redaction, traceID string, start time.Time) *httplog.LogIncomingRequest {
 	var requestBody interface{} = nil
-	if !config.SkipBodyMethods[c.Request.Method] && c.Request.Body != nil {
-		bodyBytes, _ := io.ReadAll(io.LimitReader(c.Request.Body, config.MaxBodySize))
-		c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
-
-		if strings.Contains(c.ContentType(), "application/json") {
-			var jsonBody interface{}
-			if err := json.Unmarshal(bodyBytes, &jsonBody); err == nil {
-				requestBody = jsonBody
-			} else {
-				requestBody = string(bodyBytes)
-			}
-		} else {
-			requestBody = string(bodyBytes)
-		}
+	truncated := false
+	size := max(c.Request.ContentLength, 0)
+	if !config.SkipBodyMethods[c.Request.Method] && c.Request.Body != nil && policy.logsBody(c.ContentType()) {
+		limit := config.requestBodyLimit()
+		bodyBytes, _ := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		// The handler still reads the whole body, beyond the part that is logged
+		c.Request.Body = replayedBody{Reader: io.MultiReader(bytes.NewReader(bodyBytes), c.Request.Body), Closer: c.Request.Body}
+		size = max(size, int64(len(bodyBytes)))
+		truncated = int64(len(bodyBytes)) > limit
+		requestBody = red.decode(c.ContentType(), bodyBytes[:min(int64(len(bodyBytes)), limit)])
 	}
 
 	headers := make(map[string]string)
 	for name, values := range c.Request.Header {
 		if !contains(config.SkipHeaders, strings.ToLower(name)) {
-			headers[name] = strings.Join(values, ", ")
+			headers[name] = red.header(name, strings.Join(values, ", "))
 		}
 	}
 
-	logEntry := &httplog.LogIncomingRequest{
-		TraceID:   traceID,
-		EventName: c.FullPath(),
-		Endpoint:  c.Request.URL.Path,
-		Method:    httplog.HTTPMethod(c.Request.Method),
-		Request: map[string]interface{}{
-			"method":  c.Request.Method,
-			"url":     c.Request.URL.String(),
-			"headers": headers,
-			"body":    requestBody,
-		},
-		IPAddress: c.ClientIP(),
-		UserAgent: c.Request.UserAgent(),
+	request := map[string]interface{}{
+		"method":  c.Request.Method,
+		"url":     red.url(c.Request.URL, c.Params),
+		"headers": headers,
+		"body":    requestBody,
+	}
+	if truncated {
+		request["body_truncated"] = true
 	}
 
-	logID, err := config.Service.LogIncomingRequest(c.Request.Context(), logEntry)
-	if err != nil {
-		return nil, err
+	return &httplog.LogIncomingRequest{
+		ID:          uuid.New().String(),
+		TraceID:     traceID,
+		EventName:   c.FullPath(),
+		Endpoint:    red.text(red.endpoint(c.Request.URL.Path, c.Params)),
+		Method:      httplog.HTTPMethod(c.Request.Method),
+		Request:     request,
+		IPAddress:   c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+		XRequestID:  requestID(c),
+		RequestSize: size,
+		CreatedAt:   start,
 	}
+}
 
-	logEntry.ID = logID
-	return logEntry, nil
+// logIncomingRequest logs the incoming HTTP request with the user authenticated while
+// handling it, and reports whether it was logged
+func logIncomingRequest(c *gin.Context, reqLog *httplog.LogIncomingRequest, config Config) bool {
+	if userID, err := uuid.Parse(c.GetString(userIDKey)); err == nil {
+		id := userID.String()
+		reqLog.UserID = &id
+	}
+
+	if _, err := config.Service.LogIncomingRequest(c.Request.Context(), reqLog); err != nil {
+		log.Printf("httplog: failed to log incoming request: %v", err)
+		return false
+	}
+	return true
 }
 
-// logResponse logs the HTTP response
-func logResponse(c *gin.Context, blw *bodyLogWriter, reqLog *httplog.LogIncomingRequest, config Config, traceID string) {
+// logResponse logs the redacted HTTP response
+func logResponse(c *gin.Context, blw *bodyLogWriter, reqLog *httplog.LogIncomingRequest, config Config, policy Policy, red *redaction, start time.Time) {
 	var responseBody interface{} = nil
+	response := map[string]interface{}{
+		"status_code": c.Writer.Status(),
+		"headers":     red.headers(c.Writer.Header()),
+	}
 
-	if config.IncludeResponseBody && blw != nil && blw.body != nil {
+	contentType := c.Writer.Header().Get("Content-Type")
+	if config.IncludeResponseBody && blw != nil && blw.body != nil && policy.logsBody(contentType) {
 		bodyBytes := blw.body.Bytes()
 		if len(bodyBytes) > 0 {
-			if strings.Contains(c.Writer.Header().Get("Content-Type"), "application/json") {
-				var jsonBody interface{}
-				if err := json.Unmarshal(bodyBytes, &jsonBody); err == nil {
-					responseBody = jsonBody
-				} else {
-					responseBody = string(bodyBytes)
-				}
-			} else {
-				responseBody = string(bodyBytes)
-			}
+			responseBody = red.decode(contentType, bodyBytes)
+		}
+		if blw.truncated {
+			response["body_truncated"] = true
 		}
 	}
+	response["body"] = responseBody
 
 	logEntry := &httplog.LogOutgoingRequest{
-		TraceID:   traceID,
-		EventName: reqLog.EventName,
-		Endpoint:  reqLog.Endpoint,
-		Method:    httplog.HTTPMethod(c.Request.Method),
-		Request:   reqLog.Request,
-		Response: map[string]interface{}{
-			"status_code": c.Writer.Status(),
-			"headers":     c.Writer.Header(),
-			"body":        responseBody,
-		},
-		StatusCode: c.Writer.Status(),
+		TraceID:                  reqLog.TraceID,
+		EventName:                reqLog.EventName,
+		Endpoint:                 reqLog.Endpoint,
+		Method:                   httplog.HTTPMethod(c.Request.Method),
+		Request:                  reqLog.Request,
+		Response:                 response,
+		StatusCode:               c.Writer.Status(),
+		IncomingRequestID:        &reqLog.ID,
+		IncomingRequestCreatedAt: &reqLog.CreatedAt,
+		DurationMs:               time.Since(start).Milliseconds(),
+		ResponseSize:             int64(max(c.Writer.Size(), 0)),
 	}
 
 	if err := config.Service.LogOutgoingRequest(c.Request.Context(), logEntry); err != nil {
@@ -202,18 +249,62 @@ func logResponse(c *gin.Context, blw *bodyLogWriter, reqLog *httplog.LogIncoming
 	}
 }
 
-// bodyLogWriter is a custom ResponseWriter that captures the response body
+// logErrors logs the errors attached to the gin context while handling the request
+func logErrors(c *gin.Context, reqLog *httplog.LogIncomingRequest, config Config, red *redaction) {
+	if len(c.Errors) == 0 {
+		return
+	}
+
+	errMsg := ""
+	for _, e := range c.Errors {
+		errMsg += e.Error() + "; "
+	}
+
+	logErr := &httplog.LogError{
+		TraceID:    reqLog.TraceID,
+		RequestID:  &reqLog.ID,
+		StatusCode: c.Writer.Status(),
+		Error:      red.text(strings.TrimSuffix(errMsg, "; ")),
+	}
+	if err := config.Service.LogError(c.Request.Context(), logErr); err != nil {
+		log.Printf("httplog: failed to log error: %v", err)
+	}
+}
+
+// requestID returns the X-Request-ID of the request, as set by middleware.RequestID
+func requestID(c *gin.Context) string {
+	id := c.Writer.Header().Get(requestIDHeader)
+	if id == "" {
+		id = c.GetHeader(requestIDHeader)
+	}
+	if len(id) > maxRequestIDLength {
+		id = id[:maxRequestIDLength]
+	}
+	return id
+}
+
+// bodyLogWriter is a custom ResponseWriter that captures the start of the response body
 type bodyLogWriter struct {
 	gin.ResponseWriter
-	body *bytes.Buffer
+	body      *bytes.Buffer
+	limit     int64
+	truncated bool
 }
 
-// Write captures the response body
+// Write captures the response body up to the limit
 func (w *bodyLogWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
+	room := max(w.limit-int64(w.body.Len()), 0)
+	w.body.Write(b[:min(int64(len(b)), room)])
+	w.truncated = w.truncated || int64(len(b)) > room
 	return w.ResponseWriter.Write(b)
 }
 
+// replayedBody is a request body whose start was read for the logs
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
+
 // contains checks if a string is present in a slice
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,103 @@
+package httplog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Policy sets how the requests of the routes it matches are logged
+type Policy struct {
+	Routes       []string // Route patterns matched against the gin route and the URL path, e.g. "/api/v1/search/*"; none match every route
+	SampleRate   float64  // Share of the requests logged, from 0 to 1; every request when 0, use SkipPaths to log none
+	SkipBodies   bool     // Leave request and response bodies out of the logs
+	ContentTypes []string // Media type patterns of the bodies logged, e.g. "application/json" or "text/*"; all when empty
+}
+
+// compilePolicies checks the patterns of the skip paths and policies
+func compilePolicies(config Config) error {
+	for _, pattern := range config.SkipPaths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("httplog: invalid skip path %q: %w", pattern, err)
+		}
+	}
+	for _, policy := range config.Policies {
+		if policy.SampleRate < 0 || policy.SampleRate > 1 {
+			return fmt.Errorf("httplog: sample rate %v of %v is not between 0 and 1", policy.SampleRate, policy.Routes)
+		}
+		for _, pattern := range append(policy.Routes[:len(policy.Routes):len(policy.Routes)], policy.ContentTypes...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("httplog: invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// skipped reports whether the requests of a route are never logged. Skip paths are
+// patterns like policy routes, so "/api/*/health" skips "/api/v1/health".
+func skipped(config Config, c *gin.Context) bool {
+	return len(config.SkipPaths) > 0 && matchesRoute(config.SkipPaths, c.FullPath(), c.Request.URL.Path)
+}
+
+// policyFor returns the first policy matching the route of c, or a policy logging
+// every request in full
+func policyFor(config Config, c *gin.Context) Policy {
+	for _, policy := range config.Policies {
+		if matchesRoute(policy.Routes, c.FullPath(), c.Request.URL.Path) {
+			return policy
+		}
+	}
+	return Policy{}
+}
+
+// sampled reports whether a request of the trace is in the sample. The decision is
+// derived from the trace ID, so that the logs of a trace are kept or left out together.
+func (p Policy) sampled(traceID string) bool {
+	if p.SampleRate <= 0 || p.SampleRate >= 1 {
+		return true
+	}
+	id, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return true
+	}
+	return float64(binary.BigEndian.Uint64(id[8:])>>11)/(1<<53) < p.SampleRate
+}
+
+// logsBody reports whether bodies of the content type are logged
+func (p Policy) logsBody(contentType string) bool {
+	if p.SkipBodies {
+		return false
+	}
+	if len(p.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+	for _, pattern := range p.ContentTypes {
+		if ok, _ := path.Match(strings.ToLower(pattern), mediaType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mustLog reports whether a request is logged whatever its sampling: when it panicked,
+// failed or was slow, as configured
+func mustLog(config Config, c *gin.Context, elapsed time.Duration) bool {
+	if c.GetBool(recoveredKey) {
+		return true
+	}
+	if config.AlwaysLogErrors && (c.Writer.Status() >= 500 || len(c.Errors) > 0) {
+		return true
+	}
+	return config.SlowThreshold > 0 && elapsed >= config.SlowThreshold
+}
@@ -0,0 +1,135 @@
+package httplog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	"base-code-go-gin-clean/test"
+)
+
+func TestPolicy_Sampled(t *testing.T) {
+	policy := Policy{SampleRate: 0.25}
+
+	var kept int
+	for i := 0; i < 4000; i++ {
+		traceID := telemetry.NewTraceID().String()
+		if policy.sampled(traceID) {
+			kept++
+		}
+		assert.Equal(t, policy.sampled(traceID), policy.sampled(traceID), "the decision is the same for a trace")
+	}
+	assert.InDelta(t, 1000, kept, 150)
+
+	assert.True(t, Policy{}.sampled(telemetry.NewTraceID().String()), "every request without a rate")
+	assert.True(t, policy.sampled("not-a-trace-id"))
+}
+
+func TestPolicy_LogsBody(t *testing.T) {
+	policy := Policy{ContentTypes: []string{"application/json", "text/*"}}
+
+	assert.True(t, policy.logsBody("application/json; charset=utf-8"))
+	assert.True(t, policy.logsBody("text/plain"))
+	assert.False(t, policy.logsBody("image/png"))
+	assert.False(t, policy.logsBody(""))
+	assert.True(t, Policy{}.logsBody("image/png"))
+	assert.False(t, Policy{SkipBodies: true}.logsBody("application/json"))
+}
+
+func TestMiddleware_Policies(t *testing.T) {
+	repo := &recordingRepository{}
+	config := DefaultConfig(httplog.NewService(repo))
+	config.SlowThreshold = 50 * time.Millisecond
+	config.MaxRequestBodySize = 8
+	config.MaxResponseBodySize = 4
+	config.Policies = []Policy{
+		{Routes: []string{"/api/v1/search"}, SampleRate: 0.000001},
+		{Routes: []string{"/api/v1/files/*"}, ContentTypes: []string{"application/json"}},
+	}
+
+	router := test.SetupTestRouter()
+	router.Use(telemetry.TraceContext())
+	router.Use(Middleware(config))
+	router.GET("/api/v1/health", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.GET("/api/v1/search", func(c *gin.Context) {
+		switch c.Query("q") {
+		case "fail":
+			c.String(http.StatusServiceUnavailable, "unavailable")
+		case "slow":
+			time.Sleep(60 * time.Millisecond)
+			c.String(http.StatusOK, "slow")
+		default:
+			c.String(http.StatusOK, "found")
+		}
+	})
+	var received string
+	router.POST("/api/v1/files/:name", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		received = string(body)
+		c.Data(http.StatusOK, c.ContentType(), body)
+	})
+
+	serve := func(method, target, contentType, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("skip paths are patterns", func(t *testing.T) {
+		repo.logs = nil
+		serve("GET", "/api/v1/health", "", "")
+		assert.Empty(t, repo.logs)
+	})
+
+	t.Run("requests out of the sample are left out unless failed or slow", func(t *testing.T) {
+		repo.logs = nil
+		serve("GET", "/api/v1/search?q=books", "", "")
+		assert.Empty(t, repo.logs)
+
+		serve("GET", "/api/v1/search?q=fail", "", "")
+		serve("GET", "/api/v1/search?q=slow", "", "")
+		if assert.Len(t, repo.logs, 4) {
+			assert.Equal(t, http.StatusServiceUnavailable, repo.logs[1].(*httplog.LogOutgoingRequest).StatusCode)
+			assert.GreaterOrEqual(t, repo.logs[3].(*httplog.LogOutgoingRequest).DurationMs, int64(50))
+		}
+	})
+
+	t.Run("bodies are logged by content type up to the limit of their direction", func(t *testing.T) {
+		repo.logs = nil
+		serve("POST", "/api/v1/files/a.png", "image/png", "\x89PNG-binary")
+		serve("POST", "/api/v1/files/a.json", "application/json", `"0123456789"`)
+
+		assert.Equal(t, `"0123456789"`, received, "the handler reads the whole body")
+		if !assert.Len(t, repo.logs, 4) {
+			return
+		}
+		png := repo.logs[0].(*httplog.LogIncomingRequest).Request.(map[string]interface{})
+		assert.Nil(t, png["body"])
+		assert.Nil(t, repo.logs[1].(*httplog.LogOutgoingRequest).Response.(map[string]interface{})["body"])
+
+		request := repo.logs[2].(*httplog.LogIncomingRequest).Request.(map[string]interface{})
+		assert.Equal(t, `"0123456`, request["body"])
+		assert.Equal(t, true, request["body_truncated"])
+		response := repo.logs[3].(*httplog.LogOutgoingRequest).Response.(map[string]interface{})
+		assert.Equal(t, `"012`, response["body"])
+		assert.Equal(t, true, response["body_truncated"])
+	})
+}
+
+func TestMiddleware_InvalidPolicy(t *testing.T) {
+	config := DefaultConfig(httplog.NewService(&recordingRepository{}))
+	config.Policies = []Policy{{Routes: []string{"/api/v1/search"}, SampleRate: 1.5}}
+
+	assert.Panics(t, func() { Middleware(config) })
+}
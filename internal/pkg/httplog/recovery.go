@@ -0,0 +1,178 @@
+package httplog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"base-code-go-gin-clean/internal/domain/httplog"
+	httpPkg "base-code-go-gin-clean/internal/pkg/http"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+)
+
+const (
+	// requestLogIDKey is the gin context key of the ID of the incoming request log
+	requestLogIDKey = "httplog.requestID"
+	// recoveredKey is the gin context key set once a panic of the request is recovered
+	recoveredKey = "httplog.recovered"
+
+	maxStackFrames = 64
+)
+
+// StackFrame is a frame of the stack trace of a panic
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// PanicTrace is what log_errors.traces holds for a recovered panic
+type PanicTrace struct {
+	Panic      string       `json:"panic"`
+	Stack      []StackFrame `json:"stack"`
+	Method     string       `json:"method"`
+	Route      string       `json:"route,omitempty"`
+	Endpoint   string       `json:"endpoint"`
+	UserID     string       `json:"user_id,omitempty"`
+	XRequestID string       `json:"x_request_id,omitempty"`
+}
+
+// Recovery returns a middleware that recovers from panics. The panic is written to
+// log_errors with its stack trace and the request, recorded on the active span, and
+// answered with the standard 500 envelope carrying the trace ID.
+//
+// Registered after Middleware, the error references the incoming request log and the
+// request is logged with its 500 response. Panics are reported once: a recovered panic
+// is not added to the errors of the gin context, which Middleware logs too. Without a
+// service (no database), panics are only written to the standard logger. Panics with
+// http.ErrAbortHandler are passed on to the server, which aborts the response.
+func Recovery(service httplog.Service, redactor *Redactor) gin.HandlerFunc {
+	if redactor == nil {
+		redactor = defaultRedactor()
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// http.ErrAbortHandler aborts the response on purpose; the server closes the
+			// connection without logging it
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			stack := stackTrace()
+			c.Set(recoveredKey, true)
+			red := redactor.forRequest(c)
+			message := red.text(fmt.Sprint(recovered))
+			panicErr := errors.New("panic: " + message)
+
+			span := trace.SpanFromContext(c.Request.Context())
+			span.RecordError(panicErr)
+			span.SetStatus(codes.Error, "panic")
+
+			details := PanicTrace{
+				Panic:      message,
+				Stack:      stack,
+				Method:     c.Request.Method,
+				Route:      c.FullPath(),
+				Endpoint:   red.text(red.endpoint(c.Request.URL.Path, c.Params)),
+				UserID:     c.GetString(userIDKey),
+				XRequestID: requestID(c),
+			}
+			logPanic(c, service, panicErr, details)
+
+			// A client that went away cannot be answered
+			if brokenConnection(recovered) {
+				c.Abort()
+				return
+			}
+			if c.Writer.Written() {
+				c.AbortWithStatus(c.Writer.Status())
+				return
+			}
+			httpPkg.InternalServerError(c, "Internal server error")
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
+
+// logPanic writes a recovered panic to log_errors, or to the standard logger with its
+// stack when it cannot be
+func logPanic(c *gin.Context, service httplog.Service, panicErr error, details PanicTrace) {
+	traceID := telemetry.TraceID(c.Request.Context())
+	if service != nil {
+		var err error
+		if requestID := c.GetString(requestLogIDKey); requestID != "" {
+			err = service.LogErrorWithRequest(c.Request.Context(), requestID, http.StatusInternalServerError, panicErr, details)
+		} else {
+			err = service.LogError(c.Request.Context(), &httplog.LogError{
+				TraceID:    traceID,
+				StatusCode: http.StatusInternalServerError,
+				Error:      panicErr.Error(),
+				Traces:     details,
+			})
+		}
+		if err == nil {
+			return
+		}
+		log.Printf("httplog: failed to log panic: %v", err)
+	}
+
+	var frames strings.Builder
+	for _, frame := range details.Stack {
+		fmt.Fprintf(&frames, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+	log.Printf("httplog: recovered %s in %s %s (trace %s)%s", panicErr, details.Method, details.Endpoint, traceID, frames.String())
+}
+
+// stackTrace returns the stack of the panicking goroutine from the frame that panicked,
+// called from a deferred function
+func stackTrace() []StackFrame {
+	pcs := make([]uintptr, maxStackFrames+16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []StackFrame
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			// Frames up to here are the recovery's own
+			stack = stack[:0]
+		} else {
+			stack = append(stack, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack[:min(len(stack), maxStackFrames)]
+}
+
+// brokenConnection reports whether a panic comes from writing to a closed connection
+func brokenConnection(recovered interface{}) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	var syscallErr *os.SyscallError
+	if errors.As(err, &opErr) && errors.As(opErr.Err, &syscallErr) {
+		return errors.Is(syscallErr.Err, syscall.EPIPE) || errors.Is(syscallErr.Err, syscall.ECONNRESET)
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package httplog
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	"base-code-go-gin-clean/test"
+)
+
+func TestRecovery_LogsPanicsOnce(t *testing.T) {
+	repo := &recordingRepository{}
+	service := httplog.NewService(repo)
+	router := test.SetupTestRouter()
+	router.Use(telemetry.TraceContext())
+	router.Use(Middleware(DefaultConfig(service)))
+	router.Use(Recovery(service, nil))
+	router.GET("/api/v1/users/:id", func(c *gin.Context) {
+		c.Set("userID", "0b6f5f3e-8c8a-4c8e-9d4b-2a4a0c7c5e11")
+		panic("no profile for jane@example.com")
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.NotContains(t, resp.Body.String(), "jane@example.com")
+
+	if !assert.Len(t, repo.logs, 3, "the request, its response and one error") {
+		return
+	}
+	logErr, ok := repo.logs[0].(*httplog.LogError)
+	if !assert.True(t, ok, "the panic is logged while recovering") {
+		return
+	}
+	incoming := repo.logs[1].(*httplog.LogIncomingRequest)
+	response := repo.logs[2].(*httplog.LogOutgoingRequest)
+
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", logErr.TraceID)
+	if assert.NotNil(t, logErr.RequestID) {
+		assert.Equal(t, incoming.ID, *logErr.RequestID)
+	}
+	assert.True(t, strings.HasPrefix(logErr.Error, "panic: no profile for"), logErr.Error)
+	assert.NotContains(t, repo.stored(t), "jane@example.com")
+
+	details := logErr.Traces.(PanicTrace)
+	assert.Equal(t, "/api/v1/users/:id", details.Route)
+	assert.Equal(t, "0b6f5f3e-8c8a-4c8e-9d4b-2a4a0c7c5e11", details.UserID)
+	if assert.NotEmpty(t, details.Stack) {
+		assert.Contains(t, details.Stack[0].Function, "TestRecovery_LogsPanicsOnce", "the stack starts where the panic happened")
+	}
+}
+
+func TestRecovery_WithoutRequestLog(t *testing.T) {
+	repo := &recordingRepository{}
+	router := test.SetupTestRouter()
+	router.Use(Recovery(httplog.NewService(repo), nil))
+	router.GET("/api/v1/orders", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "partial")
+		panic("boom")
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/orders", nil))
+
+	assert.Equal(t, http.StatusAccepted, resp.Code, "a started response is left as is")
+	if assert.Len(t, repo.logs, 1) {
+		logErr := repo.logs[0].(*httplog.LogError)
+		assert.Nil(t, logErr.RequestID)
+		assert.Equal(t, "panic: boom", logErr.Error)
+	}
+}
+
+func TestRecovery_PassesOnAbortedHandlers(t *testing.T) {
+	repo := &recordingRepository{}
+	router := test.SetupTestRouter()
+	router.Use(Recovery(httplog.NewService(repo), nil))
+	router.GET("/api/v1/stream", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	resp := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(resp, httptest.NewRequest("GET", "/api/v1/stream", nil))
+	}, "the server aborts the response")
+
+	assert.Empty(t, repo.logs, "an aborted response is not an error")
+	assert.Zero(t, resp.Body.Len(), "no error envelope is written")
+}
+
+func TestRecovery_StandardLoggerFallback(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	serve := func(service httplog.Service) {
+		router := test.SetupTestRouter()
+		router.Use(Recovery(service, nil))
+		router.GET("/api/v1/orders", func(c *gin.Context) {
+			panic("boom")
+		})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/orders", nil))
+	}
+
+	serve(httplog.NewService(&recordingRepository{}))
+	assert.Empty(t, output.String(), "a panic written to log_errors is not logged again")
+
+	serve(nil)
+	assert.Contains(t, output.String(), "httplog: recovered panic: boom in GET /api/v1/orders")
+	assert.Contains(t, output.String(), "TestRecovery_StandardLoggerFallback", "the fallback carries the stack")
+}
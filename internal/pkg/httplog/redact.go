@@ -0,0 +1,501 @@
+package httplog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMask replaces redacted values
+const DefaultMask = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	cardPattern  = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+)
+
+// RedactionRule adds redactions to the logs of the routes it matches
+type RedactionRule struct {
+	Routes   []string // Route patterns matched against the gin route and the URL path, e.g. "/api/v1/auth/*"
+	Paths    []string // JSONPath expressions of body fields, e.g. "$.user.password" or "$.cards[*].number"
+	Keys     []string // Key-name patterns redacted anywhere in the body, e.g. "*_pin"
+	DropBody bool     // Replace the whole request and response body
+}
+
+// RedactionConfig configures a Redactor
+type RedactionConfig struct {
+	Keys       []string         // Key-name patterns redacted on every route in bodies, forms, query strings and path parameters
+	Headers    []string         // Request and response headers whose values are redacted
+	Patterns   []*regexp.Regexp // Matches are masked in every logged string
+	MaskEmails bool             // Mask email addresses in every logged string
+	MaskCards  bool             // Mask card numbers that pass the Luhn check in every logged string
+	Rules      []RedactionRule
+	Mask       string // Replacement of redacted values; DefaultMask when empty
+}
+
+// DefaultRedaction returns the redaction of credentials, email addresses and card numbers
+func DefaultRedaction() RedactionConfig {
+	return RedactionConfig{
+		Keys: []string{
+			"*password*", "*passwd*", "*secret*", "*token*", "*api_key*", "*apikey*",
+			"authorization", "cookie", "card_number", "cvv", "cvc", "ssn",
+		},
+		Headers:    []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		MaskEmails: true,
+		MaskCards:  true,
+	}
+}
+
+// Redactor masks secrets and personal data in HTTP logs before they are stored
+type Redactor struct {
+	keys             []string
+	sensitiveHeaders map[string]struct{}
+	patterns         []*regexp.Regexp
+	maskEmails       bool
+	maskCards        bool
+	rules            []compiledRule
+	mask             string
+}
+
+type compiledRule struct {
+	routes   []string
+	paths    [][]pathSegment
+	keys     []string
+	dropBody bool
+}
+
+// NewRedactor compiles the patterns and JSONPath expressions of cfg
+func NewRedactor(cfg RedactionConfig) (*Redactor, error) {
+	r := &Redactor{
+		sensitiveHeaders: make(map[string]struct{}, len(cfg.Headers)),
+		patterns:         cfg.Patterns,
+		maskEmails:       cfg.MaskEmails,
+		maskCards:        cfg.MaskCards,
+		mask:             cfg.Mask,
+	}
+	if r.mask == "" {
+		r.mask = DefaultMask
+	}
+
+	keys, err := compileKeys(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	r.keys = keys
+
+	for _, header := range cfg.Headers {
+		r.sensitiveHeaders[strings.ToLower(header)] = struct{}{}
+	}
+
+	for _, rule := range cfg.Rules {
+		compiled := compiledRule{routes: rule.Routes, dropBody: rule.DropBody}
+		for _, route := range rule.Routes {
+			if _, err := path.Match(route, ""); err != nil {
+				return nil, fmt.Errorf("httplog: invalid route pattern %q: %w", route, err)
+			}
+		}
+		if compiled.keys, err = compileKeys(rule.Keys); err != nil {
+			return nil, err
+		}
+		for _, expr := range rule.Paths {
+			segments, err := parsePath(expr)
+			if err != nil {
+				return nil, err
+			}
+			compiled.paths = append(compiled.paths, segments)
+		}
+		r.rules = append(r.rules, compiled)
+	}
+
+	return r, nil
+}
+
+// compileKeys lowercases key-name patterns and checks their syntax
+func compileKeys(patterns []string) ([]string, error) {
+	keys := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("httplog: invalid key pattern %q: %w", pattern, err)
+		}
+		keys = append(keys, pattern)
+	}
+	return keys, nil
+}
+
+// RedactURL redacts an absolute URL for the logs of outbound requests. User
+// credentials are left out and the query string is redacted.
+func (r *Redactor) RedactURL(u *url.URL) string {
+	redacted := r.forRoute("", "").url(u, nil)
+	if u.Host == "" {
+		return redacted
+	}
+	return u.Scheme + "://" + u.Host + redacted
+}
+
+// RedactHeaders redacts the headers of an outbound request or its response into a new map
+func (r *Redactor) RedactHeaders(header http.Header) map[string][]string {
+	return r.forRoute("", "").headers(header)
+}
+
+// RedactBody decodes the body of an outbound request or its response and redacts it
+func (r *Redactor) RedactBody(contentType string, body []byte) interface{} {
+	return r.forRoute("", "").decode(contentType, body)
+}
+
+// forRequest returns the redaction of the route of c
+func (r *Redactor) forRequest(c *gin.Context) *redaction {
+	return r.forRoute(c.FullPath(), c.Request.URL.Path)
+}
+
+// forRoute merges the rules matching the gin route or the URL path with the global redaction
+func (r *Redactor) forRoute(route, urlPath string) *redaction {
+	red := &redaction{Redactor: r, keys: r.keys}
+	for _, rule := range r.rules {
+		if !matchesRoute(rule.routes, route, urlPath) {
+			continue
+		}
+		red.keys = append(red.keys[:len(red.keys):len(red.keys)], rule.keys...)
+		red.paths = append(red.paths, rule.paths...)
+		red.dropBody = red.dropBody || rule.dropBody
+	}
+	return red
+}
+
+// matchesRoute reports whether any pattern matches the route or the URL path; no patterns match every route
+func matchesRoute(patterns []string, route, urlPath string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, route); ok && route != "" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, urlPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redaction is the redaction of one route
+type redaction struct {
+	*Redactor
+	keys     []string
+	paths    [][]pathSegment
+	dropBody bool
+}
+
+// body redacts a decoded JSON document or a raw body
+func (r *redaction) body(body interface{}) interface{} {
+	if body == nil {
+		return nil
+	}
+	if r.dropBody {
+		return r.mask
+	}
+	for _, segments := range r.paths {
+		applyPath(body, segments, r.mask)
+	}
+	return r.value(body)
+}
+
+// decode decodes a JSON or URL-encoded form body of the given content type and redacts it;
+// other bodies are redacted as text
+func (r *redaction) decode(contentType string, body []byte) interface{} {
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err == nil {
+			return r.body(doc)
+		}
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		return r.form(string(body))
+	}
+	return r.body(string(body))
+}
+
+// form redacts an URL-encoded form body
+func (r *redaction) form(body string) string {
+	if r.dropBody {
+		return r.mask
+	}
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		return r.text(body)
+	}
+	return r.encode(values)
+}
+
+// url redacts the query string and the path parameters of a request URL
+func (r *redaction) url(u *url.URL, params gin.Params) string {
+	redacted := r.text(r.endpoint(u.Path, params))
+	if query := r.encode(u.Query()); query != "" {
+		redacted += "?" + query
+	}
+	return redacted
+}
+
+// endpoint masks the values of sensitive path parameters in a URL path
+func (r *redaction) endpoint(urlPath string, params gin.Params) string {
+	for _, param := range params {
+		if param.Value != "" && r.sensitiveKey(param.Key) {
+			urlPath = strings.Replace(urlPath, param.Value, r.mask, 1)
+		}
+	}
+	return urlPath
+}
+
+// encode redacts the values of a query string or form and encodes them
+func (r *redaction) encode(values url.Values) string {
+	for key, vals := range values {
+		for i, val := range vals {
+			if r.sensitiveKey(key) {
+				vals[i] = r.mask
+			} else {
+				vals[i] = r.text(val)
+			}
+		}
+	}
+	// Keep the mask readable in the encoded string
+	return strings.ReplaceAll(values.Encode(), url.QueryEscape(r.mask), r.mask)
+}
+
+// header redacts a header value; cookies keep their names and attributes
+func (r *redaction) header(name, value string) string {
+	lower := strings.ToLower(name)
+	if _, ok := r.sensitiveHeaders[lower]; !ok {
+		return r.text(value)
+	}
+	switch lower {
+	case "set-cookie":
+		cookie, attrs, _ := strings.Cut(value, ";")
+		if cookieName, _, ok := strings.Cut(cookie, "="); ok {
+			value = cookieName + "=" + r.mask
+			if attrs != "" {
+				value += ";" + attrs
+			}
+			return value
+		}
+	case "cookie":
+		cookies := strings.Split(value, ";")
+		for i, cookie := range cookies {
+			if cookieName, _, ok := strings.Cut(cookie, "="); ok {
+				cookies[i] = cookieName + "=" + r.mask
+			}
+		}
+		return strings.Join(cookies, ";")
+	}
+	return r.mask
+}
+
+// headers redacts a header map into a new one
+func (r *redaction) headers(header map[string][]string) map[string][]string {
+	redacted := make(map[string][]string, len(header))
+	for name, values := range header {
+		masked := make([]string, len(values))
+		for i, value := range values {
+			masked[i] = r.header(name, value)
+		}
+		redacted[name] = masked
+	}
+	return redacted
+}
+
+// value masks sensitive keys and patterns in a decoded JSON value
+func (r *redaction) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if r.sensitiveKey(key) {
+				v[key] = r.mask
+			} else {
+				v[key] = r.value(child)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.value(child)
+		}
+		return v
+	case string:
+		return r.text(v)
+	default:
+		return v
+	}
+}
+
+// sensitiveKey reports whether a key name matches a key pattern
+func (r *redaction) sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// text masks email addresses, card numbers and the configured patterns in s
+func (r *Redactor) text(s string) string {
+	if r.maskEmails {
+		s = emailPattern.ReplaceAllString(s, r.mask)
+	}
+	if r.maskCards {
+		s = cardPattern.ReplaceAllStringFunc(s, func(match string) string {
+			if luhnValid(match) {
+				return r.mask
+			}
+			return match
+		})
+	}
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, r.mask)
+	}
+	return s
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum of card numbers
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+		digit := int(s[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// pathSegment is one step of a JSONPath expression
+type pathSegment struct {
+	key       string // Object key, or "*" for every key and element
+	index     int    // Array index, or -1
+	recursive bool   // Matches at any depth (..)
+}
+
+// parsePath parses the JSONPath subset of child (.name, ['name']), wildcard (.*, [*]),
+// index ([0]) and recursive descent (..name) steps
+func parsePath(expr string) ([]pathSegment, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("httplog: JSONPath %q must start with $", expr)
+	}
+
+	var segments []pathSegment
+	s := expr[1:]
+	for s != "" {
+		segment := pathSegment{index: -1}
+		switch {
+		case strings.HasPrefix(s, ".."):
+			segment.recursive = true
+			s = s[2:]
+		case s[0] == '.':
+			s = s[1:]
+		case s[0] != '[':
+			return nil, fmt.Errorf("httplog: invalid JSONPath %q", expr)
+		}
+
+		if strings.HasPrefix(s, "[") {
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("httplog: unclosed bracket in JSONPath %q", expr)
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			switch {
+			case inner == "*":
+				segment.key = "*"
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segment.key = inner[1 : len(inner)-1]
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("httplog: invalid index %q in JSONPath %q", inner, expr)
+				}
+				segment.index = index
+			}
+		} else {
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("httplog: empty step in JSONPath %q", expr)
+			}
+			segment.key = s[:end]
+			s = s[end:]
+		}
+		segments = append(segments, segment)
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("httplog: JSONPath %q selects the whole document", expr)
+	}
+	return segments, nil
+}
+
+// applyPath replaces the values selected by segments in a decoded JSON document with mask
+func applyPath(node interface{}, segments []pathSegment, mask string) {
+	if len(segments) == 0 {
+		return
+	}
+	segment, rest := segments[0], segments[1:]
+
+	if segment.recursive {
+		here := segment
+		here.recursive = false
+		applyPath(node, append([]pathSegment{here}, rest...), mask)
+		switch n := node.(type) {
+		case map[string]interface{}:
+			for _, child := range n {
+				applyPath(child, segments, mask)
+			}
+		case []interface{}:
+			for _, child := range n {
+				applyPath(child, segments, mask)
+			}
+		}
+		return
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, child := range n {
+			if segment.index >= 0 || (segment.key != "*" && segment.key != key) {
+				continue
+			}
+			if len(rest) == 0 {
+				n[key] = mask
+			} else {
+				applyPath(child, rest, mask)
+			}
+		}
+	case []interface{}:
+		for i, child := range n {
+			if segment.key != "*" && segment.index != i {
+				continue
+			}
+			if len(rest) == 0 {
+				n[i] = mask
+			} else {
+				applyPath(child, rest, mask)
+			}
+		}
+	}
+}
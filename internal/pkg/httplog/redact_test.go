@@ -0,0 +1,126 @@
+package httplog
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedactor(t *testing.T, rules ...RedactionRule) *Redactor {
+	cfg := DefaultRedaction()
+	cfg.Rules = rules
+	cfg.Patterns = []*regexp.Regexp{regexp.MustCompile(`sk_live_[A-Za-z0-9]+`)}
+	redactor, err := NewRedactor(cfg)
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+	return redactor
+}
+
+func TestRedactor_Body(t *testing.T) {
+	redactor := newTestRedactor(t, RedactionRule{
+		Routes: []string{"/api/v1/orders/*"},
+		Paths:  []string{"$.shipping.phone", "$.items[*].note", "$..iban"},
+		Keys:   []string{"*_pin"},
+	})
+
+	body := map[string]interface{}{
+		"email":        "jane@example.com",
+		"old_password": "hunter2",
+		"Access_Token": "eyJhbGciOi",
+		"card":         "pay with 4111 1111 1111 1111 please",
+		"order_number": "4111 1111 1111 1112",
+		"shipping":     map[string]interface{}{"phone": "+49 30 123456", "city": "Berlin"},
+		"items": []interface{}{
+			map[string]interface{}{"sku": "A1", "note": "ring twice"},
+			map[string]interface{}{"sku": "B2", "note": "leave at door"},
+		},
+		"payment": map[string]interface{}{"account": map[string]interface{}{"iban": "DE89370400440532013000"}, "card_pin": 1234},
+		"comment": "key sk_live_abc123 leaked",
+	}
+
+	redacted := redactor.forRoute("/api/v1/orders/:id", "/api/v1/orders/42").body(body).(map[string]interface{})
+
+	assert.Equal(t, DefaultMask, redacted["email"])
+	assert.Equal(t, DefaultMask, redacted["old_password"])
+	assert.Equal(t, DefaultMask, redacted["Access_Token"])
+	assert.Equal(t, "pay with "+DefaultMask+" please", redacted["card"])
+	assert.Equal(t, "4111 1111 1111 1112", redacted["order_number"], "numbers failing the Luhn check are kept")
+	assert.Equal(t, map[string]interface{}{"phone": DefaultMask, "city": "Berlin"}, redacted["shipping"])
+	assert.Equal(t, DefaultMask, redacted["items"].([]interface{})[1].(map[string]interface{})["note"])
+	assert.Equal(t, "B2", redacted["items"].([]interface{})[1].(map[string]interface{})["sku"])
+	payment := redacted["payment"].(map[string]interface{})
+	assert.Equal(t, DefaultMask, payment["account"].(map[string]interface{})["iban"])
+	assert.Equal(t, DefaultMask, payment["card_pin"])
+	assert.Equal(t, "key "+DefaultMask+" leaked", redacted["comment"])
+
+	t.Run("route rules only apply to their routes", func(t *testing.T) {
+		body := map[string]interface{}{"shipping": map[string]interface{}{"phone": "+49 30 123456"}, "card_pin": "1234"}
+		redacted := redactor.forRoute("/api/v1/users/:id", "/api/v1/users/42").body(body).(map[string]interface{})
+		assert.Equal(t, "+49 30 123456", redacted["shipping"].(map[string]interface{})["phone"])
+		assert.Equal(t, "1234", redacted["card_pin"])
+	})
+
+	t.Run("drop body", func(t *testing.T) {
+		redactor := newTestRedactor(t, RedactionRule{Routes: []string{"/api/v1/admin/users/export"}, DropBody: true})
+		red := redactor.forRoute("/api/v1/admin/users/export", "/api/v1/admin/users/export")
+		assert.Equal(t, DefaultMask, red.body("id,email\n1,jane@example.com"))
+		assert.Nil(t, red.body(nil))
+	})
+}
+
+func TestRedactor_RequestParts(t *testing.T) {
+	red := newTestRedactor(t).forRoute("", "")
+
+	t.Run("form", func(t *testing.T) {
+		assert.Equal(t, "email="+DefaultMask+"&password="+DefaultMask+"&remember=true",
+			red.form("email=jane%40example.com&password=hunter2&remember=true"))
+	})
+
+	t.Run("url", func(t *testing.T) {
+		u, _ := url.Parse("/api/v1/privacy/exports/abc123?token=s3cr3t&page=2")
+		params := gin.Params{{Key: "token", Value: "abc123"}}
+		assert.Equal(t, "/api/v1/privacy/exports/"+DefaultMask+"?page=2&token="+DefaultMask, red.url(u, params))
+		assert.Equal(t, "/api/v1/privacy/exports/"+DefaultMask, red.endpoint(u.Path, params))
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		headers := red.headers(map[string][]string{
+			"Set-Cookie":   {"refresh_token=abc; Path=/; HttpOnly"},
+			"Cookie":       {"session=abc; theme=dark"},
+			"X-Api-Key":    {"k-123"},
+			"Content-Type": {"application/json"},
+			"X-Reply-To":   {"jane@example.com"},
+		})
+		assert.Equal(t, []string{"refresh_token=" + DefaultMask + "; Path=/; HttpOnly"}, headers["Set-Cookie"])
+		assert.Equal(t, []string{"session=" + DefaultMask + "; theme=" + DefaultMask}, headers["Cookie"])
+		assert.Equal(t, []string{DefaultMask}, headers["X-Api-Key"])
+		assert.Equal(t, []string{"application/json"}, headers["Content-Type"])
+		assert.Equal(t, []string{DefaultMask}, headers["X-Reply-To"])
+	})
+}
+
+func TestParsePath(t *testing.T) {
+	segments, err := parsePath("$.users[*]['e-mail']..token[2]")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []pathSegment{
+		{key: "users", index: -1},
+		{key: "*", index: -1},
+		{key: "e-mail", index: -1},
+		{key: "token", index: -1, recursive: true},
+		{index: 2},
+	}, segments)
+
+	for _, expr := range []string{"", "$", "user.password", "$.items[", "$.items[-1]", "$.a..", "$a"} {
+		_, err := parsePath(expr)
+		assert.Error(t, err, expr)
+	}
+
+	_, err = NewRedactor(RedactionConfig{Rules: []RedactionRule{{Paths: []string{"password"}}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,349 @@
+// Package i18n provides message catalogs with locale negotiation,
+// CLDR pluralization and locale-aware number and date formatting.
+//
+// Catalogs are JSON files named after their BCP 47 tag (en.json, de.json,
+// pt-BR.json) of the form:
+//
+//	{
+//	  "date": {"date": "{month} {day}, {year}", "datetime": "{month} {day}, {year} at {time}", "months": ["January", ...]},
+//	  "messages": {
+//	    "greeting": "Hello {name}",
+//	    "new_users": {"=0": "No new users", "one": "{count} new user", "other": "{count} new users"}
+//	  }
+//	}
+//
+// A plural message maps CLDR plural categories (zero, one, two, few, many,
+// other) or exact counts (=0) to text; "other" is required.
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Bundle holds the catalogs of all supported locales
+type Bundle struct {
+	defaultTag language.Tag
+	tags       []language.Tag // Supported locales, default first
+	catalogs   map[language.Tag]*catalog
+	matcher    language.Matcher
+}
+
+type catalog struct {
+	dateFormat     string
+	dateTimeFormat string
+	months         []string
+	messages       map[string]entry
+}
+
+type file struct {
+	Date struct {
+		Date     string   `json:"date"`
+		DateTime string   `json:"datetime"`
+		Months   []string `json:"months"`
+	} `json:"date"`
+	Messages map[string]entry `json:"messages"`
+}
+
+// entry is a message: plain text, or text per plural form
+type entry struct {
+	text  string
+	forms map[string]string
+}
+
+func (e *entry) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &e.text); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &e.forms); err != nil {
+		return errors.New("message must be a string or an object of plural forms")
+	}
+	if _, ok := e.forms["other"]; !ok {
+		return errors.New(`plural message must have an "other" form`)
+	}
+	return nil
+}
+
+// Load reads every <locale>.json file in dir. defaultLocale must be one of
+// them; it ends every fallback chain.
+func Load(fsys fs.FS, dir, defaultLocale string) (*Bundle, error) {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{catalogs: make(map[language.Tag]*catalog)}
+	for _, p := range paths {
+		name := strings.TrimSuffix(path.Base(p), ".json")
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: %s: invalid locale: %w", p, err)
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		var f file
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("i18n: %s: %w", p, err)
+		}
+		if len(f.Date.Months) != 0 && len(f.Date.Months) != 12 {
+			return nil, fmt.Errorf("i18n: %s: date.months must list 12 months", p)
+		}
+
+		b.catalogs[tag] = &catalog{
+			dateFormat:     f.Date.Date,
+			dateTimeFormat: f.Date.DateTime,
+			months:         f.Date.Months,
+			messages:       f.Messages,
+		}
+	}
+
+	b.defaultTag, err = language.Parse(defaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("i18n: invalid default locale: %w", err)
+	}
+	if _, ok := b.catalogs[b.defaultTag]; !ok {
+		return nil, fmt.Errorf("i18n: no catalog for default locale %s in %s", defaultLocale, dir)
+	}
+
+	b.tags = append(b.tags, b.defaultTag)
+	for tag := range b.catalogs {
+		if tag != b.defaultTag {
+			b.tags = append(b.tags, tag)
+		}
+	}
+	sort.Slice(b.tags[1:], func(i, j int) bool { return b.tags[i+1].String() < b.tags[j+1].String() })
+	b.matcher = language.NewMatcher(b.tags)
+
+	return b, nil
+}
+
+// Locales returns the supported locales, default first
+func (b *Bundle) Locales() []string {
+	locales := make([]string, len(b.tags))
+	for i, tag := range b.tags {
+		locales[i] = tag.String()
+	}
+	return locales
+}
+
+// Match returns the supported locale that best fits the candidates, which are
+// tried in order. Each candidate is a language tag or a whole Accept-Language
+// header; empty and malformed candidates are skipped. Without a match the
+// default locale is returned.
+func (b *Bundle) Match(candidates ...string) language.Tag {
+	var wants []language.Tag
+	for _, candidate := range candidates {
+		if strings.TrimSpace(candidate) == "" {
+			continue
+		}
+		tags, _, err := language.ParseAcceptLanguage(candidate)
+		if err != nil {
+			continue
+		}
+		for _, tag := range tags {
+			if tag != language.Und {
+				wants = append(wants, tag)
+			}
+		}
+	}
+	if len(wants) == 0 {
+		return b.defaultTag
+	}
+
+	_, index, confidence := b.matcher.Match(wants...)
+	if confidence == language.No {
+		return b.defaultTag
+	}
+	return b.tags[index]
+}
+
+// Localizer returns a localizer for the best match of candidates (see Match)
+func (b *Bundle) Localizer(candidates ...string) *Localizer {
+	tag := b.Match(candidates...)
+
+	// Messages missing from a regional catalog come from its parents, then the default
+	var chain []*catalog
+	for t := tag; ; t = t.Parent() {
+		if c, ok := b.catalogs[t]; ok {
+			chain = append(chain, c)
+		}
+		if t == language.Und {
+			break
+		}
+	}
+	if tag != b.defaultTag {
+		chain = append(chain, b.catalogs[b.defaultTag])
+	}
+
+	return &Localizer{
+		tag:     tag,
+		chain:   chain,
+		printer: message.NewPrinter(tag),
+		loc:     time.UTC,
+	}
+}
+
+// Localizer translates messages and formats values for one locale
+type Localizer struct {
+	tag     language.Tag
+	chain   []*catalog
+	printer *message.Printer
+	loc     *time.Location
+}
+
+// Locale returns the BCP 47 tag of the localizer
+func (l *Localizer) Locale() string {
+	return l.tag.String()
+}
+
+// In returns a copy of the localizer that formats dates in loc
+func (l *Localizer) In(loc *time.Location) *Localizer {
+	c := *l
+	if loc != nil {
+		c.loc = loc
+	}
+	return &c
+}
+
+// T returns the message for key with {placeholders} replaced by args, given
+// as name/value pairs. Integer values are formatted for the locale. A key
+// missing from every catalog in the chain is returned as is.
+func (l *Localizer) T(key string, args ...any) string {
+	e, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	text := e.text
+	if e.forms != nil {
+		text = e.forms["other"]
+	}
+	return l.replace(text, args)
+}
+
+// Plural returns the form of the message for key that matches count under the
+// locale's plural rules. count is available to the message as {count}.
+func (l *Localizer) Plural(key string, count int, args ...any) string {
+	e, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+
+	args = append(args, "count", count)
+	if e.forms == nil {
+		return l.replace(e.text, args)
+	}
+	if text, ok := e.forms["="+strconv.Itoa(count)]; ok {
+		return l.replace(text, args)
+	}
+	if text, ok := e.forms[pluralForm(l.tag, count)]; ok {
+		return l.replace(text, args)
+	}
+	return l.replace(e.forms["other"], args)
+}
+
+// FormatNumber formats n with the locale's digit grouping and decimal separator
+func (l *Localizer) FormatNumber(n any) string {
+	switch n.(type) {
+	case float32, float64:
+		return l.printer.Sprintf("%.2f", n)
+	default:
+		return l.printer.Sprintf("%d", n)
+	}
+}
+
+// FormatDate formats the calendar date of t, e.g. "August 16, 2025" or "16. August 2025"
+func (l *Localizer) FormatDate(t time.Time) string {
+	return l.formatTime(t, func(c *catalog) string { return c.dateFormat }, "{month} {day}, {year}")
+}
+
+// FormatDateTime formats t with its time of day, e.g. "August 16, 2025 at 09:30 UTC"
+func (l *Localizer) FormatDateTime(t time.Time) string {
+	return l.formatTime(t, func(c *catalog) string { return c.dateTimeFormat }, "{month} {day}, {year} at {time}")
+}
+
+func (l *Localizer) formatTime(t time.Time, layout func(*catalog) string, fallback string) string {
+	t = t.In(l.loc)
+
+	format, months := fallback, []string(nil)
+	for _, c := range l.chain {
+		if f := layout(c); f != "" {
+			format, months = f, c.months
+			break
+		}
+	}
+
+	month := t.Month().String()
+	if len(months) == 12 {
+		month = months[t.Month()-1]
+	}
+	return strings.NewReplacer(
+		"{day}", strconv.Itoa(t.Day()),
+		"{month}", month,
+		"{year}", strconv.Itoa(t.Year()),
+		"{time}", t.Format("15:04 MST"),
+	).Replace(format)
+}
+
+func (l *Localizer) lookup(key string) (entry, bool) {
+	for _, c := range l.chain {
+		if e, ok := c.messages[key]; ok {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+func (l *Localizer) replace(text string, args []any) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		name, _ := args[i].(string)
+		var value string
+		switch v := args[i+1].(type) {
+		case int, int32, int64, uint, uint32, uint64:
+			value = l.FormatNumber(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// pluralForm returns the CLDR cardinal plural category of n in the language of tag
+func pluralForm(tag language.Tag, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0) {
+	case plural.Zero:
+		return "zero"
+	case plural.One:
+		return "one"
+	case plural.Two:
+		return "two"
+	case plural.Few:
+		return "few"
+	case plural.Many:
+		return "many"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFS = fstest.MapFS{
+	"locales/en.json": {Data: []byte(`{
+		"date": {"date": "{month} {day}, {year}", "datetime": "{month} {day}, {year} at {time}"},
+		"messages": {
+			"greeting": "Hello {name}",
+			"farewell": "Goodbye",
+			"files": {"=0": "No files", "one": "{count} file", "other": "{count} files"}
+		}
+	}`)},
+	"locales/de.json": {Data: []byte(`{
+		"date": {
+			"date": "{day}. {month} {year}",
+			"datetime": "{day}. {month} {year} um {time}",
+			"months": ["Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"]
+		},
+		"messages": {
+			"greeting": "Hallo {name}",
+			"files": {"one": "{count} Datei", "other": "{count} Dateien"}
+		}
+	}`)},
+	"locales/de-CH.json": {Data: []byte(`{"messages": {"greeting": "Grüezi {name}"}}`)},
+	"locales/pl.json": {Data: []byte(`{
+		"messages": {"files": {"one": "{count} plik", "few": "{count} pliki", "many": "{count} plików", "other": "{count} pliku"}}
+	}`)},
+}
+
+var testDate = time.Date(2025, 8, 16, 9, 30, 0, 0, time.UTC)
+
+func loadTestBundle(t *testing.T) *Bundle {
+	t.Helper()
+	b, err := Load(testFS, "locales", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestBundle_Match(t *testing.T) {
+	b := loadTestBundle(t)
+
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{"no candidates", nil, "en"},
+		{"exact", []string{"de"}, "de"},
+		{"region of supported language", []string{"de-AT"}, "de"},
+		{"regional catalog", []string{"de-CH"}, "de-CH"},
+		{"unsupported falls through to next candidate", []string{"ja", "de"}, "de"},
+		{"empty and malformed candidates are skipped", []string{"", "not a tag!", "pl"}, "pl"},
+		{"accept-language quality order", []string{"fr;q=0.9, de;q=0.8, en;q=0.1"}, "de"},
+		{"nothing supported", []string{"ja, ko"}, "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, b.Match(tt.candidates...).String())
+		})
+	}
+}
+
+func TestLocalizer_FallbackChain(t *testing.T) {
+	b := loadTestBundle(t)
+
+	l := b.Localizer("de-CH")
+	assert.Equal(t, "Grüezi Jane", l.T("greeting", "name", "Jane"))   // de-CH
+	assert.Equal(t, "2 Dateien", l.Plural("files", 2))                // de
+	assert.Equal(t, "Goodbye", l.T("farewell"))                       // en
+	assert.Equal(t, "missing.key", l.T("missing.key"))                // nowhere
+	assert.Equal(t, "16. August 2025", l.FormatDate(testDate))        // de date format
+	assert.Equal(t, []string{"en", "de", "de-CH", "pl"}, b.Locales()) // default first
+}
+
+func TestLocalizer_Plural(t *testing.T) {
+	b := loadTestBundle(t)
+
+	en := b.Localizer("en")
+	assert.Equal(t, "No files", en.Plural("files", 0))
+	assert.Equal(t, "1 file", en.Plural("files", 1))
+	assert.Equal(t, "1,234 files", en.Plural("files", 1234))
+
+	pl := b.Localizer("pl")
+	assert.Equal(t, "1 plik", pl.Plural("files", 1))
+	assert.Equal(t, "3 pliki", pl.Plural("files", 3))
+	assert.Equal(t, "5 plików", pl.Plural("files", 5))
+	assert.Equal(t, "22 pliki", pl.Plural("files", 22))
+}
+
+func TestLocalizer_Formatting(t *testing.T) {
+	b := loadTestBundle(t)
+
+	en := b.Localizer("en")
+	assert.Equal(t, "1,234,567", en.FormatNumber(1234567))
+	assert.Equal(t, "August 16, 2025 at 09:30 UTC", en.FormatDateTime(testDate))
+
+	de := b.Localizer("de")
+	assert.Equal(t, "1.234.567", de.FormatNumber(1234567))
+	assert.Equal(t, "16. August 2025 um 09:30 UTC", de.FormatDateTime(testDate))
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	assert.Equal(t, "16. August 2025 um 11:30 CEST", de.In(berlin).FormatDateTime(testDate))
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load(testFS, "locales", "fr")
+	assert.ErrorContains(t, err, "no catalog for default locale")
+
+	_, err = Load(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"messages": {"files": {"one": "{count} file"}}}`)},
+	}, "locales", "en")
+	assert.ErrorContains(t, err, `"other" form`)
+
+	_, err = Load(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"date": {"months": ["Jan"]}}`)},
+	}, "locales", "en")
+	assert.ErrorContains(t, err, "12 months")
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// Supported SMTP authentication mechanisms
+const (
+	AuthNone    = "none"
+	AuthPlain   = "plain"
+	AuthLogin   = "login"
+	AuthCRAMMD5 = "cram-md5"
+)
+
+// newAuth returns the smtp.Auth for the configured mechanism, or nil for AuthNone
+func newAuth(mechanism, username, password, host string) (smtp.Auth, error) {
+	switch strings.ToLower(mechanism) {
+	case AuthNone, "":
+		return nil, nil
+	case AuthPlain:
+		return smtp.PlainAuth("", username, password, host), nil
+	case AuthLogin:
+		return &loginAuth{username: username, password: password, host: host}, nil
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(username, password), nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown SMTP auth mechanism %q", mechanism)
+	}
+}
+
+// loginAuth implements the non-standard but widely deployed LOGIN mechanism
+// (draft-murchison-sasl-login). Like smtp.PlainAuth it refuses to send
+// credentials over an unencrypted connection to anything but localhost.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	// Servers prompt with "Username:" and "Password:", with some variation in wording
+	prompt := strings.ToLower(strings.TrimSpace(string(fromServer)))
+	switch {
+	case strings.HasPrefix(prompt, "user"):
+		return []byte(a.username), nil
+	case strings.HasPrefix(prompt, "pass"):
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN prompt %q", fromServer)
+	}
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
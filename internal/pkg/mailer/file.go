@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileTransport writes every message to its own .eml file, which can be
+// opened in any mail client. It is meant for local development.
+type FileTransport struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileTransport creates a FileTransport writing into dir
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mailer: failed to create mail directory: %w", err)
+	}
+	return &FileTransport{dir: dir, now: time.Now}, nil
+}
+
+// Send writes the message to <dir>/<timestamp>-<random>.eml. The envelope is
+// recorded in X-Envelope-From and X-Envelope-To headers so Bcc recipients are visible.
+func (t *FileTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	_, err := t.SendWithResponse(ctx, from, to, msg)
+	return err
+}
+
+// SendWithResponse writes the message like Send and returns the file name
+func (t *FileTransport) SendWithResponse(ctx context.Context, from string, to []string, msg []byte) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := t.now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "X-Envelope-From: <%s>\r\n", from)
+	fmt.Fprintf(&buf, "X-Envelope-To: %s\r\n", "<"+strings.Join(to, ">, <")+">")
+	buf.Write(msg)
+
+	// Write to a temporary name first so readers never see a partial file
+	tmp := filepath.Join(t.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("mailer: failed to write message: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(t.dir, name)); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("mailer: failed to write message: %w", err)
+	}
+	return "written to " + name, nil
+}
+
+func (t *FileTransport) Close() error {
+	return nil
+}
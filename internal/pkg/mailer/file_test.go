@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileTransport_Send(t *testing.T) {
+	dir := t.TempDir()
+	transport, err := NewFileTransport(filepath.Join(dir, "mail"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	msg := []byte("Subject: Hello\r\n\r\nHi there\r\n")
+	assert.NoError(t, transport.Send(context.Background(), "app@example.com", []string{"a@example.com", "hidden@example.com"}, msg))
+
+	files, _ := filepath.Glob(filepath.Join(dir, "mail", "*.eml"))
+	if !assert.Len(t, files, 1) {
+		return
+	}
+	data, _ := os.ReadFile(files[0])
+	assert.True(t, strings.HasPrefix(string(data), "X-Envelope-From: <app@example.com>\r\nX-Envelope-To: <a@example.com>, <hidden@example.com>\r\n"))
+	assert.True(t, strings.HasSuffix(string(data), string(msg)))
+}
+
+func TestMemoryTransport_Send(t *testing.T) {
+	transport := NewMemoryTransport()
+	ctx := context.Background()
+
+	assert.NoError(t, transport.Send(ctx, "app@example.com", []string{"a@example.com"}, []byte("one")))
+	assert.NoError(t, transport.Send(ctx, "app@example.com", []string{"b@example.com"}, []byte("two")))
+
+	messages := transport.Messages()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, []string{"b@example.com"}, messages[1].To)
+	assert.Equal(t, "two", string(messages[1].Data))
+
+	transport.Reset()
+	assert.Empty(t, transport.Messages())
+}
@@ -0,0 +1,44 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"mime"
+	"net/mail"
+)
+
+// LogTransport logs a summary of each message instead of delivering it
+type LogTransport struct {
+	logger *log.Logger
+}
+
+// NewLogTransport creates a LogTransport writing to logger, or to the
+// standard logger when logger is nil
+func NewLogTransport(logger *log.Logger) *LogTransport {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogTransport{logger: logger}
+}
+
+// Send logs the envelope, subject and size of the message
+func (t *LogTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	subject := ""
+	if m, err := mail.ReadMessage(bytes.NewReader(msg)); err == nil {
+		subject = m.Header.Get("Subject")
+		if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err == nil {
+			subject = decoded
+		}
+	}
+	t.logger.Printf("mailer: from=%s to=%v subject=%q size=%d", from, to, subject, len(msg))
+	return nil
+}
+
+func (t *LogTransport) Close() error {
+	return nil
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is a message captured by MemoryTransport
+type Message struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// MemoryTransport keeps sent messages in memory so tests can assert on them
+type MemoryTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+// Send records the message
+func (t *MemoryTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, Message{
+		From: from,
+		To:   append([]string(nil), to...),
+		Data: append([]byte(nil), msg...),
+	})
+	return nil
+}
+
+// Messages returns a copy of all recorded messages in send order
+func (t *MemoryTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Message(nil), t.messages...)
+}
+
+// Reset discards all recorded messages
+func (t *MemoryTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
+
+func (t *MemoryTransport) Close() error {
+	return nil
+}
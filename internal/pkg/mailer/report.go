@@ -0,0 +1,187 @@
+package mailer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// ErrNotReport is returned by ParseReport for messages that are neither a
+// delivery status notification nor a feedback report
+var ErrNotReport = errors.New("mailer: not a delivery status or feedback report")
+
+// ReportType is the report-type parameter of a multipart/report message
+type ReportType string
+
+const (
+	// ReportDeliveryStatus is a bounce or other delivery status notification (RFC 3464)
+	ReportDeliveryStatus ReportType = "delivery-status"
+	// ReportFeedback is a spam complaint or other abuse feedback report (RFC 5965)
+	ReportFeedback ReportType = "feedback-report"
+)
+
+// Report is a parsed multipart/report message
+type Report struct {
+	Type ReportType
+	// FeedbackType of a feedback report, e.g. "abuse"
+	FeedbackType string
+	// Recipients the report is about. Feedback reports list the recipients of
+	// the original message with the Action "complaint".
+	Recipients []RecipientStatus
+}
+
+// RecipientStatus is the outcome reported for one recipient
+type RecipientStatus struct {
+	Address    string // Bare address
+	Action     string // failed, delayed, delivered, relayed, expanded or complaint
+	Status     string // Enhanced status code (RFC 3463), e.g. 5.1.1
+	Diagnostic string // Diagnostic-Code as reported by the remote server
+}
+
+// Permanent reports whether delivery failed for good, i.e. a hard bounce
+func (r RecipientStatus) Permanent() bool {
+	return r.Action == "failed" && strings.HasPrefix(r.Status, "5")
+}
+
+// ParseReport reads a delivery status notification or a feedback report from
+// a complete RFC 5322 message
+func ParseReport(r io.Reader) (*Report, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: invalid message: %w", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/report" || params["boundary"] == "" {
+		return nil, ErrNotReport
+	}
+
+	report := &Report{Type: ReportType(strings.ToLower(params["report-type"]))}
+	if report.Type != ReportDeliveryStatus && report.Type != ReportFeedback {
+		return nil, ErrNotReport
+	}
+
+	var originalTo []string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mailer: invalid report: %w", err)
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body := partBody(part)
+		switch strings.ToLower(contentType) {
+		case "message/delivery-status", "message/global-delivery-status":
+			groups, err := readFieldGroups(body)
+			if err != nil {
+				return nil, fmt.Errorf("mailer: invalid delivery status: %w", err)
+			}
+			// The first group describes the message, the others one recipient each
+			for _, fields := range groups[min(1, len(groups)):] {
+				if status, ok := recipientStatus(fields); ok {
+					report.Recipients = append(report.Recipients, status)
+				}
+			}
+		case "message/feedback-report":
+			groups, err := readFieldGroups(body)
+			if err != nil || len(groups) == 0 {
+				return nil, fmt.Errorf("mailer: invalid feedback report: %v", err)
+			}
+			report.FeedbackType = strings.ToLower(groups[0].Get("Feedback-Type"))
+			for _, rcpt := range groups[0].Values("Original-Rcpt-To") {
+				report.Recipients = append(report.Recipients, RecipientStatus{Address: bareAddress(rcpt), Action: "complaint"})
+			}
+		case "message/rfc822", "text/rfc822-headers", "message/global-headers":
+			// Feedback reports need not name the recipient; fall back to the original To
+			if original, err := mail.ReadMessage(body); err == nil {
+				if addrs, err := original.Header.AddressList("To"); err == nil {
+					for _, addr := range addrs {
+						originalTo = append(originalTo, addr.Address)
+					}
+				}
+			}
+		}
+	}
+
+	if report.Type == ReportFeedback && len(report.Recipients) == 0 {
+		for _, addr := range originalTo {
+			report.Recipients = append(report.Recipients, RecipientStatus{Address: addr, Action: "complaint"})
+		}
+	}
+
+	return report, nil
+}
+
+// partBody decodes a base64 part; multipart.Reader already decodes quoted-printable
+func partBody(part *multipart.Part) io.Reader {
+	if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+		return base64.NewDecoder(base64.StdEncoding, part)
+	}
+	return part
+}
+
+// readFieldGroups reads blocks of header-style fields separated by blank lines
+func readFieldGroups(r io.Reader) ([]textproto.MIMEHeader, error) {
+	tp := textproto.NewReader(bufio.NewReader(r))
+
+	var groups []textproto.MIMEHeader
+	for {
+		fields, err := tp.ReadMIMEHeader()
+		if len(fields) > 0 {
+			groups = append(groups, fields)
+		}
+		if err == io.EOF {
+			return groups, nil
+		}
+		if err != nil {
+			return groups, err
+		}
+	}
+}
+
+func recipientStatus(fields textproto.MIMEHeader) (RecipientStatus, bool) {
+	recipient := fields.Get("Final-Recipient")
+	if recipient == "" {
+		recipient = fields.Get("Original-Recipient")
+	}
+	address := bareAddress(typedValue(recipient))
+	if address == "" {
+		return RecipientStatus{}, false
+	}
+
+	status, _, _ := strings.Cut(strings.TrimSpace(fields.Get("Status")), " ")
+	return RecipientStatus{
+		Address:    address,
+		Action:     strings.ToLower(strings.TrimSpace(fields.Get("Action"))),
+		Status:     status,
+		Diagnostic: typedValue(fields.Get("Diagnostic-Code")),
+	}, true
+}
+
+// typedValue strips the type of a typed field such as "rfc822; jane@example.com"
+func typedValue(value string) string {
+	if _, v, ok := strings.Cut(value, ";"); ok {
+		return strings.TrimSpace(v)
+	}
+	return strings.TrimSpace(value)
+}
+
+// bareAddress returns the address without angle brackets or display name
+func bareAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if addr, err := mail.ParseAddress(value); err == nil {
+		return addr.Address
+	}
+	return strings.Trim(value, "<>")
+}
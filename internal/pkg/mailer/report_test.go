@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const bounceReport = "From: MAILER-DAEMON@mx.example.net\r\n" +
+	"To: app@example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--b1\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.net\r\n" +
+	"Arrival-Date: Sat, 16 Aug 2025 09:00:00 +0000\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; Gone@Example.org\r\n" +
+	"Action: failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 <Gone@Example.org>: Recipient address\r\n" +
+	" rejected: User unknown\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; busy@example.org\r\n" +
+	"Action: delayed\r\n" +
+	"Status: 4.2.2\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"To: Gone@Example.org, busy@example.org\r\n" +
+	"Subject: Your Daily Report\r\n" +
+	"\r\n" +
+	"--b1--\r\n"
+
+func TestParseReport_DeliveryStatus(t *testing.T) {
+	report, err := ParseReport(strings.NewReader(bounceReport))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, ReportDeliveryStatus, report.Type)
+	if !assert.Len(t, report.Recipients, 2) {
+		return
+	}
+
+	gone := report.Recipients[0]
+	assert.Equal(t, "Gone@Example.org", gone.Address)
+	assert.Equal(t, "failed", gone.Action)
+	assert.Equal(t, "5.1.1", gone.Status)
+	assert.Equal(t, "550 5.1.1 <Gone@Example.org>: Recipient address rejected: User unknown", gone.Diagnostic)
+	assert.True(t, gone.Permanent())
+
+	assert.Equal(t, "busy@example.org", report.Recipients[1].Address)
+	assert.False(t, report.Recipients[1].Permanent())
+}
+
+func TestParseReport_Feedback(t *testing.T) {
+	msg := "From: fbl@isp.example\r\n" +
+		"Content-Type: multipart/report; report-type=feedback-report; boundary=b2\r\n" +
+		"\r\n" +
+		"--b2\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"This is an email abuse report.\r\n" +
+		"--b2\r\n" +
+		"Content-Type: message/feedback-report\r\n" +
+		"\r\n" +
+		"Feedback-Type: abuse\r\n" +
+		"User-Agent: ExampleFBL/1.0\r\n" +
+		"Version: 1\r\n" +
+		"\r\n" +
+		"--b2\r\n" +
+		"Content-Type: message/rfc822\r\n" +
+		"\r\n" +
+		"From: app@example.com\r\n" +
+		"To: Jane <jane@example.org>\r\n" +
+		"Subject: Your Daily Report\r\n" +
+		"\r\n" +
+		"Hello\r\n" +
+		"--b2--\r\n"
+
+	report, err := ParseReport(strings.NewReader(msg))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, ReportFeedback, report.Type)
+	assert.Equal(t, "abuse", report.FeedbackType)
+	assert.Equal(t, []RecipientStatus{{Address: "jane@example.org", Action: "complaint"}}, report.Recipients)
+}
+
+func TestParseReport_NotReport(t *testing.T) {
+	_, err := ParseReport(strings.NewReader("Content-Type: text/plain\r\n\r\nhello\r\n"))
+	assert.ErrorIs(t, err, ErrNotReport)
+
+	_, err = ParseReport(strings.NewReader("Content-Type: multipart/report; report-type=disposition-notification; boundary=x\r\n\r\n--x--\r\n"))
+	assert.ErrorIs(t, err, ErrNotReport)
+}
@@ -0,0 +1,307 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Connection security modes
+const (
+	// SecurityStartTLS upgrades a plain connection and fails if the server does not offer STARTTLS
+	SecurityStartTLS = "starttls"
+	// SecurityTLS connects over TLS from the start (SMTPS, usually port 465)
+	SecurityTLS = "tls"
+	// SecurityNone never encrypts; only suitable for local relays such as MailHog
+	SecurityNone = "none"
+)
+
+const (
+	defaultPoolSize    = 4
+	defaultIdleTimeout = 30 * time.Second
+	defaultSendTimeout = time.Minute
+)
+
+// SMTPConfig configures an SMTPTransport
+type SMTPConfig struct {
+	Host        string
+	Port        string
+	Username    string
+	Password    string
+	Security    string        // SecurityStartTLS (default), SecurityTLS or SecurityNone
+	Auth        string        // AuthPlain (default), AuthLogin, AuthCRAMMD5 or AuthNone
+	LocalName   string        // Name sent in EHLO; defaults to "localhost"
+	PoolSize    int           // Maximum number of open connections
+	IdleTimeout time.Duration // Idle connections older than this are closed instead of reused
+	TLSConfig   *tls.Config   // Optional; ServerName defaults to Host
+}
+
+// SMTPTransport delivers mail over SMTP, keeping up to PoolSize
+// authenticated connections open between messages.
+type SMTPTransport struct {
+	cfg       SMTPConfig
+	auth      smtp.Auth
+	tlsConfig *tls.Config
+	sem       chan struct{}
+	idle      chan *smtpConn
+	now       func() time.Time
+
+	mu     sync.Mutex
+	closed bool
+}
+
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// NewSMTPTransport validates cfg and creates the transport. Connections are opened lazily.
+func NewSMTPTransport(cfg SMTPConfig) (*SMTPTransport, error) {
+	if cfg.Host == "" || cfg.Port == "" {
+		return nil, errors.New("mailer: SMTP host and port are required")
+	}
+	if cfg.Security == "" {
+		cfg.Security = SecurityStartTLS
+	}
+	switch cfg.Security {
+	case SecurityStartTLS, SecurityTLS, SecurityNone:
+	default:
+		return nil, fmt.Errorf("mailer: unknown SMTP security mode %q", cfg.Security)
+	}
+	if cfg.Auth == "" {
+		cfg.Auth = AuthPlain
+	}
+	if cfg.LocalName == "" {
+		cfg.LocalName = "localhost"
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = defaultPoolSize
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+
+	auth, err := newAuth(cfg.Auth, cfg.Username, cfg.Password, cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = cfg.Host
+		}
+	}
+
+	return &SMTPTransport{
+		cfg:       cfg,
+		auth:      auth,
+		tlsConfig: tlsConfig,
+		sem:       make(chan struct{}, cfg.PoolSize),
+		idle:      make(chan *smtpConn, cfg.PoolSize),
+		now:       time.Now,
+	}, nil
+}
+
+// Send delivers msg over a pooled connection. The connection is dropped when
+// ctx is cancelled or the server rejects the message, and returned to the
+// pool otherwise.
+func (t *SMTPTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	_, err := t.SendWithResponse(ctx, from, to, msg)
+	return err
+}
+
+// SendWithResponse delivers msg like Send and returns the server's reply to
+// the message data, e.g. "250 2.0.0 Ok: queued as 4F2A1C0E12"
+func (t *SMTPTransport) SendWithResponse(ctx context.Context, from string, to []string, msg []byte) (string, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-t.sem }()
+
+	if t.isClosed() {
+		return "", ErrClosed
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = t.now().Add(defaultSendTimeout)
+	}
+
+	c, err := t.acquire(ctx, deadline)
+	if err != nil {
+		return "", err
+	}
+
+	stop := context.AfterFunc(ctx, func() { c.conn.Close() })
+	response, err := deliver(c.client, from, to, msg)
+	if !stop() {
+		// ctx was cancelled mid-conversation and the connection is gone
+		c.client.Close()
+		return "", ctx.Err()
+	}
+	if err != nil {
+		c.client.Close()
+		return "", err
+	}
+
+	c.lastUsed = t.now()
+	t.release(c)
+	return response, nil
+}
+
+// Close closes all idle connections. Connections in use are closed when their send finishes.
+func (t *SMTPTransport) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+
+	for {
+		select {
+		case c := <-t.idle:
+			c.client.Quit()
+		default:
+			return nil
+		}
+	}
+}
+
+func (t *SMTPTransport) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+// acquire returns a healthy idle connection or dials a new one
+func (t *SMTPTransport) acquire(ctx context.Context, deadline time.Time) (*smtpConn, error) {
+	for {
+		select {
+		case c := <-t.idle:
+			if t.now().Sub(c.lastUsed) > t.cfg.IdleTimeout {
+				c.client.Close()
+				continue
+			}
+			// RSET doubles as a liveness check; servers drop idle clients without notice
+			c.conn.SetDeadline(deadline)
+			if err := c.client.Reset(); err != nil {
+				c.client.Close()
+				continue
+			}
+			return c, nil
+		default:
+			return t.dial(ctx, deadline)
+		}
+	}
+}
+
+func (t *SMTPTransport) release(c *smtpConn) {
+	c.conn.SetDeadline(time.Time{})
+	if t.isClosed() {
+		c.client.Quit()
+		return
+	}
+	select {
+	case t.idle <- c:
+	default:
+		c.client.Quit()
+	}
+}
+
+func (t *SMTPTransport) dial(ctx context.Context, deadline time.Time) (*smtpConn, error) {
+	addr := net.JoinHostPort(t.cfg.Host, t.cfg.Port)
+
+	var conn net.Conn
+	var err error
+	if t.cfg.Security == SecurityTLS {
+		conn, err = (&tls.Dialer{Config: t.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to connect to %s: %w", addr, err)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, t.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("mailer: SMTP handshake failed: %w", err)
+	}
+	if err := t.handshake(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return &smtpConn{conn: conn, client: client, lastUsed: t.now()}, nil
+}
+
+func (t *SMTPTransport) handshake(client *smtp.Client) error {
+	if err := client.Hello(t.cfg.LocalName); err != nil {
+		return fmt.Errorf("mailer: EHLO failed: %w", err)
+	}
+	if t.cfg.Security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("mailer: server does not support STARTTLS")
+		}
+		if err := client.StartTLS(t.tlsConfig); err != nil {
+			return fmt.Errorf("mailer: STARTTLS failed: %w", err)
+		}
+	}
+	if t.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("mailer: server does not support authentication")
+		}
+		if err := client.Auth(t.auth); err != nil {
+			return fmt.Errorf("mailer: authentication failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// deliver runs one MAIL/RCPT/DATA transaction on an established connection
+// and returns the server's reply to the message data. DATA is driven on the
+// text connection because smtp.Client.Data discards that reply.
+func deliver(client *smtp.Client, from string, to []string, msg []byte) (string, error) {
+	if err := client.Mail(from); err != nil {
+		return "", err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return "", fmt.Errorf("recipient %s rejected: %w", rcpt, err)
+		}
+	}
+
+	id, err := client.Text.Cmd("DATA")
+	if err != nil {
+		return "", err
+	}
+	client.Text.StartResponse(id)
+	_, _, err = client.Text.ReadResponse(354)
+	client.Text.EndResponse(id)
+	if err != nil {
+		return "", err
+	}
+
+	w := client.Text.DotWriter()
+	if _, err := w.Write(msg); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	code, message, err := client.Text.ReadResponse(250)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(code) + " " + message, nil
+}
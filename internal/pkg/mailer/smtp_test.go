@@ -0,0 +1,255 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSMTPServer implements enough of RFC 5321 to exercise the transport:
+// EHLO, AUTH LOGIN/CRAM-MD5, MAIL, RCPT, DATA, RSET and QUIT.
+type fakeSMTPServer struct {
+	listener net.Listener
+	username string
+	password string
+
+	mu          sync.Mutex
+	connections int
+	messages    []string
+	rcpts       [][]string
+}
+
+func newFakeSMTPServer(t *testing.T, username, password string) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: l, username: username, password: password}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *fakeSMTPServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.connections++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err == nil
+	}
+	decode := func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+
+	reply("220 localhost ESMTP fake")
+	var rcpts []string
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH LOGIN CRAM-MD5")
+		case cmd == "AUTH LOGIN":
+			reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Username:")))
+			user, _ := readLine()
+			reply("334 %s", base64.StdEncoding.EncodeToString([]byte("Password:")))
+			pass, _ := readLine()
+			if decode(user) == s.username && decode(pass) == s.password {
+				reply("235 2.7.0 Authentication successful")
+			} else {
+				reply("535 5.7.8 Authentication credentials invalid")
+			}
+		case cmd == "AUTH CRAM-MD5":
+			challenge := "<123.456@localhost>"
+			reply("334 %s", base64.StdEncoding.EncodeToString([]byte(challenge)))
+			resp, _ := readLine()
+			mac := hmac.New(md5.New, []byte(s.password))
+			mac.Write([]byte(challenge))
+			if decode(resp) == s.username+" "+hex.EncodeToString(mac.Sum(nil)) {
+				reply("235 2.7.0 Authentication successful")
+			} else {
+				reply("535 5.7.8 Authentication credentials invalid")
+			}
+		case strings.HasPrefix(cmd, "MAIL FROM:"), cmd == "RSET", cmd == "NOOP":
+			rcpts = nil
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			addr := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if strings.HasPrefix(addr, "reject") {
+				reply("550 5.1.1 No such user")
+				continue
+			}
+			rcpts = append(rcpts, addr)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, ok := readLine()
+				if !ok || l == "." {
+					break
+				}
+				data.WriteString(l + "\r\n")
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.rcpts = append(s.rcpts, rcpts)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) stats() (connections int, messages []string, rcpts [][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, append([]string(nil), s.messages...), append([][]string(nil), s.rcpts...)
+}
+
+func TestSMTPTransport_Send(t *testing.T) {
+	msg := []byte("Subject: Hello\r\n\r\nHi there\r\n")
+
+	for _, auth := range []string{AuthLogin, AuthCRAMMD5} {
+		t.Run("reuses an authenticated connection with "+auth, func(t *testing.T) {
+			server := newFakeSMTPServer(t, "user", "secret")
+			transport, err := NewSMTPTransport(SMTPConfig{
+				Host:     "localhost",
+				Port:     server.port(),
+				Username: "user",
+				Password: "secret",
+				Security: SecurityNone,
+				Auth:     auth,
+				PoolSize: 1,
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer transport.Close()
+
+			ctx := context.Background()
+			assert.NoError(t, transport.Send(ctx, "app@example.com", []string{"a@example.com", "b@example.com"}, msg))
+			response, err := transport.SendWithResponse(ctx, "app@example.com", []string{"c@example.com"}, msg)
+			assert.NoError(t, err)
+			assert.Equal(t, "250 OK queued", response)
+
+			connections, messages, rcpts := server.stats()
+			assert.Equal(t, 1, connections)
+			assert.Len(t, messages, 2)
+			assert.Contains(t, messages[0], "Hi there")
+			assert.Equal(t, [][]string{{"a@example.com", "b@example.com"}, {"c@example.com"}}, rcpts)
+		})
+	}
+
+	t.Run("wrong credentials", func(t *testing.T) {
+		server := newFakeSMTPServer(t, "user", "secret")
+		transport, _ := NewSMTPTransport(SMTPConfig{
+			Host: "localhost", Port: server.port(), Username: "user", Password: "wrong",
+			Security: SecurityNone, Auth: AuthLogin,
+		})
+		defer transport.Close()
+
+		err := transport.Send(context.Background(), "app@example.com", []string{"a@example.com"}, msg)
+
+		assert.ErrorContains(t, err, "authentication failed")
+	})
+
+	t.Run("rejected recipient drops the connection", func(t *testing.T) {
+		server := newFakeSMTPServer(t, "", "")
+		transport, _ := NewSMTPTransport(SMTPConfig{
+			Host: "localhost", Port: server.port(), Security: SecurityNone, Auth: AuthNone, PoolSize: 1,
+		})
+		defer transport.Close()
+		ctx := context.Background()
+
+		err := transport.Send(ctx, "app@example.com", []string{"reject@example.com"}, msg)
+		assert.ErrorContains(t, err, "reject@example.com rejected")
+
+		assert.NoError(t, transport.Send(ctx, "app@example.com", []string{"a@example.com"}, msg))
+		connections, _, _ := server.stats()
+		assert.Equal(t, 2, connections)
+	})
+
+	t.Run("STARTTLS is required unless disabled", func(t *testing.T) {
+		server := newFakeSMTPServer(t, "", "")
+		transport, _ := NewSMTPTransport(SMTPConfig{Host: "localhost", Port: server.port(), Auth: AuthNone})
+		defer transport.Close()
+
+		err := transport.Send(context.Background(), "app@example.com", []string{"a@example.com"}, msg)
+
+		assert.ErrorContains(t, err, "does not support STARTTLS")
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		server := newFakeSMTPServer(t, "", "")
+		transport, _ := NewSMTPTransport(SMTPConfig{
+			Host: "localhost", Port: server.port(), Security: SecurityNone, Auth: AuthNone,
+		})
+		defer transport.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		time.Sleep(time.Millisecond)
+
+		err := transport.Send(ctx, "app@example.com", []string{"a@example.com"}, msg)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestNewSMTPTransport_Validation(t *testing.T) {
+	_, err := NewSMTPTransport(SMTPConfig{Host: "smtp.example.com", Port: "587", Security: "ssl"})
+	assert.ErrorContains(t, err, "unknown SMTP security mode")
+
+	_, err = NewSMTPTransport(SMTPConfig{Host: "smtp.example.com", Port: "587", Auth: "xoauth2"})
+	assert.ErrorContains(t, err, "unknown SMTP auth mechanism")
+}
+
+func TestLoginAuth_RefusesUnencryptedRemoteServer(t *testing.T) {
+	auth, _ := newAuth(AuthLogin, "user", "secret", "smtp.example.com")
+
+	_, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com"})
+
+	assert.ErrorContains(t, err, "unencrypted connection")
+}
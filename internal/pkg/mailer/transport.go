@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrClosed is returned when sending through a transport that has been closed
+var ErrClosed = errors.New("mailer: transport closed")
+
+// Transport delivers fully built RFC 5322 messages
+type Transport interface {
+	// Send delivers msg from the envelope sender to the envelope recipients.
+	// Addresses are bare (user@host) and msg must use CRLF line endings.
+	Send(ctx context.Context, from string, to []string, msg []byte) error
+	// Close releases resources such as pooled connections
+	Close() error
+}
+
+// Responder is implemented by transports that report how a message was
+// accepted, such as the final reply of the SMTP server
+type Responder interface {
+	// SendWithResponse delivers msg like Transport.Send and returns the response
+	SendWithResponse(ctx context.Context, from string, to []string, msg []byte) (string, error)
+}
+
+// Deliver sends msg through t and returns the transport's response, or ""
+// when t does not report one
+func Deliver(ctx context.Context, t Transport, from string, to []string, msg []byte) (string, error) {
+	if r, ok := t.(Responder); ok {
+		return r.SendWithResponse(ctx, from, to, msg)
+	}
+	return "", t.Send(ctx, from, to, msg)
+}
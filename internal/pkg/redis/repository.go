@@ -9,6 +9,9 @@ import (
 type Repository interface {
 	// Set sets a key-value pair with an expiration time
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// SetNX sets a key-value pair with an expiration time unless the key exists,
+	// and reports whether it was set
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 	// Get retrieves a value by key
 	Get(ctx context.Context, key string) (string, error)
 	// Delete removes a key
@@ -36,6 +39,11 @@ func (r *redisRepository) Set(ctx context.Context, key string, value interface{}
 	return r.client.client.Set(ctx, key, value, expiration).Err()
 }
 
+// SetNX sets a key-value pair with an expiration time unless the key exists
+func (r *redisRepository) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 // Get retrieves a value by key
 func (r *redisRepository) Get(ctx context.Context, key string) (string, error) {
 	return r.client.client.Get(ctx, key).Result()
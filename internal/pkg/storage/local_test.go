@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStore_PutGetDelete(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "http://localhost:8080/api/v1/files", "secret")
+	if !assert.NoError(t, err) {
+		return
+	}
+	ctx := context.Background()
+
+	err = store.Put(ctx, "avatars/1/a.png", strings.NewReader("data"), 4, "image/png")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	blob, err := store.Get(ctx, "avatars/1/a.png")
+	if !assert.NoError(t, err) {
+		return
+	}
+	content, _ := io.ReadAll(blob)
+	blob.Close()
+	assert.Equal(t, "data", string(content))
+
+	assert.NoError(t, store.Delete(ctx, "avatars/1/a.png"))
+	assert.NoError(t, store.Delete(ctx, "avatars/1/a.png"), "deleting a missing blob is not an error")
+
+	_, err = store.Get(ctx, "avatars/1/a.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStore_RejectsTraversal(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "http://localhost", "secret")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = store.Put(context.Background(), "../escape", strings.NewReader("x"), 1, "text/plain")
+	assert.Error(t, err)
+}
+
+func TestLocalStore_SignedURL(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "http://localhost:8080/api/v1/files/", "secret")
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+
+	signed, err := store.SignedURL(context.Background(), "avatars/1/a_64.png", time.Minute)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	u, err := url.Parse(signed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "/api/v1/files/avatars/1/a_64.png", u.Path)
+
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+	assert.NoError(t, store.VerifySignature("avatars/1/a_64.png", expires, signature))
+	assert.ErrorIs(t, store.VerifySignature("avatars/1/a_128.png", expires, signature), ErrInvalidSignature)
+	assert.ErrorIs(t, store.VerifySignature("avatars/1/a_64.png", expires, "bad"), ErrInvalidSignature)
+
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, store.VerifySignature("avatars/1/a_64.png", expires, signature), ErrInvalidSignature)
+}
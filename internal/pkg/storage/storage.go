@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("storage: blob not found")
+
+// ErrInvalidSignature is returned when a signed URL fails verification
+var ErrInvalidSignature = errors.New("storage: invalid or expired signature")
+
+// BlobStore defines the interface for object storage backends
+type BlobStore interface {
+	// Put stores the content of r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that grants read access to key until ttl elapses
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
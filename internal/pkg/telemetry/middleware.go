@@ -50,14 +50,16 @@ func Middleware(serviceName string) gin.HandlerFunc {
 			attribute.String("http.client_ip", c.ClientIP()),
 		}
 
-		// Create a new context with the span
-		_, span := tracer.Start(
+		// Create a new context with the span and hand it down, so that outbound
+		// calls made while handling the request continue the trace
+		ctx, span := tracer.Start(
 			ctx,
 			spanName,
 			trace.WithSpanKind(trace.SpanKindServer),
 			trace.WithAttributes(attrs...),
 		)
 		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
 
 		// Store the span in the context
 		c.Set(tracerKey, span)
@@ -0,0 +1,74 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader is the response header carrying the trace ID of a request
+const TraceIDHeader = "X-Trace-ID"
+
+// TraceID returns the trace ID of the span context in ctx, the single ID correlating
+// spans, HTTP logs, log records and responses, or "" outside of a trace
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// NewTraceID returns a random W3C trace ID
+func NewTraceID() trace.TraceID {
+	var id trace.TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// ContextWithTraceID returns a copy of ctx in the trace with the given ID. It is the
+// parent of the spans started with the context, as if received in a traceparent.
+func ContextWithTraceID(ctx context.Context, traceID trace.TraceID) context.Context {
+	var spanID trace.SpanID
+	for !spanID.IsValid() {
+		_, _ = rand.Read(spanID[:])
+	}
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	}))
+}
+
+// EnsureTraceID returns ctx if it is in a trace, or a copy of ctx in a new trace otherwise
+func EnsureTraceID(ctx context.Context) context.Context {
+	if TraceID(ctx) != "" {
+		return ctx
+	}
+	return ContextWithTraceID(ctx, NewTraceID())
+}
+
+// TraceContext returns a gin middleware that puts each request in a trace: the one of
+// its W3C traceparent header, the one of an X-Trace-ID header holding a trace ID, or a
+// new one. It runs whether tracing is enabled or not, so that the trace ID is always
+// there to correlate the request, and returns it in the X-Trace-ID header.
+func TraceContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := propagation.TraceContext{}.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		if TraceID(ctx) == "" {
+			traceID, err := trace.TraceIDFromHex(c.GetHeader(TraceIDHeader))
+			if err != nil {
+				traceID = NewTraceID()
+			}
+			ctx = ContextWithTraceID(ctx, traceID)
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(TraceIDHeader, TraceID(ctx))
+		c.Next()
+	}
+}
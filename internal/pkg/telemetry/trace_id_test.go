@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"base-code-go-gin-clean/test"
+)
+
+func TestTraceContext(t *testing.T) {
+	var traceID string
+	router := test.SetupTestRouter()
+	router.Use(TraceContext())
+	router.GET("/ping", func(c *gin.Context) {
+		traceID = TraceID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	send := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/ping", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("continues the trace of the traceparent", func(t *testing.T) {
+		resp := send(map[string]string{
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			TraceIDHeader: "0af7651916cd43dd8448eb211c80319c",
+		})
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+		assert.Equal(t, traceID, resp.Header().Get(TraceIDHeader))
+	})
+
+	t.Run("accepts a trace ID in X-Trace-ID", func(t *testing.T) {
+		resp := send(map[string]string{TraceIDHeader: "0af7651916cd43dd8448eb211c80319c"})
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", traceID)
+		assert.Equal(t, traceID, resp.Header().Get(TraceIDHeader))
+	})
+
+	t.Run("starts a trace otherwise", func(t *testing.T) {
+		resp := send(map[string]string{TraceIDHeader: "not-a-trace-id"})
+		assert.Len(t, traceID, 32)
+		assert.NotEqual(t, "0af7651916cd43dd8448eb211c80319c", traceID)
+		assert.Equal(t, traceID, resp.Header().Get(TraceIDHeader))
+	})
+}
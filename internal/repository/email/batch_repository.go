@@ -0,0 +1,189 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// recipientInsertChunk bounds the rows per INSERT so large batches stay below
+// the bind parameter limit of Postgres
+const recipientInsertChunk = 1000
+
+type batchRepository struct {
+	db *bun.DB
+}
+
+func NewBatchRepository(db *bun.DB) email.BatchRepository {
+	return &batchRepository{
+		db: db,
+	}
+}
+
+func (r *batchRepository) Create(ctx context.Context, batch *email.Batch, recipients []*email.BatchRecipient) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(batch).
+			Returning("id, created_at, updated_at").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, rcpt := range recipients {
+			rcpt.BatchID = batch.ID
+		}
+		for start := 0; start < len(recipients); start += recipientInsertChunk {
+			chunk := recipients[start:min(start+recipientInsertChunk, len(recipients))]
+			if _, err := tx.NewInsert().Model(&chunk).Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *batchRepository) GetByID(ctx context.Context, id uuid.UUID) (*email.Batch, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	batch := new(email.Batch)
+	err := r.db.NewSelect().
+		Model(batch).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *batchRepository) Cancel(ctx context.Context, id uuid.UUID, at time.Time) (*email.Batch, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	// The status condition makes cancelling and claiming mutually exclusive
+	batch := new(email.Batch)
+	err := r.db.NewUpdate().
+		Model(batch).
+		Set("status = ?", email.BatchCancelled).
+		Set("cancelled_at = ?", at).
+		Set("updated_at = ?", at).
+		Where("id = ?", id).
+		Where("status = ?", email.BatchScheduled).
+		Returning("*").
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (r *batchRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*email.Batch, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	due := r.db.NewSelect().
+		Model((*email.Batch)(nil)).
+		Column("id").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.
+				WhereOr("status = ? AND send_at <= ?", email.BatchScheduled, now).
+				WhereOr("status = ? AND locked_until <= ?", email.BatchDispatching, now)
+		}).
+		Order("send_at ASC").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	var batches []*email.Batch
+	err := r.db.NewUpdate().
+		Model((*email.Batch)(nil)).
+		Set("status = ?", email.BatchDispatching).
+		Set("locked_until = ?", leaseUntil).
+		Set("dispatch_started_at = COALESCE(dispatch_started_at, ?)", now).
+		Set("updated_at = ?", now).
+		Where("id IN (?)", due).
+		Returning("*").
+		Scan(ctx, &batches)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+func (r *batchRepository) Update(ctx context.Context, batch *email.Batch) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	batch.UpdatedAt = time.Now()
+	_, err := r.db.NewUpdate().
+		Model(batch).
+		Column("status", "queued", "failed", "locked_until", "dispatched_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *batchRepository) ListRecipients(ctx context.Context, batchID uuid.UUID) ([]*email.BatchRecipient, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var recipients []*email.BatchRecipient
+	err := r.db.NewSelect().
+		Model(&recipients).
+		Where("batch_id = ?", batchID).
+		Order("position ASC").
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+func (r *batchRepository) UpdateRecipient(ctx context.Context, recipient *email.BatchRecipient) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewUpdate().
+		Model(recipient).
+		Column("status", "message_id", "error").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
@@ -0,0 +1,183 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type messageLogRepository struct {
+	db *bun.DB
+}
+
+func NewMessageLogRepository(db *bun.DB) email.MessageLogRepository {
+	return &messageLogRepository{
+		db: db,
+	}
+}
+
+func (r *messageLogRepository) Create(ctx context.Context, entry *email.MessageLog) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewInsert().
+		Model(entry).
+		Returning("created_at, updated_at").
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *messageLogRepository) UpdateDelivery(ctx context.Context, entry *email.MessageLog) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	entry.UpdatedAt = time.Now()
+	res, err := r.db.NewUpdate().
+		Model(entry).
+		Column("status", "attempts", "provider_response", "last_error", "sent_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err == nil {
+		err = expectOneRow(res)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *messageLogRepository) RecordEvent(ctx context.Context, id uuid.UUID, event email.TrackingEvent, at time.Time) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	query := r.db.NewUpdate().
+		Model((*email.MessageLog)(nil)).
+		Where("id = ?", id)
+	switch event {
+	case email.TrackingOpen:
+		query = query.
+			Set("opens = opens + 1").
+			Set("first_opened_at = COALESCE(first_opened_at, ?)", at).
+			Set("last_opened_at = ?", at)
+	case email.TrackingClick:
+		query = query.
+			Set("clicks = clicks + 1").
+			Set("first_clicked_at = COALESCE(first_clicked_at, ?)", at).
+			Set("last_clicked_at = ?", at)
+	default:
+		return fmt.Errorf("unknown tracking event %q", event)
+	}
+
+	res, err := query.Exec(ctx)
+	if err == nil {
+		err = expectOneRow(res)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *messageLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*email.MessageLog, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	entry := new(email.MessageLog)
+	err := r.db.NewSelect().
+		Model(entry).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *messageLogRepository) List(ctx context.Context, filter email.MessageLogFilter) ([]*email.MessageLog, int, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var entries []*email.MessageLog
+	query := r.db.NewSelect().
+		Model(&entries).
+		Order("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset)
+	if filter.Template != "" {
+		query = query.Where("template = ?", filter.Template)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Recipient != "" {
+		query = query.Where("recipients @> ARRAY[?]::text[]", filter.Recipient)
+	}
+	if filter.TraceID != "" {
+		query = query.Where("trace_id = ?", filter.TraceID)
+	}
+	query = whereCreatedBetween(query, filter.Since, filter.Until)
+
+	total, err := query.ScanAndCount(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func (r *messageLogRepository) Stats(ctx context.Context, since, until *time.Time) ([]*email.TemplateStats, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var stats []*email.TemplateStats
+	query := r.db.NewSelect().
+		Model((*email.MessageLog)(nil)).
+		ColumnExpr("COALESCE(template, '') AS template").
+		ColumnExpr("COUNT(*) AS total").
+		ColumnExpr("COUNT(*) FILTER (WHERE status = ?) AS sent", email.MessageSent).
+		ColumnExpr("COUNT(*) FILTER (WHERE status IN (?)) AS pending", bun.In([]email.MessageStatus{email.MessageQueued, email.MessageSending})).
+		ColumnExpr("COUNT(*) FILTER (WHERE status = ?) AS dead", email.MessageDead).
+		ColumnExpr("COUNT(*) FILTER (WHERE status = ?) AS suppressed", email.MessageSuppressed).
+		ColumnExpr("COUNT(*) FILTER (WHERE tracked AND status = ?) AS tracked", email.MessageSent).
+		ColumnExpr("COUNT(*) FILTER (WHERE tracked AND first_opened_at IS NOT NULL) AS opened").
+		ColumnExpr("COUNT(*) FILTER (WHERE tracked AND first_clicked_at IS NOT NULL) AS clicked").
+		GroupExpr("COALESCE(template, '')").
+		OrderExpr("total DESC")
+	query = whereCreatedBetween(query, since, until)
+
+	if err := query.Scan(ctx, &stats); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func whereCreatedBetween(query *bun.SelectQuery, since, until *time.Time) *bun.SelectQuery {
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+	if until != nil {
+		query = query.Where("created_at < ?", *until)
+	}
+	return query
+}
@@ -0,0 +1,108 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type outboxRepository struct {
+	db *bun.DB
+}
+
+func NewOutboxRepository(db *bun.DB) email.OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+func (r *outboxRepository) Create(ctx context.Context, msg *email.OutboxMessage) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewInsert().
+		Model(msg).
+		Returning("id, created_at, updated_at").
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *outboxRepository) GetByID(ctx context.Context, id uuid.UUID) (*email.OutboxMessage, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	msg := new(email.OutboxMessage)
+	err := r.db.NewSelect().
+		Model(msg).
+		Where("id = ?", id).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func (r *outboxRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*email.OutboxMessage, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	// SKIP LOCKED lets several workers (and several app instances) poll the
+	// same table without handing out a message twice.
+	due := r.db.NewSelect().
+		Model((*email.OutboxMessage)(nil)).
+		Column("id").
+		Where("status IN (?)", bun.In([]email.MessageStatus{email.MessageQueued, email.MessageSending})).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	var msgs []*email.OutboxMessage
+	err := r.db.NewUpdate().
+		Model((*email.OutboxMessage)(nil)).
+		Set("status = ?", email.MessageSending).
+		Set("attempts = attempts + 1").
+		Set("next_attempt_at = ?", leaseUntil).
+		Set("updated_at = ?", now).
+		Where("id IN (?)", due).
+		Returning("*").
+		Scan(ctx, &msgs)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return msgs, nil
+}
+
+func (r *outboxRepository) Update(ctx context.Context, msg *email.OutboxMessage) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	msg.UpdatedAt = time.Now()
+	_, err := r.db.NewUpdate().
+		Model(msg).
+		Column("status", "next_attempt_at", "last_error", "sent_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
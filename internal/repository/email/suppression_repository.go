@@ -0,0 +1,108 @@
+package email
+
+import (
+	"context"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/uptrace/bun"
+)
+
+type suppressionRepository struct {
+	db *bun.DB
+}
+
+func NewSuppressionRepository(db *bun.DB) email.SuppressionRepository {
+	return &suppressionRepository{
+		db: db,
+	}
+}
+
+func (r *suppressionRepository) Upsert(ctx context.Context, s *email.Suppression) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	s.UpdatedAt = time.Now()
+	// An unsubscribe must not downgrade a bounce or complaint, which also block transactional mail
+	_, err := r.db.NewInsert().
+		Model(s).
+		On("CONFLICT (email) DO UPDATE").
+		Set("reason = EXCLUDED.reason").
+		Set("detail = EXCLUDED.detail").
+		Set("updated_at = EXCLUDED.updated_at").
+		Where("es.reason = ? OR EXCLUDED.reason <> ?", email.SuppressionUnsubscribed, email.SuppressionUnsubscribed).
+		Returning("id, created_at").
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *suppressionRepository) FindByEmails(ctx context.Context, emails []string) ([]*email.Suppression, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var suppressions []*email.Suppression
+	if len(emails) == 0 {
+		return suppressions, nil
+	}
+
+	err := r.db.NewSelect().
+		Model(&suppressions).
+		Where("email IN (?)", bun.In(emails)).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return suppressions, nil
+}
+
+func (r *suppressionRepository) List(ctx context.Context, reason email.SuppressionReason, limit, offset int) ([]*email.Suppression, int, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var suppressions []*email.Suppression
+	query := r.db.NewSelect().
+		Model(&suppressions).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset)
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+
+	total, err := query.ScanAndCount(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	return suppressions, total, nil
+}
+
+func (r *suppressionRepository) Delete(ctx context.Context, address string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	res, err := r.db.NewDelete().
+		Model((*email.Suppression)(nil)).
+		Where("email = ?", address).
+		Exec(ctx)
+	if err == nil {
+		err = expectOneRow(res)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
@@ -0,0 +1,201 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/uptrace/bun"
+)
+
+type templateRepository struct {
+	db *bun.DB
+}
+
+func NewTemplateRepository(db *bun.DB) email.TemplateRepository {
+	return &templateRepository{
+		db: db,
+	}
+}
+
+func (r *templateRepository) CreateVersion(ctx context.Context, v *email.TemplateVersion) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	// The unique (name, version) index turns a concurrent create into an error
+	// rather than a duplicate version number.
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var latest sql.NullInt64
+		err := tx.NewSelect().
+			Model((*email.TemplateVersion)(nil)).
+			ColumnExpr("MAX(version)").
+			Where("name = ?", v.Name).
+			Scan(ctx, &latest)
+		if err != nil {
+			return err
+		}
+
+		v.Version = int(latest.Int64) + 1
+		_, err = tx.NewInsert().
+			Model(v).
+			Returning("id, created_at, updated_at").
+			Exec(ctx)
+		return err
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *templateRepository) GetVersion(ctx context.Context, name string, version int) (*email.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	v := new(email.TemplateVersion)
+	err := r.db.NewSelect().
+		Model(v).
+		Where("name = ?", name).
+		Where("version = ?", version).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *templateRepository) GetPublished(ctx context.Context, name string) (*email.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	v := new(email.TemplateVersion)
+	err := r.db.NewSelect().
+		Model(v).
+		Where("name = ?", name).
+		Where("status = ?", email.TemplatePublished).
+		Scan(ctx)
+
+	if err != nil {
+		// No published version is the common case, not a failure
+		if err != sql.ErrNoRows {
+			span.RecordError(err)
+		}
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (r *templateRepository) ListVersions(ctx context.Context, name string) ([]*email.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var versions []*email.TemplateVersion
+	err := r.db.NewSelect().
+		Model(&versions).
+		Where("name = ?", name).
+		Order("version DESC").
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (r *templateRepository) UpdateDraft(ctx context.Context, v *email.TemplateVersion) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	v.UpdatedAt = time.Now()
+	res, err := r.db.NewUpdate().
+		Model(v).
+		Column("subject", "html_body", "text_body", "updated_at").
+		WherePK().
+		Where("status = ?", email.TemplateDraft).
+		Exec(ctx)
+	if err == nil {
+		err = expectOneRow(res)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *templateRepository) Publish(ctx context.Context, name string, version int, at time.Time) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := archivePublished(ctx, tx, name, at); err != nil {
+			return err
+		}
+
+		res, err := tx.NewUpdate().
+			Model((*email.TemplateVersion)(nil)).
+			Set("status = ?", email.TemplatePublished).
+			Set("published_at = ?", at).
+			Set("updated_at = ?", at).
+			Where("name = ?", name).
+			Where("version = ?", version).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return expectOneRow(res)
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *templateRepository) Unpublish(ctx context.Context, name string, at time.Time) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	err := archivePublished(ctx, r.db, name, at)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func archivePublished(ctx context.Context, db bun.IDB, name string, at time.Time) error {
+	_, err := db.NewUpdate().
+		Model((*email.TemplateVersion)(nil)).
+		Set("status = ?", email.TemplateArchived).
+		Set("updated_at = ?", at).
+		Where("name = ?", name).
+		Where("status = ?", email.TemplatePublished).
+		Exec(ctx)
+	return err
+}
+
+// expectOneRow maps an update that matched nothing to sql.ErrNoRows
+func expectOneRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package preference
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/preference"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+type preferenceRepository struct {
+	db *bun.DB
+}
+
+func NewPreferenceRepository(db *bun.DB) preference.Repository {
+	return &preferenceRepository{
+		db: db,
+	}
+}
+
+func (r *preferenceRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*preference.UserPreferences, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	prefs := new(preference.UserPreferences)
+	err := r.db.NewSelect().
+		Model(prefs).
+		Where("user_id = ?", userID).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+func (r *preferenceRepository) Merge(ctx context.Context, userID uuid.UUID, set map[string]json.RawMessage, reset []string, now time.Time) (map[string]json.RawMessage, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	// The returned settings are scanned into a copy of the ones set
+	settings := maps.Clone(set)
+	if settings == nil {
+		settings = map[string]json.RawMessage{}
+	}
+	if reset == nil {
+		reset = []string{}
+	}
+
+	prefs := &preference.UserPreferences{
+		UserID:    userID,
+		Settings:  settings,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err := r.db.NewInsert().
+		Model(prefs).
+		On("CONFLICT (user_id) DO UPDATE").
+		Set("settings = (up.settings || EXCLUDED.settings) - ?::text[]", pgdialect.Array(reset)).
+		Set("updated_at = EXCLUDED.updated_at").
+		Returning("settings").
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return prefs.Settings, nil
+}
+
+func (r *preferenceRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewDelete().
+		Model((*preference.UserPreferences)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
@@ -0,0 +1,156 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/privacy"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+type deletionRequestRepository struct {
+	db *bun.DB
+}
+
+func NewDeletionRequestRepository(db *bun.DB) privacy.DeletionRequestRepository {
+	return &deletionRequestRepository{
+		db: db,
+	}
+}
+
+func (r *deletionRequestRepository) Create(ctx context.Context, req *privacy.DeletionRequest) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewInsert().
+		Model(req).
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *deletionRequestRepository) GetPendingByUserID(ctx context.Context, userID uuid.UUID) (*privacy.DeletionRequest, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	req := new(privacy.DeletionRequest)
+	err := r.db.NewSelect().
+		Model(req).
+		Where("user_id = ?", userID).
+		Where("status = ?", privacy.DeletionPending).
+		Order("created_at DESC").
+		Limit(1).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *deletionRequestRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*privacy.DeletionRequest, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var reqs []*privacy.DeletionRequest
+	err := r.db.NewSelect().
+		Model(&reqs).
+		Where("status = ?", privacy.DeletionPending).
+		Where("scheduled_for <= ?", before).
+		Order("scheduled_for ASC").
+		Limit(limit).
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return reqs, nil
+}
+
+func (r *deletionRequestRepository) Update(ctx context.Context, req *privacy.DeletionRequest) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	req.UpdatedAt = time.Now()
+	result, err := r.db.NewUpdate().
+		Model(req).
+		Column("status", "cancelled_at", "completed_at", "updated_at").
+		WherePK().
+		Where("status = ?", privacy.DeletionPending).
+		Exec(ctx)
+	if err == nil {
+		err = requireRow(result)
+	}
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *deletionRequestRepository) Process(ctx context.Context, id uuid.UUID, fn func(ctx context.Context, req *privacy.DeletionRequest) error) (bool, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	claimed := false
+	err := r.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		req := new(privacy.DeletionRequest)
+		err := tx.NewSelect().
+			Model(req).
+			Where("id = ?", id).
+			Where("status = ?", privacy.DeletionPending).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		claimed = true
+
+		if err := fn(ctx, req); err != nil {
+			return err
+		}
+
+		req.UpdatedAt = time.Now()
+		_, err = tx.NewUpdate().
+			Model(req).
+			Column("status", "cancelled_at", "completed_at", "updated_at").
+			WherePK().
+			Exec(ctx)
+		return err
+	})
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return claimed, err
+}
+
+// requireRow returns sql.ErrNoRows when a statement changed no row
+func requireRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package user
+
+import (
+	"context"
+	"strings"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/uptrace/bun"
+)
+
+type userBulkRepository struct {
+	db *bun.DB
+}
+
+func NewUserBulkRepository(db *bun.DB) user.BulkRepository {
+	return &userBulkRepository{
+		db: db,
+	}
+}
+
+func (r *userBulkRepository) ExistingUsers(ctx context.Context, emails []string) (map[string]*user.User, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	existing := make(map[string]*user.User, len(emails))
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	var found []*user.User
+	err := r.db.NewSelect().
+		Model(&found).
+		Column("id", "email", "status", "deleted_at").
+		Where("email IN (?)", bun.In(emails)).
+		WhereAllWithDeleted().
+		Scan(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	for _, u := range found {
+		existing[u.Email] = u
+	}
+
+	return existing, nil
+}
+
+func (r *userBulkRepository) UpsertBatch(ctx context.Context, users []*user.User) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	// A row without a status change time keeps the status of the existing user
+	const keepStatus = "EXCLUDED.status_changed_at IS NULL OR EXCLUDED.status = u.status"
+
+	_, err := r.db.NewInsert().
+		Model(&users).
+		On("CONFLICT (email) DO UPDATE").
+		Set("name = EXCLUDED.name").
+		Set("status = CASE WHEN "+keepStatus+" THEN u.status ELSE EXCLUDED.status END").
+		Set("suspension_reason = CASE WHEN "+keepStatus+" THEN u.suspension_reason END").
+		Set("suspended_until = CASE WHEN "+keepStatus+" THEN u.suspended_until END").
+		Set("status_changed_at = CASE WHEN "+keepStatus+" THEN u.status_changed_at ELSE EXCLUDED.status_changed_at END").
+		Set("password = CASE WHEN EXCLUDED.password = ? THEN u.password ELSE EXCLUDED.password END", user.UnsetPassword).
+		Set("updated_at = EXCLUDED.updated_at").
+		Where("u.deleted_at IS NULL").
+		Returning("NULL").
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *userBulkRepository) Stream(ctx context.Context, filter user.ExportFilter, fn func(*user.User) error) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	query := r.db.NewSelect().
+		Model((*user.User)(nil)).
+		Order("created_at ASC", "id ASC")
+
+	if filter.EmailContains != "" {
+		query = query.Where("email ILIKE ?", "%"+escapeLike(filter.EmailContains)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	rows, err := query.Rows(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u := new(user.User)
+		if err := r.db.ScanRow(ctx, rows, u); err != nil {
+			span.RecordError(err)
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
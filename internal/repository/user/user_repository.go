@@ -70,3 +70,71 @@ func (r *userRepository) Create(ctx context.Context, user *user.User) error {
 
 	return err
 }
+
+func (r *userRepository) UpdateStatus(ctx context.Context, user *user.User) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewUpdate().
+		Model(user).
+		Column("status", "suspension_reason", "suspended_until", "status_changed_at", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *userRepository) UpdateAvatar(ctx context.Context, user *user.User) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewUpdate().
+		Model(user).
+		Column("avatar_key", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, user *user.User) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewUpdate().
+		Model(user).
+		Column("role", "updated_at").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, user *user.User) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	_, err := r.db.NewUpdate().
+		Model(user).
+		Column("name", "email", "password", "status", "suspension_reason", "suspended_until", "avatar_key", "status_changed_at", "updated_at", "deleted_at").
+		WherePK().
+		Exec(ctx)
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return err
+}
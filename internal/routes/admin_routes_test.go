@@ -0,0 +1,56 @@
+package routes_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/handler"
+	"base-code-go-gin-clean/internal/routes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// noRoles is a role checker for users without any role
+type noRoles struct{}
+
+func (noRoles) EnsureUserRole(ctx context.Context, userID string, role user.Role) error {
+	return user.ErrInsufficientRole
+}
+
+func TestAdminRoutesRequireAdminRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	public := router.Group("/api/v1")
+	protected := router.Group("/api/v1", func(c *gin.Context) {
+		c.Set("userID", "123")
+	})
+
+	roleChecker := noRoles{}
+	routes.SetupUserRoutes(protected, &handler.UserHandler{}, roleChecker)
+	routes.SetupUserBulkRoutes(protected, &handler.UserBulkHandler{}, 1<<20, roleChecker)
+	routes.SetupEmailTemplateRoutes(protected, &handler.EmailTemplateHandler{}, roleChecker)
+	routes.SetupEmailSuppressionRoutes(public, protected, &handler.EmailSuppressionHandler{}, roleChecker)
+	routes.SetupEmailMessageLogRoutes(public, protected, &handler.EmailMessageLogHandler{}, roleChecker)
+	routes.SetupEmailBatchRoutes(protected, &handler.EmailBatchHandler{}, roleChecker)
+	routes.SetupHTTPLogRoutes(protected, &handler.HTTPLogHandler{}, roleChecker)
+
+	var adminRoutes int
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/v1/admin/") {
+			continue
+		}
+		adminRoutes++
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(route.Method, route.Path, nil))
+
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", route.Method, route.Path)
+	}
+	assert.Equal(t, 22, adminRoutes)
+}
@@ -10,12 +10,12 @@ import (
 )
 
 // SetupAuthRoutes configures all the authentication routes
-func SetupAuthRoutes(router *gin.RouterGroup, authHandler *auth.AuthHandler, tokenConfig *config.TokenConfig) {
+func SetupAuthRoutes(router *gin.RouterGroup, authHandler *auth.AuthHandler, tokenConfig *config.TokenConfig, statusChecker middleware.StatusChecker) {
 	// Initialize token service with configuration
 	tokenService := token.NewTokenService(tokenConfig)
 
 	// Initialize auth middleware with token service
-	authMiddleware := middleware.AuthMiddleware(tokenService)
+	authMiddleware := middleware.AuthMiddleware(tokenService, statusChecker)
 
 	// Public routes (no authentication required)
 	authGroup := router.Group("/auth")
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"base-code-go-gin-clean/internal/handler"
+	"base-code-go-gin-clean/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAvatarRoutes configures the avatar upload route.
+// Uploads larger than maxUploadBytes are rejected with 413.
+func SetupAvatarRoutes(protected *gin.RouterGroup, avatarHandler *handler.AvatarHandler, maxUploadBytes int64) {
+	protected.PUT("/users/me/avatar", middleware.BodyLimitMiddleware(maxUploadBytes), avatarHandler.UploadAvatar)
+}
+
+// SetupFileRoutes configures the route serving signed URLs from the local blob store.
+// The route is public because access is granted by the URL signature.
+func SetupFileRoutes(public *gin.RouterGroup, fileHandler *handler.FileHandler) {
+	public.GET("/files/*key", fileHandler.ServeFile)
+}
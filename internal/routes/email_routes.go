@@ -1,7 +1,10 @@
 package routes
 
 import (
+	"base-code-go-gin-clean/internal/domain/user"
 	"base-code-go-gin-clean/internal/handler"
+	"base-code-go-gin-clean/internal/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,5 +13,67 @@ func SetupEmailRoutes(router *gin.RouterGroup, emailHandler *handler.EmailHandle
 	emailGroup := router.Group("/email")
 	{
 		emailGroup.POST("/send", emailHandler.SendEmail)
+		emailGroup.POST("/send-template", emailHandler.SendTemplate)
+		emailGroup.GET("/messages/:id", emailHandler.GetMessage)
+		emailGroup.GET("/templates", emailHandler.ListTemplates)
+		emailGroup.POST("/templates/:name/preview", emailHandler.PreviewTemplate)
+	}
+}
+
+// SetupEmailTemplateRoutes configures the admin routes for editing stored email templates
+func SetupEmailTemplateRoutes(router *gin.RouterGroup, templateHandler *handler.EmailTemplateHandler, roleChecker middleware.RoleChecker) {
+	adminGroup := router.Group("/admin/email/templates")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.GET("/:name/versions", templateHandler.ListVersions)
+		adminGroup.POST("/:name/versions", templateHandler.CreateDraft)
+		adminGroup.PUT("/:name/versions/:version", templateHandler.UpdateDraft)
+		adminGroup.POST("/:name/versions/:version/preview", templateHandler.PreviewVersion)
+		adminGroup.POST("/:name/versions/:version/publish", templateHandler.Publish)
+		adminGroup.POST("/:name/rollback", templateHandler.Rollback)
+		adminGroup.DELETE("/:name/published", templateHandler.Unpublish)
+	}
+}
+
+// SetupEmailSuppressionRoutes configures unsubscribe links, the bounce webhook and
+// the admin suppression list. Unsubscribe links carry a signed token and the
+// webhook a shared secret, so both are public.
+func SetupEmailSuppressionRoutes(public, protected *gin.RouterGroup, suppressionHandler *handler.EmailSuppressionHandler, roleChecker middleware.RoleChecker) {
+	public.GET("/email/unsubscribe", suppressionHandler.ConfirmUnsubscribe)
+	public.POST("/email/unsubscribe", suppressionHandler.Unsubscribe)
+	public.POST("/email/webhooks/bounces", suppressionHandler.BounceWebhook)
+
+	adminGroup := protected.Group("/admin/email/suppressions")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.GET("", suppressionHandler.ListSuppressions)
+		adminGroup.POST("", suppressionHandler.AddSuppression)
+		adminGroup.DELETE("/:email", suppressionHandler.RemoveSuppression)
+	}
+}
+
+// SetupEmailMessageLogRoutes configures the open and click tracking endpoints,
+// which are public and verify their signed token, and the admin delivery log
+func SetupEmailMessageLogRoutes(public, protected *gin.RouterGroup, messageLogHandler *handler.EmailMessageLogHandler, roleChecker middleware.RoleChecker) {
+	public.GET("/email/track/open/:token", messageLogHandler.TrackOpen)
+	public.GET("/email/track/click/:token", messageLogHandler.TrackClick)
+
+	adminGroup := protected.Group("/admin/email")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.GET("/messages", messageLogHandler.ListMessages)
+		adminGroup.GET("/messages/:id", messageLogHandler.GetMessage)
+		adminGroup.GET("/stats", messageLogHandler.Stats)
+	}
+}
+
+// SetupEmailBatchRoutes configures the admin routes for scheduling and cancelling email batches
+func SetupEmailBatchRoutes(router *gin.RouterGroup, batchHandler *handler.EmailBatchHandler, roleChecker middleware.RoleChecker) {
+	adminGroup := router.Group("/admin/email/batches")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.POST("", batchHandler.CreateBatch)
+		adminGroup.GET("/:id", batchHandler.GetBatch)
+		adminGroup.POST("/:id/cancel", batchHandler.CancelBatch)
 	}
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/handler"
+	"base-code-go-gin-clean/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupHTTPLogRoutes configures the admin routes for searching the HTTP request logs
+func SetupHTTPLogRoutes(router *gin.RouterGroup, httpLogHandler *handler.HTTPLogHandler, roleChecker middleware.RoleChecker) {
+	adminGroup := router.Group("/admin/http-logs")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.GET("/requests", httpLogHandler.SearchRequests)
+		adminGroup.GET("/requests/:id", httpLogHandler.GetRequest)
+		adminGroup.GET("/traces/:trace_id", httpLogHandler.GetTrace)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"base-code-go-gin-clean/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPreferenceRoutes configures the user preference routes
+func SetupPreferenceRoutes(protected *gin.RouterGroup, preferenceHandler *handler.PreferenceHandler) {
+	protected.GET("/preferences/schema", preferenceHandler.GetSchema)
+	protected.GET("/users/me/preferences", preferenceHandler.GetPreferences)
+	protected.PUT("/users/me/preferences", preferenceHandler.UpdatePreferences)
+}
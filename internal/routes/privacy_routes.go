@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"base-code-go-gin-clean/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPrivacyRoutes configures data export and account deletion routes.
+// The download route is public because it is protected by the emailed token.
+func SetupPrivacyRoutes(public, protected *gin.RouterGroup, privacyHandler *handler.PrivacyHandler) {
+	public.GET("/privacy/exports/:token", privacyHandler.DownloadExport)
+
+	meGroup := protected.Group("/users/me")
+	{
+		meGroup.POST("/export", privacyHandler.RequestExport)
+		meGroup.POST("/deletion", privacyHandler.RequestDeletion)
+		meGroup.DELETE("/deletion", privacyHandler.CancelDeletion)
+	}
+}
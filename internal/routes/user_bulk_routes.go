@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/handler"
+	"base-code-go-gin-clean/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupUserBulkRoutes configures the admin bulk import and export routes
+func SetupUserBulkRoutes(router *gin.RouterGroup, bulkHandler *handler.UserBulkHandler, maxUploadBytes int64, roleChecker middleware.RoleChecker) {
+	adminGroup := router.Group("/admin/users")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.POST("/import", middleware.BodyLimitMiddleware(maxUploadBytes), bulkHandler.ImportUsers)
+		adminGroup.GET("/export", bulkHandler.ExportUsers)
+	}
+}
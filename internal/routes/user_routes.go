@@ -1,13 +1,22 @@
 package routes
 
 import (
+	"base-code-go-gin-clean/internal/domain/user"
 	"base-code-go-gin-clean/internal/handler"
+	"base-code-go-gin-clean/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupUserRoutes configures all the user routes
-func SetupUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler) {
+func SetupUserRoutes(router *gin.RouterGroup, userHandler *handler.UserHandler, roleChecker middleware.RoleChecker) {
 	// User routes under /api/v1/users
 	router.GET("/users/:id", userHandler.GetUserByID)
+
+	// Admin routes under /api/v1/admin/users
+	adminGroup := router.Group("/admin/users")
+	adminGroup.Use(middleware.RoleMiddleware(roleChecker, user.RoleAdmin))
+	{
+		adminGroup.PUT("/:id/status", userHandler.ChangeUserStatus)
+	}
 }
@@ -34,22 +34,62 @@ func (s *Server) setupRoutes(opts *ServerOptions) {
 		// If token config provided, apply auth middleware
 		if opts.TokenConfig != nil {
 			tokenService := token.NewTokenService(opts.TokenConfig)
-			protected.Use(middleware.AuthMiddleware(tokenService))
+			protected.Use(middleware.AuthMiddleware(tokenService, opts.StatusChecker))
 		}
 
 		// Setup user routes even if TokenConfig is nil
 		if opts.UserHandler != nil {
-			routes.SetupUserRoutes(protected, opts.UserHandler)
+			routes.SetupUserRoutes(protected, opts.UserHandler, opts.RoleChecker)
+		}
+
+		// Setup bulk user import/export routes
+		if opts.UserBulkHandler != nil {
+			routes.SetupUserBulkRoutes(protected, opts.UserBulkHandler, s.maxUploadBytes(), opts.RoleChecker)
+		}
+
+		// Setup privacy routes (export and account deletion)
+		if opts.PrivacyHandler != nil {
+			routes.SetupPrivacyRoutes(public, protected, opts.PrivacyHandler)
+		}
+
+		// Setup preference routes
+		if opts.PreferenceHandler != nil {
+			routes.SetupPreferenceRoutes(protected, opts.PreferenceHandler)
+		}
+
+		// Setup avatar upload and signed file routes
+		if opts.AvatarHandler != nil {
+			routes.SetupAvatarRoutes(protected, opts.AvatarHandler, s.maxUploadBytes())
+		}
+		if opts.FileHandler != nil {
+			routes.SetupFileRoutes(public, opts.FileHandler)
 		}
 
 		// Setup email routes
 		if opts.EmailHandler != nil {
 			routes.SetupEmailRoutes(apiV1, opts.EmailHandler)
 		}
+		if opts.EmailTemplateHandler != nil {
+			routes.SetupEmailTemplateRoutes(protected, opts.EmailTemplateHandler, opts.RoleChecker)
+		}
+		if opts.EmailSuppressionHandler != nil {
+			routes.SetupEmailSuppressionRoutes(public, protected, opts.EmailSuppressionHandler, opts.RoleChecker)
+		}
+		if opts.EmailMessageLogHandler != nil {
+			routes.SetupEmailMessageLogRoutes(public, protected, opts.EmailMessageLogHandler, opts.RoleChecker)
+		}
+		if opts.EmailBatchHandler != nil {
+			routes.SetupEmailBatchRoutes(protected, opts.EmailBatchHandler, opts.RoleChecker)
+		}
+
+		// Setup HTTP log search routes
+		if opts.HTTPLogHandler != nil {
+			routes.SetupHTTPLogRoutes(protected, opts.HTTPLogHandler, opts.RoleChecker)
+		}
 
 		// Setup auth routes (requires TokenConfig)
 		if opts.AuthHandler != nil && opts.TokenConfig != nil {
-			routes.SetupAuthRoutes(apiV1, opts.AuthHandler, opts.TokenConfig)
+			routes.SetupAuthRoutes(apiV1, opts.AuthHandler, opts.TokenConfig, opts.StatusChecker)
 		}
 	}
 }
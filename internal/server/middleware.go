@@ -17,8 +17,25 @@ import (
 
 // setupMiddlewares configures all middleware for the server
 func (s *Server) setupMiddlewares(db *bun.DB) {
-	// Add request size limiting middleware (10MB max)
-	s.router.MaxMultipartMemory = 10 << 20 // 10MB
+	// Limit multipart memory to the configured upload size (10MB by default)
+	s.router.MaxMultipartMemory = s.maxUploadBytes()
+
+	// Logs are queued and bulk inserted in the background so that logging
+	// adds no database round-trips to the request
+	var httpLogService domainhttplog.Service
+	redactor := s.httpLogRedactor()
+	if s.db != nil {
+		s.httpLogs = domainhttplog.NewAsyncRepository(domainhttplog.NewRepository(s.db), domainhttplog.AsyncConfig{
+			QueueSize:     s.config.HTTPLog.QueueSize,
+			BatchSize:     s.config.HTTPLog.BatchSize,
+			FlushInterval: time.Duration(s.config.HTTPLog.FlushMillis) * time.Millisecond,
+			Overflow:      domainhttplog.OverflowPolicy(s.config.HTTPLog.Overflow),
+		})
+		httpLogService = domainhttplog.NewService(s.httpLogs)
+	}
+
+	// Put every request in a trace, whose ID correlates its spans, logs and responses
+	s.router.Use(telemetry.TraceContext())
 
 	// Add Jaeger tracing middleware if configured
 	if s.config.Tracing.Enabled && s.config.Tracing.DSN != "" {
@@ -45,8 +62,9 @@ func (s *Server) setupMiddlewares(db *bun.DB) {
 	// Compression middleware
 	s.router.Use(gzip.Gzip(gzip.DefaultCompression))
 
-	// Recovery middleware
-	s.router.Use(gin.Recovery())
+	// Recovery middleware for panics outside of the handlers, written to log_errors
+	// with their stack trace
+	s.router.Use(pkghttplog.Recovery(httpLogService, redactor))
 
 	// CORS middleware
 	s.router.Use(middleware.CORS())
@@ -61,22 +79,29 @@ func (s *Server) setupMiddlewares(db *bun.DB) {
 	s.router.Use(TimeoutMiddleware(10 * time.Second))
 
 	// Only set up HTTP log middleware if we have a database connection
-	if s.db != nil {
-		httpLogRepo := domainhttplog.NewRepository(s.db)
-		httpLogService := domainhttplog.NewService(httpLogRepo)
-
+	if httpLogService != nil {
 		s.router.Use(pkghttplog.Middleware(pkghttplog.Config{
 			Service:             httpLogService,
-			SkipPaths:           []string{"/health", "/metrics"},
+			SkipPaths:           []string{"/health", "/metrics", "/api/*/health", "/swagger/*"},
 			SkipHeaders:         []string{"Authorization", "Cookie"},
 			SkipBodyMethods:     map[string]bool{"GET": true, "HEAD": true, "OPTIONS": true},
 			MaxBodySize:         1024 * 1024,
 			IncludeResponseBody: true,
+			Redactor:            redactor,
+			Policies:            s.httpLogPolicies(),
+			AlwaysLogErrors:     true,
+			SlowThreshold:       time.Duration(s.config.HTTPLog.SlowMillis) * time.Millisecond,
+			MaxRequestBodySize:  int64(s.config.HTTPLog.MaxRequestBodyKB) << 10,
+			MaxResponseBodySize: int64(s.config.HTTPLog.MaxResponseBodyKB) << 10,
 		}))
 	} else {
 		s.logger.Warn("No database connection available, HTTP logging will be disabled")
 	}
 
+	// Panics of handlers are logged with a reference to the request log, which then
+	// records the 500 response
+	s.router.Use(pkghttplog.Recovery(httpLogService, redactor))
+
 	// Add database connection to the Gin context
 	s.router.Use(func(c *gin.Context) {
 		c.Set("db_conn", db)
@@ -87,6 +112,52 @@ func (s *Server) setupMiddlewares(db *bun.DB) {
 	s.router.Use(dbutils.TransactionMiddleware(db))
 }
 
+// httpLogPolicies returns the logging policies of the routes: email tracking, hit by
+// every opened mail, is sampled, and only text bodies are logged
+func (s *Server) httpLogPolicies() []pkghttplog.Policy {
+	textBodies := []string{"application/json", "application/*+json", "application/x-www-form-urlencoded", "text/*"}
+	return []pkghttplog.Policy{
+		{
+			Routes:     []string{"/api/v1/email/track/open/:token", "/api/v1/email/track/click/:token"},
+			SampleRate: float64(s.config.HTTPLog.TrackingSamplePercent) / 100,
+			SkipBodies: true,
+		},
+		{ContentTypes: textBodies},
+	}
+}
+
+// httpLogRedactor returns the redaction of HTTP logs: credentials, email addresses and
+// card numbers everywhere, free-form template data, and no bodies at all of uploads
+// and exports, which are either binary or full of personal data
+func (s *Server) httpLogRedactor() *pkghttplog.Redactor {
+	cfg := pkghttplog.DefaultRedaction()
+	cfg.Rules = []pkghttplog.RedactionRule{
+		{
+			Routes: []string{"/api/v1/email/send-template", "/api/v1/email/templates/*/preview", "/api/v1/admin/email/batches"},
+			Paths:  []string{"$.data", "$.recipients[*].data"},
+		},
+		{
+			Routes:   []string{"/api/v1/users/me/avatar", "/api/v1/admin/users/import", "/api/v1/admin/users/export", "/api/v1/privacy/exports/*"},
+			DropBody: true,
+		},
+	}
+
+	redactor, err := pkghttplog.NewRedactor(cfg)
+	if err != nil {
+		s.logger.Error("invalid HTTP log redaction, using the default", "error", err)
+		return nil
+	}
+	return redactor
+}
+
+// maxUploadBytes returns the configured maximum upload size in bytes
+func (s *Server) maxUploadBytes() int64 {
+	if s.config.Server.MaxUploadMB <= 0 {
+		return 10 << 20
+	}
+	return int64(s.config.Server.MaxUploadMB) << 20
+}
+
 // TimeoutMiddleware creates a middleware that times out requests after specified duration
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
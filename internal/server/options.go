@@ -5,6 +5,7 @@ import (
 	"base-code-go-gin-clean/internal/handler"
 	"base-code-go-gin-clean/internal/handler/auth"
 	emailHandler "base-code-go-gin-clean/internal/handler/email"
+	"base-code-go-gin-clean/internal/middleware"
 	"base-code-go-gin-clean/internal/pkg/redis"
 	"github.com/uptrace/bun"
 	"go.opentelemetry.io/otel/sdk/trace"
@@ -14,9 +15,21 @@ import (
 
 type ServerOptions struct {
 	UserHandler  *handler.UserHandler
+	UserBulkHandler *handler.UserBulkHandler
 	AuthHandler  *auth.AuthHandler
 	EmailHandler *emailHandler.EmailHandler
+	EmailTemplateHandler *handler.EmailTemplateHandler
+	EmailSuppressionHandler *handler.EmailSuppressionHandler
+	EmailMessageLogHandler *handler.EmailMessageLogHandler
+	EmailBatchHandler *handler.EmailBatchHandler
+	HTTPLogHandler *handler.HTTPLogHandler
+	PrivacyHandler *handler.PrivacyHandler
+	AvatarHandler *handler.AvatarHandler
+	PreferenceHandler *handler.PreferenceHandler
+	FileHandler   *handler.FileHandler // Serves signed URLs for the local blob store; nil when using S3
 	TokenConfig  *config.TokenConfig
+	StatusChecker middleware.StatusChecker // Rejects suspended or locked accounts in AuthMiddleware
+	RoleChecker  middleware.RoleChecker // Checks the role of users on admin routes; they are closed without one
 	DB           *bun.DB // Add database connection to options
 	RedisRepo    redis.Repository // Add Redis repository to options
 	TracerProvider *trace.TracerProvider // Add TracerProvider for distributed tracing
@@ -32,6 +45,13 @@ func WithUserHandler(h *handler.UserHandler) Option {
 	}
 }
 
+// WithUserBulkHandler is an option to set the bulk user import/export handler
+func WithUserBulkHandler(h *handler.UserBulkHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.UserBulkHandler = h
+	}
+}
+
 // WithRolesHandler is an option to set the roles handler
 // func WithRolesHandler(h *handler.RolesHandler) Option {
 // 	return func(opts *ServerOptions) {
@@ -46,6 +66,69 @@ func WithEmailHandler(h *emailHandler.EmailHandler) Option {
 	}
 }
 
+// WithEmailTemplateHandler is an option to set the admin email template handler
+func WithEmailTemplateHandler(h *handler.EmailTemplateHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.EmailTemplateHandler = h
+	}
+}
+
+// WithEmailSuppressionHandler is an option to set the unsubscribe, bounce webhook and suppression list handler
+func WithEmailSuppressionHandler(h *handler.EmailSuppressionHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.EmailSuppressionHandler = h
+	}
+}
+
+// WithEmailMessageLogHandler is an option to set the email tracking and delivery log handler
+func WithEmailMessageLogHandler(h *handler.EmailMessageLogHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.EmailMessageLogHandler = h
+	}
+}
+
+// WithEmailBatchHandler is an option to set the scheduled email batch handler
+func WithEmailBatchHandler(h *handler.EmailBatchHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.EmailBatchHandler = h
+	}
+}
+
+// WithHTTPLogHandler is an option to set the HTTP log search handler
+func WithHTTPLogHandler(h *handler.HTTPLogHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.HTTPLogHandler = h
+	}
+}
+
+// WithPrivacyHandler is an option to set the privacy handler
+func WithPrivacyHandler(h *handler.PrivacyHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.PrivacyHandler = h
+	}
+}
+
+// WithAvatarHandler is an option to set the avatar handler
+func WithAvatarHandler(h *handler.AvatarHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.AvatarHandler = h
+	}
+}
+
+// WithPreferenceHandler is an option to set the preference handler
+func WithPreferenceHandler(h *handler.PreferenceHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.PreferenceHandler = h
+	}
+}
+
+// WithFileHandler is an option to set the local file handler
+func WithFileHandler(h *handler.FileHandler) Option {
+	return func(opts *ServerOptions) {
+		opts.FileHandler = h
+	}
+}
+
 // WithAuthHandler is an option to set the auth handler
 func WithAuthHandler(h *auth.AuthHandler) Option {
 	return func(opts *ServerOptions) {
@@ -60,6 +143,20 @@ func WithTokenConfig(cfg *config.TokenConfig) Option {
 	}
 }
 
+// WithStatusChecker is an option to set the account status checker used by AuthMiddleware
+func WithStatusChecker(checker middleware.StatusChecker) Option {
+	return func(opts *ServerOptions) {
+		opts.StatusChecker = checker
+	}
+}
+
+// WithRoleChecker is an option to set the role checker used by RoleMiddleware
+func WithRoleChecker(checker middleware.RoleChecker) Option {
+	return func(opts *ServerOptions) {
+		opts.RoleChecker = checker
+	}
+}
+
 // WithRedisRepo is an option to set the Redis repository
 func WithRedisRepo(repo redis.Repository) Option {
 	return func(opts *ServerOptions) {
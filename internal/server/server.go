@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"base-code-go-gin-clean/internal/config"
+	domainhttplog "base-code-go-gin-clean/internal/domain/httplog"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -22,6 +23,7 @@ type Server struct {
 	server        *http.Server
 	tracerCleanup func()  // Function to clean up tracer resources
 	db            *bun.DB // Database connection
+	httpLogs      *domainhttplog.AsyncRepository
 }
 
 // New creates a new Server instance
@@ -92,6 +94,16 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	err := s.server.Shutdown(shutdownCtx)
+
+	// Write the HTTP logs still queued once no request can add to them
+	if s.httpLogs != nil {
+		if err := s.httpLogs.Close(shutdownCtx); err != nil {
+			s.logger.Error("Failed to flush HTTP logs", "error", err)
+		}
+		stats := s.httpLogs.Stats()
+		s.logger.Info("HTTP logs flushed", "written", stats.Written, "dropped", stats.Dropped, "failed", stats.Failed)
+	}
+
 	if err != nil {
 		s.logger.Error("Forced to shutdown", "error", err)
 		return err
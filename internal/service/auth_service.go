@@ -64,9 +64,10 @@ func (s *authService) Register(ctx context.Context, name, email, password string
 
 	// Create new user
 	newUser := &user.User{
-		ID:    uuid.New(),
-		Name:  name,
-		Email: email,
+		ID:     uuid.New(),
+		Name:   name,
+		Email:  email,
+		Status: user.StatusActive,
 	}
 
 	// Hash password
@@ -84,18 +85,23 @@ func (s *authService) Register(ctx context.Context, name, email, password string
 
 func (s *authService) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
 	// Find user by email
-	user, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil || user == nil {
+	u, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || u == nil {
 		return nil, errors.New("invalid email or password")
 	}
 
 	// Verify password
-	if err := user.CheckPassword(password); err != nil {
+	if err := u.CheckPassword(password); err != nil {
 		return nil, errors.New("invalid email or password")
 	}
 
+	// Reject accounts that are suspended, locked or pending
+	if !u.StatusSnapshot().IsActiveAt(time.Now()) {
+		return nil, user.ErrAccountInactive
+	}
+
 	// Generate tokens
-	accessToken, err := s.tokenService.GenerateAccessToken(user.ID.String())
+	accessToken, err := s.tokenService.GenerateAccessToken(u.ID.String())
 	if err != nil {
 		return nil, errors.New("failed to generate access token")
 	}
@@ -107,12 +113,12 @@ func (s *authService) Login(ctx context.Context, email, password string) (*Login
 	}
 
 	// Store refresh token in Redis
-	if err := s.redisRepo.Set(ctx, "refresh_token:"+user.ID.String(), refreshToken, 15*time.Minute); err != nil {
+	if err := s.redisRepo.Set(ctx, "refresh_token:"+u.ID.String(), refreshToken, 15*time.Minute); err != nil {
 		return nil, errors.New("failed to store refresh token")
 	}
 
 	// Convert user to response DTO
-	userResponse := user.ToResponse()
+	userResponse := u.ToResponse()
 
 	return &LoginResponse{
 		User: userResponse,
@@ -131,6 +137,11 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*T
 		return nil, errors.New("invalid refresh token")
 	}
 
+	// Make sure the account is still allowed to authenticate
+	if err := s.ensureActive(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	// Generate new access token
 	accessToken, err := s.tokenService.GenerateAccessToken(userID)
 	if err != nil {
@@ -163,3 +174,21 @@ func (s *authService) Logout(ctx context.Context, userID string) error {
 	}
 	return nil
 }
+
+// ensureActive loads the user and checks that the account may authenticate
+func (s *authService) ensureActive(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.New("invalid refresh token")
+	}
+
+	u, err := s.userRepo.GetByID(ctx, id)
+	if err != nil || u == nil {
+		return errors.New("invalid refresh token")
+	}
+
+	if !u.StatusSnapshot().IsActiveAt(time.Now()) {
+		return user.ErrAccountInactive
+	}
+	return nil
+}
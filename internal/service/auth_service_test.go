@@ -183,6 +183,26 @@ func TestAuthService_Login(t *testing.T) {
 		assert.Equal(t, "invalid email or password", err.Error())
 	})
 
+	t.Run("suspended account", func(t *testing.T) {
+		email := "suspended@example.com"
+		password := "password123"
+
+		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		suspendedUser := &user.User{
+			ID:       uuid.New(),
+			Email:    email,
+			Password: string(hashedPassword),
+			Status:   user.StatusSuspended,
+		}
+
+		userRepo.On("GetByEmail", ctx, email).Return(suspendedUser, nil)
+
+		userResp, err := service.Login(ctx, email, password)
+
+		assert.ErrorIs(t, err, user.ErrAccountInactive)
+		assert.Nil(t, userResp)
+	})
+
 }
 
 func TestAuthService_RefreshToken(t *testing.T) {
@@ -198,15 +218,18 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("success", func(t *testing.T) {
+		userID := uuid.New()
+
 		// Setup Redis Get expectation
-		redisRepo.On("Get", mock.Anything, "valid-refresh-token").Return("user123", nil)
+		redisRepo.On("Get", mock.Anything, "valid-refresh-token").Return(userID.String(), nil)
+		userRepo.On("GetByID", mock.Anything, userID).Return(&user.User{ID: userID, Status: user.StatusActive}, nil)
 
 		// Setup TokenService expectations
-		tokenService.On("GenerateAccessToken", "user123").Return("new-access-token", nil)
+		tokenService.On("GenerateAccessToken", userID.String()).Return("new-access-token", nil)
 		tokenService.On("GenerateRefreshToken").Return("new-refresh-token", nil)
 
 		// Setup Redis Set expectation for new token
-		redisRepo.On("Set", mock.Anything, "refresh_token:user123",
+		redisRepo.On("Set", mock.Anything, "refresh_token:"+userID.String(),
 			"new-refresh-token", 7*24*time.Hour).Return(nil)
 
 		// Test and assertions
@@ -219,6 +242,17 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		tokenService.AssertExpectations(t)
 		redisRepo.AssertExpectations(t)
 	})
+
+	t.Run("locked account", func(t *testing.T) {
+		userID := uuid.New()
+
+		redisRepo.On("Get", mock.Anything, "locked-refresh-token").Return(userID.String(), nil)
+		userRepo.On("GetByID", mock.Anything, userID).Return(&user.User{ID: userID, Status: user.StatusLocked}, nil)
+
+		tokenResp, err := service.RefreshToken(ctx, "locked-refresh-token")
+		assert.ErrorIs(t, err, user.ErrAccountInactive)
+		assert.Nil(t, tokenResp)
+	})
 }
 
 func TestAuthService_Logout(t *testing.T) {
@@ -0,0 +1,184 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/pkg/redis"
+	"base-code-go-gin-clean/internal/pkg/storage"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+)
+
+// AvatarService handles profile picture uploads
+type AvatarService interface {
+	// Upload validates, resizes and stores a new avatar for the user, replacing the previous one
+	Upload(ctx context.Context, userID string, r io.Reader) (*user.UserResponse, error)
+}
+
+// Cache key prefix shared with the user service
+const userCacheKeyPrefix = "user:"
+
+// DefaultURLTTL is how long signed avatar URLs stay valid
+const DefaultURLTTL = time.Hour
+
+type avatarService struct {
+	userRepo  user.UserRepository
+	redisRepo redis.Repository
+	store     storage.BlobStore
+	urlTTL    time.Duration
+	now       func() time.Time
+}
+
+// AvatarServiceConfig holds the dependencies of the avatar service
+type AvatarServiceConfig struct {
+	UserRepo  user.UserRepository
+	RedisRepo redis.Repository
+	Store     storage.BlobStore
+	URLTTL    time.Duration
+}
+
+// NewAvatarService creates a new avatar service
+func NewAvatarService(cfg AvatarServiceConfig) AvatarService {
+	svc := &avatarService{
+		userRepo:  cfg.UserRepo,
+		redisRepo: cfg.RedisRepo,
+		store:     cfg.Store,
+		urlTTL:    DefaultURLTTL,
+		now:       time.Now,
+	}
+
+	if cfg.URLTTL > 0 {
+		svc.urlTTL = cfg.URLTTL
+	}
+
+	return svc
+}
+
+// Upload validates, resizes and stores a new avatar for the user
+func (s *avatarService) Upload(ctx context.Context, userID string, r io.Reader) (*user.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		err = fmt.Errorf("invalid user ID format: %v", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	contentType, err := sniff(data)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	img, err := decode(data, contentType)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	renditions, err := render(img, contentType)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = user.ErrUserNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Each upload gets a fresh key so cached URLs never serve a stale image
+	baseKey := fmt.Sprintf("avatars/%s/%d.%s", id, s.now().UnixNano(), renditions[0].Extension)
+	for _, rend := range renditions {
+		key := RenditionKey(baseKey, rend.Size)
+		if err := s.store.Put(ctx, key, bytes.NewReader(rend.Data), int64(len(rend.Data)), rend.ContentType); err != nil {
+			span.RecordError(err)
+			_ = DeleteAll(ctx, s.store, baseKey)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	previousKey := u.AvatarKey
+	u.AvatarKey = baseKey
+	u.UpdatedAt = s.now()
+	if err := s.userRepo.UpdateAvatar(ctx, u); err != nil {
+		span.RecordError(err)
+		_ = DeleteAll(ctx, s.store, baseKey)
+		return nil, fmt.Errorf("failed to update avatar: %w", err)
+	}
+
+	if previousKey != "" {
+		if err := DeleteAll(ctx, s.store, previousKey); err != nil {
+			span.RecordError(err)
+		}
+	}
+	if err := s.redisRepo.Delete(ctx, userCacheKeyPrefix+userID); err != nil {
+		span.RecordError(err)
+	}
+
+	resp := u.ToResponse()
+	resp.AvatarURLs, err = SignedURLs(ctx, s.store, baseKey, s.urlTTL)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return resp, nil
+}
+
+// RenditionKey returns the storage key of the given size of an avatar
+func RenditionKey(baseKey string, size int) string {
+	ext := path.Ext(baseKey)
+	return strings.TrimSuffix(baseKey, ext) + "_" + strconv.Itoa(size) + ext
+}
+
+// SignedURLs returns signed URLs for every rendition of an avatar, keyed by size
+func SignedURLs(ctx context.Context, store storage.BlobStore, baseKey string, ttl time.Duration) (map[string]string, error) {
+	if baseKey == "" {
+		return nil, nil
+	}
+
+	urls := make(map[string]string, len(Sizes))
+	for _, size := range Sizes {
+		u, err := store.SignedURL(ctx, RenditionKey(baseKey, size), ttl)
+		if err != nil {
+			return nil, err
+		}
+		urls[strconv.Itoa(size)] = u
+	}
+
+	return urls, nil
+}
+
+// DeleteAll removes every rendition of an avatar
+func DeleteAll(ctx context.Context, store storage.BlobStore, baseKey string) error {
+	var errs []error
+	for _, size := range Sizes {
+		if err := store.Delete(ctx, RenditionKey(baseKey, size)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,128 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/pkg/storage"
+	"base-code-go-gin-clean/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRender(t *testing.T) {
+	img, err := decode(encodePNG(t, 300, 200), "image/png")
+	assert.NoError(t, err)
+
+	renditions, err := render(img, "image/png")
+	assert.NoError(t, err)
+	assert.Len(t, renditions, len(Sizes))
+
+	for i, r := range renditions {
+		assert.Equal(t, Sizes[i], r.Size)
+		assert.Equal(t, "image/png", r.ContentType)
+
+		cfg, err := png.DecodeConfig(bytes.NewReader(r.Data))
+		assert.NoError(t, err)
+		assert.Equal(t, Sizes[i], cfg.Width)
+		assert.Equal(t, Sizes[i], cfg.Height)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	_, err := sniff([]byte("<html><body>not an image</body></html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	contentType, err := sniff(encodePNG(t, 10, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+}
+
+func TestDecode_RejectsHugeDimensions(t *testing.T) {
+	// Only the header is inspected, so a 1x5000 image is cheap to build
+	_, err := decode(encodePNG(t, 1, MaxSourceDimension+1), "image/png")
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
+
+func TestRenditionKey(t *testing.T) {
+	assert.Equal(t, "avatars/u/1_64.jpg", RenditionKey("avatars/u/1.jpg", 64))
+}
+
+func TestAvatarService_Upload(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir(), "http://localhost/api/v1/files", "secret")
+	assert.NoError(t, err)
+
+	userRepo := &mocks.MockUserRepository{}
+	redisRepo := &mocks.MockRedisRepository{}
+	svc := NewAvatarService(AvatarServiceConfig{
+		UserRepo:  userRepo,
+		RedisRepo: redisRepo,
+		Store:     store,
+	})
+	ctx := context.Background()
+
+	t.Run("replaces previous avatar", func(t *testing.T) {
+		userID := uuid.New()
+		oldKey := "avatars/" + userID.String() + "/1.png"
+		for _, size := range Sizes {
+			assert.NoError(t, store.Put(ctx, RenditionKey(oldKey, size), bytes.NewReader([]byte("old")), 3, "image/png"))
+		}
+		existing := &user.User{ID: userID, Name: "Test User", AvatarKey: oldKey}
+
+		var photo bytes.Buffer
+		assert.NoError(t, jpeg.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 400, 300)), nil))
+
+		userRepo.On("GetByID", mock.Anything, userID).Return(existing, nil)
+		userRepo.On("UpdateAvatar", mock.Anything, existing).Return(nil)
+		redisRepo.On("Delete", mock.Anything, "user:"+userID.String()).Return(nil)
+
+		resp, err := svc.Upload(ctx, userID.String(), &photo)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, oldKey, existing.AvatarKey)
+		assert.Equal(t, ".jpg", existing.AvatarKey[len(existing.AvatarKey)-4:])
+		assert.Len(t, resp.AvatarURLs, len(Sizes))
+		assert.Contains(t, resp.AvatarURLs["128"], "signature=")
+
+		_, err = store.Get(ctx, RenditionKey(oldKey, 64))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		blob, err := store.Get(ctx, RenditionKey(existing.AvatarKey, 256))
+		if assert.NoError(t, err) {
+			blob.Close()
+		}
+		userRepo.AssertExpectations(t)
+		redisRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects non-image content", func(t *testing.T) {
+		userID := uuid.New()
+
+		resp, err := svc.Upload(ctx, userID.String(), bytes.NewReader([]byte("%PDF-1.4 not an image")))
+
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+		assert.Nil(t, resp)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything, userID)
+	})
+}
@@ -0,0 +1,134 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Sizes are the square edge lengths, in pixels, every avatar is rendered at
+var Sizes = []int{64, 128, 256}
+
+// MaxSourceDimension bounds the width and height of uploaded images so that
+// decoding cannot be used to exhaust memory
+const MaxSourceDimension = 4096
+
+// ErrUnsupportedFormat is returned when the upload is not a supported image type
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ErrImageTooLarge is returned when the image dimensions exceed MaxSourceDimension
+var ErrImageTooLarge = errors.New("image dimensions too large")
+
+// rendition is a resized avatar ready to be stored
+type rendition struct {
+	Size        int
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// sniff detects the image type from its content, ignoring any client-supplied header
+func sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return contentType, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// decode decodes data according to the sniffed content type after checking its dimensions
+func decode(data []byte, contentType string) (image.Image, error) {
+	var (
+		cfg image.Config
+		err error
+	)
+
+	r := bytes.NewReader(data)
+	switch contentType {
+	case "image/jpeg":
+		cfg, err = jpeg.DecodeConfig(r)
+	case "image/png":
+		cfg, err = png.DecodeConfig(r)
+	case "image/gif":
+		cfg, err = gif.DecodeConfig(r)
+	case "image/webp":
+		cfg, err = webp.DecodeConfig(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if cfg.Width > MaxSourceDimension || cfg.Height > MaxSourceDimension {
+		return nil, ErrImageTooLarge
+	}
+
+	r = bytes.NewReader(data)
+	var img image.Image
+	switch contentType {
+	case "image/jpeg":
+		img, err = jpeg.Decode(r)
+	case "image/png":
+		img, err = png.Decode(r)
+	case "image/gif":
+		img, err = gif.Decode(r)
+	case "image/webp":
+		img, err = webp.Decode(r)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	return img, nil
+}
+
+// render center-crops img to a square and scales it to every entry in Sizes.
+// Photos stay JPEG; everything else is re-encoded as PNG to keep transparency.
+func render(img image.Image, contentType string) ([]rendition, error) {
+	src := squareCrop(img.Bounds())
+
+	renditions := make([]rendition, 0, len(Sizes))
+	for _, size := range Sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Over, nil)
+
+		var buf bytes.Buffer
+		r := rendition{Size: size}
+		if contentType == "image/jpeg" {
+			if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+				return nil, fmt.Errorf("failed to encode avatar: %w", err)
+			}
+			r.ContentType, r.Extension = "image/jpeg", "jpg"
+		} else {
+			if err := png.Encode(&buf, dst); err != nil {
+				return nil, fmt.Errorf("failed to encode avatar: %w", err)
+			}
+			r.ContentType, r.Extension = "image/png", "png"
+		}
+		r.Data = buf.Bytes()
+		renditions = append(renditions, r)
+	}
+
+	return renditions, nil
+}
+
+// squareCrop returns the largest centered square inside b
+func squareCrop(b image.Rectangle) image.Rectangle {
+	w, h := b.Dx(), b.Dy()
+	if w > h {
+		offset := (w - h) / 2
+		return image.Rect(b.Min.X+offset, b.Min.Y, b.Min.X+offset+h, b.Max.Y)
+	}
+	offset := (h - w) / 2
+	return image.Rect(b.Min.X, b.Min.Y+offset, b.Max.X, b.Min.Y+offset+w)
+}
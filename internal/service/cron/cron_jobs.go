@@ -1,5 +1,10 @@
 package cron
 
+import (
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/service/privacy"
+)
+
 // CronJob represents a cron job registration entry
 // Handler harus berupa fungsi tanpa parameter
 
@@ -9,12 +14,35 @@ type CronJob struct {
 }
 
 // GetCronJobs returns all cron jobs with injected dependencies
-func GetCronJobs(dailyReportSvc *DailyReportService) []CronJob {
-	return []CronJob{
+func GetCronJobs(dailyReportSvc *DailyReportService, privacySvc privacy.PrivacyService, httpLogRetention httplog.RetentionService, httpLogArchive httplog.ArchiveService) []CronJob {
+	jobs := []CronJob{
 		{
 			Spec:    "0 5 * * * *",
 			Handler: dailyReportSvc.GenerateAndSendDailyReport,
 		},
+		{
+			// Erase accounts whose deletion grace period has ended
+			Spec:    "0 */15 * * * *",
+			Handler: privacySvc.ProcessDueDeletions,
+		},
+		{
+			Spec:    "0 30 * * * *",
+			Handler: privacySvc.PurgeExpiredExports,
+		},
+		{
+			// Create upcoming HTTP log partitions and drop expired ones
+			Spec:    "0 10 0 * * *",
+			Handler: httpLogRetention.EnforceRetention,
+		},
 		// Tambahkan job lain di sini
 	}
+
+	if httpLogArchive != nil {
+		jobs = append(jobs, CronJob{
+			// Archive the HTTP logs of the ended periods before retention drops them
+			Spec:    "0 5 0 * * *",
+			Handler: httpLogArchive.ArchiveCompletedPeriods,
+		})
+	}
+	return jobs
 }
@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"context"
 	"fmt"
 
 	domain "base-code-go-gin-clean/internal/domain/email"
@@ -25,19 +26,22 @@ func (s *DailyReportService) GenerateAndSendDailyReport() {
 		"totalUsers":     "42",
 	}
 
-	subject, body, err := email.DailyReportEmail("Admin", reportData)
+	// Replace with the admin's locale; the default locale is used when empty
+	subject, body, err := email.DailyReportEmail("", "Admin", reportData)
 	if err != nil {
 		fmt.Printf("Failed to generate email template: %v\n", err)
 		return
 	}
 
 	email := &domain.Email{
-		To:      []string{"admin@example.com"}, // Replace with actual admin email
-		Subject: subject,
-		Body:    body,
+		To:       []string{"admin@example.com"}, // Replace with actual admin email
+		Subject:  subject,
+		Body:     body,
+		Bulk:     true, // Recipients can unsubscribe from reports
+		Template: "daily_report",
 	}
 
-	if err := s.emailService.SendEmail(email); err != nil {
+	if err := s.emailService.SendEmail(context.Background(), email); err != nil {
 		// Log the error, but don't fail the entire application
 		// You might want to use a proper logger here
 		fmt.Printf("Failed to send daily report: %v\n", err)
@@ -1,6 +1,7 @@
 package cron_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,8 +14,8 @@ type mockEmailService struct {
 	mock.Mock
 }
 
-func (m *mockEmailService) SendEmail(e *email.Email) error {
-	args := m.Called(e)
+func (m *mockEmailService) SendEmail(ctx context.Context, e *email.Email) error {
+	args := m.Called(ctx, e)
 	return args.Error(0)
 }
 
@@ -31,7 +32,7 @@ func TestDailyReportService_GenerateAndSendDailyReport(t *testing.T) {
 	service := cron.NewDailyReportService(mockEmailSvc)
 
 	t.Run("successful report generation", func(t *testing.T) {
-		mockEmailSvc.On("SendEmail", mock.AnythingOfType("*email.Email")).Return(nil)
+		mockEmailSvc.On("SendEmail", mock.Anything, mock.AnythingOfType("*email.Email")).Return(nil)
 
 		service.GenerateAndSendDailyReport()
 
@@ -39,7 +40,7 @@ func TestDailyReportService_GenerateAndSendDailyReport(t *testing.T) {
 	})
 
 	t.Run("email send failure", func(t *testing.T) {
-		mockEmailSvc.On("SendEmail", mock.AnythingOfType("*email.Email")).Return(assert.AnError)
+		mockEmailSvc.On("SendEmail", mock.Anything, mock.AnythingOfType("*email.Email")).Return(assert.AnError)
 
 		service.GenerateAndSendDailyReport()
 
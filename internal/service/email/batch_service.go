@@ -0,0 +1,212 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/mail"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrBatchNotFound is returned when a batch does not exist
+	ErrBatchNotFound = errors.New("email batch not found")
+	// ErrBatchNotCancellable is returned when cancelling a batch that is already dispatching or done
+	ErrBatchNotCancellable = errors.New("only scheduled batches can be cancelled")
+)
+
+// maxBatchRecipients bounds the size of one batch request
+const maxBatchRecipients = 10000
+
+// BatchRequest asks for a catalog template to be sent to many recipients.
+// Data is shared by all recipients and merged under each recipient's own data.
+// Locale applies to recipients without their own locale.
+type BatchRequest struct {
+	Template   string                     `json:"template"`
+	ReplyTo    string                     `json:"reply_to,omitempty"`
+	Locale     string                     `json:"locale,omitempty" example:"de"`
+	SendAt     *time.Time                 `json:"send_at,omitempty"`
+	Data       map[string]json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	Recipients []BatchRecipientRequest    `json:"recipients"`
+}
+
+// BatchRecipientRequest is one recipient of a batch with its template data
+type BatchRecipientRequest struct {
+	To     string                     `json:"to"`
+	Locale string                     `json:"locale,omitempty"`
+	Data   map[string]json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+// BatchService schedules batches of templated mail. BatchWorker fans them out
+// into the outbox once they are due; until then they can be cancelled.
+type BatchService interface {
+	// Create validates the data of every recipient and schedules the batch at
+	// req.SendAt, or immediately when it is not set
+	Create(ctx context.Context, req *BatchRequest, createdBy *uuid.UUID) (*domain.Batch, error)
+	// Get returns a batch with its progress
+	Get(ctx context.Context, id string) (*domain.Batch, error)
+	// Cancel stops a batch that has not started dispatching
+	Cancel(ctx context.Context, id string) (*domain.Batch, error)
+}
+
+type batchService struct {
+	repo domain.BatchRepository
+	now  func() time.Time
+}
+
+func NewBatchService(repo domain.BatchRepository) BatchService {
+	return &batchService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (s *batchService) Create(ctx context.Context, req *BatchRequest, createdBy *uuid.UUID) (*domain.Batch, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	def, err := lookupTemplate(req.Template)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Recipients) == 0 {
+		return nil, fmt.Errorf("%w: at least one recipient is required", domain.ErrInvalidEmail)
+	}
+	if len(req.Recipients) > maxBatchRecipients {
+		return nil, fmt.Errorf("%w: a batch has at most %d recipients", domain.ErrInvalidEmail, maxBatchRecipients)
+	}
+	if req.ReplyTo != "" {
+		if _, err := mail.ParseAddress(req.ReplyTo); err != nil {
+			return nil, fmt.Errorf("%w: invalid reply-to %q", domain.ErrInvalidEmail, req.ReplyTo)
+		}
+	}
+
+	// Every recipient is checked up front so a batch does not fail halfway
+	// through dispatch; problems are reported per recipient
+	errs := templates.ValidationErrors{}
+	seen := make(map[string]bool, len(req.Recipients))
+	recipients := make([]*domain.BatchRecipient, 0, len(req.Recipients))
+	for i, rcpt := range req.Recipients {
+		prefix := fmt.Sprintf("recipients[%d]", i)
+		addr := normalizeAddress(rcpt.To)
+		switch {
+		case addr == "":
+			errs[prefix+".to"] = "invalid address"
+			continue
+		case seen[addr]:
+			errs[prefix+".to"] = "duplicate recipient"
+			continue
+		}
+		seen[addr] = true
+
+		if _, err := def.Decode(mergeData(req.Data, rcpt.Data)); err != nil {
+			var fieldErrs templates.ValidationErrors
+			if !errors.As(err, &fieldErrs) {
+				return nil, err
+			}
+			for field, reason := range fieldErrs {
+				errs[prefix+"."+field] = reason
+			}
+			continue
+		}
+
+		recipients = append(recipients, &domain.BatchRecipient{
+			Position: i,
+			Email:    addr,
+			Locale:   rcpt.Locale,
+			Data:     rcpt.Data,
+			Status:   domain.RecipientPending,
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	now := s.now()
+	sendAt := now
+	if req.SendAt != nil && req.SendAt.After(now) {
+		sendAt = *req.SendAt
+	}
+
+	batch := &domain.Batch{
+		Template:  req.Template,
+		ReplyTo:   req.ReplyTo,
+		Locale:    req.Locale,
+		Data:      req.Data,
+		Status:    domain.BatchScheduled,
+		SendAt:    sendAt,
+		Total:     len(recipients),
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.Create(ctx, batch, recipients); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+	return batch, nil
+}
+
+func (s *batchService) Get(ctx context.Context, id string) (*domain.Batch, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	batchID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch ID format: %v", err)
+	}
+
+	batch, err := s.repo.GetByID(ctx, batchID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBatchNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (s *batchService) Cancel(ctx context.Context, id string) (*domain.Batch, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	batchID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch ID format: %v", err)
+	}
+
+	batch, err := s.repo.Cancel(ctx, batchID, s.now())
+	if err == nil {
+		return batch, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to cancel batch: %w", err)
+	}
+
+	// Tell a missing batch apart from one that is past scheduling
+	batch, err = s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == domain.BatchCancelled {
+		return batch, nil
+	}
+	return nil, ErrBatchNotCancellable
+}
+
+// mergeData returns the batch data overlaid with a recipient's data
+func mergeData(shared, own map[string]json.RawMessage) map[string]json.RawMessage {
+	data := make(map[string]json.RawMessage, len(shared)+len(own))
+	maps.Copy(data, shared)
+	maps.Copy(data, own)
+	return data
+}
@@ -0,0 +1,288 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBatchRepository struct {
+	mock.Mock
+}
+
+func (m *mockBatchRepository) Create(ctx context.Context, batch *domain.Batch, recipients []*domain.BatchRecipient) error {
+	return m.Called(ctx, batch, recipients).Error(0)
+}
+
+func (m *mockBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Batch, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Batch), args.Error(1)
+}
+
+func (m *mockBatchRepository) Cancel(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Batch, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Batch), args.Error(1)
+}
+
+func (m *mockBatchRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.Batch, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	return args.Get(0).([]*domain.Batch), args.Error(1)
+}
+
+func (m *mockBatchRepository) Update(ctx context.Context, batch *domain.Batch) error {
+	return m.Called(ctx, batch).Error(0)
+}
+
+func (m *mockBatchRepository) ListRecipients(ctx context.Context, batchID uuid.UUID) ([]*domain.BatchRecipient, error) {
+	args := m.Called(ctx, batchID)
+	return args.Get(0).([]*domain.BatchRecipient), args.Error(1)
+}
+
+func (m *mockBatchRepository) UpdateRecipient(ctx context.Context, recipient *domain.BatchRecipient) error {
+	return m.Called(ctx, recipient).Error(0)
+}
+
+func raw(s string) json.RawMessage {
+	return json.RawMessage(s)
+}
+
+func TestBatchService_Create(t *testing.T) {
+	now := time.Date(2025, 8, 22, 9, 0, 0, 0, time.UTC)
+	newService := func() (*batchService, *mockBatchRepository) {
+		repo := &mockBatchRepository{}
+		svc := NewBatchService(repo).(*batchService)
+		svc.now = func() time.Time { return now }
+		return svc, repo
+	}
+	shared := map[string]json.RawMessage{"login_url": raw(`"https://app.example.com"`)}
+
+	t.Run("schedules valid recipients", func(t *testing.T) {
+		svc, repo := newService()
+		sendAt := now.Add(2 * time.Hour)
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(b *domain.Batch) bool {
+			return b.Status == domain.BatchScheduled && b.SendAt.Equal(sendAt) && b.Total == 2
+		}), []*domain.BatchRecipient{
+			{Position: 0, Email: "jane@example.com", Data: map[string]json.RawMessage{"name": raw(`"Jane"`)}, Status: domain.RecipientPending},
+			{Position: 1, Email: "max@example.de", Locale: "de", Data: map[string]json.RawMessage{"name": raw(`"Max"`)}, Status: domain.RecipientPending},
+		}).Return(nil).Once()
+
+		batch, err := svc.Create(context.Background(), &BatchRequest{
+			Template: "welcome",
+			SendAt:   &sendAt,
+			Data:     shared,
+			Recipients: []BatchRecipientRequest{
+				{To: "Jane <Jane@Example.com>", Data: map[string]json.RawMessage{"name": raw(`"Jane"`)}},
+				{To: "max@example.de", Locale: "de", Data: map[string]json.RawMessage{"name": raw(`"Max"`)}},
+			},
+		}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "welcome", batch.Template)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("past send_at dispatches immediately", func(t *testing.T) {
+		svc, repo := newService()
+		past := now.Add(-time.Hour)
+		repo.On("Create", mock.Anything, mock.MatchedBy(func(b *domain.Batch) bool {
+			return b.SendAt.Equal(now)
+		}), mock.Anything).Return(nil).Once()
+
+		_, err := svc.Create(context.Background(), &BatchRequest{
+			Template:   "welcome",
+			SendAt:     &past,
+			Recipients: []BatchRecipientRequest{{To: "jane@example.com", Data: map[string]json.RawMessage{"name": raw(`"Jane"`), "login_url": raw(`"https://app.example.com"`)}}},
+		}, nil)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("reports problems per recipient", func(t *testing.T) {
+		svc, repo := newService()
+
+		_, err := svc.Create(context.Background(), &BatchRequest{
+			Template: "welcome",
+			Data:     shared,
+			Recipients: []BatchRecipientRequest{
+				{To: "jane@example.com", Data: map[string]json.RawMessage{"name": raw(`"Jane"`)}},
+				{To: "not-an-address"},
+				{To: "JANE@example.com", Data: map[string]json.RawMessage{"name": raw(`"Jane"`)}},
+				{To: "max@example.de", Data: map[string]json.RawMessage{"login_url": raw(`"ftp://x"`)}},
+			},
+		}, nil)
+
+		var validationErrs templates.ValidationErrors
+		if !assert.ErrorAs(t, err, &validationErrs) {
+			return
+		}
+		assert.Equal(t, "invalid address", validationErrs["recipients[1].to"])
+		assert.Equal(t, "duplicate recipient", validationErrs["recipients[2].to"])
+		assert.Equal(t, "is required", validationErrs["recipients[3].name"])
+		assert.Contains(t, validationErrs, "recipients[3].login_url")
+		assert.Len(t, validationErrs, 4)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects unknown templates and empty batches", func(t *testing.T) {
+		svc, _ := newService()
+
+		_, err := svc.Create(context.Background(), &BatchRequest{Template: "nope", Recipients: []BatchRecipientRequest{{To: "a@example.com"}}}, nil)
+		assert.ErrorIs(t, err, templates.ErrUnknownTemplate)
+
+		_, err = svc.Create(context.Background(), &BatchRequest{Template: "welcome"}, nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidEmail)
+	})
+}
+
+func TestBatchService_Cancel(t *testing.T) {
+	repo := &mockBatchRepository{}
+	svc := NewBatchService(repo)
+	ctx := context.Background()
+
+	scheduled := uuid.New()
+	repo.On("Cancel", mock.Anything, scheduled, mock.Anything).Return(&domain.Batch{ID: scheduled, Status: domain.BatchCancelled}, nil).Once()
+	batch, err := svc.Cancel(ctx, scheduled.String())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.BatchCancelled, batch.Status)
+
+	dispatched := uuid.New()
+	repo.On("Cancel", mock.Anything, dispatched, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+	repo.On("GetByID", mock.Anything, dispatched).Return(&domain.Batch{ID: dispatched, Status: domain.BatchDispatching}, nil).Once()
+	_, err = svc.Cancel(ctx, dispatched.String())
+	assert.ErrorIs(t, err, ErrBatchNotCancellable)
+
+	// Cancelling twice is not an error
+	repo.On("Cancel", mock.Anything, scheduled, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+	repo.On("GetByID", mock.Anything, scheduled).Return(&domain.Batch{ID: scheduled, Status: domain.BatchCancelled}, nil).Once()
+	_, err = svc.Cancel(ctx, scheduled.String())
+	assert.NoError(t, err)
+
+	missing := uuid.New()
+	repo.On("Cancel", mock.Anything, missing, mock.Anything).Return(nil, sql.ErrNoRows).Once()
+	repo.On("GetByID", mock.Anything, missing).Return(nil, sql.ErrNoRows).Once()
+	_, err = svc.Cancel(ctx, missing.String())
+	assert.ErrorIs(t, err, ErrBatchNotFound)
+
+	_, err = svc.Cancel(ctx, "not-a-uuid")
+	assert.ErrorContains(t, err, "invalid batch ID format")
+}
+
+func TestBatchWorker_ProcessDue(t *testing.T) {
+	now := time.Date(2025, 8, 22, 9, 0, 0, 0, time.UTC)
+	started := now
+
+	newWorker := func() (*BatchWorker, *mockBatchRepository, *mockOutboxRepository, *mockSuppressionRepository) {
+		repo := &mockBatchRepository{}
+		outboxRepo := &mockOutboxRepository{}
+		suppressionRepo := &mockSuppressionRepository{}
+		outbox := NewOutboxService(OutboxServiceConfig{Repo: outboxRepo, Suppressions: newTestSuppressionService(suppressionRepo)})
+		outbox.(*outboxService).now = func() time.Time { return now }
+		w := NewBatchWorker(BatchWorkerConfig{
+			Repo:       repo,
+			Templates:  NewTemplateService(TemplateServiceConfig{Outbox: outbox}),
+			DomainRate: 2,
+		})
+		w.now = func() time.Time { return now }
+		return w, repo, outboxRepo, suppressionRepo
+	}
+	data := func(name string) map[string]json.RawMessage {
+		return map[string]json.RawMessage{"name": raw(`"` + name + `"`)}
+	}
+
+	t.Run("fans out with per-domain spacing", func(t *testing.T) {
+		w, repo, outboxRepo, suppressionRepo := newWorker()
+		batch := &domain.Batch{
+			ID:                uuid.New(),
+			Template:          "welcome",
+			Status:            domain.BatchDispatching,
+			Data:              map[string]json.RawMessage{"login_url": raw(`"https://app.example.com"`)},
+			DispatchStartedAt: &started,
+		}
+		queuedID := uuid.New()
+		recipients := []*domain.BatchRecipient{
+			// Queued by an earlier, interrupted run
+			{Position: 0, Email: "a@gmail.com", Data: data("A"), Status: domain.RecipientQueued, MessageID: &queuedID},
+			{Position: 1, Email: "b@gmail.com", Data: data("B"), Status: domain.RecipientPending},
+			{Position: 2, Email: "c@example.org", Data: data("C"), Status: domain.RecipientPending},
+			{Position: 3, Email: "d@gmail.com", Data: data("D"), Status: domain.RecipientPending},
+			{Position: 4, Email: "gone@example.org", Data: data("E"), Status: domain.RecipientPending},
+		}
+
+		repo.On("ClaimDue", mock.Anything, now, now.Add(defaultBatchLeaseDuration), 1).Return([]*domain.Batch{batch}, nil).Once()
+		repo.On("ListRecipients", mock.Anything, batch.ID).Return(recipients, nil)
+		repo.On("UpdateRecipient", mock.Anything, mock.Anything).Return(nil)
+		repo.On("Update", mock.Anything, batch).Return(nil)
+		suppressionRepo.On("FindByEmails", mock.Anything, []string{"gone@example.org"}).
+			Return([]*domain.Suppression{{Email: "gone@example.org", Reason: domain.SuppressionHardBounce}}, nil)
+		suppressionRepo.On("FindByEmails", mock.Anything, mock.Anything).Return([]*domain.Suppression{}, nil)
+		var queued []*domain.OutboxMessage
+		outboxRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			queued = append(queued, args.Get(1).(*domain.OutboxMessage))
+		}).Return(nil)
+
+		assert.True(t, w.ProcessDue(context.Background()))
+
+		if !assert.Len(t, queued, 3) {
+			return
+		}
+		// Two messages per minute to a domain: gmail.com already used its first slot
+		assert.Equal(t, []string{"b@gmail.com"}, queued[0].Payload.To)
+		assert.Equal(t, started.Add(30*time.Second), queued[0].NextAttemptAt)
+		assert.Equal(t, started, queued[1].NextAttemptAt, "first message to example.org")
+		assert.Equal(t, started.Add(time.Minute), queued[2].NextAttemptAt)
+		assert.Contains(t, queued[2].Payload.Text, "D")
+
+		assert.Equal(t, domain.RecipientFailed, recipients[4].Status)
+		assert.Contains(t, recipients[4].Error, "suppressed")
+		assert.Equal(t, domain.BatchDispatched, batch.Status)
+		assert.Equal(t, 4, batch.Queued)
+		assert.Equal(t, 1, batch.Failed)
+		assert.Equal(t, &now, batch.DispatchedAt)
+	})
+
+	t.Run("stops on transient errors and leaves the rest pending", func(t *testing.T) {
+		w, repo, outboxRepo, suppressionRepo := newWorker()
+		batch := &domain.Batch{ID: uuid.New(), Template: "welcome", Status: domain.BatchDispatching, DispatchStartedAt: &started,
+			Data: map[string]json.RawMessage{"login_url": raw(`"https://app.example.com"`)}}
+		recipients := []*domain.BatchRecipient{
+			{Position: 0, Email: "a@example.com", Data: data("A"), Status: domain.RecipientPending},
+			{Position: 1, Email: "b@example.com", Data: data("B"), Status: domain.RecipientPending},
+		}
+
+		repo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, 1).Return([]*domain.Batch{batch}, nil).Once()
+		repo.On("ListRecipients", mock.Anything, batch.ID).Return(recipients, nil)
+		suppressionRepo.On("FindByEmails", mock.Anything, mock.Anything).Return([]*domain.Suppression{}, nil)
+		outboxRepo.On("Create", mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+
+		assert.True(t, w.ProcessDue(context.Background()))
+
+		assert.Equal(t, domain.RecipientPending, recipients[0].Status)
+		assert.Equal(t, domain.BatchDispatching, batch.Status)
+		repo.AssertNotCalled(t, "UpdateRecipient", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		w, repo, _, _ := newWorker()
+		repo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, 1).Return([]*domain.Batch{}, nil).Once()
+
+		assert.False(t, w.ProcessDue(context.Background()))
+	})
+}
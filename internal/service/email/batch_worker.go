@@ -0,0 +1,221 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	templates "base-code-go-gin-clean/internal/email"
+)
+
+const (
+	defaultDomainRate         = 60
+	defaultBatchLeaseDuration = 5 * time.Minute
+	// batchProgressInterval is the number of recipients between progress
+	// updates, which also renew the lease
+	batchProgressInterval = 100
+)
+
+// BatchWorkerConfig holds the dependencies and settings of the batch worker
+type BatchWorkerConfig struct {
+	Repo          domain.BatchRepository
+	Templates     TemplateService // Renders each recipient's email into the outbox
+	DomainRate    int             // Messages per minute to one recipient domain
+	PollInterval  time.Duration   // Delay between polls for due batches
+	LeaseDuration time.Duration   // How long a claimed batch is hidden from other workers without progress
+}
+
+// BatchWorker fans due batches out into the outbox, one templated email per
+// recipient. Mail to the same domain is spread out to DomainRate messages per
+// minute by scheduling each message's delivery, so large providers do not
+// throttle or block the sender.
+type BatchWorker struct {
+	repo          domain.BatchRepository
+	templates     TemplateService
+	interval      time.Duration // Delay between two messages to one domain
+	pollInterval  time.Duration
+	leaseDuration time.Duration
+	now           func() time.Time
+}
+
+func NewBatchWorker(cfg BatchWorkerConfig) *BatchWorker {
+	rate := cfg.DomainRate
+	if rate <= 0 {
+		rate = defaultDomainRate
+	}
+
+	w := &BatchWorker{
+		repo:          cfg.Repo,
+		templates:     cfg.Templates,
+		interval:      time.Minute / time.Duration(rate),
+		pollInterval:  cfg.PollInterval,
+		leaseDuration: cfg.LeaseDuration,
+		now:           time.Now,
+	}
+
+	if w.pollInterval <= 0 {
+		w.pollInterval = defaultPollInterval
+	}
+	if w.leaseDuration <= 0 {
+		w.leaseDuration = defaultBatchLeaseDuration
+	}
+
+	return w
+}
+
+// Run dispatches due batches until ctx is cancelled. A batch interrupted by
+// shutdown is released and resumed by the next poll of any worker.
+func (w *BatchWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil && w.ProcessDue(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue claims one due batch and dispatches it. It reports whether a
+// batch was claimed.
+func (w *BatchWorker) ProcessDue(ctx context.Context) bool {
+	now := w.now()
+	batches, err := w.repo.ClaimDue(ctx, now, now.Add(w.leaseDuration), 1)
+	if err != nil {
+		log.Printf("email batch: failed to claim batches: %v", err)
+		return false
+	}
+	if len(batches) == 0 {
+		return false
+	}
+
+	w.dispatch(ctx, batches[0])
+	return true
+}
+
+func (w *BatchWorker) dispatch(ctx context.Context, batch *domain.Batch) {
+	// Progress is recorded even when shutdown cancels ctx mid-batch
+	updateCtx := context.WithoutCancel(ctx)
+
+	recipients, err := w.repo.ListRecipients(ctx, batch.ID)
+	if err != nil {
+		log.Printf("email batch: failed to load recipients of batch %s: %v", batch.ID, err)
+		return
+	}
+
+	start := w.now()
+	if batch.DispatchStartedAt != nil {
+		start = *batch.DispatchStartedAt
+	}
+
+	// Slots are counted over all recipients in request order, so a resumed
+	// batch keeps the schedule of the messages it already queued
+	slots := make(map[string]int)
+	batch.Queued, batch.Failed = 0, 0
+	for i, rcpt := range recipients {
+		slot := slots[recipientDomain(rcpt.Email)]
+		slots[recipientDomain(rcpt.Email)]++
+
+		if rcpt.Status == domain.RecipientPending {
+			if ctx.Err() != nil {
+				w.release(updateCtx, batch)
+				return
+			}
+			sendAt := start.Add(time.Duration(slot) * w.interval)
+			if err := w.send(ctx, batch, rcpt, sendAt); err != nil {
+				if ctx.Err() != nil {
+					w.release(updateCtx, batch)
+					return
+				}
+				// The lease expires and the batch is resumed from this recipient
+				log.Printf("email batch: failed to queue recipient %d of batch %s: %v", rcpt.Position, batch.ID, err)
+				return
+			}
+			if err := w.repo.UpdateRecipient(updateCtx, rcpt); err != nil {
+				// The recipient stays pending, so at worst it is sent twice
+				log.Printf("email batch: failed to record recipient %d of batch %s: %v", rcpt.Position, batch.ID, err)
+			}
+		}
+
+		switch rcpt.Status {
+		case domain.RecipientQueued:
+			batch.Queued++
+		case domain.RecipientFailed:
+			batch.Failed++
+		}
+
+		if (i+1)%batchProgressInterval == 0 {
+			leaseUntil := w.now().Add(w.leaseDuration)
+			batch.LockedUntil = &leaseUntil
+			if err := w.repo.Update(updateCtx, batch); err != nil {
+				log.Printf("email batch: failed to record progress of batch %s: %v", batch.ID, err)
+			}
+		}
+	}
+
+	now := w.now()
+	batch.Status = domain.BatchDispatched
+	batch.DispatchedAt = &now
+	batch.LockedUntil = nil
+	if err := w.repo.Update(updateCtx, batch); err != nil {
+		// Every recipient is queued or failed, so a retry only completes the batch
+		log.Printf("email batch: failed to complete batch %s: %v", batch.ID, err)
+	}
+}
+
+// send queues the email of one recipient and records the outcome on rcpt. It
+// returns an error only for failures worth retrying, which leave rcpt pending.
+func (w *BatchWorker) send(ctx context.Context, batch *domain.Batch, rcpt *domain.BatchRecipient, sendAt time.Time) error {
+	locale := rcpt.Locale
+	if locale == "" {
+		locale = batch.Locale
+	}
+
+	msg, err := w.templates.SendTemplate(ctx, &domain.TemplateEmail{
+		Template: batch.Template,
+		To:       rcpt.Email,
+		ReplyTo:  batch.ReplyTo,
+		Locale:   locale,
+		Data:     mergeData(batch.Data, rcpt.Data),
+		SendAt:   &sendAt,
+	})
+
+	var validationErrs templates.ValidationErrors
+	switch {
+	case err == nil:
+		rcpt.Status = domain.RecipientQueued
+		rcpt.MessageID = &msg.ID
+	case errors.Is(err, domain.ErrRecipientSuppressed),
+		errors.Is(err, domain.ErrInvalidEmail),
+		errors.Is(err, templates.ErrUnknownTemplate),
+		errors.As(err, &validationErrs):
+		rcpt.Status = domain.RecipientFailed
+		rcpt.Error = err.Error()
+	default:
+		return err
+	}
+	return nil
+}
+
+// release hands an interrupted batch back for the next poll
+func (w *BatchWorker) release(ctx context.Context, batch *domain.Batch) {
+	now := w.now()
+	batch.LockedUntil = &now
+	if err := w.repo.Update(ctx, batch); err != nil {
+		log.Printf("email batch: failed to release batch %s: %v", batch.ID, err)
+	}
+}
+
+// recipientDomain returns the domain part of a normalized address
+func recipientDomain(address string) string {
+	_, host, _ := strings.Cut(address, "@")
+	return host
+}
@@ -0,0 +1,298 @@
+package email
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"base-code-go-gin-clean/internal/config"
+)
+
+// minDKIMRSABits is the smallest RSA key verifiers accept (RFC 8301)
+const minDKIMRSABits = 1024
+
+// dkimSignedHeaders are the header fields covered by the signature when present.
+// From is listed twice so that a second From header added in transit breaks
+// the signature.
+var dkimSignedHeaders = []string{
+	"from", "from", "reply-to", "to", "cc", "subject", "date", "message-id",
+	"mime-version", "content-type", "content-transfer-encoding",
+	"list-unsubscribe", "list-unsubscribe-post",
+}
+
+// DKIMSigner adds DKIM-Signature headers (RFC 6376) to outgoing messages with
+// relaxed/relaxed canonicalization. Messages are signed with the key of their
+// From domain or its closest parent domain; mail from other domains is left
+// unsigned. A nil signer signs nothing.
+type DKIMSigner struct {
+	keys map[string]*dkimKey
+}
+
+type dkimKey struct {
+	domain    string
+	selector  string
+	algorithm string // "rsa-sha256" or "ed25519-sha256" (RFC 8463)
+	signer    crypto.Signer
+}
+
+// NewDKIMSigner loads the signing keys. It returns nil when no key is configured.
+func NewDKIMSigner(keys []config.DKIMKey) (*DKIMSigner, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	s := &DKIMSigner{keys: make(map[string]*dkimKey, len(keys))}
+	for _, k := range keys {
+		domain := strings.ToLower(strings.TrimSuffix(k.Domain, "."))
+		if _, ok := s.keys[domain]; ok {
+			return nil, fmt.Errorf("dkim: duplicate key for %s", domain)
+		}
+
+		key, err := parseDKIMKey(k.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("dkim: key for %s: %w", domain, err)
+		}
+		key.domain = domain
+		key.selector = k.Selector
+		s.keys[domain] = key
+	}
+	return s, nil
+}
+
+// Sign returns msg with a DKIM-Signature header prepended, or msg unchanged
+// when there is no key for its From domain. msg must use CRLF line endings.
+func (s *DKIMSigner) Sign(msg []byte, now time.Time) ([]byte, error) {
+	if s == nil {
+		return msg, nil
+	}
+
+	fields, body, err := splitMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	key := s.keyFor(fields)
+	if key == nil {
+		return msg, nil
+	}
+
+	bodyHash := sha256.Sum256(relaxedBody(body))
+
+	// Header fields are signed from the bottom up; names listed more often
+	// than they occur sign the absence of further instances
+	var headers bytes.Buffer
+	used := map[string]int{}
+	var names []string
+	for _, name := range dkimSignedHeaders {
+		if !containsField(fields, name) {
+			continue
+		}
+		names = append(names, name)
+		used[name]++
+		if f := nthFieldFromBottom(fields, name, used[name]); f != "" {
+			headers.WriteString(relaxedHeader(f))
+		}
+	}
+
+	tags := []string{
+		"v=1",
+		"a=" + key.algorithm,
+		"c=relaxed/relaxed",
+		"d=" + key.domain,
+		"s=" + key.selector,
+		"t=" + strconv.FormatInt(now.Unix(), 10),
+		"h=" + strings.Join(names, ":"),
+		"bh=" + base64.StdEncoding.EncodeToString(bodyHash[:]),
+		"b=",
+	}
+	// Folding keeps lines short; relaxed canonicalization undoes it before hashing
+	field := "DKIM-Signature: " + strings.Join(tags, ";\r\n\t")
+	headers.WriteString(strings.TrimSuffix(relaxedHeader(field+"\r\n"), "\r\n"))
+
+	digest := sha256.Sum256(headers.Bytes())
+	var opts crypto.SignerOpts = crypto.SHA256
+	if key.algorithm == "ed25519-sha256" {
+		// Ed25519 signs the SHA-256 digest itself (RFC 8463 section 3)
+		opts = crypto.Hash(0)
+	}
+	signature, err := key.signer.Sign(rand.Reader, digest[:], opts)
+	if err != nil {
+		return nil, fmt.Errorf("dkim: failed to sign: %w", err)
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(field) + 512 + len(msg))
+	out.WriteString(field)
+	out.WriteString(base64.StdEncoding.EncodeToString(signature))
+	out.WriteString("\r\n")
+	out.Write(msg)
+	return out.Bytes(), nil
+}
+
+// keyFor returns the key for the From domain or its closest parent domain
+func (s *DKIMSigner) keyFor(fields []string) *dkimKey {
+	from := nthFieldFromBottom(fields, "from", 1)
+	_, value, _ := strings.Cut(from, ":")
+	addr, err := mail.ParseAddress(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	_, domain, ok := strings.Cut(strings.ToLower(addr.Address), "@")
+	if !ok {
+		return nil
+	}
+
+	for {
+		if key, ok := s.keys[domain]; ok {
+			return key
+		}
+		_, parent, ok := strings.Cut(domain, ".")
+		if !ok || !strings.Contains(parent, ".") {
+			return nil
+		}
+		domain = parent
+	}
+}
+
+func parseDKIMKey(privateKey string) (*dkimKey, error) {
+	data := []byte(privateKey)
+	if !strings.Contains(privateKey, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM private key found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minDKIMRSABits {
+			return nil, fmt.Errorf("RSA key has %d bits, at least %d are required", k.N.BitLen(), minDKIMRSABits)
+		}
+		return &dkimKey{algorithm: "rsa-sha256", signer: k}, nil
+	case ed25519.PrivateKey:
+		return &dkimKey{algorithm: "ed25519-sha256", signer: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+}
+
+// splitMessage returns the raw header fields, each including its folded
+// continuation lines and final CRLF, and the body
+func splitMessage(msg []byte) ([]string, []byte, error) {
+	end := bytes.Index(msg, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil, nil, errors.New("dkim: message has no header/body separator")
+	}
+
+	var fields []string
+	for _, line := range strings.SplitAfter(string(msg[:end+2]), "\r\n") {
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(fields) > 0:
+			fields[len(fields)-1] += line
+		default:
+			fields = append(fields, line)
+		}
+	}
+	return fields, msg[end+4:], nil
+}
+
+func fieldName(field string) string {
+	name, _, _ := strings.Cut(field, ":")
+	return strings.ToLower(strings.TrimRight(name, " \t"))
+}
+
+func containsField(fields []string, name string) bool {
+	return nthFieldFromBottom(fields, name, 1) != ""
+}
+
+// nthFieldFromBottom returns the n-th last instance of the named field, or ""
+func nthFieldFromBottom(fields []string, name string, n int) string {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fieldName(fields[i]) == name {
+			if n--; n == 0 {
+				return fields[i]
+			}
+		}
+	}
+	return ""
+}
+
+// relaxedHeader canonicalizes a header field (RFC 6376 section 3.4.2): the
+// name is lower-cased, the value unfolded, runs of whitespace are reduced to
+// one space and whitespace around the colon and at the end is removed
+func relaxedHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+	return strings.ToLower(strings.TrimRight(name, " \t")) + ":" + strings.TrimSpace(collapseWhitespace(value)) + "\r\n"
+}
+
+// relaxedBody canonicalizes the body (RFC 6376 section 3.4.4): runs of
+// whitespace within lines become one space, trailing whitespace and trailing
+// empty lines are removed, and a non-empty body ends with CRLF
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(collapseWhitespace(line), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' || s[i] == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteByte(s[i])
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -1,54 +1,110 @@
 package email
 
 import (
+	"context"
 	"fmt"
-	"net/smtp"
+	"net/mail"
+	"time"
 
 	"base-code-go-gin-clean/internal/config"
 	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/mailer"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
 )
 
 type emailService struct {
-	smtpServer   string
-	smtpPort     string
-	smtpUsername string
-	smtpPassword string
 	from         string
-	sendMail     func(string, smtp.Auth, string, []string, []byte) error
+	transport    mailer.Transport
+	suppressions SuppressionService
+	dkim         *DKIMSigner
+	now          func() time.Time
 }
 
-func NewEmailService(cfg *config.Config) domain.EmailService {
+// NewEmailService creates an email service that builds MIME messages and
+// hands them to transport (SMTP, .eml file sink, log or memory). When
+// suppressions is set, mail to suppressed recipients is refused and bulk
+// mail carries one-click unsubscribe headers. When dkim is set, messages
+// are DKIM-signed before they are handed to the transport.
+func NewEmailService(cfg *config.Config, transport mailer.Transport, suppressions SuppressionService, dkim *DKIMSigner) domain.EmailService {
 	return &emailService{
-		smtpServer:   cfg.Email.SMTPServer,
-		smtpPort:     cfg.Email.SMTPPort,
-		smtpUsername: cfg.Email.SMTPUsername,
-		smtpPassword: cfg.Email.SMTPPassword,
 		from:         cfg.Email.From,
-		sendMail:     smtp.SendMail,
+		transport:    transport,
+		suppressions: suppressions,
+		dkim:         dkim,
+		now:          time.Now,
 	}
 }
 
-func (s *emailService) SendEmail(email *domain.Email) error {
-	auth := smtp.PlainAuth("", s.smtpUsername, s.smtpPassword, s.smtpServer)
-
-	headers := fmt.Sprintf("From: %s\r\n", s.from)
-	headers += fmt.Sprintf("To: %s\r\n", email.To[0])
-	headers += fmt.Sprintf("Subject: %s\r\n", email.Subject)
-	headers += "MIME-version: 1.0;\r\n"
-	headers += "Content-Type: text/html; charset=\"UTF-8\"\r\n"
-	headers += "\r\n"
-
-	msg := []byte(headers + email.Body)
-
-	err := s.sendMail(
-		fmt.Sprintf("%s:%s", s.smtpServer, s.smtpPort),
-		auth,
-		s.from,
-		email.To,
-		msg,
-	)
+// Deliverer is implemented by senders that report how the transport accepted
+// a message, such as the SMTP server's reply. The outbox worker records the
+// response in the delivery log.
+type Deliverer interface {
+	Deliver(ctx context.Context, email *domain.Email) (string, error)
+}
+
+func (s *emailService) SendEmail(ctx context.Context, email *domain.Email) error {
+	_, err := s.Deliver(ctx, email)
+	return err
+}
+
+func (s *emailService) Deliver(ctx context.Context, email *domain.Email) (string, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if err := email.Validate(); err != nil {
+		return "", err
+	}
+
+	sender, err := mail.ParseAddress(s.from)
 	if err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
+		return "", fmt.Errorf("invalid sender address %q: %w", s.from, err)
+	}
+
+	if s.suppressions != nil {
+		// Checked again at delivery: the address may have bounced since the message was queued
+		if err := s.suppressions.Check(ctx, email); err != nil {
+			return "", err
+		}
+		if email.Bulk {
+			email = withUnsubscribeHeaders(email, s.suppressions.UnsubscribeURL(email.To[0]))
+		}
+	}
+
+	now := s.now()
+	msg, err := buildMessage(s.from, email, now)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to build email: %w", err)
+	}
+	if msg, err = s.dkim.Sign(msg, now); err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to sign email: %w", err)
+	}
+
+	// The envelope takes bare addresses; display names only belong in the headers
+	recipients := email.Recipients()
+	for i, rcpt := range recipients {
+		addr, _ := mail.ParseAddress(rcpt) // checked by Validate
+		recipients[i] = addr.Address
+	}
+
+	response, err := mailer.Deliver(ctx, s.transport, sender.Address, recipients, msg)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to send email: %w", err)
+	}
+	return response, nil
+}
+
+// withUnsubscribeHeaders returns a copy of email with the RFC 8058 one-click
+// unsubscribe headers, which mail clients show as an unsubscribe button
+func withUnsubscribeHeaders(email *domain.Email, unsubscribeURL string) *domain.Email {
+	c := *email
+	c.Headers = make(map[string]string, len(email.Headers)+2)
+	for name, value := range email.Headers {
+		c.Headers[name] = value
 	}
-	return nil
+	c.Headers["List-Unsubscribe"] = "<" + unsubscribeURL + ">"
+	c.Headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	return &c
 }
@@ -2,63 +2,51 @@
 package email
 
 import (
+	"context"
 	"testing"
 
 	"base-code-go-gin-clean/internal/config"
 	"base-code-go-gin-clean/internal/domain/email"
-
-	"net/smtp"
+	"base-code-go-gin-clean/internal/pkg/mailer"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
-// Mock implementation
-type MockSender struct {
-	mock.Mock
-}
-
-func (m *MockSender) Send(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
-	args := m.Called(addr, a, from, to, msg)
-	return args.Error(0)
-}
 func TestEmailService_SendEmail(t *testing.T) {
-	called := false
-
 	cfg := &config.Config{
 		Email: config.EmailConfig{
-			SMTPServer:   "localhost",
-			SMTPPort:     "1025",
-			SMTPUsername: "test",
-			SMTPPassword: "test",
-			From:         "test@example.com",
-		},
-	}
-
-	svc := &emailService{
-		smtpServer:   cfg.Email.SMTPServer,
-		smtpPort:     cfg.Email.SMTPPort,
-		smtpUsername: cfg.Email.SMTPUsername,
-		smtpPassword: cfg.Email.SMTPPassword,
-		from:         cfg.Email.From,
-		sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
-			called = true
-			assert.Equal(t, "localhost:1025", addr)
-			assert.Equal(t, "test@example.com", from)
-			assert.Equal(t, []string{"test@example.com"}, to)
-			assert.Contains(t, string(msg), "Test Subject")
-			return nil
+			From: "App <test@example.com>",
 		},
 	}
+	transport := mailer.NewMemoryTransport()
+	svc := NewEmailService(cfg, transport, nil, nil)
 
 	testEmail := &email.Email{
 		To:      []string{"test@example.com"},
+		Bcc:     []string{"The Boss <boss@example.com>"},
 		Subject: "Test Subject",
 		Body:    "Test body",
 	}
 
-	err := svc.SendEmail(testEmail)
+	err := svc.SendEmail(context.Background(), testEmail)
 
 	assert.NoError(t, err)
-	assert.True(t, called, "Expected sendMail to be called")
+	messages := transport.Messages()
+	if !assert.Len(t, messages, 1, "Expected the transport to be called") {
+		return
+	}
+	assert.Equal(t, "test@example.com", messages[0].From)
+	assert.Equal(t, []string{"test@example.com", "boss@example.com"}, messages[0].To)
+	assert.Contains(t, string(messages[0].Data), "Test Subject")
+	assert.NotContains(t, string(messages[0].Data), "boss@example.com", "Bcc must not appear in the headers")
+}
+
+func TestEmailService_SendEmailRejectsInvalidMessage(t *testing.T) {
+	transport := mailer.NewMemoryTransport()
+	svc := NewEmailService(&config.Config{Email: config.EmailConfig{From: "test@example.com"}}, transport, nil, nil)
+
+	err := svc.SendEmail(context.Background(), &email.Email{To: []string{"not-an-address"}, Subject: "Hi"})
+
+	assert.ErrorIs(t, err, email.ErrInvalidEmail)
+	assert.Empty(t, transport.Messages())
 }
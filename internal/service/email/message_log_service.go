@@ -0,0 +1,326 @@
+package email
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTrackingToken is returned for tracking links that were not issued by this service
+var ErrInvalidTrackingToken = errors.New("invalid tracking token")
+
+const (
+	defaultMessageLogPageSize = 50
+	maxMessageLogPageSize     = 200
+)
+
+// Public endpoints that tracking links point to; the token is appended
+const (
+	openTrackingPath  = "/api/v1/email/track/open/"
+	clickTrackingPath = "/api/v1/email/track/click/"
+)
+
+// trackedLink matches the href of anchors in an HTML body
+var trackedLink = regexp.MustCompile(`(?i)(<a\b[^>]*?\shref\s*=\s*)(?:"([^"]*)"|'([^']*)')`)
+
+// bodyEnd matches the closing body tag, before which the open pixel is placed
+var bodyEnd = regexp.MustCompile(`(?i)</body\s*>`)
+
+// MessageLogList is one page of the delivery log
+type MessageLogList struct {
+	Messages []*domain.MessageLog `json:"messages"`
+	Total    int                  `json:"total"`
+}
+
+// MessageLogService keeps the delivery log of queued mail and tracks opens and
+// clicks through links served by our own endpoints
+type MessageLogService interface {
+	// Instrument returns email with an open-tracking pixel and click-tracking
+	// links for message id, as configured. Emails without an HTML body are
+	// returned unchanged.
+	Instrument(id uuid.UUID, email *domain.Email) *domain.Email
+	// Record creates the log entry of a newly queued message, with the trace
+	// ID of the API request that queued it
+	Record(ctx context.Context, msg *domain.OutboxMessage) error
+	// RecordDelivery updates the entry after a delivery attempt with the
+	// outcome and the transport's response
+	RecordDelivery(ctx context.Context, msg *domain.OutboxMessage, response string) error
+
+	// TrackOpen counts an open. Only invalid tokens are reported; recording
+	// failures are logged so the pixel is always served.
+	TrackOpen(ctx context.Context, token string) error
+	// TrackClick counts a click and returns the original link. Only invalid
+	// tokens are reported; recording failures are logged so links keep working.
+	TrackClick(ctx context.Context, token string) (string, error)
+
+	// Get returns the log entry of a message
+	Get(ctx context.Context, id string) (*domain.MessageLog, error)
+	// List returns a page of the log, newest first
+	List(ctx context.Context, filter domain.MessageLogFilter) (*MessageLogList, error)
+	// Stats returns delivery and engagement counts per template
+	Stats(ctx context.Context, since, until *time.Time) ([]*domain.TemplateStats, error)
+}
+
+// MessageLogServiceConfig holds the dependencies and settings of the message log service
+type MessageLogServiceConfig struct {
+	Repo        domain.MessageLogRepository
+	SigningKey  string // HMAC key for tracking links
+	BaseURL     string // Public URL of the API, used in tracking links
+	TrackOpens  bool   // Add an open-tracking pixel to HTML mail
+	TrackClicks bool   // Route links in HTML mail through the click-tracking endpoint
+}
+
+type messageLogService struct {
+	repo        domain.MessageLogRepository
+	signingKey  []byte
+	baseURL     string
+	trackOpens  bool
+	trackClicks bool
+	now         func() time.Time
+}
+
+func NewMessageLogService(cfg MessageLogServiceConfig) MessageLogService {
+	return &messageLogService{
+		repo:        cfg.Repo,
+		signingKey:  []byte(cfg.SigningKey),
+		baseURL:     strings.TrimRight(cfg.BaseURL, "/"),
+		trackOpens:  cfg.TrackOpens,
+		trackClicks: cfg.TrackClicks,
+		now:         time.Now,
+	}
+}
+
+func (s *messageLogService) tracks(email *domain.Email) bool {
+	return (s.trackOpens || s.trackClicks) && email.Body != ""
+}
+
+func (s *messageLogService) Instrument(id uuid.UUID, email *domain.Email) *domain.Email {
+	if !s.tracks(email) {
+		return email
+	}
+
+	c := *email
+	if s.trackClicks {
+		c.Body = trackedLink.ReplaceAllStringFunc(c.Body, func(anchor string) string {
+			m := trackedLink.FindStringSubmatch(anchor)
+			link := html.UnescapeString(m[2] + m[3])
+			if !s.trackable(link) {
+				return anchor
+			}
+			return m[1] + `"` + s.baseURL + clickTrackingPath + s.token("click", append(id[:], link...)) + `"`
+		})
+	}
+	if s.trackOpens {
+		pixel := `<img src="` + s.baseURL + openTrackingPath + s.token("open", id[:]) +
+			`" width="1" height="1" alt="" style="display:block;width:1px;height:1px;border:0" />`
+		if loc := bodyEnd.FindAllStringIndex(c.Body, -1); len(loc) > 0 {
+			end := loc[len(loc)-1][0]
+			c.Body = c.Body[:end] + pixel + c.Body[end:]
+		} else {
+			c.Body += pixel
+		}
+	}
+	return &c
+}
+
+// trackable reports whether a link is rewritten: web links other than our
+// own unsubscribe and tracking endpoints
+func (s *messageLogService) trackable(link string) bool {
+	lower := strings.ToLower(link)
+	if !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(lower, "http://") {
+		return false
+	}
+	for _, path := range []string{unsubscribePath, openTrackingPath, clickTrackingPath} {
+		if strings.HasPrefix(link, s.baseURL+path) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *messageLogService) Record(ctx context.Context, msg *domain.OutboxMessage) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var recipients []string
+	for _, rcpt := range msg.Payload.Recipients() {
+		if addr := normalizeAddress(rcpt); addr != "" {
+			recipients = append(recipients, addr)
+		}
+	}
+
+	entry := &domain.MessageLog{
+		ID:         msg.ID,
+		Template:   msg.Payload.Template,
+		Subject:    msg.Payload.Subject,
+		Recipients: recipients,
+		Bulk:       msg.Payload.Bulk,
+		Status:     msg.Status,
+		TraceID:    telemetry.TraceID(ctx),
+		Tracked:    s.tracks(&msg.Payload),
+	}
+	if err := s.repo.Create(ctx, entry); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to log message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+func (s *messageLogService) RecordDelivery(ctx context.Context, msg *domain.OutboxMessage, response string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	entry := &domain.MessageLog{
+		ID:               msg.ID,
+		Status:           msg.Status,
+		Attempts:         msg.Attempts,
+		ProviderResponse: response,
+		LastError:        msg.LastError,
+		SentAt:           msg.SentAt,
+	}
+	if err := s.repo.UpdateDelivery(ctx, entry); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Queued before the delivery log existed
+			return nil
+		}
+		span.RecordError(err)
+		return fmt.Errorf("failed to log delivery of message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+func (s *messageLogService) TrackOpen(ctx context.Context, token string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	payload, err := s.verifyToken("open", token)
+	if err != nil || len(payload) != len(uuid.UUID{}) {
+		return ErrInvalidTrackingToken
+	}
+
+	s.recordEvent(ctx, uuid.UUID(payload), domain.TrackingOpen)
+	return nil
+}
+
+func (s *messageLogService) TrackClick(ctx context.Context, token string) (string, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	payload, err := s.verifyToken("click", token)
+	if err != nil || len(payload) <= len(uuid.UUID{}) {
+		return "", ErrInvalidTrackingToken
+	}
+
+	id := uuid.UUID(payload[:len(uuid.UUID{})])
+	s.recordEvent(ctx, id, domain.TrackingClick)
+	return string(payload[len(uuid.UUID{}):]), nil
+}
+
+func (s *messageLogService) recordEvent(ctx context.Context, id uuid.UUID, event domain.TrackingEvent) {
+	if err := s.repo.RecordEvent(ctx, id, event, s.now()); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("email: failed to record %s of message %s: %v", event, id, err)
+	}
+}
+
+func (s *messageLogService) Get(ctx context.Context, id string) (*domain.MessageLog, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	msgID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message ID format: %v", err)
+	}
+
+	entry, err := s.repo.GetByID(ctx, msgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *messageLogService) List(ctx context.Context, filter domain.MessageLogFilter) (*MessageLogList, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, fmt.Errorf("invalid message status: %q", filter.Status)
+	}
+	if filter.Recipient != "" {
+		if addr := normalizeAddress(filter.Recipient); addr != "" {
+			filter.Recipient = addr
+		}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultMessageLogPageSize
+	}
+	filter.Limit = min(filter.Limit, maxMessageLogPageSize)
+	filter.Offset = max(filter.Offset, 0)
+
+	entries, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return &MessageLogList{Messages: entries, Total: total}, nil
+}
+
+func (s *messageLogService) Stats(ctx context.Context, since, until *time.Time) ([]*domain.TemplateStats, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	stats, err := s.repo.Stats(ctx, since, until)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	for _, st := range stats {
+		if st.Tracked > 0 {
+			st.OpenRate = float64(st.Opened) / float64(st.Tracked)
+			st.ClickRate = float64(st.Clicked) / float64(st.Tracked)
+		}
+	}
+	return stats, nil
+}
+
+// token encodes payload with its HMAC, so tracking links need no server-side state
+func (s *messageLogService) token(kind string, payload []byte) string {
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + s.sign(kind, payload)
+}
+
+func (s *messageLogService) verifyToken(kind, token string) ([]byte, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidTrackingToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !hmac.Equal([]byte(s.sign(kind, payload)), []byte(signature)) {
+		return nil, ErrInvalidTrackingToken
+	}
+	return payload, nil
+}
+
+func (s *messageLogService) sign(kind string, payload []byte) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(kind + "\n"))
+	mac.Write(payload)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
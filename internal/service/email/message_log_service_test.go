@@ -0,0 +1,305 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/config"
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockMessageLogRepository struct {
+	mock.Mock
+}
+
+func (m *mockMessageLogRepository) Create(ctx context.Context, entry *domain.MessageLog) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *mockMessageLogRepository) UpdateDelivery(ctx context.Context, entry *domain.MessageLog) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *mockMessageLogRepository) RecordEvent(ctx context.Context, id uuid.UUID, event domain.TrackingEvent, at time.Time) error {
+	return m.Called(ctx, id, event, at).Error(0)
+}
+
+func (m *mockMessageLogRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.MessageLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MessageLog), args.Error(1)
+}
+
+func (m *mockMessageLogRepository) List(ctx context.Context, filter domain.MessageLogFilter) ([]*domain.MessageLog, int, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.MessageLog), args.Int(1), args.Error(2)
+}
+
+func (m *mockMessageLogRepository) Stats(ctx context.Context, since, until *time.Time) ([]*domain.TemplateStats, error) {
+	args := m.Called(ctx, since, until)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TemplateStats), args.Error(1)
+}
+
+func newTestMessageLogService(repo *mockMessageLogRepository, opens, clicks bool) *messageLogService {
+	return NewMessageLogService(MessageLogServiceConfig{
+		Repo:        repo,
+		SigningKey:  "test-key",
+		BaseURL:     "https://app.example.com/",
+		TrackOpens:  opens,
+		TrackClicks: clicks,
+	}).(*messageLogService)
+}
+
+var trackingToken = regexp.MustCompile(`/api/v1/email/track/(open|click)/([A-Za-z0-9_.-]+)`)
+
+func TestMessageLogService_Instrument(t *testing.T) {
+	svc := newTestMessageLogService(new(mockMessageLogRepository), true, true)
+	id := uuid.New()
+	email := &domain.Email{
+		To:      []string{"jane@example.com"},
+		Subject: "Hi",
+		Body: `<html><body><p><a class="btn" href="https://example.org/reset?token=abc&amp;lang=de">Reset</a> ` +
+			`<a href='mailto:help@example.com'>Help</a> ` +
+			`<a href="https://app.example.com/api/v1/email/unsubscribe?token=x">Unsubscribe</a></p></BODY></html>`,
+		Text: "Reset: https://example.org/reset?token=abc&lang=de",
+	}
+
+	instrumented := svc.Instrument(id, email)
+
+	assert.NotSame(t, email, instrumented)
+	assert.Contains(t, email.Body, `href="https://example.org/reset`, "the original is not modified")
+	assert.Equal(t, email.Text, instrumented.Text, "the plain-text part is not rewritten")
+	assert.Contains(t, instrumented.Body, `<a class="btn" href="https://app.example.com/api/v1/email/track/click/`)
+	assert.Contains(t, instrumented.Body, `href='mailto:help@example.com'`)
+	assert.Contains(t, instrumented.Body, `href="https://app.example.com/api/v1/email/unsubscribe?token=x"`)
+	assert.Regexp(t, `<img src="https://app.example.com/api/v1/email/track/open/[^"]+" width="1" height="1"[^>]*/></BODY></html>$`, instrumented.Body)
+
+	tokens := trackingToken.FindAllStringSubmatch(instrumented.Body, -1)
+	if !assert.Len(t, tokens, 2) {
+		return
+	}
+	repo := svc.repo.(*mockMessageLogRepository)
+	repo.On("RecordEvent", mock.Anything, id, domain.TrackingClick, mock.Anything).Return(nil).Once()
+	repo.On("RecordEvent", mock.Anything, id, domain.TrackingOpen, mock.Anything).Return(nil).Once()
+
+	link, err := svc.TrackClick(context.Background(), tokens[0][2])
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.org/reset?token=abc&lang=de", link)
+	assert.NoError(t, svc.TrackOpen(context.Background(), tokens[1][2]))
+	repo.AssertExpectations(t)
+
+	t.Run("disabled or plain-text mail is left alone", func(t *testing.T) {
+		off := newTestMessageLogService(new(mockMessageLogRepository), false, false)
+		assert.Same(t, email, off.Instrument(id, email))
+
+		text := &domain.Email{To: []string{"jane@example.com"}, Text: "https://example.org"}
+		assert.Same(t, text, svc.Instrument(id, text))
+	})
+}
+
+func TestMessageLogService_TrackingTokens(t *testing.T) {
+	repo := new(mockMessageLogRepository)
+	svc := newTestMessageLogService(repo, true, true)
+	id := uuid.New()
+	clickToken := svc.token("click", append(id[:], "https://example.org"...))
+	openToken := svc.token("open", id[:])
+
+	// A valid token of one kind is not accepted as the other, and links cannot be swapped
+	_, err := svc.TrackClick(context.Background(), openToken)
+	assert.ErrorIs(t, err, ErrInvalidTrackingToken)
+	assert.ErrorIs(t, svc.TrackOpen(context.Background(), clickToken), ErrInvalidTrackingToken)
+
+	encoded, signature, _ := strings.Cut(clickToken, ".")
+	forged := svc.token("click", append(id[:], "https://evil.example"...))
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+	for _, bad := range []string{"", "garbage", encoded + ".AAAA", forgedPayload + "." + signature} {
+		_, err := svc.TrackClick(context.Background(), bad)
+		assert.ErrorIs(t, err, ErrInvalidTrackingToken, bad)
+	}
+
+	other := newTestMessageLogService(repo, true, true)
+	other.signingKey = []byte("other-key")
+	_, err = other.TrackClick(context.Background(), clickToken)
+	assert.ErrorIs(t, err, ErrInvalidTrackingToken)
+
+	// Recording failures do not break the link
+	repo.On("RecordEvent", mock.Anything, id, domain.TrackingClick, mock.Anything).Return(errors.New("db down")).Once()
+	link, err := svc.TrackClick(context.Background(), clickToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.org", link)
+}
+
+func TestMessageLogService_Record(t *testing.T) {
+	repo := new(mockMessageLogRepository)
+	svc := newTestMessageLogService(repo, true, false)
+	msg := &domain.OutboxMessage{
+		ID:     uuid.New(),
+		Status: domain.MessageQueued,
+		Payload: domain.Email{
+			To:       []string{"Jane <Jane@Example.com>"},
+			Bcc:      []string{"audit@example.com"},
+			Subject:  "Reset your password",
+			Body:     "<p>Hi</p>",
+			Template: "password_reset",
+		},
+	}
+	traceID := telemetry.NewTraceID()
+	ctx := telemetry.ContextWithTraceID(context.Background(), traceID)
+
+	repo.On("Create", mock.Anything, &domain.MessageLog{
+		ID:         msg.ID,
+		Template:   "password_reset",
+		Subject:    "Reset your password",
+		Recipients: []string{"jane@example.com", "audit@example.com"},
+		Status:     domain.MessageQueued,
+		TraceID:    traceID.String(),
+		Tracked:    true,
+	}).Return(nil).Once()
+
+	assert.NoError(t, svc.Record(ctx, msg))
+
+	sentAt := time.Now()
+	msg.Status, msg.Attempts, msg.SentAt = domain.MessageSent, 1, &sentAt
+	repo.On("UpdateDelivery", mock.Anything, &domain.MessageLog{
+		ID:               msg.ID,
+		Status:           domain.MessageSent,
+		Attempts:         1,
+		ProviderResponse: "250 OK queued as 1234",
+		SentAt:           &sentAt,
+	}).Return(nil).Once()
+
+	assert.NoError(t, svc.RecordDelivery(context.Background(), msg, "250 OK queued as 1234"))
+
+	// Messages queued before the log existed have no entry to update
+	repo.On("UpdateDelivery", mock.Anything, mock.Anything).Return(sql.ErrNoRows).Once()
+	assert.NoError(t, svc.RecordDelivery(context.Background(), msg, ""))
+	repo.AssertExpectations(t)
+}
+
+func TestMessageLogService_Query(t *testing.T) {
+	repo := new(mockMessageLogRepository)
+	svc := newTestMessageLogService(repo, false, false)
+	ctx := context.Background()
+
+	repo.On("List", mock.Anything, domain.MessageLogFilter{Recipient: "jane@example.com", Status: domain.MessageDead, Limit: 200}).
+		Return([]*domain.MessageLog{{Subject: "Hi"}}, 1, nil).Once()
+	list, err := svc.List(ctx, domain.MessageLogFilter{Recipient: "Jane@Example.com", Status: domain.MessageDead, Limit: 1000, Offset: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+
+	_, err = svc.List(ctx, domain.MessageLogFilter{Status: "lost"})
+	assert.ErrorContains(t, err, "invalid message status")
+
+	id := uuid.New()
+	repo.On("GetByID", mock.Anything, id).Return(nil, sql.ErrNoRows).Once()
+	_, err = svc.Get(ctx, id.String())
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+
+	repo.On("Stats", mock.Anything, (*time.Time)(nil), (*time.Time)(nil)).Return([]*domain.TemplateStats{
+		{Template: "welcome", Total: 12, Sent: 10, Tracked: 8, Opened: 4, Clicked: 1},
+		{Template: "", Total: 3, Sent: 3},
+	}, nil).Once()
+	stats, err := svc.Stats(ctx, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, stats[0].OpenRate)
+	assert.Equal(t, 0.125, stats[0].ClickRate)
+	assert.Zero(t, stats[1].OpenRate)
+}
+
+func TestOutbox_MessageLog(t *testing.T) {
+	logRepo := new(mockMessageLogRepository)
+	messages := newTestMessageLogService(logRepo, true, true)
+
+	t.Run("enqueue records and instruments the message", func(t *testing.T) {
+		repo := &mockOutboxRepository{}
+		svc := NewOutboxService(OutboxServiceConfig{Repo: repo, Log: messages})
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*email.OutboxMessage")).Return(nil).Once()
+		logRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *domain.MessageLog) bool {
+			return e.Template == "welcome" && e.Tracked
+		})).Return(errors.New("db down")).Once()
+
+		msg, err := svc.Enqueue(context.Background(), &domain.Email{
+			To: []string{"jane@example.com"}, Subject: "Hi", Body: `<a href="https://example.org">Go</a>`, Template: "welcome",
+		})
+
+		assert.NoError(t, err, "a failed log entry does not fail the queued message")
+		assert.NotEqual(t, uuid.Nil, msg.ID)
+		link := trackingToken.FindStringSubmatch(msg.Payload.Body)
+		if assert.NotNil(t, link) {
+			assert.Equal(t, "click", link[1])
+		}
+	})
+
+	t.Run("worker records the provider response", func(t *testing.T) {
+		repo := &mockOutboxRepository{}
+		transport := &respondingTransport{response: "250 2.0.0 Ok: queued as 4F2A1"}
+		w := NewOutboxWorker(OutboxWorkerConfig{
+			Repo:   repo,
+			Sender: NewEmailService(&config.Config{Email: config.EmailConfig{From: "app@example.com"}}, transport, nil, nil),
+			Log:    messages,
+		})
+		msg := &domain.OutboxMessage{ID: uuid.New(), Status: domain.MessageSending, Attempts: 1, MaxAttempts: 3,
+			Payload: domain.Email{To: []string{"jane@example.com"}, Subject: "Hi", Body: "<p>Hi</p>"}}
+
+		repo.On("ClaimDue", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*domain.OutboxMessage{msg}, nil)
+		repo.On("Update", mock.Anything, msg).Return(nil)
+		logRepo.On("UpdateDelivery", mock.Anything, mock.MatchedBy(func(e *domain.MessageLog) bool {
+			return e.ID == msg.ID && e.Status == domain.MessageSent && e.ProviderResponse == "250 2.0.0 Ok: queued as 4F2A1"
+		})).Return(nil).Once()
+
+		w.ProcessDue(context.Background())
+
+		logRepo.AssertExpectations(t)
+	})
+}
+
+// respondingTransport accepts every message with a fixed server response
+type respondingTransport struct {
+	response string
+}
+
+func (t *respondingTransport) Send(ctx context.Context, from string, to []string, msg []byte) error {
+	_, err := t.SendWithResponse(ctx, from, to, msg)
+	return err
+}
+
+func (t *respondingTransport) SendWithResponse(ctx context.Context, from string, to []string, msg []byte) (string, error) {
+	return t.response, ctx.Err()
+}
+
+func (t *respondingTransport) Close() error {
+	return nil
+}
+
+func TestMessageLogService_OpenPixelURL(t *testing.T) {
+	svc := newTestMessageLogService(new(mockMessageLogRepository), true, false)
+	body := svc.Instrument(uuid.New(), &domain.Email{To: []string{"a@example.com"}, Body: "<p>Hi</p>"}).Body
+
+	m := regexp.MustCompile(`src="([^"]+)"`).FindStringSubmatch(body)
+	if !assert.NotNil(t, m) {
+		return
+	}
+	u, err := url.Parse(m[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "app.example.com", u.Host)
+	assert.True(t, strings.HasSuffix(body, `/>`), "without a body tag the pixel is appended")
+}
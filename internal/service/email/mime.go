@@ -0,0 +1,264 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+)
+
+// base64LineLength is the maximum encoded line length allowed by RFC 2045
+const base64LineLength = 76
+
+// mimePart is a node of the MIME tree: either a leaf with an encoded body or
+// a multipart container of children.
+type mimePart struct {
+	header   textproto.MIMEHeader
+	body     []byte
+	subtype  string
+	children []*mimePart
+}
+
+// buildMessage renders email as an RFC 5322 message. The part layout is
+//
+//	multipart/mixed                 when there are regular attachments
+//	  multipart/related             when there are inline attachments
+//	    multipart/alternative       when both Text and Body are set
+//	      text/plain
+//	      text/html
+//	    inline attachments
+//	  attachments
+//
+// with each container left out when it would hold a single part.
+func buildMessage(from string, email *domain.Email, date time.Time) ([]byte, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", fromAddr.String())
+	if err := writeAddressHeader(&buf, "To", email.To); err != nil {
+		return nil, err
+	}
+	if err := writeAddressHeader(&buf, "Cc", email.Cc); err != nil {
+		return nil, err
+	}
+	if email.ReplyTo != "" {
+		if err := writeAddressHeader(&buf, "Reply-To", []string{email.ReplyTo}); err != nil {
+			return nil, err
+		}
+	}
+	writeHeader(&buf, "Subject", encodeHeaderValue(email.Subject))
+	writeHeader(&buf, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", newMessageID(fromAddr.Address))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	names := make([]string, 0, len(email.Headers))
+	for name := range email.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(&buf, textproto.CanonicalMIMEHeaderKey(name), encodeHeaderValue(email.Headers[name]))
+	}
+
+	header, body, err := messageBody(email).render()
+	if err != nil {
+		return nil, err
+	}
+	writeMIMEHeader(&buf, header)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// messageBody assembles the MIME tree for the email's content
+func messageBody(email *domain.Email) *mimePart {
+	var body *mimePart
+	switch {
+	case email.Text != "" && email.Body != "":
+		body = &mimePart{subtype: "alternative", children: []*mimePart{
+			textPart("text/plain", email.Text),
+			textPart("text/html", email.Body),
+		}}
+	case email.Text != "":
+		body = textPart("text/plain", email.Text)
+	default:
+		body = textPart("text/html", email.Body)
+	}
+
+	var inline, attached []*mimePart
+	for _, a := range email.Attachments {
+		if a.Inline() {
+			inline = append(inline, attachmentPart(a))
+		} else {
+			attached = append(attached, attachmentPart(a))
+		}
+	}
+
+	if len(inline) > 0 {
+		body = &mimePart{subtype: "related", children: append([]*mimePart{body}, inline...)}
+	}
+	if len(attached) > 0 {
+		body = &mimePart{subtype: "mixed", children: append([]*mimePart{body}, attached...)}
+	}
+	return body
+}
+
+// render returns the part's MIME header and encoded body
+func (p *mimePart) render() (textproto.MIMEHeader, []byte, error) {
+	if p.children == nil {
+		return p.header, p.body, nil
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, child := range p.children {
+		header, body, err := child.render()
+		if err != nil {
+			return nil, nil, err
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := w.Write(body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+p.subtype, map[string]string{"boundary": mw.Boundary()}))
+	return header, buf.Bytes(), nil
+}
+
+func textPart(contentType, content string) *mimePart {
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(content))
+	qp.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+func attachmentPart(a domain.Attachment) *mimePart {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(a.Filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	disposition := "attachment"
+	if a.Inline() {
+		disposition = "inline"
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", withParam(contentType, "name", a.Filename))
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	if a.Inline() {
+		header.Set("Content-ID", "<"+a.ContentID+">")
+	}
+	return &mimePart{header: header, body: wrapBase64(a.Content)}
+}
+
+// withParam adds a parameter to a media type, keeping any existing parameters
+func withParam(contentType, key, value string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "application/octet-stream", map[string]string{}
+	}
+	params[key] = value
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// wrapBase64 encodes data as base64 in lines of at most 76 characters
+func wrapBase64(data []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf bytes.Buffer
+	for len(encoded) > base64LineLength {
+		buf.WriteString(encoded[:base64LineLength])
+		buf.WriteString("\r\n")
+		encoded = encoded[base64LineLength:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
+
+// encodeHeaderValue encodes non-ASCII text as RFC 2047 encoded words
+func encodeHeaderValue(value string) string {
+	return mime.QEncoding.Encode("UTF-8", value)
+}
+
+// writeAddressHeader writes an address list with display names encoded as needed.
+// Addresses are folded onto continuation lines to respect the line length limit.
+func writeAddressHeader(buf *bytes.Buffer, name string, addrs []string) error {
+	if len(addrs) == 0 {
+		return nil
+	}
+	formatted := make([]string, len(addrs))
+	for i, a := range addrs {
+		addr, err := mail.ParseAddress(a)
+		if err != nil {
+			return fmt.Errorf("invalid %s address %q: %w", name, a, err)
+		}
+		formatted[i] = addr.String()
+	}
+	writeHeader(buf, name, strings.Join(formatted, ",\r\n "))
+	return nil
+}
+
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+func writeMIMEHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			writeHeader(buf, k, v)
+		}
+	}
+}
+
+// newMessageID returns a globally unique Message-ID in the sender's domain
+func newMessageID(fromAddress string) string {
+	domainPart := "localhost"
+	if at := strings.LastIndex(fromAddress, "@"); at >= 0 {
+		domainPart = fromAddress[at+1:]
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domainPart + ">"
+}
@@ -0,0 +1,138 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2025, 8, 14, 9, 0, 0, 0, time.UTC)
+
+	t.Run("html only", func(t *testing.T) {
+		raw, err := buildMessage("App <noreply@example.com>", &email.Email{
+			To:      []string{"Jörg Müller <jorg@example.com>"},
+			Subject: "Grüße aus Köln",
+			Body:    "<p>Hello</p>",
+		}, date)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// Non-ASCII headers are RFC 2047 encoded and decode back to the original
+		assert.NotContains(t, msg.Header.Get("Subject"), "ü")
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		assert.NoError(t, err)
+		assert.Equal(t, "Grüße aus Köln", subject)
+
+		to, err := msg.Header.AddressList("To")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Jörg Müller", to[0].Name)
+		}
+		assert.Equal(t, "1.0", msg.Header.Get("MIME-Version"))
+		assert.Contains(t, msg.Header.Get("Message-ID"), "@example.com>")
+		assert.Equal(t, "text/html; charset=UTF-8", msg.Header.Get("Content-Type"))
+	})
+
+	t.Run("alternative, inline image and attachment", func(t *testing.T) {
+		raw, err := buildMessage("noreply@example.com", &email.Email{
+			To:      []string{"a@example.com"},
+			Cc:      []string{"b@example.com"},
+			Bcc:     []string{"hidden@example.com"},
+			ReplyTo: "support@example.com",
+			Subject: "Report",
+			Body:    `<p>See <img src="cid:logo"></p>`,
+			Text:    "See the attached report",
+			Headers: map[string]string{"X-Campaign": "weekly"},
+			Attachments: []email.Attachment{
+				{Filename: "logo.png", Content: []byte("\x89PNG"), ContentID: "logo"},
+				{Filename: "report.csv", Content: []byte("a,b\n1,2\n")},
+			},
+		}, date)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "b@example.com", strings.Trim(msg.Header.Get("Cc"), "<>"))
+		assert.Equal(t, "support@example.com", strings.Trim(msg.Header.Get("Reply-To"), "<>"))
+		assert.Equal(t, "weekly", msg.Header.Get("X-Campaign"))
+		assert.Empty(t, msg.Header.Get("Bcc"))
+		assert.NotContains(t, string(raw), "hidden@example.com")
+
+		// mixed → [related → [alternative → [text, html], logo], report]
+		mixed := readParts(t, msg.Header.Get("Content-Type"), msg.Body)
+		if !assert.Len(t, mixed, 2) {
+			return
+		}
+		assert.Equal(t, `attachment; filename=report.csv`, mixed[1].header.Get("Content-Disposition"))
+		assert.Equal(t, "a,b\n1,2\n", string(mixed[1].body))
+
+		related := readParts(t, mixed[0].header.Get("Content-Type"), bytes.NewReader(mixed[0].body))
+		if !assert.Len(t, related, 2) {
+			return
+		}
+		assert.Equal(t, "<logo>", related[1].header.Get("Content-ID"))
+		assert.Equal(t, "image/png; name=logo.png", related[1].header.Get("Content-Type"))
+
+		alternative := readParts(t, related[0].header.Get("Content-Type"), bytes.NewReader(related[0].body))
+		if !assert.Len(t, alternative, 2) {
+			return
+		}
+		assert.Equal(t, "See the attached report", string(alternative[0].body))
+		assert.Equal(t, `<p>See <img src="cid:logo"></p>`, string(alternative[1].body))
+	})
+}
+
+type parsedPart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// readParts decodes one level of a multipart body
+func readParts(t *testing.T, contentType string, body io.Reader) []parsedPart {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if !assert.NoError(t, err) || !assert.True(t, strings.HasPrefix(mediaType, "multipart/")) {
+		return nil
+	}
+
+	var parts []parsedPart
+	mr := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		if !assert.NoError(t, err) {
+			return nil
+		}
+
+		// NextPart decodes quoted-printable transparently; base64 is left to us
+		var r io.Reader = p
+		if p.Header.Get("Content-Transfer-Encoding") == "base64" {
+			r = base64.NewDecoder(base64.StdEncoding, p)
+		}
+		data, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		parts = append(parts, parsedPart{header: p.Header, body: data})
+	}
+}
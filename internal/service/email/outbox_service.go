@@ -0,0 +1,134 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+)
+
+// ErrMessageNotFound is returned when an outbox message does not exist
+var ErrMessageNotFound = errors.New("email message not found")
+
+const defaultMaxAttempts = 8
+
+// OutboxService queues emails for asynchronous delivery by OutboxWorker.
+// Its SendEmail method enqueues, so it can be handed to any code that depends
+// on domain.EmailService without that code waiting on SMTP.
+type OutboxService interface {
+	domain.EmailService
+	// Enqueue persists a message for delivery, at email.SendAt when set, and
+	// returns it with its ID
+	Enqueue(ctx context.Context, email *domain.Email) (*domain.OutboxMessage, error)
+	// GetMessage returns the delivery status of a queued message
+	GetMessage(ctx context.Context, id string) (*domain.OutboxMessage, error)
+}
+
+// OutboxServiceConfig holds the dependencies and settings of the outbox service
+type OutboxServiceConfig struct {
+	Repo         domain.OutboxRepository
+	Suppressions SuppressionService // Optional; refuses mail to suppressed recipients
+	Log          MessageLogService  // Optional; records queued messages and adds tracking
+	MaxAttempts  int                // Delivery attempts before a message is dead-lettered
+}
+
+type outboxService struct {
+	repo         domain.OutboxRepository
+	suppressions SuppressionService
+	log          MessageLogService
+	maxAttempts  int
+	now          func() time.Time
+}
+
+func NewOutboxService(cfg OutboxServiceConfig) OutboxService {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &outboxService{
+		repo:         cfg.Repo,
+		suppressions: cfg.Suppressions,
+		log:          cfg.Log,
+		maxAttempts:  maxAttempts,
+		now:          time.Now,
+	}
+}
+
+func (s *outboxService) SendEmail(ctx context.Context, email *domain.Email) error {
+	_, err := s.Enqueue(ctx, email)
+	return err
+}
+
+func (s *outboxService) Enqueue(ctx context.Context, email *domain.Email) (*domain.OutboxMessage, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if err := email.Validate(); err != nil {
+		return nil, err
+	}
+	if s.suppressions != nil {
+		if err := s.suppressions.Check(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+
+	// Scheduled messages wait in the queue; the worker only claims them once due
+	nextAttemptAt := s.now()
+	if email.SendAt != nil && email.SendAt.After(nextAttemptAt) {
+		nextAttemptAt = *email.SendAt
+	}
+
+	// The ID is assigned up front because tracking links carry it
+	msg := &domain.OutboxMessage{
+		ID:            uuid.New(),
+		Payload:       *email,
+		Status:        domain.MessageQueued,
+		MaxAttempts:   s.maxAttempts,
+		NextAttemptAt: nextAttemptAt,
+	}
+	if s.log != nil {
+		msg.Payload = *s.log.Instrument(msg.ID, email)
+	}
+	if err := s.repo.Create(ctx, msg); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to enqueue email: %w", err)
+	}
+
+	if s.log != nil {
+		// The message is queued either way; a missing log entry must not cause a resend
+		if err := s.log.Record(ctx, msg); err != nil {
+			log.Printf("email: %v", err)
+		}
+	}
+
+	return msg, nil
+}
+
+func (s *outboxService) GetMessage(ctx context.Context, id string) (*domain.OutboxMessage, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	msgID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message ID format: %v", err)
+	}
+
+	msg, err := s.repo.GetByID(ctx, msgID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,186 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/email"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *mockOutboxRepository) Create(ctx context.Context, msg *email.OutboxMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *mockOutboxRepository) GetByID(ctx context.Context, id uuid.UUID) (*email.OutboxMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*email.OutboxMessage), args.Error(1)
+}
+
+func (m *mockOutboxRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*email.OutboxMessage, error) {
+	args := m.Called(ctx, now, leaseUntil, limit)
+	return args.Get(0).([]*email.OutboxMessage), args.Error(1)
+}
+
+func (m *mockOutboxRepository) Update(ctx context.Context, msg *email.OutboxMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+type mockSender struct {
+	mock.Mock
+}
+
+func (m *mockSender) SendEmail(ctx context.Context, e *email.Email) error {
+	args := m.Called(ctx, e)
+	return args.Error(0)
+}
+
+func TestOutboxService_Enqueue(t *testing.T) {
+	repo := &mockOutboxRepository{}
+	svc := NewOutboxService(OutboxServiceConfig{Repo: repo, MaxAttempts: 3})
+	ctx := context.Background()
+
+	t.Run("persists a queued message", func(t *testing.T) {
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*email.OutboxMessage")).Return(nil).Once()
+
+		msg, err := svc.Enqueue(ctx, &email.Email{To: []string{"a@example.com"}, Subject: "Hi"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, email.MessageQueued, msg.Status)
+		assert.Equal(t, 3, msg.MaxAttempts)
+		assert.Equal(t, "Hi", msg.Payload.Subject)
+		assert.WithinDuration(t, time.Now(), msg.NextAttemptAt, time.Second)
+	})
+
+	t.Run("holds scheduled messages until send_at", func(t *testing.T) {
+		repo.On("Create", mock.Anything, mock.AnythingOfType("*email.OutboxMessage")).Return(nil).Twice()
+		later := time.Now().Add(2 * time.Hour)
+		earlier := time.Now().Add(-time.Hour)
+
+		msg, err := svc.Enqueue(ctx, &email.Email{To: []string{"a@example.com"}, Subject: "Reminder", SendAt: &later})
+		assert.NoError(t, err)
+		assert.Equal(t, later, msg.NextAttemptAt)
+
+		msg, err = svc.Enqueue(ctx, &email.Email{To: []string{"a@example.com"}, Subject: "Reminder", SendAt: &earlier})
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), msg.NextAttemptAt, time.Second)
+	})
+
+	t.Run("requires a recipient", func(t *testing.T) {
+		msg, err := svc.Enqueue(ctx, &email.Email{Subject: "Hi"})
+
+		assert.ErrorIs(t, err, email.ErrInvalidEmail)
+		assert.Nil(t, msg)
+	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		id := uuid.New()
+		repo.On("GetByID", mock.Anything, id).Return(nil, sql.ErrNoRows)
+
+		msg, err := svc.GetMessage(ctx, id.String())
+
+		assert.ErrorIs(t, err, ErrMessageNotFound)
+		assert.Nil(t, msg)
+	})
+}
+
+func TestOutboxWorker_ProcessDue(t *testing.T) {
+	now := time.Date(2025, 8, 14, 9, 0, 0, 0, time.UTC)
+
+	newWorker := func() (*OutboxWorker, *mockOutboxRepository, *mockSender) {
+		repo := &mockOutboxRepository{}
+		sender := &mockSender{}
+		w := NewOutboxWorker(OutboxWorkerConfig{
+			Repo:        repo,
+			Sender:      sender,
+			Concurrency: 2,
+			BaseBackoff: time.Minute,
+			MaxBackoff:  10 * time.Minute,
+		})
+		w.now = func() time.Time { return now }
+		return w, repo, sender
+	}
+
+	t.Run("delivers and marks sent", func(t *testing.T) {
+		w, repo, sender := newWorker()
+		msg := &email.OutboxMessage{ID: uuid.New(), Status: email.MessageSending, Attempts: 1, MaxAttempts: 3}
+
+		repo.On("ClaimDue", mock.Anything, now, now.Add(defaultLeaseDuration), 2).Return([]*email.OutboxMessage{msg}, nil)
+		sender.On("SendEmail", mock.Anything, &msg.Payload).Return(nil)
+		repo.On("Update", mock.Anything, msg).Return(nil)
+
+		assert.Equal(t, 1, w.ProcessDue(context.Background()))
+		assert.Equal(t, email.MessageSent, msg.Status)
+		assert.Equal(t, &now, msg.SentAt)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed delivery is retried with backoff", func(t *testing.T) {
+		w, repo, sender := newWorker()
+		msg := &email.OutboxMessage{ID: uuid.New(), Status: email.MessageSending, Attempts: 2, MaxAttempts: 3}
+
+		repo.On("ClaimDue", mock.Anything, now, mock.Anything, 2).Return([]*email.OutboxMessage{msg}, nil)
+		sender.On("SendEmail", mock.Anything, &msg.Payload).Return(errors.New("421 try again later"))
+		repo.On("Update", mock.Anything, msg).Return(nil)
+
+		w.ProcessDue(context.Background())
+
+		assert.Equal(t, email.MessageQueued, msg.Status)
+		assert.Equal(t, now.Add(2*time.Minute), msg.NextAttemptAt)
+		assert.Equal(t, "421 try again later", msg.LastError)
+	})
+
+	t.Run("last failed attempt dead-letters the message", func(t *testing.T) {
+		w, repo, sender := newWorker()
+		msg := &email.OutboxMessage{ID: uuid.New(), Status: email.MessageSending, Attempts: 3, MaxAttempts: 3}
+
+		repo.On("ClaimDue", mock.Anything, now, mock.Anything, 2).Return([]*email.OutboxMessage{msg}, nil)
+		sender.On("SendEmail", mock.Anything, &msg.Payload).Return(errors.New("550 mailbox unavailable"))
+		repo.On("Update", mock.Anything, msg).Return(nil)
+
+		w.ProcessDue(context.Background())
+
+		assert.Equal(t, email.MessageDead, msg.Status)
+		assert.Nil(t, msg.SentAt)
+	})
+
+	t.Run("suppressed recipient is not retried", func(t *testing.T) {
+		w, repo, sender := newWorker()
+		msg := &email.OutboxMessage{ID: uuid.New(), Status: email.MessageSending, Attempts: 1, MaxAttempts: 3}
+
+		repo.On("ClaimDue", mock.Anything, now, mock.Anything, 2).Return([]*email.OutboxMessage{msg}, nil)
+		sender.On("SendEmail", mock.Anything, &msg.Payload).Return(fmt.Errorf("%w: gone@example.com (hard_bounce)", email.ErrRecipientSuppressed))
+		repo.On("Update", mock.Anything, msg).Return(nil)
+
+		w.ProcessDue(context.Background())
+
+		assert.Equal(t, email.MessageSuppressed, msg.Status)
+		assert.Contains(t, msg.LastError, "gone@example.com")
+	})
+}
+
+func TestOutboxWorker_Backoff(t *testing.T) {
+	w := NewOutboxWorker(OutboxWorkerConfig{BaseBackoff: time.Minute, MaxBackoff: 10 * time.Minute})
+
+	assert.Equal(t, time.Minute, w.backoff(1))
+	assert.Equal(t, 2*time.Minute, w.backoff(2))
+	assert.Equal(t, 8*time.Minute, w.backoff(4))
+	assert.Equal(t, 10*time.Minute, w.backoff(5))
+	assert.Equal(t, 10*time.Minute, w.backoff(40))
+}
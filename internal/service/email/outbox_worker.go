@@ -0,0 +1,183 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+)
+
+const (
+	defaultWorkerConcurrency = 4
+	defaultPollInterval      = 5 * time.Second
+	defaultLeaseDuration     = 5 * time.Minute
+	defaultBaseBackoff       = 30 * time.Second
+	defaultMaxBackoff        = 6 * time.Hour
+)
+
+// OutboxWorkerConfig holds the dependencies and settings of the outbox worker
+type OutboxWorkerConfig struct {
+	Repo          domain.OutboxRepository
+	Sender        domain.EmailService // Delivers messages, e.g. the SMTP email service
+	Log           MessageLogService   // Optional; records delivery outcomes
+	Concurrency   int                 // Messages delivered in parallel
+	PollInterval  time.Duration       // Delay between polls when the outbox is empty
+	LeaseDuration time.Duration       // How long a claimed message is hidden from other workers
+	BaseBackoff   time.Duration       // Delay before the first retry; doubled on every further failure
+	MaxBackoff    time.Duration       // Upper bound for the retry delay
+}
+
+// OutboxWorker delivers queued emails with a pool of concurrent senders,
+// retrying failures with exponential backoff and dead-lettering messages
+// that run out of attempts.
+type OutboxWorker struct {
+	repo          domain.OutboxRepository
+	sender        domain.EmailService
+	log           MessageLogService
+	concurrency   int
+	pollInterval  time.Duration
+	leaseDuration time.Duration
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	now           func() time.Time
+}
+
+func NewOutboxWorker(cfg OutboxWorkerConfig) *OutboxWorker {
+	w := &OutboxWorker{
+		repo:          cfg.Repo,
+		sender:        cfg.Sender,
+		log:           cfg.Log,
+		concurrency:   cfg.Concurrency,
+		pollInterval:  cfg.PollInterval,
+		leaseDuration: cfg.LeaseDuration,
+		baseBackoff:   cfg.BaseBackoff,
+		maxBackoff:    cfg.MaxBackoff,
+		now:           time.Now,
+	}
+
+	if w.concurrency <= 0 {
+		w.concurrency = defaultWorkerConcurrency
+	}
+	if w.pollInterval <= 0 {
+		w.pollInterval = defaultPollInterval
+	}
+	if w.leaseDuration <= 0 {
+		w.leaseDuration = defaultLeaseDuration
+	}
+	if w.baseBackoff <= 0 {
+		w.baseBackoff = defaultBaseBackoff
+	}
+	if w.maxBackoff <= 0 {
+		w.maxBackoff = defaultMaxBackoff
+	}
+
+	return w
+}
+
+// Run polls the outbox until ctx is cancelled. Deliveries already in
+// progress are completed before Run returns.
+func (w *OutboxWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep claiming while full batches come back so a backlog drains
+		// without waiting for the next tick
+		for ctx.Err() == nil {
+			if w.ProcessDue(ctx) < w.concurrency {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue claims one batch of due messages, delivers them in parallel and
+// returns the number of messages claimed.
+func (w *OutboxWorker) ProcessDue(ctx context.Context) int {
+	now := w.now()
+	msgs, err := w.repo.ClaimDue(ctx, now, now.Add(w.leaseDuration), w.concurrency)
+	if err != nil {
+		log.Printf("email outbox: failed to claim messages: %v", err)
+		return 0
+	}
+
+	// Outcomes are recorded even when shutdown cancels ctx mid-batch
+	updateCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, msg := range msgs {
+		wg.Add(1)
+		go func(msg *domain.OutboxMessage) {
+			defer wg.Done()
+			w.deliver(updateCtx, msg)
+		}(msg)
+	}
+	wg.Wait()
+
+	return len(msgs)
+}
+
+func (w *OutboxWorker) deliver(ctx context.Context, msg *domain.OutboxMessage) {
+	// Give up before the lease runs out so no other worker picks the message up mid-send
+	sendCtx, cancel := context.WithTimeout(ctx, w.leaseDuration)
+	var response string
+	var err error
+	if d, ok := w.sender.(Deliverer); ok {
+		response, err = d.Deliver(sendCtx, &msg.Payload)
+	} else {
+		err = w.sender.SendEmail(sendCtx, &msg.Payload)
+	}
+	cancel()
+	now := w.now()
+
+	switch {
+	case err == nil:
+		msg.Status = domain.MessageSent
+		msg.SentAt = &now
+		msg.LastError = ""
+	case errors.Is(err, domain.ErrRecipientSuppressed):
+		// Retrying will not help until the address is removed from the suppression list
+		msg.Status = domain.MessageSuppressed
+		msg.LastError = err.Error()
+	case msg.Attempts >= msg.MaxAttempts:
+		msg.Status = domain.MessageDead
+		msg.LastError = err.Error()
+		log.Printf("email outbox: message %s dead-lettered after %d attempts: %v", msg.ID, msg.Attempts, err)
+	default:
+		msg.Status = domain.MessageQueued
+		msg.NextAttemptAt = now.Add(w.backoff(msg.Attempts))
+		msg.LastError = err.Error()
+	}
+
+	if err := w.repo.Update(ctx, msg); err != nil {
+		// The lease expires and the message is retried, so at worst it is sent twice
+		log.Printf("email outbox: failed to record outcome of message %s: %v", msg.ID, err)
+	}
+	if w.log != nil {
+		if err := w.log.RecordDelivery(ctx, msg, response); err != nil {
+			log.Printf("email outbox: %v", err)
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt after the given number of
+// failed attempts: BaseBackoff, 2×BaseBackoff, 4×BaseBackoff, ... up to MaxBackoff.
+func (w *OutboxWorker) backoff(attempts int) time.Duration {
+	delay := w.baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.maxBackoff {
+			return w.maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,270 @@
+package email
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/mailer"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+)
+
+var (
+	// ErrInvalidUnsubscribeToken is returned for unsubscribe tokens that were not issued by UnsubscribeURL
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+	// ErrSuppressionNotFound is returned when removing an address that is not on the suppression list
+	ErrSuppressionNotFound = errors.New("address is not suppressed")
+)
+
+const (
+	defaultSuppressionPageSize = 50
+	maxSuppressionPageSize     = 200
+)
+
+// unsubscribePath is the public endpoint that unsubscribe links point to
+const unsubscribePath = "/api/v1/email/unsubscribe"
+
+// SuppressionList is one page of the suppression list
+type SuppressionList struct {
+	Suppressions []*domain.Suppression `json:"suppressions"`
+	Total        int                   `json:"total"`
+}
+
+// SuppressAddressRequest adds an address to the suppression list by hand
+type SuppressAddressRequest struct {
+	Email  string                   `json:"email" binding:"required"`
+	Reason domain.SuppressionReason `json:"reason" binding:"required" example:"hard_bounce"`
+	Detail string                   `json:"detail,omitempty"`
+}
+
+// SuppressionService maintains the list of addresses that must not receive
+// mail: recipients who unsubscribed from bulk mail, hard bounces and spam
+// complaints.
+type SuppressionService interface {
+	// Check returns an error wrapping domain.ErrRecipientSuppressed when a
+	// recipient of email is suppressed for its kind of mail
+	Check(ctx context.Context, email *domain.Email) error
+	// UnsubscribeURL returns the signed one-click unsubscribe link for address
+	UnsubscribeURL(address string) string
+	// Unsubscribe suppresses bulk mail to the address a token was issued for
+	// and returns the address
+	Unsubscribe(ctx context.Context, token string) (string, error)
+	// ProcessReport reads a bounce (RFC 3464) or spam complaint (RFC 5965)
+	// report and suppresses the hard-bounced or complaining recipients
+	ProcessReport(ctx context.Context, report io.Reader) ([]*domain.Suppression, error)
+
+	// List returns a page of the suppression list, optionally filtered by reason
+	List(ctx context.Context, reason string, limit, offset int) (*SuppressionList, error)
+	// Suppress adds an address to the list by hand
+	Suppress(ctx context.Context, req SuppressAddressRequest) (*domain.Suppression, error)
+	// Remove takes an address off the list
+	Remove(ctx context.Context, address string) error
+}
+
+// SuppressionServiceConfig holds the dependencies and settings of the suppression service
+type SuppressionServiceConfig struct {
+	Repo       domain.SuppressionRepository
+	SigningKey string // HMAC key for unsubscribe tokens
+	BaseURL    string // Public URL of the API, used in unsubscribe links
+}
+
+type suppressionService struct {
+	repo       domain.SuppressionRepository
+	signingKey []byte
+	baseURL    string
+}
+
+func NewSuppressionService(cfg SuppressionServiceConfig) SuppressionService {
+	return &suppressionService{
+		repo:       cfg.Repo,
+		signingKey: []byte(cfg.SigningKey),
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+	}
+}
+
+func (s *suppressionService) Check(ctx context.Context, email *domain.Email) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	var addresses []string
+	for _, rcpt := range email.Recipients() {
+		if addr := normalizeAddress(rcpt); addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	suppressions, err := s.repo.FindByEmails(ctx, addresses)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+
+	var blocked []string
+	for _, sup := range suppressions {
+		if sup.Reason.Blocks(email.Bulk) {
+			blocked = append(blocked, fmt.Sprintf("%s (%s)", sup.Email, sup.Reason))
+		}
+	}
+	if len(blocked) > 0 {
+		return fmt.Errorf("%w: %s", domain.ErrRecipientSuppressed, strings.Join(blocked, ", "))
+	}
+	return nil
+}
+
+func (s *suppressionService) UnsubscribeURL(address string) string {
+	return s.baseURL + unsubscribePath + "?token=" + url.QueryEscape(s.token(normalizeAddress(address)))
+}
+
+func (s *suppressionService) Unsubscribe(ctx context.Context, token string) (string, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	address, err := s.verifyToken(token)
+	if err != nil {
+		return "", err
+	}
+
+	sup := &domain.Suppression{Email: address, Reason: domain.SuppressionUnsubscribed}
+	if err := s.repo.Upsert(ctx, sup); err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	return address, nil
+}
+
+func (s *suppressionService) ProcessReport(ctx context.Context, r io.Reader) ([]*domain.Suppression, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	report, err := mailer.ParseReport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var suppressed []*domain.Suppression
+	for _, rcpt := range report.Recipients {
+		sup := &domain.Suppression{Email: normalizeAddress(rcpt.Address)}
+		switch {
+		case sup.Email == "":
+			continue
+		case report.Type == mailer.ReportFeedback:
+			sup.Reason = domain.SuppressionComplaint
+			sup.Detail = "feedback-type: " + report.FeedbackType
+		case rcpt.Permanent():
+			sup.Reason = domain.SuppressionHardBounce
+			sup.Detail = strings.TrimSpace(rcpt.Status + " " + rcpt.Diagnostic)
+		default:
+			// Delays and soft bounces are retried by the outbox
+			continue
+		}
+
+		if err := s.repo.Upsert(ctx, sup); err != nil {
+			span.RecordError(err)
+			return suppressed, fmt.Errorf("failed to suppress %s: %w", sup.Email, err)
+		}
+		suppressed = append(suppressed, sup)
+	}
+
+	return suppressed, nil
+}
+
+func (s *suppressionService) List(ctx context.Context, reason string, limit, offset int) (*SuppressionList, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if reason != "" && !domain.SuppressionReason(reason).Valid() {
+		return nil, fmt.Errorf("invalid suppression reason: %q", reason)
+	}
+	if limit <= 0 {
+		limit = defaultSuppressionPageSize
+	}
+	limit = min(limit, maxSuppressionPageSize)
+	offset = max(offset, 0)
+
+	suppressions, total, err := s.repo.List(ctx, domain.SuppressionReason(reason), limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return &SuppressionList{Suppressions: suppressions, Total: total}, nil
+}
+
+func (s *suppressionService) Suppress(ctx context.Context, req SuppressAddressRequest) (*domain.Suppression, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	address := normalizeAddress(req.Email)
+	if address == "" {
+		return nil, fmt.Errorf("%w: invalid address %q", domain.ErrInvalidEmail, req.Email)
+	}
+	if !req.Reason.Valid() {
+		return nil, fmt.Errorf("invalid suppression reason: %q", req.Reason)
+	}
+
+	sup := &domain.Suppression{Email: address, Reason: req.Reason, Detail: req.Detail}
+	if err := s.repo.Upsert(ctx, sup); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to suppress address: %w", err)
+	}
+	return sup, nil
+}
+
+func (s *suppressionService) Remove(ctx context.Context, address string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if err := s.repo.Delete(ctx, normalizeAddress(address)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSuppressionNotFound
+		}
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// token encodes the address with its HMAC, so links keep working without server-side state
+func (s *suppressionService) token(address string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(address)) + "." + s.sign(address)
+}
+
+func (s *suppressionService) verifyToken(token string) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidUnsubscribeToken
+	}
+
+	address := string(raw)
+	if !hmac.Equal([]byte(s.sign(address)), []byte(signature)) || normalizeAddress(address) != address {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	return address, nil
+}
+
+func (s *suppressionService) sign(address string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("unsubscribe\n" + address))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// normalizeAddress returns the lower-cased bare address, or "" if it does not parse
+func normalizeAddress(address string) string {
+	addr, err := mail.ParseAddress(address)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(addr.Address)
+}
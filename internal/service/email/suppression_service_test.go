@@ -0,0 +1,184 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"strings"
+	"testing"
+
+	"base-code-go-gin-clean/internal/config"
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/pkg/mailer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSuppressionRepository struct {
+	mock.Mock
+}
+
+func (m *mockSuppressionRepository) Upsert(ctx context.Context, s *domain.Suppression) error {
+	return m.Called(ctx, s).Error(0)
+}
+
+func (m *mockSuppressionRepository) FindByEmails(ctx context.Context, emails []string) ([]*domain.Suppression, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Suppression), args.Error(1)
+}
+
+func (m *mockSuppressionRepository) List(ctx context.Context, reason domain.SuppressionReason, limit, offset int) ([]*domain.Suppression, int, error) {
+	args := m.Called(ctx, reason, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Suppression), args.Int(1), args.Error(2)
+}
+
+func (m *mockSuppressionRepository) Delete(ctx context.Context, email string) error {
+	return m.Called(ctx, email).Error(0)
+}
+
+func newTestSuppressionService(repo *mockSuppressionRepository) SuppressionService {
+	return NewSuppressionService(SuppressionServiceConfig{
+		Repo:       repo,
+		SigningKey: "test-key",
+		BaseURL:    "https://app.example.com/",
+	})
+}
+
+func TestSuppressionService_Check(t *testing.T) {
+	repo := new(mockSuppressionRepository)
+	svc := newTestSuppressionService(repo)
+
+	repo.On("FindByEmails", mock.Anything, []string{"jane@example.com", "bounced@example.com"}).Return([]*domain.Suppression{
+		{Email: "jane@example.com", Reason: domain.SuppressionUnsubscribed},
+	}, nil).Once()
+	transactional := &domain.Email{To: []string{"Jane <Jane@Example.com>"}, Bcc: []string{"bounced@example.com"}}
+	assert.NoError(t, svc.Check(context.Background(), transactional), "unsubscribing only stops bulk mail")
+
+	repo.On("FindByEmails", mock.Anything, []string{"jane@example.com"}).Return([]*domain.Suppression{
+		{Email: "jane@example.com", Reason: domain.SuppressionUnsubscribed},
+	}, nil).Once()
+	err := svc.Check(context.Background(), &domain.Email{To: []string{"jane@example.com"}, Bulk: true})
+	assert.ErrorIs(t, err, domain.ErrRecipientSuppressed)
+
+	repo.On("FindByEmails", mock.Anything, []string{"bounced@example.com"}).Return([]*domain.Suppression{
+		{Email: "bounced@example.com", Reason: domain.SuppressionHardBounce},
+	}, nil).Once()
+	err = svc.Check(context.Background(), &domain.Email{To: []string{"bounced@example.com"}})
+	assert.ErrorIs(t, err, domain.ErrRecipientSuppressed)
+	assert.ErrorContains(t, err, "bounced@example.com (hard_bounce)")
+}
+
+func TestSuppressionService_Unsubscribe(t *testing.T) {
+	repo := new(mockSuppressionRepository)
+	svc := newTestSuppressionService(repo)
+
+	link, err := url.Parse(svc.UnsubscribeURL("Jane <Jane@Example.com>"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "https://app.example.com/api/v1/email/unsubscribe", link.Scheme+"://"+link.Host+link.Path)
+	token := link.Query().Get("token")
+
+	repo.On("Upsert", mock.Anything, mock.MatchedBy(func(s *domain.Suppression) bool {
+		return s.Email == "jane@example.com" && s.Reason == domain.SuppressionUnsubscribed
+	})).Return(nil).Once()
+
+	address, err := svc.Unsubscribe(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane@example.com", address)
+
+	// A token for another address cannot be forged from a valid one
+	encoded, signature, _ := strings.Cut(token, ".")
+	forged := strings.Replace(encoded, "amFuZ", "am9obi", 1) + "." + signature
+	for _, bad := range []string{"", "garbage", forged, encoded + ".AAAA"} {
+		_, err := svc.Unsubscribe(context.Background(), bad)
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken, bad)
+	}
+
+	// Tokens are only valid with the key that signed them
+	other := NewSuppressionService(SuppressionServiceConfig{Repo: repo, SigningKey: "other-key"})
+	_, err = other.Unsubscribe(context.Background(), token)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+
+	repo.AssertExpectations(t)
+}
+
+func TestSuppressionService_ProcessReport(t *testing.T) {
+	repo := new(mockSuppressionRepository)
+	svc := newTestSuppressionService(repo)
+
+	report := "Content-Type: multipart/report; report-type=delivery-status; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: message/delivery-status\r\n" +
+		"\r\n" +
+		"Reporting-MTA: dns; mx.example.net\r\n" +
+		"\r\n" +
+		"Final-Recipient: rfc822; Gone@Example.org\r\n" +
+		"Action: failed\r\n" +
+		"Status: 5.1.1\r\n" +
+		"Diagnostic-Code: smtp; 550 User unknown\r\n" +
+		"\r\n" +
+		"Final-Recipient: rfc822; full@example.org\r\n" +
+		"Action: delayed\r\n" +
+		"Status: 4.2.2\r\n" +
+		"\r\n" +
+		"--b--\r\n"
+
+	repo.On("Upsert", mock.Anything, mock.MatchedBy(func(s *domain.Suppression) bool {
+		return s.Email == "gone@example.org" && s.Reason == domain.SuppressionHardBounce && s.Detail == "5.1.1 550 User unknown"
+	})).Return(nil).Once()
+
+	suppressed, err := svc.ProcessReport(context.Background(), strings.NewReader(report))
+
+	assert.NoError(t, err)
+	assert.Len(t, suppressed, 1)
+	repo.AssertExpectations(t)
+
+	_, err = svc.ProcessReport(context.Background(), strings.NewReader("Subject: hi\r\n\r\nhello\r\n"))
+	assert.ErrorIs(t, err, mailer.ErrNotReport)
+}
+
+func TestSuppressionService_Remove(t *testing.T) {
+	repo := new(mockSuppressionRepository)
+	svc := newTestSuppressionService(repo)
+
+	repo.On("Delete", mock.Anything, "jane@example.com").Return(sql.ErrNoRows).Once()
+
+	assert.ErrorIs(t, svc.Remove(context.Background(), "Jane@Example.com"), ErrSuppressionNotFound)
+}
+
+func TestEmailService_Suppressions(t *testing.T) {
+	repo := new(mockSuppressionRepository)
+	transport := mailer.NewMemoryTransport()
+	svc := NewEmailService(&config.Config{Email: config.EmailConfig{From: "app@example.com"}}, transport, newTestSuppressionService(repo), nil)
+
+	t.Run("bulk mail carries one-click unsubscribe headers", func(t *testing.T) {
+		repo.On("FindByEmails", mock.Anything, []string{"jane@example.com"}).Return([]*domain.Suppression{}, nil).Once()
+
+		err := svc.SendEmail(context.Background(), &domain.Email{To: []string{"jane@example.com"}, Subject: "Report", Body: "<p>Hi</p>", Bulk: true})
+
+		assert.NoError(t, err)
+		data := string(transport.Messages()[0].Data)
+		assert.Contains(t, data, "List-Unsubscribe: <https://app.example.com/api/v1/email/unsubscribe?token=")
+		assert.Contains(t, data, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	})
+
+	t.Run("suppressed recipient is refused", func(t *testing.T) {
+		repo.On("FindByEmails", mock.Anything, []string{"gone@example.com"}).Return([]*domain.Suppression{
+			{Email: "gone@example.com", Reason: domain.SuppressionComplaint},
+		}, nil).Once()
+
+		err := svc.SendEmail(context.Background(), &domain.Email{To: []string{"gone@example.com"}, Subject: "Reset", Body: "<p>Hi</p>"})
+
+		assert.ErrorIs(t, err, domain.ErrRecipientSuppressed)
+		assert.Len(t, transport.Messages(), 1)
+	})
+}
@@ -0,0 +1,415 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/domain/user"
+	templates "base-code-go-gin-clean/internal/email"
+	"base-code-go-gin-clean/internal/pkg/i18n"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	preferenceService "base-code-go-gin-clean/internal/service/preference"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTemplateVersionNotFound is returned when a stored template version does not exist
+	ErrTemplateVersionNotFound = errors.New("template version not found")
+	// ErrTemplateNotDraft is returned when editing a version that was already published
+	ErrTemplateNotDraft = errors.New("only draft versions can be edited")
+	// ErrNoRollbackTarget is returned when a template has no previously published version
+	ErrNoRollbackTarget = errors.New("no previously published version to roll back to")
+)
+
+// TemplateSource is the editable content of a stored template version
+type TemplateSource struct {
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body" binding:"required"`
+	TextBody string `json:"text_body"`
+}
+
+// TemplateService renders catalog templates from request data and queues them
+// in the outbox. Published database versions take precedence over the embedded files.
+//
+// Localized templates are rendered in the first supported locale of: the
+// locale requested explicitly, the recipient's locale preference, the
+// Accept-Language of the request, and finally English.
+type TemplateService interface {
+	// ListTemplates returns the templates with their data schemas
+	ListTemplates() []templates.Definition
+	// Preview renders a template without sending it. locale is a language tag
+	// or an Accept-Language value.
+	Preview(ctx context.Context, name, locale string, data map[string]json.RawMessage) (*templates.Rendered, error)
+	// SendTemplate renders a template in the recipient's locale and queues it for delivery
+	SendTemplate(ctx context.Context, req *domain.TemplateEmail) (*domain.OutboxMessage, error)
+
+	// ListVersions returns the stored versions of a template, newest first
+	ListVersions(ctx context.Context, name string) ([]*domain.TemplateVersion, error)
+	// CreateDraft stores a new draft version of a template
+	CreateDraft(ctx context.Context, name string, src TemplateSource, createdBy *uuid.UUID) (*domain.TemplateVersion, error)
+	// UpdateDraft replaces the content of a draft version
+	UpdateDraft(ctx context.Context, name, version string, src TemplateSource) (*domain.TemplateVersion, error)
+	// PreviewVersion renders a stored version, draft or not, without sending it
+	PreviewVersion(ctx context.Context, name, version, locale string, data map[string]json.RawMessage) (*templates.Rendered, error)
+	// Publish validates a version and makes it the one used for rendering
+	Publish(ctx context.Context, name, version string) (*domain.TemplateVersion, error)
+	// Rollback republishes the version that was published before the current one
+	Rollback(ctx context.Context, name string) (*domain.TemplateVersion, error)
+	// Unpublish reverts a template to its embedded file
+	Unpublish(ctx context.Context, name string) error
+}
+
+// TemplateServiceConfig holds the dependencies of the template service
+type TemplateServiceConfig struct {
+	Outbox OutboxService
+	Repo   domain.TemplateRepository
+	// Users and Preferences look up the locale and time zone of recipients
+	// with an account. Both are optional.
+	Users       user.UserRepository
+	Preferences preferenceService.PreferenceService
+}
+
+type templateService struct {
+	outbox      OutboxService
+	repo        domain.TemplateRepository
+	users       user.UserRepository
+	preferences preferenceService.PreferenceService
+	now         func() time.Time
+}
+
+func NewTemplateService(cfg TemplateServiceConfig) TemplateService {
+	return &templateService{
+		outbox:      cfg.Outbox,
+		repo:        cfg.Repo,
+		users:       cfg.Users,
+		preferences: cfg.Preferences,
+		now:         time.Now,
+	}
+}
+
+func (s *templateService) ListTemplates() []templates.Definition {
+	return templates.Catalog()
+}
+
+func (s *templateService) Preview(ctx context.Context, name, locale string, data map[string]json.RawMessage) (*templates.Rendered, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	return s.render(ctx, name, templates.Messages.Localizer(locale), data)
+}
+
+func (s *templateService) SendTemplate(ctx context.Context, req *domain.TemplateEmail) (*domain.OutboxMessage, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	def, err := lookupTemplate(req.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := s.render(ctx, req.Template, s.recipientLocalizer(ctx, req), req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.outbox.Enqueue(ctx, &domain.Email{
+		To:       []string{req.To},
+		ReplyTo:  req.ReplyTo,
+		Subject:  rendered.Subject,
+		Body:     rendered.HTML,
+		Text:     rendered.Text,
+		Bulk:     def.Bulk,
+		SendAt:   req.SendAt,
+		Template: req.Template,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to send template %s: %w", req.Template, err)
+	}
+	return msg, nil
+}
+
+// recipientLocalizer picks the locale and time zone for a templated email. The
+// preference lookup is best effort: unknown recipients and lookup errors fall
+// through to the Accept-Language of the request.
+func (s *templateService) recipientLocalizer(ctx context.Context, req *domain.TemplateEmail) *i18n.Localizer {
+	candidates := []string{req.Locale}
+	loc := time.UTC
+
+	if s.users != nil && s.preferences != nil {
+		u, err := s.users.GetByEmail(ctx, req.To)
+		switch {
+		case err == nil:
+			prefs, err := s.preferences.Get(ctx, u.ID.String())
+			if err != nil {
+				log.Printf("email: failed to load preferences of user %s: %v", u.ID, err)
+				break
+			}
+			candidates = append(candidates, prefs.Locale())
+			loc = prefs.Location()
+		case !errors.Is(err, sql.ErrNoRows):
+			log.Printf("email: failed to look up recipient for locale: %v", err)
+		}
+	}
+
+	candidates = append(candidates, req.AcceptLanguage)
+	return templates.Messages.Localizer(candidates...).In(loc)
+}
+
+// render validates data and renders the published version of a template,
+// falling back to the embedded file when there is none or it fails to render
+func (s *templateService) render(ctx context.Context, name string, l *i18n.Localizer, data map[string]json.RawMessage) (*templates.Rendered, error) {
+	def, err := lookupTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := def.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if published := s.published(ctx, name); published != nil {
+		rendered, err := def.Render(l, values, sourceOf(published))
+		if err == nil {
+			return rendered, nil
+		}
+		log.Printf("email: version %d of template %s failed to render, using the embedded template: %v",
+			published.Version, name, err)
+	}
+
+	return def.Render(l, values, nil)
+}
+
+// published returns the published version of a template, or nil when rendering
+// should use the embedded file. A database outage must not stop mail, so
+// lookup errors are logged rather than returned.
+func (s *templateService) published(ctx context.Context, name string) *domain.TemplateVersion {
+	if s.repo == nil {
+		return nil
+	}
+
+	v, err := s.repo.GetPublished(ctx, name)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("email: failed to load published template %s: %v", name, err)
+		}
+		return nil
+	}
+	return v
+}
+
+func (s *templateService) ListVersions(ctx context.Context, name string) ([]*domain.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if _, err := lookupTemplate(name); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.repo.ListVersions(ctx, name)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (s *templateService) CreateDraft(ctx context.Context, name string, src TemplateSource, createdBy *uuid.UUID) (*domain.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if _, err := lookupTemplate(name); err != nil {
+		return nil, err
+	}
+
+	v := &domain.TemplateVersion{
+		Name:      name,
+		Status:    domain.TemplateDraft,
+		Subject:   src.Subject,
+		HTMLBody:  src.HTMLBody,
+		TextBody:  src.TextBody,
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.CreateVersion(ctx, v); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create template version: %w", err)
+	}
+	return v, nil
+}
+
+func (s *templateService) UpdateDraft(ctx context.Context, name, version string, src TemplateSource) (*domain.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	v, err := s.getVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status != domain.TemplateDraft {
+		return nil, ErrTemplateNotDraft
+	}
+
+	v.Subject = src.Subject
+	v.HTMLBody = src.HTMLBody
+	v.TextBody = src.TextBody
+	if err := s.repo.UpdateDraft(ctx, v); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Published between the read and the write
+			return nil, ErrTemplateNotDraft
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to update template version: %w", err)
+	}
+	return v, nil
+}
+
+func (s *templateService) PreviewVersion(ctx context.Context, name, version, locale string, data map[string]json.RawMessage) (*templates.Rendered, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	v, err := s.getVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := lookupTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	values, err := def.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drafts may not compile yet; report that like invalid data
+	rendered, err := def.Render(templates.Messages.Localizer(locale), values, sourceOf(v))
+	if err != nil {
+		var validationErrs templates.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			return nil, err
+		}
+		return nil, templates.ValidationErrors{"template": err.Error()}
+	}
+	return rendered, nil
+}
+
+func (s *templateService) Publish(ctx context.Context, name, version string) (*domain.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	v, err := s.getVersion(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	if v.Status == domain.TemplatePublished {
+		return v, nil
+	}
+
+	if err := templates.ValidateSource(name, *sourceOf(v)); err != nil {
+		return nil, err
+	}
+
+	return s.publish(ctx, v)
+}
+
+func (s *templateService) Rollback(ctx context.Context, name string) (*domain.TemplateVersion, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	versions, err := s.ListVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived versions were all published once; take the one published most recently
+	var target *domain.TemplateVersion
+	for _, v := range versions {
+		if v.Status != domain.TemplateArchived || v.PublishedAt == nil {
+			continue
+		}
+		if target == nil || v.PublishedAt.After(*target.PublishedAt) {
+			target = v
+		}
+	}
+	if target == nil {
+		return nil, ErrNoRollbackTarget
+	}
+
+	// Catalog fields may have changed since the version was last live
+	if err := templates.ValidateSource(name, *sourceOf(target)); err != nil {
+		return nil, err
+	}
+
+	return s.publish(ctx, target)
+}
+
+func (s *templateService) Unpublish(ctx context.Context, name string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	if _, err := lookupTemplate(name); err != nil {
+		return err
+	}
+
+	if err := s.repo.Unpublish(ctx, name, s.now()); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to unpublish template: %w", err)
+	}
+	return nil
+}
+
+func (s *templateService) publish(ctx context.Context, v *domain.TemplateVersion) (*domain.TemplateVersion, error) {
+	now := s.now()
+	if err := s.repo.Publish(ctx, v.Name, v.Version, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTemplateVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to publish template version: %w", err)
+	}
+
+	v.Status = domain.TemplatePublished
+	v.PublishedAt = &now
+	v.UpdatedAt = now
+	return v, nil
+}
+
+func (s *templateService) getVersion(ctx context.Context, name, version string) (*domain.TemplateVersion, error) {
+	if _, err := lookupTemplate(name); err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(version)
+	if err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid template version format: %q", version)
+	}
+
+	v, err := s.repo.GetVersion(ctx, name, n)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTemplateVersionNotFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+func lookupTemplate(name string) (templates.Definition, error) {
+	def, ok := templates.LookupTemplate(name)
+	if !ok {
+		return def, fmt.Errorf("%w: %s", templates.ErrUnknownTemplate, name)
+	}
+	return def, nil
+}
+
+func sourceOf(v *domain.TemplateVersion) *templates.Source {
+	return &templates.Source{Subject: v.Subject, HTML: v.HTMLBody, Text: v.TextBody}
+}
@@ -0,0 +1,301 @@
+package email
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	domain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/domain/preference"
+	"base-code-go-gin-clean/internal/domain/user"
+	templates "base-code-go-gin-clean/internal/email"
+	"base-code-go-gin-clean/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *mockTemplateRepository) CreateVersion(ctx context.Context, v *domain.TemplateVersion) error {
+	return m.Called(ctx, v).Error(0)
+}
+
+func (m *mockTemplateRepository) GetVersion(ctx context.Context, name string, version int) (*domain.TemplateVersion, error) {
+	args := m.Called(ctx, name, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TemplateVersion), args.Error(1)
+}
+
+func (m *mockTemplateRepository) GetPublished(ctx context.Context, name string) (*domain.TemplateVersion, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.TemplateVersion), args.Error(1)
+}
+
+func (m *mockTemplateRepository) ListVersions(ctx context.Context, name string) ([]*domain.TemplateVersion, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.TemplateVersion), args.Error(1)
+}
+
+func (m *mockTemplateRepository) UpdateDraft(ctx context.Context, v *domain.TemplateVersion) error {
+	return m.Called(ctx, v).Error(0)
+}
+
+func (m *mockTemplateRepository) Publish(ctx context.Context, name string, version int, at time.Time) error {
+	return m.Called(ctx, name, version, at).Error(0)
+}
+
+func (m *mockTemplateRepository) Unpublish(ctx context.Context, name string, at time.Time) error {
+	return m.Called(ctx, name, at).Error(0)
+}
+
+type mockPreferenceService struct {
+	mock.Mock
+}
+
+func (m *mockPreferenceService) Get(ctx context.Context, userID string) (preference.Preferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(preference.Preferences), args.Error(1)
+}
+
+func (m *mockPreferenceService) Update(ctx context.Context, userID string, changes map[string]json.RawMessage) (preference.Preferences, error) {
+	args := m.Called(ctx, userID, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(preference.Preferences), args.Error(1)
+}
+
+func (m *mockPreferenceService) Schema() []preference.Definition {
+	return nil
+}
+
+var welcomeData = map[string]json.RawMessage{
+	"name":      json.RawMessage(`"Jane"`),
+	"login_url": json.RawMessage(`"https://example.com/login"`),
+}
+
+func TestTemplateService_PreviewFallsBackToEmbedded(t *testing.T) {
+	repo := new(mockTemplateRepository)
+	svc := NewTemplateService(TemplateServiceConfig{Repo: repo})
+
+	t.Run("no published version", func(t *testing.T) {
+		repo.On("GetPublished", mock.Anything, "welcome").Return(nil, sql.ErrNoRows).Once()
+
+		rendered, err := svc.Preview(context.Background(), "welcome", "", welcomeData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to Our Platform!", rendered.Subject)
+	})
+
+	t.Run("database unavailable", func(t *testing.T) {
+		repo.On("GetPublished", mock.Anything, "welcome").Return(nil, errors.New("connection refused")).Once()
+
+		rendered, err := svc.Preview(context.Background(), "welcome", "", welcomeData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to Our Platform!", rendered.Subject)
+	})
+
+	t.Run("published version fails to render", func(t *testing.T) {
+		broken := &domain.TemplateVersion{Name: "welcome", Version: 3, HTMLBody: "{{.Data.removed_field}}"}
+		repo.On("GetPublished", mock.Anything, "welcome").Return(broken, nil).Once()
+
+		rendered, err := svc.Preview(context.Background(), "welcome", "", welcomeData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to Our Platform!", rendered.Subject)
+	})
+}
+
+func TestTemplateService_SendTemplateUsesPublishedVersion(t *testing.T) {
+	repo := new(mockTemplateRepository)
+	outboxRepo := new(mockOutboxRepository)
+	outbox := NewOutboxService(OutboxServiceConfig{Repo: outboxRepo})
+	svc := NewTemplateService(TemplateServiceConfig{Outbox: outbox, Repo: repo})
+
+	repo.On("GetPublished", mock.Anything, "welcome").Return(&domain.TemplateVersion{
+		Name:     "welcome",
+		Version:  2,
+		Status:   domain.TemplatePublished,
+		Subject:  "Hi {{.Data.name}}",
+		HTMLBody: `<p><a href="{{.Data.login_url}}">Sign in</a></p>`,
+	}, nil)
+	outboxRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *domain.OutboxMessage) bool {
+		e := msg.Payload
+		return e.Subject == "Hi Jane" &&
+			e.Body == `<p><a href="https://example.com/login">Sign in</a></p>` &&
+			e.Text == "Sign in\n" &&
+			len(e.To) == 1 && e.To[0] == "jane@example.com"
+	})).Return(nil)
+
+	_, err := svc.SendTemplate(context.Background(), &domain.TemplateEmail{
+		Template: "welcome",
+		To:       "jane@example.com",
+		Data:     welcomeData,
+	})
+
+	assert.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestTemplateService_SendTemplateLocale(t *testing.T) {
+	users := new(mocks.MockUserRepository)
+	prefs := new(mockPreferenceService)
+	outboxRepo := new(mockOutboxRepository)
+	svc := NewTemplateService(TemplateServiceConfig{
+		Outbox:      NewOutboxService(OutboxServiceConfig{Repo: outboxRepo}),
+		Users:       users,
+		Preferences: prefs,
+	})
+
+	subject := func(locale string) string {
+		return templates.Messages.Localizer(locale).T("welcome.subject")
+	}
+	expectSubject := func(want string) {
+		outboxRepo.On("Create", mock.Anything, mock.MatchedBy(func(msg *domain.OutboxMessage) bool {
+			return msg.Payload.Subject == want
+		})).Return(nil).Once()
+	}
+
+	userID := uuid.New()
+	users.On("GetByEmail", mock.Anything, "jane@example.com").Return(&user.User{ID: userID}, nil)
+	prefs.On("Get", mock.Anything, userID.String()).Return(preference.Preferences{preference.KeyLocale: "de"}, nil)
+	users.On("GetByEmail", mock.Anything, "guest@example.com").Return((*user.User)(nil), sql.ErrNoRows)
+
+	tests := []struct {
+		name string
+		req  domain.TemplateEmail
+		want string
+	}{
+		{"recipient preference", domain.TemplateEmail{To: "jane@example.com", AcceptLanguage: "fr"}, "de"},
+		{"explicit locale overrides preference", domain.TemplateEmail{To: "jane@example.com", Locale: "fr"}, "fr"},
+		{"accept-language for unknown recipient", domain.TemplateEmail{To: "guest@example.com", AcceptLanguage: "fr-CH, de;q=0.5"}, "fr"},
+		{"default locale", domain.TemplateEmail{To: "guest@example.com", AcceptLanguage: "ja"}, "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectSubject(subject(tt.want))
+			tt.req.Template = "welcome"
+			tt.req.Data = welcomeData
+
+			_, err := svc.SendTemplate(context.Background(), &tt.req)
+
+			assert.NoError(t, err)
+		})
+	}
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestTemplateService_Publish(t *testing.T) {
+	repo := new(mockTemplateRepository)
+	svc := NewTemplateService(TemplateServiceConfig{Repo: repo})
+	now := time.Date(2025, 8, 16, 9, 0, 0, 0, time.UTC)
+	svc.(*templateService).now = func() time.Time { return now }
+
+	t.Run("valid draft", func(t *testing.T) {
+		draft := &domain.TemplateVersion{Name: "welcome", Version: 4, Status: domain.TemplateDraft, HTMLBody: `{{template "base.html" .}}`}
+		repo.On("GetVersion", mock.Anything, "welcome", 4).Return(draft, nil).Once()
+		repo.On("Publish", mock.Anything, "welcome", 4, now).Return(nil).Once()
+
+		v, err := svc.Publish(context.Background(), "welcome", "4")
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, domain.TemplatePublished, v.Status)
+			assert.Equal(t, now, *v.PublishedAt)
+		}
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		draft := &domain.TemplateVersion{Name: "welcome", Version: 5, Status: domain.TemplateDraft, HTMLBody: `{{if .Data.name}}`}
+		repo.On("GetVersion", mock.Anything, "welcome", 5).Return(draft, nil).Once()
+
+		_, err := svc.Publish(context.Background(), "welcome", "5")
+
+		var validationErrs templates.ValidationErrors
+		if assert.True(t, errors.As(err, &validationErrs)) {
+			assert.Contains(t, validationErrs, "html_body")
+		}
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		_, err := svc.Publish(context.Background(), "welcome", "latest")
+		assert.ErrorContains(t, err, "invalid template version format")
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		repo.On("GetVersion", mock.Anything, "welcome", 9).Return(nil, sql.ErrNoRows).Once()
+
+		_, err := svc.Publish(context.Background(), "welcome", "9")
+		assert.ErrorIs(t, err, ErrTemplateVersionNotFound)
+	})
+
+	repo.AssertExpectations(t)
+}
+
+func TestTemplateService_Rollback(t *testing.T) {
+	repo := new(mockTemplateRepository)
+	svc := NewTemplateService(TemplateServiceConfig{Repo: repo})
+
+	earlier := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(24 * time.Hour)
+	repo.On("ListVersions", mock.Anything, "welcome").Return([]*domain.TemplateVersion{
+		{Name: "welcome", Version: 4, Status: domain.TemplateDraft, HTMLBody: "<p>draft</p>"},
+		{Name: "welcome", Version: 3, Status: domain.TemplatePublished, HTMLBody: "<p>current</p>", PublishedAt: &later},
+		{Name: "welcome", Version: 2, Status: domain.TemplateArchived, HTMLBody: "<p>previous</p>", PublishedAt: &later},
+		{Name: "welcome", Version: 1, Status: domain.TemplateArchived, HTMLBody: "<p>first</p>", PublishedAt: &earlier},
+	}, nil).Once()
+	repo.On("Publish", mock.Anything, "welcome", 2, mock.Anything).Return(nil).Once()
+
+	v, err := svc.Rollback(context.Background(), "welcome")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, v.Version)
+	}
+
+	repo.On("ListVersions", mock.Anything, "welcome").Return([]*domain.TemplateVersion{
+		{Name: "welcome", Version: 1, Status: domain.TemplatePublished, HTMLBody: "<p>only</p>", PublishedAt: &earlier},
+	}, nil).Once()
+
+	_, err = svc.Rollback(context.Background(), "welcome")
+	assert.ErrorIs(t, err, ErrNoRollbackTarget)
+}
+
+func TestTemplateService_UpdateDraft(t *testing.T) {
+	repo := new(mockTemplateRepository)
+	svc := NewTemplateService(TemplateServiceConfig{Repo: repo})
+
+	published := &domain.TemplateVersion{Name: "welcome", Version: 1, Status: domain.TemplatePublished}
+	repo.On("GetVersion", mock.Anything, "welcome", 1).Return(published, nil)
+
+	_, err := svc.UpdateDraft(context.Background(), "welcome", "1", TemplateSource{HTMLBody: "<p>new</p>"})
+
+	assert.ErrorIs(t, err, ErrTemplateNotDraft)
+	repo.AssertNotCalled(t, "UpdateDraft", mock.Anything, mock.Anything)
+}
+
+func TestTemplateService_UnknownTemplate(t *testing.T) {
+	svc := NewTemplateService(TemplateServiceConfig{Repo: new(mockTemplateRepository)})
+
+	_, err := svc.CreateDraft(context.Background(), "nope", TemplateSource{HTMLBody: "<p>x</p>"}, nil)
+
+	assert.ErrorIs(t, err, templates.ErrUnknownTemplate)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/service"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,6 +22,27 @@ func (m *UserService) GetUserByID(ctx context.Context, id string) (*user.UserRes
 	return args.Get(0).(*user.UserResponse), args.Error(1)
 }
 
+// ChangeUserStatus provides a mock function with given fields: ctx, id, req
+func (m *UserService) ChangeUserStatus(ctx context.Context, id string, req service.ChangeUserStatusRequest) (*user.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*user.UserResponse), args.Error(1)
+}
+
+// EnsureUserActive provides a mock function with given fields: ctx, id
+func (m *UserService) EnsureUserActive(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// EnsureUserRole provides a mock function with given fields: ctx, id, role
+func (m *UserService) EnsureUserRole(ctx context.Context, id string, role user.Role) error {
+	args := m.Called(ctx, id, role)
+	return args.Error(0)
+}
+
 // On provides a mock function with given fields: methodName, arguments...
 func (m *UserService) On(methodName string, arguments ...interface{}) *mock.Call {
 	return m.Mock.On(methodName, arguments...)
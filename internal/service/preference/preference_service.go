@@ -0,0 +1,195 @@
+package preference
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/preference"
+	"base-code-go-gin-clean/internal/pkg/redis"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+)
+
+// PreferenceService reads and updates user preferences.
+// Other services use Get to personalise their behaviour, e.g. the email locale.
+type PreferenceService interface {
+	// Get returns the resolved preferences of a user, with defaults for unset keys
+	Get(ctx context.Context, userID string) (preference.Preferences, error)
+
+	// Update validates and applies a partial update. A null value resets a key to its default.
+	Update(ctx context.Context, userID string, changes map[string]json.RawMessage) (preference.Preferences, error)
+
+	// Schema returns the definitions of all allowed preference keys
+	Schema() []preference.Definition
+}
+
+// Cache key prefix
+const preferencesCacheKeyPrefix = "user_preferences:"
+
+// Cache TTLs
+const (
+	defaultCacheTTL = 10 * time.Minute
+)
+
+type preferenceService struct {
+	repo      preference.Repository
+	redisRepo redis.Repository
+	registry  *preference.Registry
+	cacheTTL  time.Duration
+	now       func() time.Time
+}
+
+// PreferenceServiceConfig holds the dependencies of the preference service
+type PreferenceServiceConfig struct {
+	Repo      preference.Repository
+	RedisRepo redis.Repository
+	Registry  *preference.Registry // defaults to preference.DefaultRegistry()
+	CacheTTL  time.Duration
+}
+
+func NewPreferenceService(cfg PreferenceServiceConfig) PreferenceService {
+	svc := &preferenceService{
+		repo:      cfg.Repo,
+		redisRepo: cfg.RedisRepo,
+		registry:  cfg.Registry,
+		cacheTTL:  defaultCacheTTL,
+		now:       time.Now,
+	}
+
+	if svc.registry == nil {
+		svc.registry = preference.DefaultRegistry()
+	}
+	if cfg.CacheTTL > 0 {
+		svc.cacheTTL = cfg.CacheTTL
+	}
+
+	return svc
+}
+
+// Get returns the resolved preferences of a user
+func (s *preferenceService) Get(ctx context.Context, userID string) (preference.Preferences, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		err = fmt.Errorf("invalid user ID format: %v", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	settings, err := s.loadSettings(ctx, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return s.registry.Resolve(settings), nil
+}
+
+// Update validates and applies a partial update
+func (s *preferenceService) Update(ctx context.Context, userID string, changes map[string]json.RawMessage) (preference.Preferences, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		err = fmt.Errorf("invalid user ID format: %v", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	normalized, err := s.registry.Validate(changes)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	set := make(map[string]json.RawMessage, len(normalized))
+	var reset []string
+	for key, value := range normalized {
+		if value == nil {
+			reset = append(reset, key)
+		} else {
+			set[key] = value
+		}
+	}
+
+	// Merge the changes in the database rather than over a copy read first, so
+	// that concurrent updates of other keys are not lost
+	settings, err := s.repo.Merge(ctx, id, set, reset, s.now())
+	if err != nil {
+		err = fmt.Errorf("failed to save preferences: %w", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Drop the cached copy rather than caching these settings, which a concurrent
+	// update may already have replaced
+	if err := s.redisRepo.Delete(ctx, s.cacheKey(id)); err != nil {
+		span.RecordError(err)
+	}
+
+	return s.registry.Resolve(settings), nil
+}
+
+// Schema returns the definitions of all allowed preference keys
+func (s *preferenceService) Schema() []preference.Definition {
+	return s.registry.Definitions()
+}
+
+// loadSettings returns the stored settings, using the cache when possible
+func (s *preferenceService) loadSettings(ctx context.Context, id uuid.UUID) (map[string]json.RawMessage, error) {
+	if data, err := s.redisRepo.Get(ctx, s.cacheKey(id)); err == nil {
+		var settings map[string]json.RawMessage
+		if json.Unmarshal([]byte(data), &settings) == nil {
+			return settings, nil
+		}
+	}
+
+	settings, err := s.fetchSettings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cacheSettings(ctx, id, settings); err != nil {
+		telemetry.SpanFromContext(ctx).RecordError(err)
+	}
+
+	return settings, nil
+}
+
+// fetchSettings reads the stored settings from the database
+func (s *preferenceService) fetchSettings(ctx context.Context, id uuid.UUID) (map[string]json.RawMessage, error) {
+	prefs, err := s.repo.GetByUserID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return map[string]json.RawMessage{}, nil
+		}
+		return nil, fmt.Errorf("failed to load preferences: %w", err)
+	}
+
+	if prefs.Settings == nil {
+		return map[string]json.RawMessage{}, nil
+	}
+	return prefs.Settings, nil
+}
+
+// cacheSettings stores the raw settings in the cache
+func (s *preferenceService) cacheSettings(ctx context.Context, id uuid.UUID, settings map[string]json.RawMessage) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences for caching: %v", err)
+	}
+
+	return s.redisRepo.Set(ctx, s.cacheKey(id), string(data), s.cacheTTL)
+}
+
+func (s *preferenceService) cacheKey(id uuid.UUID) string {
+	return preferencesCacheKeyPrefix + id.String()
+}
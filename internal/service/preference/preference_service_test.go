@@ -0,0 +1,125 @@
+package preference
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/preference"
+	"base-code-go-gin-clean/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPreferenceRepository struct {
+	mock.Mock
+}
+
+func (m *mockPreferenceRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*preference.UserPreferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*preference.UserPreferences), args.Error(1)
+}
+
+func (m *mockPreferenceRepository) Merge(ctx context.Context, userID uuid.UUID, set map[string]json.RawMessage, reset []string, now time.Time) (map[string]json.RawMessage, error) {
+	args := m.Called(ctx, userID, set, reset, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]json.RawMessage), args.Error(1)
+}
+
+func (m *mockPreferenceRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func TestPreferenceService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("from cache", func(t *testing.T) {
+		repo := &mockPreferenceRepository{}
+		redisRepo := &mocks.MockRedisRepository{}
+		svc := NewPreferenceService(PreferenceServiceConfig{Repo: repo, RedisRepo: redisRepo})
+		userID := uuid.New()
+
+		redisRepo.On("Get", mock.Anything, "user_preferences:"+userID.String()).Return(`{"locale":"fr"}`, nil)
+
+		prefs, err := svc.Get(ctx, userID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "fr", prefs.Locale())
+		assert.True(t, prefs.Bool(preference.KeyNotificationsEmail))
+		repo.AssertNotCalled(t, "GetByUserID", mock.Anything, userID)
+	})
+
+	t.Run("defaults when nothing is stored", func(t *testing.T) {
+		repo := &mockPreferenceRepository{}
+		redisRepo := &mocks.MockRedisRepository{}
+		svc := NewPreferenceService(PreferenceServiceConfig{Repo: repo, RedisRepo: redisRepo})
+		userID := uuid.New()
+
+		redisRepo.On("Get", mock.Anything, "user_preferences:"+userID.String()).Return("", redis.Nil)
+		repo.On("GetByUserID", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+		redisRepo.On("Set", mock.Anything, "user_preferences:"+userID.String(), "{}", defaultCacheTTL).Return(nil)
+
+		prefs, err := svc.Get(ctx, userID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, "en", prefs.Locale())
+		assert.Equal(t, "system", prefs.String(preference.KeyUITheme))
+		redisRepo.AssertExpectations(t)
+	})
+}
+
+func TestPreferenceService_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merges changes and drops the cache", func(t *testing.T) {
+		repo := &mockPreferenceRepository{}
+		redisRepo := &mocks.MockRedisRepository{}
+		svc := NewPreferenceService(PreferenceServiceConfig{Repo: repo, RedisRepo: redisRepo})
+		userID := uuid.New()
+
+		repo.On("Merge", mock.Anything, userID,
+			map[string]json.RawMessage{"timezone": json.RawMessage(`"Europe/Berlin"`)},
+			[]string{"ui.theme"},
+			mock.AnythingOfType("time.Time"),
+		).Return(map[string]json.RawMessage{"locale": json.RawMessage(`"de"`), "timezone": json.RawMessage(`"Europe/Berlin"`)}, nil)
+		redisRepo.On("Delete", mock.Anything, "user_preferences:"+userID.String()).Return(nil)
+
+		prefs, err := svc.Update(ctx, userID.String(), map[string]json.RawMessage{
+			"timezone": json.RawMessage(`"Europe/Berlin"`),
+			"ui.theme": json.RawMessage(`null`),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "de", prefs.Locale())
+		assert.Equal(t, "Europe/Berlin", prefs.Location().String())
+		assert.Equal(t, "system", prefs.String(preference.KeyUITheme))
+		repo.AssertExpectations(t)
+		redisRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid values without saving", func(t *testing.T) {
+		repo := &mockPreferenceRepository{}
+		svc := NewPreferenceService(PreferenceServiceConfig{Repo: repo, RedisRepo: &mocks.MockRedisRepository{}})
+		userID := uuid.New()
+
+		prefs, err := svc.Update(ctx, userID.String(), map[string]json.RawMessage{
+			"ui.theme": json.RawMessage(`"neon"`),
+		})
+
+		var errs preference.ValidationErrors
+		assert.ErrorAs(t, err, &errs)
+		assert.Nil(t, prefs)
+		repo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,567 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	emailDomain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/domain/preference"
+	"base-code-go-gin-clean/internal/domain/privacy"
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/email"
+	"base-code-go-gin-clean/internal/pkg/redis"
+	"base-code-go-gin-clean/internal/pkg/storage"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+	"base-code-go-gin-clean/internal/service/avatar"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrExportInProgress is returned when an export is already being assembled for the user
+	ErrExportInProgress = errors.New("a data export is already in progress")
+	// ErrExportNotFound is returned when an export token is unknown or expired
+	ErrExportNotFound = errors.New("export not found or expired")
+	// ErrDeletionAlreadyRequested is returned when the user already has a pending deletion
+	ErrDeletionAlreadyRequested = errors.New("account deletion already requested")
+	// ErrNoPendingDeletion is returned when there is no deletion to cancel
+	ErrNoPendingDeletion = errors.New("no pending account deletion")
+)
+
+// Redis key prefixes
+const (
+	exportTokenKeyPrefix   = "gdpr_export:"
+	exportPendingKeyPrefix = "gdpr_export_pending:"
+	userCacheKeyPrefix     = "user:"
+	userStatusKeyPrefix    = "user_status:"
+	refreshTokenKeyPrefix  = "refresh_token:"
+	preferencesKeyPrefix   = "user_preferences:"
+)
+
+const (
+	defaultExportLinkTTL       = 48 * time.Hour
+	defaultDeletionGracePeriod = 14 * 24 * time.Hour
+	exportPendingTTL           = time.Hour
+	maxExportedHTTPLogs        = 1000
+	deletionBatchSize          = 100
+	redactedValue              = "[REDACTED]"
+)
+
+// PrivacyService handles subject access exports and right-to-erasure requests
+type PrivacyService interface {
+	// RequestExport starts assembling a data export in the background and emails a link when done
+	RequestExport(ctx context.Context, userID string) error
+	// OpenExport resolves a download token to the archive path on disk
+	OpenExport(ctx context.Context, token string) (string, error)
+	// RequestDeletion schedules the user's account for erasure after the grace period
+	RequestDeletion(ctx context.Context, userID string) (*privacy.DeletionRequest, error)
+	// CancelDeletion cancels a pending deletion during the grace period
+	CancelDeletion(ctx context.Context, userID string) (*privacy.DeletionRequest, error)
+	// ProcessDueDeletions erases all accounts whose grace period has ended
+	ProcessDueDeletions()
+	// PurgeExpiredExports removes export archives whose download link has expired
+	PurgeExpiredExports()
+}
+
+// PrivacyServiceConfig holds the dependencies and settings of the privacy service
+type PrivacyServiceConfig struct {
+	UserRepo            user.UserRepository
+	DeletionRepo        privacy.DeletionRequestRepository
+	HTTPLogRepo         httplog.Repository
+	RedisRepo           redis.Repository
+	EmailService        emailDomain.EmailService
+	AvatarStore         storage.BlobStore     // optional, avatars are deleted on erasure
+	PreferenceRepo      preference.Repository // optional, preferences are exported and deleted on erasure
+	ExportDir           string
+	ExportLinkTTL       time.Duration
+	DeletionGracePeriod time.Duration
+	BaseURL             string
+}
+
+type privacyService struct {
+	userRepo            user.UserRepository
+	deletionRepo        privacy.DeletionRequestRepository
+	httpLogRepo         httplog.Repository
+	redisRepo           redis.Repository
+	emailService        emailDomain.EmailService
+	avatarStore         storage.BlobStore
+	preferenceRepo      preference.Repository
+	exportDir           string
+	exportLinkTTL       time.Duration
+	deletionGracePeriod time.Duration
+	baseURL             string
+	runAsync            func(func())
+	now                 func() time.Time
+}
+
+func NewPrivacyService(cfg PrivacyServiceConfig) PrivacyService {
+	svc := &privacyService{
+		userRepo:            cfg.UserRepo,
+		deletionRepo:        cfg.DeletionRepo,
+		httpLogRepo:         cfg.HTTPLogRepo,
+		redisRepo:           cfg.RedisRepo,
+		emailService:        cfg.EmailService,
+		avatarStore:         cfg.AvatarStore,
+		preferenceRepo:      cfg.PreferenceRepo,
+		exportDir:           cfg.ExportDir,
+		exportLinkTTL:       defaultExportLinkTTL,
+		deletionGracePeriod: defaultDeletionGracePeriod,
+		baseURL:             cfg.BaseURL,
+		runAsync:            func(fn func()) { go fn() },
+		now:                 time.Now,
+	}
+
+	// Override defaults if provided
+	if cfg.ExportLinkTTL > 0 {
+		svc.exportLinkTTL = cfg.ExportLinkTTL
+	}
+	if cfg.DeletionGracePeriod > 0 {
+		svc.deletionGracePeriod = cfg.DeletionGracePeriod
+	}
+
+	return svc
+}
+
+// RequestExport starts assembling a data export in the background
+func (s *privacyService) RequestExport(ctx context.Context, userID string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	pendingKey := exportPendingKeyPrefix + userID
+	marked, err := s.redisRepo.SetNX(ctx, pendingKey, "1", exportPendingTTL)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to mark export as pending: %w", err)
+	}
+	if !marked {
+		return ErrExportInProgress
+	}
+
+	// Keep the trace but do not let the request's cancellation stop the export
+	bgCtx := context.WithoutCancel(ctx)
+	s.runAsync(func() {
+		defer s.redisRepo.Delete(bgCtx, pendingKey)
+
+		if err := s.buildExport(bgCtx, u); err != nil {
+			log.Printf("privacy: failed to build export for user %s: %v", userID, err)
+		}
+	})
+
+	return nil
+}
+
+// buildExport writes the export archive, stores a download token and emails the link
+func (s *privacyService) buildExport(ctx context.Context, u *user.User) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	userID := u.ID.String()
+
+	refreshTokens := []map[string]interface{}{}
+	if token, err := s.redisRepo.Get(ctx, refreshTokenKeyPrefix+userID); err == nil && token != "" {
+		refreshTokens = append(refreshTokens, map[string]interface{}{
+			"token": maskSecret(token),
+		})
+	}
+
+	httpLogs, err := s.httpLogRepo.FindIncomingRequestsByUserID(ctx, userID, maxExportedHTTPLogs)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to load http logs: %w", err)
+	}
+
+	var settings interface{} = map[string]interface{}{}
+	if s.preferenceRepo != nil {
+		prefs, err := s.preferenceRepo.GetByUserID(ctx, u.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			span.RecordError(err)
+			return fmt.Errorf("failed to load preferences: %w", err)
+		}
+		if prefs != nil {
+			settings = prefs.Settings
+		}
+	}
+
+	archive := privacy.ExportArchive{
+		GeneratedAt:   s.now().UTC(),
+		Profile:       u.ToResponse(),
+		Preferences:   settings,
+		RefreshTokens: refreshTokens,
+		HTTPLogs:      httpLogs,
+	}
+
+	path, err := s.writeArchive(userID, archive)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if err := s.redisRepo.Set(ctx, exportTokenKeyPrefix+token, path, s.exportLinkTTL); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to store export token: %w", err)
+	}
+
+	if s.emailService == nil {
+		return nil
+	}
+
+	downloadURL := s.baseURL + "/api/v1/privacy/exports/" + token
+	subject, body, err := email.DataExportReadyEmail(s.userLocale(ctx, u), u.Name, downloadURL, s.now().Add(s.exportLinkTTL))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to render export email: %w", err)
+	}
+
+	return s.emailService.SendEmail(ctx, &emailDomain.Email{
+		To:      []string{u.Email},
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// userLocale returns the locale preference of u for its emails, or "" for the default
+func (s *privacyService) userLocale(ctx context.Context, u *user.User) string {
+	if s.preferenceRepo == nil {
+		return ""
+	}
+	prefs, err := s.preferenceRepo.GetByUserID(ctx, u.ID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("privacy: failed to load preferences of user %s: %v", u.ID, err)
+		}
+		return ""
+	}
+	return preference.DefaultRegistry().Resolve(prefs.Settings).Locale()
+}
+
+// writeArchive writes the export as a zip file of JSON documents and returns its path
+func (s *privacyService) writeArchive(userID string, archive privacy.ExportArchive) (string, error) {
+	if err := os.MkdirAll(s.exportDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	path := filepath.Join(s.exportDir, fmt.Sprintf("%s-%d.zip", userID, s.now().Unix()))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export archive: %w", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	entries := []struct {
+		name string
+		data interface{}
+	}{
+		{"export.json", map[string]interface{}{"generated_at": archive.GeneratedAt, "user_id": userID}},
+		{"profile.json", archive.Profile},
+		{"preferences.json", archive.Preferences},
+		{"refresh_tokens.json", archive.RefreshTokens},
+		{"http_logs.json", archive.HTTPLogs},
+	}
+
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			return "", fmt.Errorf("failed to add %s to archive: %w", entry.name, err)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entry.data); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize export archive: %w", err)
+	}
+
+	return path, nil
+}
+
+// OpenExport resolves a download token to the archive path on disk
+func (s *privacyService) OpenExport(ctx context.Context, token string) (string, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	path, err := s.redisRepo.Get(ctx, exportTokenKeyPrefix+token)
+	if err != nil || path == "" {
+		return "", ErrExportNotFound
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		span.RecordError(err)
+		return "", ErrExportNotFound
+	}
+
+	return path, nil
+}
+
+// PurgeExpiredExports removes export archives whose download link has expired
+func (s *privacyService) PurgeExpiredExports() {
+	entries, err := os.ReadDir(s.exportDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("privacy: failed to read export directory: %v", err)
+		}
+		return
+	}
+
+	cutoff := s.now().Add(-s.exportLinkTTL)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".zip" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.exportDir, entry.Name())); err != nil {
+			log.Printf("privacy: failed to remove expired export %s: %v", entry.Name(), err)
+		}
+	}
+}
+
+// RequestDeletion schedules the user's account for erasure after the grace period
+func (s *privacyService) RequestDeletion(ctx context.Context, userID string) (*privacy.DeletionRequest, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if _, err := s.deletionRepo.GetPendingByUserID(ctx, u.ID); err == nil {
+		return nil, ErrDeletionAlreadyRequested
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to check pending deletion: %w", err)
+	}
+
+	now := s.now()
+	req := &privacy.DeletionRequest{
+		ID:           uuid.New(),
+		UserID:       u.ID,
+		Status:       privacy.DeletionPending,
+		ScheduledFor: now.Add(s.deletionGracePeriod),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.deletionRepo.Create(ctx, req); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to create deletion request: %w", err)
+	}
+
+	if s.emailService != nil {
+		subject, body, err := email.AccountDeletionScheduledEmail(s.userLocale(ctx, u), u.Name, req.ScheduledFor)
+		if err == nil {
+			err = s.emailService.SendEmail(ctx, &emailDomain.Email{
+				To:      []string{u.Email},
+				Subject: subject,
+				Body:    body,
+			})
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	return req, nil
+}
+
+// CancelDeletion cancels a pending deletion during the grace period
+func (s *privacyService) CancelDeletion(ctx context.Context, userID string) (*privacy.DeletionRequest, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		err = fmt.Errorf("invalid user ID format: %v", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	req, err := s.deletionRepo.GetPendingByUserID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoPendingDeletion
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := s.now()
+	req.Status = privacy.DeletionCancelled
+	req.CancelledAt = &now
+	if err := s.deletionRepo.Update(ctx, req); err != nil {
+		// The deletion was carried out since it was loaded
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoPendingDeletion
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to cancel deletion request: %w", err)
+	}
+
+	return req, nil
+}
+
+// ProcessDueDeletions erases all accounts whose grace period has ended
+func (s *privacyService) ProcessDueDeletions() {
+	ctx, span := telemetry.Start(context.Background())
+	defer span.End()
+
+	reqs, err := s.deletionRepo.ListDue(ctx, s.now(), deletionBatchSize)
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("privacy: failed to list due deletions: %v", err)
+		return
+	}
+
+	// Requests cancelled since they were listed are skipped by Process
+	for _, req := range reqs {
+		if _, err := s.deletionRepo.Process(ctx, req.ID, s.eraseUser); err != nil {
+			span.RecordError(err)
+			log.Printf("privacy: failed to erase user %s: %v", req.UserID, err)
+		}
+	}
+}
+
+// eraseUser scrubs the user's PII from the logs, anonymizes the account and clears
+// caches, then marks the request completed
+func (s *privacyService) eraseUser(ctx context.Context, req *privacy.DeletionRequest) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	u, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+
+	if u != nil {
+		if _, err := s.httpLogRepo.ScrubValues(ctx, piiReplacements(u)); err != nil {
+			return fmt.Errorf("failed to scrub http logs: %w", err)
+		}
+
+		if s.avatarStore != nil && u.AvatarKey != "" {
+			if err := avatar.DeleteAll(ctx, s.avatarStore, u.AvatarKey); err != nil {
+				return fmt.Errorf("failed to delete avatar: %w", err)
+			}
+		}
+
+		if s.preferenceRepo != nil {
+			if err := s.preferenceRepo.Delete(ctx, u.ID); err != nil {
+				return fmt.Errorf("failed to delete preferences: %w", err)
+			}
+		}
+
+		u.Anonymize(s.now())
+		if err := s.userRepo.Anonymize(ctx, u); err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+	}
+
+	userID := req.UserID.String()
+	for _, key := range []string{
+		userCacheKeyPrefix + userID,
+		userStatusKeyPrefix + userID,
+		refreshTokenKeyPrefix + userID,
+		preferencesKeyPrefix + userID,
+		exportPendingKeyPrefix + userID,
+	} {
+		if err := s.redisRepo.Delete(ctx, key); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	now := s.now()
+	req.Status = privacy.DeletionCompleted
+	req.CompletedAt = &now
+	return nil
+}
+
+// getUser parses the ID and loads the user
+func (s *privacyService) getUser(ctx context.Context, userID string) (*user.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %v", err)
+	}
+
+	u, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// piiReplacements maps the user's personal data, as it appears in JSON documents,
+// to its redacted form. The email is matched anywhere inside a string, the name
+// only as a complete JSON string value.
+func piiReplacements(u *user.User) map[string]string {
+	replacements := make(map[string]string)
+
+	if u.Email != "" {
+		encoded := jsonString(u.Email)
+		replacements[encoded[1:len(encoded)-1]] = redactedValue
+	}
+	if u.Name != "" {
+		replacements[jsonString(u.Name)] = `"` + redactedValue + `"`
+	}
+
+	return replacements
+}
+
+// jsonString encodes s as a JSON string the way PostgreSQL writes it, leaving &, <
+// and > unescaped
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// maskSecret hides all but the last four characters of a secret
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+// generateToken returns a random URL-safe token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate export token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
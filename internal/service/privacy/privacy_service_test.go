@@ -0,0 +1,272 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	emailDomain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/domain/privacy"
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/test/mocks"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockDeletionRepository struct {
+	mock.Mock
+}
+
+func (m *mockDeletionRepository) Create(ctx context.Context, req *privacy.DeletionRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *mockDeletionRepository) GetPendingByUserID(ctx context.Context, userID uuid.UUID) (*privacy.DeletionRequest, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*privacy.DeletionRequest), args.Error(1)
+}
+
+func (m *mockDeletionRepository) ListDue(ctx context.Context, before time.Time, limit int) ([]*privacy.DeletionRequest, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).([]*privacy.DeletionRequest), args.Error(1)
+}
+
+func (m *mockDeletionRepository) Update(ctx context.Context, req *privacy.DeletionRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+// Process calls fn with the request it is set up to return, unless it is no longer pending
+func (m *mockDeletionRepository) Process(ctx context.Context, id uuid.UUID, fn func(ctx context.Context, req *privacy.DeletionRequest) error) (bool, error) {
+	args := m.Called(ctx, id)
+	req, _ := args.Get(0).(*privacy.DeletionRequest)
+	if req == nil || req.Status != privacy.DeletionPending {
+		return false, args.Error(1)
+	}
+	return true, fn(ctx, req)
+}
+
+type mockHTTPLogRepository struct {
+	httplog.Repository
+	mock.Mock
+}
+
+func (m *mockHTTPLogRepository) FindIncomingRequestsByUserID(ctx context.Context, userID string, limit int) ([]*httplog.LogIncomingRequest, error) {
+	args := m.Called(ctx, userID, limit)
+	return args.Get(0).([]*httplog.LogIncomingRequest), args.Error(1)
+}
+
+func (m *mockHTTPLogRepository) ScrubValues(ctx context.Context, replacements map[string]string) (int64, error) {
+	args := m.Called(ctx, replacements)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+type mockEmailService struct {
+	mock.Mock
+}
+
+func (m *mockEmailService) SendEmail(ctx context.Context, e *emailDomain.Email) error {
+	args := m.Called(ctx, e)
+	return args.Error(0)
+}
+
+func newTestService(t *testing.T) (*privacyService, *mocks.MockUserRepository, *mockDeletionRepository, *mockHTTPLogRepository, *mocks.MockRedisRepository, *mockEmailService) {
+	userRepo := &mocks.MockUserRepository{}
+	deletionRepo := &mockDeletionRepository{}
+	httpLogRepo := &mockHTTPLogRepository{}
+	redisRepo := &mocks.MockRedisRepository{}
+	emailSvc := &mockEmailService{}
+
+	svc := NewPrivacyService(PrivacyServiceConfig{
+		UserRepo:     userRepo,
+		DeletionRepo: deletionRepo,
+		HTTPLogRepo:  httpLogRepo,
+		RedisRepo:    redisRepo,
+		EmailService: emailSvc,
+		ExportDir:    t.TempDir(),
+		BaseURL:      "http://localhost:8080",
+	}).(*privacyService)
+	svc.runAsync = func(fn func()) { fn() }
+
+	return svc, userRepo, deletionRepo, httpLogRepo, redisRepo, emailSvc
+}
+
+func TestPrivacyService_RequestExport(t *testing.T) {
+	svc, userRepo, _, httpLogRepo, redisRepo, emailSvc := newTestService(t)
+	ctx := context.Background()
+
+	u := &user.User{ID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com", Status: user.StatusActive}
+	userID := u.ID.String()
+
+	var storedPath string
+	userRepo.On("GetByID", mock.Anything, u.ID).Return(u, nil)
+	redisRepo.On("SetNX", mock.Anything, "gdpr_export_pending:"+userID, "1", exportPendingTTL).Return(true, nil)
+	redisRepo.On("Get", mock.Anything, "refresh_token:"+userID).Return("abcdefgh1234", nil)
+	httpLogRepo.On("FindIncomingRequestsByUserID", mock.Anything, userID, maxExportedHTTPLogs).
+		Return([]*httplog.LogIncomingRequest{{TraceID: "trace-1", Endpoint: "/api/v1/users/" + userID}}, nil)
+	redisRepo.On("Set", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return len(key) > len(exportTokenKeyPrefix) && key[:len(exportTokenKeyPrefix)] == exportTokenKeyPrefix
+	}), mock.AnythingOfType("string"), defaultExportLinkTTL).Run(func(args mock.Arguments) {
+		storedPath = args.String(2)
+	}).Return(nil)
+	emailSvc.On("SendEmail", mock.Anything, mock.MatchedBy(func(e *emailDomain.Email) bool {
+		return len(e.To) == 1 && e.To[0] == u.Email
+	})).Return(nil)
+	redisRepo.On("Delete", mock.Anything, "gdpr_export_pending:"+userID).Return(nil)
+
+	err := svc.RequestExport(ctx, userID)
+	assert.NoError(t, err)
+
+	// The archive contains one JSON document per data category
+	zr, err := zip.OpenReader(storedPath)
+	assert.NoError(t, err)
+	defer zr.Close()
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.ElementsMatch(t, []string{"export.json", "profile.json", "preferences.json", "refresh_tokens.json", "http_logs.json"}, names)
+
+	redisRepo.AssertExpectations(t)
+	emailSvc.AssertExpectations(t)
+}
+
+func TestPrivacyService_RequestExport_InProgress(t *testing.T) {
+	svc, userRepo, _, _, redisRepo, _ := newTestService(t)
+	ctx := context.Background()
+
+	u := &user.User{ID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com", Status: user.StatusActive}
+	userID := u.ID.String()
+
+	userRepo.On("GetByID", mock.Anything, u.ID).Return(u, nil)
+	redisRepo.On("SetNX", mock.Anything, "gdpr_export_pending:"+userID, "1", exportPendingTTL).Return(false, nil)
+
+	err := svc.RequestExport(ctx, userID)
+
+	assert.ErrorIs(t, err, ErrExportInProgress)
+}
+
+func TestPIIReplacements(t *testing.T) {
+	u := &user.User{Name: "Tom & Jerry <TJ>", Email: "tom&jerry@example.com"}
+
+	replacements := piiReplacements(u)
+
+	// PostgreSQL writes these characters unescaped in jsonb text
+	assert.Equal(t, map[string]string{
+		"tom&jerry@example.com": redactedValue,
+		`"Tom & Jerry <TJ>"`:    `"` + redactedValue + `"`,
+	}, replacements)
+}
+
+func TestPrivacyService_RequestDeletion(t *testing.T) {
+	svc, userRepo, deletionRepo, _, _, emailSvc := newTestService(t)
+	ctx := context.Background()
+
+	t.Run("schedules deletion after grace period", func(t *testing.T) {
+		u := &user.User{ID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com"}
+
+		userRepo.On("GetByID", mock.Anything, u.ID).Return(u, nil)
+		deletionRepo.On("GetPendingByUserID", mock.Anything, u.ID).Return(nil, sql.ErrNoRows)
+		deletionRepo.On("Create", mock.Anything, mock.AnythingOfType("*privacy.DeletionRequest")).Return(nil)
+		emailSvc.On("SendEmail", mock.Anything, mock.AnythingOfType("*email.Email")).Return(nil)
+
+		req, err := svc.RequestDeletion(ctx, u.ID.String())
+
+		assert.NoError(t, err)
+		assert.Equal(t, privacy.DeletionPending, req.Status)
+		assert.WithinDuration(t, time.Now().Add(defaultDeletionGracePeriod), req.ScheduledFor, time.Minute)
+	})
+
+	t.Run("already requested", func(t *testing.T) {
+		u := &user.User{ID: uuid.New(), Name: "John Doe", Email: "john@example.com"}
+
+		userRepo.On("GetByID", mock.Anything, u.ID).Return(u, nil)
+		deletionRepo.On("GetPendingByUserID", mock.Anything, u.ID).Return(&privacy.DeletionRequest{UserID: u.ID}, nil)
+
+		req, err := svc.RequestDeletion(ctx, u.ID.String())
+
+		assert.ErrorIs(t, err, ErrDeletionAlreadyRequested)
+		assert.Nil(t, req)
+	})
+}
+
+func TestPrivacyService_ProcessDueDeletions(t *testing.T) {
+	svc, userRepo, deletionRepo, httpLogRepo, redisRepo, _ := newTestService(t)
+
+	u := &user.User{ID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com", Status: user.StatusActive}
+	userID := u.ID.String()
+	req := &privacy.DeletionRequest{ID: uuid.New(), UserID: u.ID, Status: privacy.DeletionPending}
+
+	deletionRepo.On("ListDue", mock.Anything, mock.AnythingOfType("time.Time"), deletionBatchSize).
+		Return([]*privacy.DeletionRequest{req}, nil)
+	userRepo.On("GetByID", mock.Anything, u.ID).Return(u, nil)
+	httpLogRepo.On("ScrubValues", mock.Anything, map[string]string{
+		"jane@example.com": "[REDACTED]",
+		`"Jane Doe"`:       `"[REDACTED]"`,
+	}).Return(3, nil)
+	userRepo.On("Anonymize", mock.Anything, u).Return(nil)
+	for _, key := range []string{"user:", "user_status:", "refresh_token:", "user_preferences:", "gdpr_export_pending:"} {
+		redisRepo.On("Delete", mock.Anything, key+userID).Return(nil)
+	}
+	deletionRepo.On("Process", mock.Anything, req.ID).Return(req, nil)
+
+	svc.ProcessDueDeletions()
+
+	assert.Equal(t, privacy.DeletionCompleted, req.Status)
+	assert.NotNil(t, req.CompletedAt)
+	assert.Equal(t, "Deleted User", u.Name)
+	assert.NotEqual(t, "jane@example.com", u.Email)
+	assert.False(t, u.DeletedAt.IsZero())
+	httpLogRepo.AssertExpectations(t)
+	redisRepo.AssertExpectations(t)
+}
+
+func TestPrivacyService_ProcessDueDeletions_CancelledAfterListing(t *testing.T) {
+	svc, userRepo, deletionRepo, httpLogRepo, _, _ := newTestService(t)
+
+	u := &user.User{ID: uuid.New(), Name: "Jane Doe", Email: "jane@example.com", Status: user.StatusActive}
+	stored := &privacy.DeletionRequest{ID: uuid.New(), UserID: u.ID, Status: privacy.DeletionPending}
+	listed := *stored
+
+	// The user cancels between the listing and the erasure
+	deletionRepo.On("ListDue", mock.Anything, mock.AnythingOfType("time.Time"), deletionBatchSize).
+		Return([]*privacy.DeletionRequest{&listed}, nil).
+		Run(func(mock.Arguments) {
+			_, err := svc.CancelDeletion(context.Background(), u.ID.String())
+			assert.NoError(t, err)
+		})
+	deletionRepo.On("GetPendingByUserID", mock.Anything, u.ID).Return(stored, nil)
+	deletionRepo.On("Update", mock.Anything, stored).Return(nil)
+	deletionRepo.On("Process", mock.Anything, stored.ID).Return(stored, nil)
+
+	svc.ProcessDueDeletions()
+
+	assert.Equal(t, privacy.DeletionCancelled, stored.Status)
+	assert.Nil(t, stored.CompletedAt)
+	assert.Equal(t, "Jane Doe", u.Name)
+	userRepo.AssertNotCalled(t, "Anonymize", mock.Anything, mock.Anything)
+	httpLogRepo.AssertNotCalled(t, "ScrubValues", mock.Anything, mock.Anything)
+}
+
+func TestPrivacyService_CancelDeletion_AlreadyProcessed(t *testing.T) {
+	svc, _, deletionRepo, _, _, _ := newTestService(t)
+
+	userID := uuid.New()
+	req := &privacy.DeletionRequest{ID: uuid.New(), UserID: userID, Status: privacy.DeletionPending}
+	deletionRepo.On("GetPendingByUserID", mock.Anything, userID).Return(req, nil)
+	deletionRepo.On("Update", mock.Anything, req).Return(sql.ErrNoRows)
+
+	_, err := svc.CancelDeletion(context.Background(), userID.String())
+
+	assert.ErrorIs(t, err, ErrNoPendingDeletion)
+}
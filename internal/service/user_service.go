@@ -2,30 +2,53 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	emailDomain "base-code-go-gin-clean/internal/domain/email"
 	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/email"
 	"base-code-go-gin-clean/internal/pkg/redis"
+	"base-code-go-gin-clean/internal/pkg/storage"
 	"base-code-go-gin-clean/internal/pkg/telemetry"
+	"base-code-go-gin-clean/internal/service/avatar"
+	preferenceService "base-code-go-gin-clean/internal/service/preference"
 
 	"github.com/google/uuid"
 )
 
 type UserService interface {
 	GetUserByID(ctx context.Context, id string) (*user.UserResponse, error)
+	ChangeUserStatus(ctx context.Context, id string, req ChangeUserStatusRequest) (*user.UserResponse, error)
+	EnsureUserActive(ctx context.Context, id string) error
+	EnsureUserRole(ctx context.Context, id string, role user.Role) error
+}
+
+// ChangeUserStatusRequest describes an administrative status change
+type ChangeUserStatusRequest struct {
+	Status         user.Status
+	Reason         string
+	SuspendedUntil *time.Time
 }
 
 type userService struct {
-	userRepo  user.UserRepository
-	redisRepo redis.Repository
-	cacheTTL  time.Duration
+	userRepo     user.UserRepository
+	redisRepo    redis.Repository
+	emailService emailDomain.EmailService
+	preferences  preferenceService.PreferenceService
+	avatarStore  storage.BlobStore
+	avatarURLTTL time.Duration
+	cacheTTL     time.Duration
 }
 
 // Cache key prefixes
 const (
-	userCacheKeyPrefix = "user:"
+	userCacheKeyPrefix       = "user:"
+	userStatusCacheKeyPrefix = "user_status:"
+	refreshTokenKeyPrefix    = "refresh_token:"
 )
 
 // Cache TTLs
@@ -34,22 +57,33 @@ const (
 )
 
 type UserServiceConfig struct {
-	UserRepo  user.UserRepository
-	RedisRepo redis.Repository
-	CacheTTL  time.Duration
+	UserRepo     user.UserRepository
+	RedisRepo    redis.Repository
+	EmailService emailDomain.EmailService            // optional, used for status notifications
+	Preferences  preferenceService.PreferenceService // optional, used for the locale of notifications
+	AvatarStore  storage.BlobStore                   // optional, used to sign avatar URLs
+	AvatarURLTTL time.Duration                       // should comfortably exceed CacheTTL since URLs are cached
+	CacheTTL     time.Duration
 }
 
 func NewUserService(cfg UserServiceConfig) UserService {
 	svc := &userService{
-		userRepo:  cfg.UserRepo,
-		redisRepo: cfg.RedisRepo,
-		cacheTTL:  defaultCacheTTL,
+		userRepo:     cfg.UserRepo,
+		redisRepo:    cfg.RedisRepo,
+		emailService: cfg.EmailService,
+		preferences:  cfg.Preferences,
+		avatarStore:  cfg.AvatarStore,
+		avatarURLTTL: avatar.DefaultURLTTL,
+		cacheTTL:     defaultCacheTTL,
 	}
 
 	// Override default cache TTL if provided
 	if cfg.CacheTTL > 0 {
 		svc.cacheTTL = cfg.CacheTTL
 	}
+	if cfg.AvatarURLTTL > 0 {
+		svc.avatarURLTTL = cfg.AvatarURLTTL
+	}
 
 	return svc
 }
@@ -58,6 +92,10 @@ func (s *userService) getUserCacheKey(id string) string {
 	return userCacheKeyPrefix + id
 }
 
+func (s *userService) getUserStatusCacheKey(id string) string {
+	return userStatusCacheKeyPrefix + id
+}
+
 // GetUserByID retrieves a user by ID with caching
 func (s *userService) GetUserByID(ctx context.Context, idStr string) (*user.UserResponse, error) {
 	// Start a new span for the service method
@@ -89,6 +127,12 @@ func (s *userService) GetUserByID(ctx context.Context, idStr string) (*user.User
 
 	// Convert to response model
 	userResponse := user.ToResponse()
+	if s.avatarStore != nil {
+		userResponse.AvatarURLs, err = avatar.SignedURLs(ctx, s.avatarStore, user.AvatarKey, s.avatarURLTTL)
+		if err != nil {
+			span.RecordError(err)
+		}
+	}
 
 	// Cache the result
 	if err := s.cacheUser(ctx, cacheKey, userResponse); err != nil {
@@ -138,3 +182,165 @@ func (s *userService) cacheUser(ctx context.Context, key string, user *user.User
 
 	return err
 }
+
+// ChangeUserStatus applies an administrative status change and revokes
+// the user's sessions when the account is no longer active
+func (s *userService) ChangeUserStatus(ctx context.Context, idStr string, req ChangeUserStatusRequest) (*user.UserResponse, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		err = fmt.Errorf("invalid user ID format: %v", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = user.ErrUserNotFound
+		}
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if req.Status == user.StatusSuspended && req.SuspendedUntil != nil && !req.SuspendedUntil.After(time.Now()) {
+		err = fmt.Errorf("%w: suspended_until must be in the future", user.ErrInvalidStatus)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := u.ChangeStatus(req.Status, req.Reason, req.SuspendedUntil, time.Now()); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateStatus(ctx, u); err != nil {
+		err = fmt.Errorf("failed to update user status: %w", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Refresh the status cache so the change takes effect immediately
+	if err := s.cacheUserStatus(ctx, idStr, u.StatusSnapshot()); err != nil {
+		span.RecordError(err)
+	}
+	if err := s.redisRepo.Delete(ctx, s.getUserCacheKey(idStr)); err != nil {
+		span.RecordError(err)
+	}
+	if u.Status != user.StatusActive {
+		if err := s.redisRepo.Delete(ctx, refreshTokenKeyPrefix+idStr); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	if u.Status == user.StatusSuspended {
+		if err := s.sendSuspensionNotice(ctx, u); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	return u.ToResponse(), nil
+}
+
+// EnsureUserActive returns user.ErrAccountInactive if the user may not authenticate
+func (s *userService) EnsureUserActive(ctx context.Context, idStr string) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	snapshot, err := s.statusSnapshot(ctx, idStr)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if !snapshot.IsActiveAt(time.Now()) {
+		return user.ErrAccountInactive
+	}
+	return nil
+}
+
+// EnsureUserRole returns user.ErrInsufficientRole if the user does not have the role
+func (s *userService) EnsureUserRole(ctx context.Context, idStr string, role user.Role) error {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	snapshot, err := s.statusSnapshot(ctx, idStr)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if !snapshot.HasRole(role) {
+		return user.ErrInsufficientRole
+	}
+	return nil
+}
+
+// statusSnapshot returns the cached status and role of a user, loading them from
+// the database on a cache miss
+func (s *userService) statusSnapshot(ctx context.Context, idStr string) (user.StatusSnapshot, error) {
+	var snapshot user.StatusSnapshot
+	data, err := s.redisRepo.Get(ctx, s.getUserStatusCacheKey(idStr))
+	if err == nil && json.Unmarshal([]byte(data), &snapshot) == nil {
+		return snapshot, nil
+	}
+
+	userID, err := uuid.Parse(idStr)
+	if err != nil {
+		return snapshot, fmt.Errorf("invalid user ID format: %v", err)
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = user.ErrUserNotFound
+		}
+		return snapshot, err
+	}
+
+	snapshot = u.StatusSnapshot()
+	if err := s.cacheUserStatus(ctx, idStr, snapshot); err != nil {
+		telemetry.SpanFromContext(ctx).RecordError(err)
+	}
+	return snapshot, nil
+}
+
+// cacheUserStatus stores a status snapshot in the cache
+func (s *userService) cacheUserStatus(ctx context.Context, id string, snapshot user.StatusSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user status for caching: %v", err)
+	}
+
+	return s.redisRepo.Set(ctx, s.getUserStatusCacheKey(id), string(data), s.cacheTTL)
+}
+
+// sendSuspensionNotice notifies the user that their account was suspended
+func (s *userService) sendSuspensionNotice(ctx context.Context, u *user.User) error {
+	if s.emailService == nil {
+		return nil
+	}
+
+	locale := ""
+	if s.preferences != nil {
+		prefs, err := s.preferences.Get(ctx, u.ID.String())
+		if err != nil {
+			telemetry.SpanFromContext(ctx).RecordError(err)
+		} else {
+			locale = prefs.Locale()
+		}
+	}
+
+	subject, body, err := email.AccountSuspendedEmail(locale, u.Name, u.SuspensionReason, u.SuspendedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to render suspension email: %w", err)
+	}
+
+	return s.emailService.SendEmail(ctx, &emailDomain.Email{
+		To:      []string{u.Email},
+		Subject: subject,
+		Body:    body,
+	})
+}
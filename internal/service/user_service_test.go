@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"testing"
@@ -42,6 +43,11 @@ func (m *mockRedisRepository) Delete(ctx context.Context, key string) error {
 	return args.Error(0)
 }
 
+func (m *mockRedisRepository) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *mockRedisRepository) Exists(ctx context.Context, key string) (bool, error) {
 	args := m.Called(ctx, key)
 	return args.Bool(0), args.Error(1)
@@ -78,6 +84,26 @@ func (m *mockUserRepository) Create(ctx context.Context, user *user.User) error
 	return args.Error(0)
 }
 
+func (m *mockUserRepository) UpdateStatus(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateAvatar(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) UpdateRole(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) Anonymize(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
 func (m *mockUserRepository) On(methodName string, arguments ...interface{}) *mock.Call {
 	return m.Mock.On(methodName, arguments...)
 }
@@ -190,3 +216,144 @@ func TestUserService_GetUserByID(t *testing.T) {
 	})
 
 }
+
+func TestUserService_ChangeUserStatus(t *testing.T) {
+	mockRepo := new(mockUserRepository)
+	mockRedis := new(mockRedisRepository)
+
+	service := svc.NewUserService(svc.UserServiceConfig{
+		UserRepo:  mockRepo,
+		RedisRepo: mockRedis,
+	})
+	ctx := context.Background()
+
+	t.Run("suspend active user", func(t *testing.T) {
+		testID := uuid.New()
+		existing := &user.User{ID: testID, Name: "Test User", Email: "test@example.com", Status: user.StatusActive}
+		until := time.Now().Add(24 * time.Hour)
+
+		mockRepo.On("GetByID", mock.Anything, testID).Return(existing, nil)
+		mockRepo.On("UpdateStatus", mock.Anything, existing).Return(nil)
+		mockRedis.On("Set", mock.Anything, "user_status:"+testID.String(), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
+		mockRedis.On("Delete", mock.Anything, "user:"+testID.String()).Return(nil)
+		mockRedis.On("Delete", mock.Anything, "refresh_token:"+testID.String()).Return(nil)
+
+		result, err := service.ChangeUserStatus(ctx, testID.String(), svc.ChangeUserStatusRequest{
+			Status:         user.StatusSuspended,
+			Reason:         "abuse",
+			SuspendedUntil: &until,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, user.StatusSuspended, result.Status)
+		assert.Equal(t, "abuse", result.SuspensionReason)
+		mockRepo.AssertExpectations(t)
+		mockRedis.AssertExpectations(t)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		testID := uuid.New()
+		existing := &user.User{ID: testID, Status: user.StatusActive}
+
+		mockRepo.On("GetByID", mock.Anything, testID).Return(existing, nil)
+
+		result, err := service.ChangeUserStatus(ctx, testID.String(), svc.ChangeUserStatusRequest{
+			Status: user.StatusPending,
+		})
+
+		assert.ErrorIs(t, err, user.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, existing)
+	})
+}
+
+func TestUserService_EnsureUserActive(t *testing.T) {
+	mockRepo := new(mockUserRepository)
+	mockRedis := new(mockRedisRepository)
+
+	service := svc.NewUserService(svc.UserServiceConfig{
+		UserRepo:  mockRepo,
+		RedisRepo: mockRedis,
+	})
+	ctx := context.Background()
+
+	t.Run("suspended from cache", func(t *testing.T) {
+		testID := uuid.New()
+		cached, _ := json.Marshal(user.StatusSnapshot{Status: user.StatusSuspended})
+
+		mockRedis.On("Get", mock.Anything, "user_status:"+testID.String()).Return(string(cached), nil)
+
+		err := service.EnsureUserActive(ctx, testID.String())
+
+		assert.ErrorIs(t, err, user.ErrAccountInactive)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, testID)
+	})
+
+	t.Run("expired suspension is lifted", func(t *testing.T) {
+		testID := uuid.New()
+		past := time.Now().Add(-time.Hour)
+		cached, _ := json.Marshal(user.StatusSnapshot{Status: user.StatusSuspended, SuspendedUntil: &past})
+
+		mockRedis.On("Get", mock.Anything, "user_status:"+testID.String()).Return(string(cached), nil)
+
+		assert.NoError(t, service.EnsureUserActive(ctx, testID.String()))
+	})
+
+	t.Run("cache miss loads from database", func(t *testing.T) {
+		testID := uuid.New()
+
+		mockRedis.On("Get", mock.Anything, "user_status:"+testID.String()).Return("", redis.Nil)
+		mockRepo.On("GetByID", mock.Anything, testID).Return(&user.User{ID: testID, Status: user.StatusLocked}, nil)
+		mockRedis.On("Set", mock.Anything, "user_status:"+testID.String(), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
+
+		err := service.EnsureUserActive(ctx, testID.String())
+
+		assert.ErrorIs(t, err, user.ErrAccountInactive)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_EnsureUserRole(t *testing.T) {
+	mockRepo := new(mockUserRepository)
+	mockRedis := new(mockRedisRepository)
+
+	service := svc.NewUserService(svc.UserServiceConfig{
+		UserRepo:  mockRepo,
+		RedisRepo: mockRedis,
+	})
+	ctx := context.Background()
+
+	t.Run("admin from cache", func(t *testing.T) {
+		testID := uuid.New()
+		cached, _ := json.Marshal(user.StatusSnapshot{Status: user.StatusActive, Role: user.RoleAdmin})
+
+		mockRedis.On("Get", mock.Anything, "user_status:"+testID.String()).Return(string(cached), nil)
+
+		assert.NoError(t, service.EnsureUserRole(ctx, testID.String(), user.RoleAdmin))
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, testID)
+	})
+
+	t.Run("user without the role", func(t *testing.T) {
+		testID := uuid.New()
+
+		mockRedis.On("Get", mock.Anything, "user_status:"+testID.String()).Return("", redis.Nil)
+		mockRepo.On("GetByID", mock.Anything, testID).Return(&user.User{ID: testID, Status: user.StatusActive}, nil)
+		mockRedis.On("Set", mock.Anything, "user_status:"+testID.String(), mock.AnythingOfType("string"), mock.AnythingOfType("time.Duration")).Return(nil)
+
+		err := service.EnsureUserRole(ctx, testID.String(), user.RoleAdmin)
+
+		assert.ErrorIs(t, err, user.ErrInsufficientRole)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		testID := uuid.New()
+
+		mockRedis.On("Get", mock.Anything, "user_status:"+testID.String()).Return("", redis.Nil)
+		mockRepo.On("GetByID", mock.Anything, testID).Return(nil, sql.ErrNoRows)
+
+		err := service.EnsureUserRole(ctx, testID.String(), user.RoleAdmin)
+
+		assert.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,247 @@
+package userbulk
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/user"
+)
+
+// Format is a bulk file format
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// ErrUnsupportedFormat is returned for formats other than CSV and NDJSON
+var ErrUnsupportedFormat = errors.New("unsupported format, expected csv or ndjson")
+
+// ParseFormat parses a format name or file extension
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(s, ".")) {
+	case "csv":
+		return FormatCSV, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// ImportRecord is a single user row read from an import file
+type ImportRecord struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Status   string `json:"status"`
+}
+
+// ExportRecord is a single user row written to an export file
+type ExportRecord struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// exportColumns is the CSV header of export files
+var exportColumns = []string{"id", "email", "name", "status", "created_at", "updated_at"}
+
+// recordReader yields import records one by one. A parse error affects only its row.
+type recordReader interface {
+	// Next returns the next record and its 1-based row number, or io.EOF
+	Next() (ImportRecord, int, error)
+}
+
+func newRecordReader(r io.Reader, format Format) (recordReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return &ndjsonReader{scanner: newLineScanner(r)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, errors.New("csv header must contain an email column")
+	}
+
+	return &csvReader{reader: reader, columns: columns, row: 1}, nil
+}
+
+func (r *csvReader) Next() (ImportRecord, int, error) {
+	fields, err := r.reader.Read()
+	r.row++
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return ImportRecord{}, r.row, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return ImportRecord{}, r.row, fmt.Errorf("malformed csv row: %v", parseErr.Err)
+		}
+		return ImportRecord{}, r.row, err
+	}
+
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+
+	return ImportRecord{
+		Email:    field("email"),
+		Name:     field("name"),
+		Password: field("password"),
+		Status:   field("status"),
+	}, r.row, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (r *ndjsonReader) Next() (ImportRecord, int, error) {
+	for r.scanner.Scan() {
+		r.row++
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var rec ImportRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return ImportRecord{}, r.row, fmt.Errorf("malformed json: %v", err)
+		}
+		rec.Email = strings.TrimSpace(rec.Email)
+		rec.Name = strings.TrimSpace(rec.Name)
+		rec.Status = strings.TrimSpace(rec.Status)
+		return rec, r.row, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return ImportRecord{}, r.row, err
+	}
+	return ImportRecord{}, r.row, io.EOF
+}
+
+// newLineScanner returns a scanner that accepts lines up to 1MB
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return scanner
+}
+
+// recordWriter writes export records in a given format
+type recordWriter interface {
+	Write(rec ExportRecord) error
+	Flush() error
+}
+
+func newRecordWriter(w io.Writer, format Format) (recordWriter, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportColumns); err != nil {
+			return nil, err
+		}
+		return &csvWriter{writer: writer}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(rec ExportRecord) error {
+	return w.writer.Write([]string{
+		rec.ID,
+		rec.Email,
+		rec.Name,
+		rec.Status,
+		rec.CreatedAt.UTC().Format(time.RFC3339),
+		rec.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(rec ExportRecord) error {
+	return w.encoder.Encode(rec)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// toExportRecord maps a user to an export row
+func toExportRecord(u *user.User) ExportRecord {
+	status := u.Status
+	if status == "" {
+		status = user.StatusActive
+	}
+	return ExportRecord{
+		ID:        u.ID.String(),
+		Email:     u.Email,
+		Name:      u.Name,
+		Status:    string(status),
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
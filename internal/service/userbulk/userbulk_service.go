@@ -0,0 +1,325 @@
+package userbulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"base-code-go-gin-clean/internal/domain/user"
+	"base-code-go-gin-clean/internal/pkg/redis"
+	"base-code-go-gin-clean/internal/pkg/telemetry"
+
+	"github.com/google/uuid"
+)
+
+// UserBulkService imports and exports users in bulk
+type UserBulkService interface {
+	// Import validates every row of r and upserts the valid ones by email.
+	// Row problems are collected in the report; only unreadable input returns an error.
+	Import(ctx context.Context, r io.Reader, format Format, opts ImportOptions) (*ImportReport, error)
+
+	// Export streams the users matching filter to w and returns how many were written
+	Export(ctx context.Context, w io.Writer, format Format, filter user.ExportFilter) (int, error)
+}
+
+// ImportOptions controls an import run
+type ImportOptions struct {
+	DryRun bool // Validate and report without writing to the database
+}
+
+// ImportReport summarises an import run
+type ImportReport struct {
+	DryRun    bool       `json:"dry_run"`
+	TotalRows int        `json:"total_rows"`
+	Created   int        `json:"created"`
+	Updated   int        `json:"updated"`
+	Failed    int        `json:"failed"`
+	Errors    []RowError `json:"errors"`
+}
+
+// RowError describes why a row was rejected
+type RowError struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email,omitempty"`
+	Errors []string `json:"errors"`
+}
+
+// Cache key prefixes shared with the user service
+const (
+	userCacheKeyPrefix       = "user:"
+	userStatusCacheKeyPrefix = "user_status:"
+	refreshTokenKeyPrefix    = "refresh_token:"
+)
+
+const (
+	defaultBatchSize  = 500
+	maxNameLength     = 100
+	maxEmailLength    = 100
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything longer
+)
+
+type userBulkService struct {
+	repo      user.BulkRepository
+	redisRepo redis.Repository
+	batchSize int
+	now       func() time.Time
+}
+
+// UserBulkServiceConfig holds the dependencies of the bulk service
+type UserBulkServiceConfig struct {
+	Repo      user.BulkRepository
+	RedisRepo redis.Repository // optional, used to invalidate cached users after an import
+	BatchSize int
+}
+
+// NewUserBulkService creates a new bulk import/export service
+func NewUserBulkService(cfg UserBulkServiceConfig) UserBulkService {
+	svc := &userBulkService{
+		repo:      cfg.Repo,
+		redisRepo: cfg.RedisRepo,
+		batchSize: defaultBatchSize,
+		now:       time.Now,
+	}
+
+	if cfg.BatchSize > 0 {
+		svc.batchSize = cfg.BatchSize
+	}
+
+	return svc
+}
+
+// pendingRow is a validated row waiting to be written
+type pendingRow struct {
+	row  int
+	user *user.User
+}
+
+// Import validates and upserts users in batches
+func (s *userBulkService) Import(ctx context.Context, r io.Reader, format Format, opts ImportOptions) (*ImportReport, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	reader, err := newRecordReader(r, format)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []RowError{}}
+	seen := make(map[string]int)
+	batch := make([]pendingRow, 0, s.batchSize)
+
+	for {
+		rec, row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		report.TotalRows++
+		if err != nil {
+			report.addError(row, "", err.Error())
+			continue
+		}
+
+		u, problems := s.buildUser(rec, opts.DryRun)
+		if firstRow, ok := seen[u.Email]; ok && u.Email != "" {
+			problems = append(problems, fmt.Sprintf("duplicate email, first seen on row %d", firstRow))
+		}
+		if len(problems) > 0 {
+			report.addError(row, rec.Email, problems...)
+			continue
+		}
+		seen[u.Email] = row
+
+		batch = append(batch, pendingRow{row: row, user: u})
+		if len(batch) >= s.batchSize {
+			if err := s.flush(ctx, batch, report, opts.DryRun); err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := s.flush(ctx, batch, report, opts.DryRun); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// flush writes a batch and updates the report. Only context cancellation aborts the import;
+// database errors fail the rows of the affected batch.
+func (s *userBulkService) flush(ctx context.Context, batch []pendingRow, report *ImportReport, dryRun bool) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	emails := make([]string, len(batch))
+	for i, p := range batch {
+		emails[i] = p.user.Email
+	}
+
+	existing, err := s.repo.ExistingUsers(ctx, emails)
+	if err != nil {
+		s.failBatch(batch, report, err)
+		return nil
+	}
+
+	// Deleted users keep their email, but an import does not bring them back
+	writable := make([]pendingRow, 0, len(batch))
+	for _, p := range batch {
+		if e := existing[p.user.Email]; e != nil && !e.DeletedAt.IsZero() {
+			report.addError(p.row, p.user.Email, "user was deleted")
+			continue
+		}
+		writable = append(writable, p)
+	}
+
+	if !dryRun {
+		users := make([]*user.User, len(writable))
+		for i, p := range writable {
+			users[i] = p.user
+		}
+		if err := s.repo.UpsertBatch(ctx, users); err != nil {
+			s.failBatch(writable, report, err)
+			return nil
+		}
+	}
+
+	for _, p := range writable {
+		e := existing[p.user.Email]
+		if e == nil {
+			report.Created++
+			continue
+		}
+		report.Updated++
+		if !dryRun {
+			s.invalidateCache(ctx, e.ID, p.user.Status)
+		}
+	}
+
+	return nil
+}
+
+// failBatch records a database error against every row of a batch
+func (s *userBulkService) failBatch(batch []pendingRow, report *ImportReport, err error) {
+	for _, p := range batch {
+		report.addError(p.row, p.user.Email, "failed to save: "+err.Error())
+	}
+}
+
+// invalidateCache drops cached copies of an updated user, and signs them out when the
+// import gave them a status that may not authenticate
+func (s *userBulkService) invalidateCache(ctx context.Context, userID uuid.UUID, status user.Status) {
+	if s.redisRepo == nil || userID == uuid.Nil {
+		return
+	}
+	id := userID.String()
+	_ = s.redisRepo.Delete(ctx, userCacheKeyPrefix+id)
+	_ = s.redisRepo.Delete(ctx, userStatusCacheKeyPrefix+id)
+	if status != "" && status != user.StatusActive {
+		_ = s.redisRepo.Delete(ctx, refreshTokenKeyPrefix+id)
+	}
+}
+
+// buildUser validates a record and converts it to a user.
+// Passwords are not hashed during a dry run since hashing dominates the import time.
+func (s *userBulkService) buildUser(rec ImportRecord, dryRun bool) (*user.User, []string) {
+	var problems []string
+	email := strings.ToLower(rec.Email)
+
+	switch {
+	case email == "":
+		problems = append(problems, "email is required")
+	case len(email) > maxEmailLength:
+		problems = append(problems, fmt.Sprintf("email must be at most %d characters", maxEmailLength))
+	default:
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			problems = append(problems, "email is invalid")
+		}
+	}
+
+	if rec.Name == "" {
+		problems = append(problems, "name is required")
+	} else if utf8.RuneCountInString(rec.Name) > maxNameLength {
+		problems = append(problems, fmt.Sprintf("name must be at most %d characters", maxNameLength))
+	}
+
+	if rec.Password != "" && (len(rec.Password) < minPasswordLength || len(rec.Password) > maxPasswordLength) {
+		problems = append(problems, fmt.Sprintf("password must be between %d and %d characters", minPasswordLength, maxPasswordLength))
+	}
+
+	now := s.now()
+	u := &user.User{
+		Name:      rec.Name,
+		Email:     email,
+		Password:  user.UnsetPassword,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// Without a status, new users are active and existing users keep theirs
+	if rec.Status != "" {
+		u.Status = user.Status(strings.ToLower(rec.Status))
+		u.StatusChangedAt = now
+		if !u.Status.IsValid() {
+			problems = append(problems, fmt.Sprintf("status %q is invalid", rec.Status))
+		}
+	}
+
+	if len(problems) == 0 && rec.Password != "" && !dryRun {
+		if err := u.HashPassword(rec.Password); err != nil {
+			problems = append(problems, "failed to hash password")
+		}
+	}
+
+	return u, problems
+}
+
+// Export streams users to w
+func (s *userBulkService) Export(ctx context.Context, w io.Writer, format Format, filter user.ExportFilter) (int, error) {
+	ctx, span := telemetry.Start(ctx)
+	defer span.End()
+
+	writer, err := newRecordWriter(w, format)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	count := 0
+	err = s.repo.Stream(ctx, filter, func(u *user.User) error {
+		if err := writer.Write(toExportRecord(u)); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return count, fmt.Errorf("failed to export users: %w", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		span.RecordError(err)
+		return count, err
+	}
+
+	return count, nil
+}
+
+// addError records a rejected row
+func (r *ImportReport) addError(row int, email string, problems ...string) {
+	r.Failed++
+	r.Errors = append(r.Errors, RowError{Row: row, Email: email, Errors: problems})
+}
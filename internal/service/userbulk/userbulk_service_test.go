@@ -0,0 +1,210 @@
+package userbulk
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"base-code-go-gin-clean/internal/domain/user"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockBulkRepository struct {
+	mock.Mock
+}
+
+func (m *mockBulkRepository) ExistingUsers(ctx context.Context, emails []string) (map[string]*user.User, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*user.User), args.Error(1)
+}
+
+func (m *mockBulkRepository) UpsertBatch(ctx context.Context, users []*user.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
+func (m *mockBulkRepository) Stream(ctx context.Context, filter user.ExportFilter, fn func(*user.User) error) error {
+	args := m.Called(ctx, filter, fn)
+	if users, ok := args.Get(0).([]*user.User); ok {
+		for _, u := range users {
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func TestUserBulkService_Import(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("csv with row errors", func(t *testing.T) {
+		repo := new(mockBulkRepository)
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: repo, BatchSize: 2})
+
+		input := strings.Join([]string{
+			"email,name,status",
+			"alice@example.com,Alice,active",
+			"not-an-email,Bob,active",
+			"carol@example.com,Carol,suspended",
+			"ALICE@example.com,Alice Again,",
+			"dave@example.com,,bogus",
+		}, "\n")
+
+		repo.On("ExistingUsers", mock.Anything, []string{"alice@example.com", "carol@example.com"}).
+			Return(map[string]*user.User{"carol@example.com": {ID: uuid.New(), Email: "carol@example.com"}}, nil)
+		repo.On("UpsertBatch", mock.Anything, mock.MatchedBy(func(users []*user.User) bool {
+			return len(users) == 2 && users[0].Password == user.UnsetPassword && users[1].Status == user.StatusSuspended
+		})).Return(nil)
+
+		report, err := svc.Import(ctx, strings.NewReader(input), FormatCSV, ImportOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.TotalRows)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 3, report.Failed)
+		if assert.Len(t, report.Errors, 3) {
+			assert.Equal(t, 3, report.Errors[0].Row)
+			assert.Equal(t, []string{"email is invalid"}, report.Errors[0].Errors)
+			assert.Contains(t, report.Errors[1].Errors[0], "duplicate email, first seen on row 2")
+			assert.Equal(t, []string{"name is required", `status "bogus" is invalid`}, report.Errors[2].Errors)
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ndjson dry run does not write", func(t *testing.T) {
+		repo := new(mockBulkRepository)
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: repo})
+
+		input := `{"email":"erin@example.com","name":"Erin","password":"password123"}
+{"email": 42}
+
+{"email":"frank@example.com","name":"Frank"}`
+
+		repo.On("ExistingUsers", mock.Anything, []string{"erin@example.com", "frank@example.com"}).
+			Return(map[string]*user.User{}, nil)
+
+		report, err := svc.Import(ctx, strings.NewReader(input), FormatNDJSON, ImportOptions{DryRun: true})
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.TotalRows)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, 2, report.Errors[0].Row)
+		repo.AssertNotCalled(t, "UpsertBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("database error fails the batch", func(t *testing.T) {
+		repo := new(mockBulkRepository)
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: repo})
+
+		repo.On("ExistingUsers", mock.Anything, mock.Anything).Return(map[string]*user.User{}, nil)
+		repo.On("UpsertBatch", mock.Anything, mock.Anything).Return(assert.AnError)
+
+		report, err := svc.Import(ctx, strings.NewReader("email,name\ngina@example.com,Gina\n"), FormatCSV, ImportOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, 1, report.Failed)
+		assert.Contains(t, report.Errors[0].Errors[0], "failed to save")
+	})
+
+	t.Run("deleted users are skipped and missing statuses kept", func(t *testing.T) {
+		repo := new(mockBulkRepository)
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: repo})
+
+		input := strings.Join([]string{
+			"email,name,status",
+			"hank@example.com,Hank,",
+			"ivy@example.com,Ivy,active",
+		}, "\n")
+
+		repo.On("ExistingUsers", mock.Anything, []string{"hank@example.com", "ivy@example.com"}).
+			Return(map[string]*user.User{
+				"hank@example.com": {ID: uuid.New(), Email: "hank@example.com", Status: user.StatusSuspended},
+				"ivy@example.com":  {ID: uuid.New(), Email: "ivy@example.com", DeletedAt: time.Now()},
+			}, nil)
+		repo.On("UpsertBatch", mock.Anything, mock.MatchedBy(func(users []*user.User) bool {
+			return len(users) == 1 && users[0].Email == "hank@example.com" &&
+				users[0].Status == "" && users[0].StatusChangedAt.IsZero()
+		})).Return(nil)
+
+		report, err := svc.Import(ctx, strings.NewReader(input), FormatCSV, ImportOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 1, report.Failed)
+		if assert.Len(t, report.Errors, 1) {
+			assert.Equal(t, 3, report.Errors[0].Row)
+			assert.Equal(t, []string{"user was deleted"}, report.Errors[0].Errors)
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("csv without email column", func(t *testing.T) {
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: new(mockBulkRepository)})
+
+		report, err := svc.Import(ctx, strings.NewReader("name\nAlice\n"), FormatCSV, ImportOptions{})
+
+		assert.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
+
+func TestUserBulkService_Export(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []*user.User{
+		{ID: uuid.New(), Email: "alice@example.com", Name: "Alice", Status: user.StatusActive, CreatedAt: created, UpdatedAt: created},
+		{ID: uuid.New(), Email: "bob@example.com", Name: "Bob, Jr.", CreatedAt: created, UpdatedAt: created},
+	}
+	filter := user.ExportFilter{EmailContains: "example"}
+
+	t.Run("csv", func(t *testing.T) {
+		repo := new(mockBulkRepository)
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: repo})
+		repo.On("Stream", mock.Anything, filter, mock.Anything).Return(users, nil)
+
+		var buf bytes.Buffer
+		count, err := svc.Export(ctx, &buf, FormatCSV, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Equal(t, "id,email,name,status,created_at,updated_at", lines[0])
+		assert.Contains(t, lines[2], `"Bob, Jr.",active,2025-01-02T03:04:05Z`)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		repo := new(mockBulkRepository)
+		svc := NewUserBulkService(UserBulkServiceConfig{Repo: repo})
+		repo.On("Stream", mock.Anything, filter, mock.Anything).Return(users, nil)
+
+		var buf bytes.Buffer
+		count, err := svc.Export(ctx, &buf, FormatNDJSON, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+		assert.Equal(t, 2, strings.Count(buf.String(), "\n"))
+		assert.Contains(t, buf.String(), `"email":"alice@example.com"`)
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat(".jsonl")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatNDJSON, f)
+
+	_, err = ParseFormat("xlsx")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
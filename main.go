@@ -11,7 +11,6 @@ import (
 	"base-code-go-gin-clean/internal/config"
 	"base-code-go-gin-clean/internal/pkg/telemetry"
 	cronsvc "base-code-go-gin-clean/internal/service/cron"
-	email "base-code-go-gin-clean/internal/service/email"
 	"base-code-go-gin-clean/pkg/logger"
 	"base-code-go-gin-clean/wire"
 
@@ -77,10 +76,52 @@ func main() {
 	cronSvc := cronsvc.NewCronService()
 
 	// Initialize daily report service
-	dailyReportSvc := cronsvc.NewDailyReportService(email.NewEmailService(cfg))
+	dailyReportSvc, err := wire.InitializeDailyReportService()
+	if err != nil {
+		log.Error("Failed to initialize daily report service", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize the worker that delivers queued emails
+	emailWorker, emailCleanup, err := wire.InitializeEmailWorker()
+	if err != nil {
+		log.Error("Failed to initialize email worker", "error", err)
+		os.Exit(1)
+	}
+	defer emailCleanup()
+
+	// Initialize the worker that fans scheduled email batches out into the outbox
+	batchWorker, err := wire.InitializeEmailBatchWorker()
+	if err != nil {
+		log.Error("Failed to initialize email batch worker", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize privacy service for scheduled account deletions
+	privacySvc, err := wire.InitializePrivacyService()
+	if err != nil {
+		log.Error("Failed to initialize privacy service", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize the HTTP log retention job; it also runs once at startup so that
+	// the partitions of the current period exist before logs are written
+	httpLogRetention, err := wire.InitializeHTTPLogRetention()
+	if err != nil {
+		log.Error("Failed to initialize HTTP log retention", "error", err)
+		os.Exit(1)
+	}
+	go httpLogRetention.EnforceRetention()
+
+	// Initialize the HTTP log archive, nil unless HTTPLOG_ARCHIVE_DRIVER is set
+	httpLogArchive, err := wire.InitializeHTTPLogArchive()
+	if err != nil {
+		log.Error("Failed to initialize HTTP log archive", "error", err)
+		os.Exit(1)
+	}
 
 	// Register all cron jobs from the registry
-	for _, job := range cronsvc.GetCronJobs(dailyReportSvc) {
+	for _, job := range cronsvc.GetCronJobs(dailyReportSvc, privacySvc, httpLogRetention, httpLogArchive) {
 		_, err := cronSvc.AddJob(job.Spec, job.Handler)
 		if err != nil {
 			log.Error("Failed to schedule cron job", "spec", job.Spec, "error", err)
@@ -97,11 +138,29 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Deliver queued emails until shutdown; in-flight deliveries finish before exit
+	emailWorkerDone := make(chan struct{})
+	go func() {
+		defer close(emailWorkerDone)
+		emailWorker.Run(ctx)
+	}()
+	log.Info("Email outbox worker started")
+
+	// Dispatch due batches until shutdown; an interrupted batch is resumed on the next start
+	batchWorkerDone := make(chan struct{})
+	go func() {
+		defer close(batchWorkerDone)
+		batchWorker.Run(ctx)
+	}()
+	log.Info("Email batch worker started")
+
 	log.Info("Starting server", "port", cfg.Server.Port)
 	if err := srv.Start(ctx); err != nil {
 		log.Error("Server shutdown with error", "error", err)
 		os.Exit(1)
 	}
+	<-emailWorkerDone
+	<-batchWorkerDone
 
 	log.Info("Server exited gracefully")
 }
@@ -1,24 +1,59 @@
 package logger
 
 import (
+	"context"
 	"log/slog"
 	"os"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
-// New creates a new logger instance
+// New creates a new logger instance. Records logged with a context in a trace,
+// e.g. with InfoContext, carry its trace_id and span_id.
 func New(env string) *slog.Logger {
-	var log *slog.Logger
+	var handler slog.Handler
 
 	switch env {
 	case "production":
-		log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelInfo,
-		}))
+		})
 	default:
-		log = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelDebug,
-		}))
+		})
+	}
+
+	return slog.New(NewTraceHandler(handler))
+}
+
+// TraceHandler is a slog.Handler adding the trace and span IDs of the record's context
+type TraceHandler struct {
+	slog.Handler
+}
+
+// NewTraceHandler wraps handler in a TraceHandler
+func NewTraceHandler(handler slog.Handler) *TraceHandler {
+	return &TraceHandler{Handler: handler}
+}
+
+// Handle adds the trace_id and span_id attributes to records logged in a trace
+func (h *TraceHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		record.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+		if sc.HasSpanID() {
+			record.AddAttrs(slog.String("span_id", sc.SpanID().String()))
+		}
 	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a TraceHandler whose wrapped handler has the attributes
+func (h *TraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &TraceHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
 
-	return log
+// WithGroup returns a TraceHandler whose wrapped handler has the group
+func (h *TraceHandler) WithGroup(name string) slog.Handler {
+	return &TraceHandler{Handler: h.Handler.WithGroup(name)}
 }
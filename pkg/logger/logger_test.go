@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceHandler(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewTraceHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+	log.InfoContext(trace.ContextWithSpanContext(context.Background(), sc), "in a trace")
+	log.InfoContext(context.Background(), "outside of a trace")
+
+	var records []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		if !assert.NoError(t, json.Unmarshal(line, &record)) {
+			return
+		}
+		records = append(records, record)
+	}
+	if !assert.Len(t, records, 2) {
+		return
+	}
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", records[0]["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", records[0]["span_id"])
+	assert.Equal(t, "test", records[0]["component"])
+	assert.NotContains(t, records[1], "trace_id")
+}
@@ -1,36 +1,168 @@
 package integration
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
-	"base-code-go-gin-clean/internal/config"
+	domain "base-code-go-gin-clean/internal/domain/email"
 	"base-code-go-gin-clean/internal/handler/email"
 	emailService "base-code-go-gin-clean/internal/service/email"
 	"base-code-go-gin-clean/test"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 )
 
+// memoryOutboxRepository is an in-memory domain.OutboxRepository
+type memoryOutboxRepository struct {
+	mu   sync.Mutex
+	msgs map[uuid.UUID]*domain.OutboxMessage
+}
+
+func (r *memoryOutboxRepository) Create(ctx context.Context, msg *domain.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.ID = uuid.New()
+	msg.CreatedAt = time.Now()
+	r.msgs[msg.ID] = msg
+	return nil
+}
+
+func (r *memoryOutboxRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg, ok := r.msgs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return msg, nil
+}
+
+func (r *memoryOutboxRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*domain.OutboxMessage
+	for _, msg := range r.msgs {
+		if len(claimed) == limit {
+			break
+		}
+		if (msg.Status == domain.MessageQueued || msg.Status == domain.MessageSending) && !msg.NextAttemptAt.After(now) {
+			msg.Status = domain.MessageSending
+			msg.Attempts++
+			msg.NextAttemptAt = leaseUntil
+			claimed = append(claimed, msg)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *memoryOutboxRepository) Update(ctx context.Context, msg *domain.OutboxMessage) error {
+	return nil
+}
+
+// recordingSender captures delivered emails
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []*domain.Email
+}
+
+func (s *recordingSender) SendEmail(ctx context.Context, e *domain.Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, e)
+	return nil
+}
+
 func TestEmailAPI(t *testing.T) {
 	// Initialize services
-	cfg := config.Config{}
-	emailSvc := emailService.NewEmailService(&cfg)
+	repo := &memoryOutboxRepository{msgs: map[uuid.UUID]*domain.OutboxMessage{}}
+	outbox := emailService.NewOutboxService(emailService.OutboxServiceConfig{Repo: repo})
+	sender := &recordingSender{}
+	worker := emailService.NewOutboxWorker(emailService.OutboxWorkerConfig{Repo: repo, Sender: sender})
 
 	// Initialize handlers
-	emailHandler := email.NewEmailHandler(emailSvc)
+	templates := emailService.NewTemplateService(emailService.TemplateServiceConfig{Outbox: outbox})
+	emailHandler := email.NewEmailHandler(outbox, templates)
 
 	// Setup router
 	router := test.SetupTestRouter()
 	router.POST("/api/email", emailHandler.SendEmail)
+	router.POST("/api/email/send-template", emailHandler.SendTemplate)
+	router.GET("/api/email/messages/:id", emailHandler.GetMessage)
 
 	// Test valid request
-	t.Run("successful email send", func(t *testing.T) {
+	t.Run("email is queued and delivered", func(t *testing.T) {
 		emailData := map[string]interface{}{
 			"to":      []string{"test@example.com"},
 			"subject": "Test",
 			"body":    "Test body",
 		}
 		body := test.MakeJSONBody(t, emailData)
-		test.MakeTestRequestWithBody(router, "POST", "/api/email", body)
+		resp := test.MakeTestRequestWithBody(router, "POST", "/api/email", body).Response
+		test.AssertJSONResponse(t, resp, http.StatusAccepted)
+
+		var queued struct {
+			Data domain.OutboxMessage `json:"data"`
+		}
+		if !assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &queued)) {
+			return
+		}
+		assert.Equal(t, domain.MessageQueued, queued.Data.Status)
+
+		assert.Equal(t, 1, worker.ProcessDue(context.Background()))
+		assert.Len(t, sender.sent, 1)
+
+		resp = test.MakeTestRequest(router, "GET", "/api/email/messages/"+queued.Data.ID.String())
+		test.AssertJSONResponse(t, resp, http.StatusOK)
+
+		var status struct {
+			Data domain.OutboxMessage `json:"data"`
+		}
+		if !assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status)) {
+			return
+		}
+		assert.Equal(t, domain.MessageSent, status.Data.Status)
+		assert.Equal(t, 1, status.Data.Attempts)
+	})
+
+	t.Run("template is rendered and queued", func(t *testing.T) {
+		body := test.MakeJSONBody(t, map[string]interface{}{
+			"template": "password_reset",
+			"to":       "jane@example.com",
+			"data":     map[string]string{"name": "Jane", "reset_url": "https://example.com/reset/abc"},
+		})
+		resp := test.MakeTestRequestWithBody(router, "POST", "/api/email/send-template", body).Response
+		test.AssertJSONResponse(t, resp, http.StatusAccepted)
+
+		var queued struct {
+			Data domain.OutboxMessage `json:"data"`
+		}
+		if !assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &queued)) {
+			return
+		}
+		msg := repo.msgs[queued.Data.ID]
+		if !assert.NotNil(t, msg) {
+			return
+		}
+		assert.Equal(t, []string{"jane@example.com"}, msg.Payload.To)
+		assert.Equal(t, "Password Reset Request", msg.Payload.Subject)
+		assert.Contains(t, msg.Payload.Body, "https://example.com/reset/abc")
+		assert.Contains(t, msg.Payload.Text, "Reset Password: https://example.com/reset/abc")
+	})
+
+	t.Run("template data is validated", func(t *testing.T) {
+		body := test.MakeJSONBody(t, map[string]interface{}{
+			"template": "password_reset",
+			"to":       "jane@example.com",
+			"data":     map[string]string{"name": "Jane", "reset_url": "javascript:alert(1)"},
+		})
+		resp := test.MakeTestRequestWithBody(router, "POST", "/api/email/send-template", body).Response
+		test.AssertJSONResponse(t, resp, http.StatusUnprocessableEntity)
 	})
 
 	// Test invalid request
@@ -38,4 +170,9 @@ func TestEmailAPI(t *testing.T) {
 		resp := test.MakeTestRequest(router, "POST", "/api/email")
 		test.AssertJSONResponse(t, resp, http.StatusBadRequest)
 	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		resp := test.MakeTestRequest(router, "GET", "/api/email/messages/"+uuid.NewString())
+		test.AssertJSONResponse(t, resp, http.StatusNotFound)
+	})
 }
@@ -29,6 +29,26 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.U
 	return args.Get(0).(*user.User), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdateStatus(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateAvatar(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateRole(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Anonymize(ctx context.Context, user *user.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
 type MockTokenService struct {
 	mock.Mock
 }
@@ -67,6 +87,11 @@ func (m *MockRedisRepository) Delete(ctx context.Context, key string) error {
 	return args.Error(0)
 }
 
+func (m *MockRedisRepository) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	args := m.Called(ctx, key, value, expiration)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockRedisRepository) Exists(ctx context.Context, key string) (bool, error) {
 	args := m.Called(ctx, key)
 	return args.Bool(0), args.Error(1)
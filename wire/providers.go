@@ -1,13 +1,28 @@
 package wire
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"base-code-go-gin-clean/internal/config"
+	"base-code-go-gin-clean/internal/domain/httplog"
+	"base-code-go-gin-clean/internal/domain/preference"
+	privacyDomain "base-code-go-gin-clean/internal/domain/privacy"
 	"base-code-go-gin-clean/internal/domain/user"
 	emailDomain "base-code-go-gin-clean/internal/domain/email"
+	"base-code-go-gin-clean/internal/handler"
 	emailHandler "base-code-go-gin-clean/internal/handler/email"
+	"base-code-go-gin-clean/internal/middleware"
+	"base-code-go-gin-clean/internal/pkg/mailer"
 	"base-code-go-gin-clean/internal/pkg/redis"
+	"base-code-go-gin-clean/internal/pkg/storage"
 	"base-code-go-gin-clean/internal/service"
+	avatarService "base-code-go-gin-clean/internal/service/avatar"
 	emailService "base-code-go-gin-clean/internal/service/email"
+	preferenceService "base-code-go-gin-clean/internal/service/preference"
+	privacyService "base-code-go-gin-clean/internal/service/privacy"
+	userbulkService "base-code-go-gin-clean/internal/service/userbulk"
 	"base-code-go-gin-clean/internal/pkg/token"
 )
 
@@ -25,14 +40,143 @@ func ProvideTokenService(cfg *config.Config) (token.TokenService, error) {
 	return tokenService, nil
 }
 
-// ProvideEmailService creates a new email service
-func ProvideEmailService(cfg *config.Config) emailDomain.EmailService {
-	return emailService.NewEmailService(cfg)
+// ProvideSuppressionService creates the suppression list service that signs unsubscribe links
+func ProvideSuppressionService(cfg *config.Config, suppressionRepo emailDomain.SuppressionRepository) emailService.SuppressionService {
+	return emailService.NewSuppressionService(emailService.SuppressionServiceConfig{
+		Repo:       suppressionRepo,
+		SigningKey: cfg.Email.UnsubscribeKey,
+		BaseURL:    cfg.Server.BaseURL,
+	})
+}
+
+// ProvideMessageLogService creates the delivery log service that signs open and click tracking links
+func ProvideMessageLogService(cfg *config.Config, messageLogRepo emailDomain.MessageLogRepository) emailService.MessageLogService {
+	return emailService.NewMessageLogService(emailService.MessageLogServiceConfig{
+		Repo:        messageLogRepo,
+		SigningKey:  cfg.Email.TrackingKey,
+		BaseURL:     cfg.Server.BaseURL,
+		TrackOpens:  cfg.Email.TrackOpens,
+		TrackClicks: cfg.Email.TrackClicks,
+	})
+}
+
+// ProvideOutboxService creates the email outbox that queues messages for the outbox worker
+func ProvideOutboxService(
+	cfg *config.Config,
+	outboxRepo emailDomain.OutboxRepository,
+	suppressions emailService.SuppressionService,
+	messages emailService.MessageLogService,
+) emailService.OutboxService {
+	return emailService.NewOutboxService(emailService.OutboxServiceConfig{
+		Repo:         outboxRepo,
+		Suppressions: suppressions,
+		Log:          messages,
+		MaxAttempts:  cfg.Email.OutboxMaxAttempts,
+	})
+}
+
+// ProvideEmailService exposes the outbox as the email service, so application
+// code queues mail instead of waiting on SMTP
+func ProvideEmailService(outbox emailService.OutboxService) emailDomain.EmailService {
+	return outbox
+}
+
+// ProvideMailTransport creates the mail transport selected by EMAIL_TRANSPORT
+func ProvideMailTransport(cfg *config.Config) (mailer.Transport, func(), error) {
+	var transport mailer.Transport
+	var err error
+	switch cfg.Email.Transport {
+	case "smtp":
+		transport, err = mailer.NewSMTPTransport(mailer.SMTPConfig{
+			Host:     cfg.Email.SMTPServer,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUsername,
+			Password: cfg.Email.SMTPPassword,
+			Security: cfg.Email.SMTPSecurity,
+			Auth:     cfg.Email.SMTPAuth,
+			PoolSize: cfg.Email.SMTPPoolSize,
+		})
+	case "file":
+		transport, err = mailer.NewFileTransport(cfg.Email.FileDir)
+	case "log":
+		transport = mailer.NewLogTransport(nil)
+	case "memory":
+		transport = mailer.NewMemoryTransport()
+	default:
+		err = fmt.Errorf("unknown email transport %q", cfg.Email.Transport)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transport, func() {
+		transport.Close()
+	}, nil
+}
+
+// ProvideDKIMSigner loads the DKIM signing keys; it returns nil when DKIM is not configured
+func ProvideDKIMSigner(cfg *config.Config) (*emailService.DKIMSigner, error) {
+	return emailService.NewDKIMSigner(cfg.Email.DKIM)
+}
+
+// ProvideOutboxWorker creates the background worker that delivers queued mail through the transport
+func ProvideOutboxWorker(
+	cfg *config.Config,
+	outboxRepo emailDomain.OutboxRepository,
+	transport mailer.Transport,
+	suppressions emailService.SuppressionService,
+	messages emailService.MessageLogService,
+	dkim *emailService.DKIMSigner,
+) *emailService.OutboxWorker {
+	return emailService.NewOutboxWorker(emailService.OutboxWorkerConfig{
+		Repo:         outboxRepo,
+		Sender:       emailService.NewEmailService(cfg, transport, suppressions, dkim),
+		Log:          messages,
+		Concurrency:  cfg.Email.OutboxWorkers,
+		PollInterval: time.Duration(cfg.Email.OutboxPollSeconds) * time.Second,
+		BaseBackoff:  time.Duration(cfg.Email.OutboxBackoffSeconds) * time.Second,
+	})
+}
+
+// ProvideTemplateService creates the service that renders catalog templates, or their
+// published database versions, into the outbox in each recipient's preferred locale
+func ProvideTemplateService(
+	outbox emailService.OutboxService,
+	templateRepo emailDomain.TemplateRepository,
+	userRepo user.UserRepository,
+	preferences preferenceService.PreferenceService,
+) emailService.TemplateService {
+	return emailService.NewTemplateService(emailService.TemplateServiceConfig{
+		Outbox:      outbox,
+		Repo:        templateRepo,
+		Users:       userRepo,
+		Preferences: preferences,
+	})
+}
+
+// ProvideBatchWorker creates the background worker that fans scheduled batches out
+// into the outbox, spreading mail to each recipient domain over time
+func ProvideBatchWorker(
+	cfg *config.Config,
+	batchRepo emailDomain.BatchRepository,
+	templates emailService.TemplateService,
+) *emailService.BatchWorker {
+	return emailService.NewBatchWorker(emailService.BatchWorkerConfig{
+		Repo:         batchRepo,
+		Templates:    templates,
+		DomainRate:   cfg.Email.BatchDomainRate,
+		PollInterval: time.Duration(cfg.Email.OutboxPollSeconds) * time.Second,
+	})
+}
+
+// ProvideEmailSuppressionHandler creates the unsubscribe, bounce webhook and suppression list handler
+func ProvideEmailSuppressionHandler(cfg *config.Config, suppressions emailService.SuppressionService) *handler.EmailSuppressionHandler {
+	return handler.NewEmailSuppressionHandler(suppressions, cfg.Email.WebhookSecret)
 }
 
 // ProvideEmailHandler creates a new email handler
-func ProvideEmailHandler(emailSvc emailDomain.EmailService) *emailHandler.EmailHandler {
-	return emailHandler.NewEmailHandler(emailSvc)
+func ProvideEmailHandler(outbox emailService.OutboxService, templates emailService.TemplateService) *emailHandler.EmailHandler {
+	return emailHandler.NewEmailHandler(outbox, templates)
 }
 
 // ProvideRedisClient creates a new Redis client
@@ -45,14 +189,176 @@ func ProvideRedisRepository(client *redis.Client) redis.Repository {
 	return redis.NewRepository(client)
 }
 
+// ProvideBlobStore creates the blob store selected by STORAGE_DRIVER
+func ProvideBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	switch cfg.Storage.Driver {
+	case "s3":
+		return storage.NewS3Store(context.Background(), storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			UseSSL:    cfg.Storage.S3UseSSL,
+		})
+	case "local":
+		return storage.NewLocalStore(cfg.Storage.LocalDir, cfg.Server.BaseURL+"/api/v1/files", cfg.Storage.SigningKey)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// ProvideFileHandler creates the signed file handler when blobs are stored locally
+func ProvideFileHandler(store storage.BlobStore) *handler.FileHandler {
+	localStore, ok := store.(*storage.LocalStore)
+	if !ok {
+		return nil
+	}
+	return handler.NewFileHandler(localStore)
+}
+
+// ProvideAvatarService creates the avatar upload service
+func ProvideAvatarService(
+	cfg *config.Config,
+	userRepo user.UserRepository,
+	redisRepo redis.Repository,
+	store storage.BlobStore,
+) avatarService.AvatarService {
+	return avatarService.NewAvatarService(avatarService.AvatarServiceConfig{
+		UserRepo:  userRepo,
+		RedisRepo: redisRepo,
+		Store:     store,
+		URLTTL:    time.Duration(cfg.Storage.URLTTLMinutes) * time.Minute,
+	})
+}
+
 // ProvideUserServiceConfig creates a new user service configuration
 func ProvideUserServiceConfig(
+	cfg *config.Config,
 	userRepo user.UserRepository,
 	redisRepo redis.Repository,
+	emailSvc emailDomain.EmailService,
+	preferences preferenceService.PreferenceService,
+	store storage.BlobStore,
 ) service.UserServiceConfig {
 	return service.UserServiceConfig{
-		UserRepo:  userRepo,
-		RedisRepo: redisRepo,
+		UserRepo:     userRepo,
+		RedisRepo:    redisRepo,
+		EmailService: emailSvc,
+		Preferences:  preferences,
+		AvatarStore:  store,
+		AvatarURLTTL: time.Duration(cfg.Storage.URLTTLMinutes) * time.Minute,
 		// Use default cache TTL
 	}
 }
+
+// ProvideStatusChecker exposes the user service as the account status checker for AuthMiddleware
+func ProvideStatusChecker(userSvc service.UserService) middleware.StatusChecker {
+	return userSvc
+}
+
+// ProvideRoleChecker exposes the user service as the role checker for RoleMiddleware
+func ProvideRoleChecker(userSvc service.UserService) middleware.RoleChecker {
+	return userSvc
+}
+
+// ProvidePrivacyService creates the data export and account deletion service
+func ProvidePrivacyService(
+	cfg *config.Config,
+	userRepo user.UserRepository,
+	deletionRepo privacyDomain.DeletionRequestRepository,
+	httpLogRepo httplog.Repository,
+	redisRepo redis.Repository,
+	emailSvc emailDomain.EmailService,
+	store storage.BlobStore,
+	preferenceRepo preference.Repository,
+) privacyService.PrivacyService {
+	return privacyService.NewPrivacyService(privacyService.PrivacyServiceConfig{
+		UserRepo:            userRepo,
+		DeletionRepo:        deletionRepo,
+		HTTPLogRepo:         httpLogRepo,
+		RedisRepo:           redisRepo,
+		EmailService:        emailSvc,
+		AvatarStore:         store,
+		PreferenceRepo:      preferenceRepo,
+		ExportDir:           cfg.Privacy.ExportDir,
+		ExportLinkTTL:       time.Duration(cfg.Privacy.ExportLinkTTLHours) * time.Hour,
+		DeletionGracePeriod: time.Duration(cfg.Privacy.DeletionGracePeriod) * 24 * time.Hour,
+		BaseURL:             cfg.Server.BaseURL,
+	})
+}
+
+// ProvideHTTPLogRetention creates the job that partitions and expires the HTTP logs
+func ProvideHTTPLogRetention(
+	cfg *config.Config,
+	repo httplog.Repository,
+	partitions httplog.PartitionRepository,
+	archive httplog.ArchiveService,
+) httplog.RetentionService {
+	return httplog.NewRetentionService(httplog.RetentionConfig{
+		Repo:            repo,
+		Partitions:      partitions,
+		RetentionDays:   cfg.HTTPLog.RetentionDays,
+		Interval:        httplog.PartitionInterval(cfg.HTTPLog.PartitionInterval),
+		PartitionsAhead: cfg.HTTPLog.PartitionsAhead,
+		Archive:         archive,
+	})
+}
+
+// ProvideHTTPLogArchive creates the HTTP log archive selected by HTTPLOG_ARCHIVE_DRIVER,
+// or nil when logs are not archived
+func ProvideHTTPLogArchive(cfg *config.Config, repo httplog.ArchiveRepository) (httplog.ArchiveService, error) {
+	store, err := ProvideHTTPLogArchiveStore(cfg)
+	if err != nil || store == nil {
+		return nil, err
+	}
+
+	return httplog.NewArchiveService(httplog.ArchiveConfig{
+		Repo:          repo,
+		Store:         store,
+		Prefix:        cfg.HTTPLog.ArchivePrefix,
+		Compression:   httplog.Compression(cfg.HTTPLog.ArchiveCompression),
+		Interval:      httplog.PartitionInterval(cfg.HTTPLog.PartitionInterval),
+		RetentionDays: cfg.HTTPLog.RetentionDays,
+	}), nil
+}
+
+// ProvideHTTPLogArchiveStore creates the blob store of the HTTP log archives, or nil
+// when logs are not archived
+func ProvideHTTPLogArchiveStore(cfg *config.Config) (storage.BlobStore, error) {
+	switch cfg.HTTPLog.ArchiveDriver {
+	case "":
+		return nil, nil
+	case "s3":
+		return storage.NewS3Store(context.Background(), storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.HTTPLog.ArchiveS3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			UseSSL:    cfg.Storage.S3UseSSL,
+		})
+	case "local":
+		// Archives are never served, so their URLs need no working signature
+		return storage.NewLocalStore(cfg.HTTPLog.ArchiveDir, "", cfg.Auth.AccessTokenSecret)
+	default:
+		return nil, fmt.Errorf("unknown http log archive driver %q", cfg.HTTPLog.ArchiveDriver)
+	}
+}
+
+// ProvideUserBulkService creates the bulk user import/export service
+func ProvideUserBulkService(bulkRepo user.BulkRepository, redisRepo redis.Repository) userbulkService.UserBulkService {
+	return userbulkService.NewUserBulkService(userbulkService.UserBulkServiceConfig{
+		Repo:      bulkRepo,
+		RedisRepo: redisRepo,
+	})
+}
+
+// ProvidePreferenceService creates the user preference service backed by the default key registry
+func ProvidePreferenceService(preferenceRepo preference.Repository, redisRepo redis.Repository) preferenceService.PreferenceService {
+	return preferenceService.NewPreferenceService(preferenceService.PreferenceServiceConfig{
+		Repo:      preferenceRepo,
+		RedisRepo: redisRepo,
+		Registry:  preference.DefaultRegistry(),
+	})
+}
@@ -10,14 +10,21 @@ import (
 	"time"
 
 	"base-code-go-gin-clean/internal/config"
+	"base-code-go-gin-clean/internal/domain/httplog"
 	"base-code-go-gin-clean/internal/handler"
 	"base-code-go-gin-clean/internal/handler/auth"
 	"base-code-go-gin-clean/internal/pkg/redis"
 	"base-code-go-gin-clean/internal/pkg/telemetry"
 	"base-code-go-gin-clean/internal/pkg/token"
+	emailRepo "base-code-go-gin-clean/internal/repository/email"
+	preferenceRepo "base-code-go-gin-clean/internal/repository/preference"
+	privacyRepo "base-code-go-gin-clean/internal/repository/privacy"
 	"base-code-go-gin-clean/internal/repository/user"
 	"base-code-go-gin-clean/internal/server"
 	"base-code-go-gin-clean/internal/service"
+	cronService "base-code-go-gin-clean/internal/service/cron"
+	emailService "base-code-go-gin-clean/internal/service/email"
+	privacyService "base-code-go-gin-clean/internal/service/privacy"
 	"base-code-go-gin-clean/pkg/logger"
 
 	"github.com/google/wire"
@@ -157,18 +164,52 @@ func InitializeServer() (*server.Server, func(), error) {
 
 		// Repositories
 		user.NewUserRepository,
+		user.NewUserBulkRepository,
+		preferenceRepo.NewPreferenceRepository,
+		privacyRepo.NewDeletionRequestRepository,
+		httplog.NewRepository,
+		emailRepo.NewOutboxRepository,
+		emailRepo.NewTemplateRepository,
+		emailRepo.NewSuppressionRepository,
+		emailRepo.NewMessageLogRepository,
+		emailRepo.NewBatchRepository,
+
+		// Storage
+		ProvideBlobStore,
 
 		// Services
 		ProvideUserServiceConfig,
 		service.NewUserService,
+		ProvideStatusChecker,
+		ProvideRoleChecker,
 		ProvideTokenService,
 		service.NewAuthService,
+		ProvideSuppressionService,
+		ProvideMessageLogService,
+		ProvideOutboxService,
 		ProvideEmailService,
+		ProvideTemplateService,
+		emailService.NewBatchService,
+		ProvidePrivacyService,
+		ProvideAvatarService,
+		ProvideUserBulkService,
+		ProvidePreferenceService,
+		httplog.NewService,
 
 		// Handlers
 		handler.NewUserHandler,
+		handler.NewUserBulkHandler,
 		ProvideEmailHandler,
+		handler.NewEmailTemplateHandler,
+		ProvideEmailSuppressionHandler,
+		handler.NewEmailMessageLogHandler,
+		handler.NewEmailBatchHandler,
+		handler.NewHTTPLogHandler,
 		auth.NewAuthHandler,
+		handler.NewPrivacyHandler,
+		handler.NewAvatarHandler,
+		handler.NewPreferenceHandler,
+		ProvideFileHandler,
 
 		// Server options
 		wire.Struct(new(server.ServerOptions), "*"),
@@ -179,3 +220,114 @@ func InitializeServer() (*server.Server, func(), error) {
 	)
 	return nil, nil, nil // This will be replaced by Wire
 }
+
+// InitializePrivacyService initializes the privacy service for background jobs
+func InitializePrivacyService() (privacyService.PrivacyService, error) {
+	wire.Build(
+		ProvideConfig,
+		ProvideDB,
+		ProvideBunDB,
+		RedisSet,
+		user.NewUserRepository,
+		preferenceRepo.NewPreferenceRepository,
+		privacyRepo.NewDeletionRequestRepository,
+		httplog.NewRepository,
+		emailRepo.NewOutboxRepository,
+		emailRepo.NewSuppressionRepository,
+		ProvideSuppressionService,
+		emailRepo.NewMessageLogRepository,
+		ProvideMessageLogService,
+		ProvideOutboxService,
+		ProvideEmailService,
+		ProvideBlobStore,
+		ProvidePrivacyService,
+	)
+	return nil, nil // This will be replaced by Wire
+}
+
+// InitializeHTTPLogRetention initializes the job that partitions and expires the HTTP logs
+func InitializeHTTPLogRetention() (httplog.RetentionService, error) {
+	wire.Build(
+		ProvideConfig,
+		ProvideDB,
+		ProvideBunDB,
+		httplog.NewRepository,
+		httplog.NewPartitionRepository,
+		httplog.NewArchiveRepository,
+		ProvideHTTPLogArchive,
+		ProvideHTTPLogRetention,
+	)
+	return nil, nil // This will be replaced by Wire
+}
+
+// InitializeHTTPLogArchive initializes the archive of the HTTP logs, nil when logs are not archived
+func InitializeHTTPLogArchive() (httplog.ArchiveService, error) {
+	wire.Build(
+		ProvideConfig,
+		ProvideDB,
+		ProvideBunDB,
+		httplog.NewArchiveRepository,
+		ProvideHTTPLogArchive,
+	)
+	return nil, nil // This will be replaced by Wire
+}
+
+// InitializeEmailWorker initializes the worker that delivers queued emails
+func InitializeEmailWorker() (*emailService.OutboxWorker, func(), error) {
+	wire.Build(
+		ProvideConfig,
+		ProvideDB,
+		ProvideBunDB,
+		emailRepo.NewOutboxRepository,
+		emailRepo.NewSuppressionRepository,
+		ProvideSuppressionService,
+		emailRepo.NewMessageLogRepository,
+		ProvideMessageLogService,
+		ProvideMailTransport,
+		ProvideDKIMSigner,
+		ProvideOutboxWorker,
+	)
+	return nil, nil, nil // This will be replaced by Wire
+}
+
+// InitializeEmailBatchWorker initializes the worker that fans scheduled batches out into the outbox
+func InitializeEmailBatchWorker() (*emailService.BatchWorker, error) {
+	wire.Build(
+		ProvideConfig,
+		ProvideDB,
+		ProvideBunDB,
+		RedisSet,
+		user.NewUserRepository,
+		preferenceRepo.NewPreferenceRepository,
+		ProvidePreferenceService,
+		emailRepo.NewOutboxRepository,
+		emailRepo.NewSuppressionRepository,
+		ProvideSuppressionService,
+		emailRepo.NewMessageLogRepository,
+		ProvideMessageLogService,
+		ProvideOutboxService,
+		emailRepo.NewTemplateRepository,
+		ProvideTemplateService,
+		emailRepo.NewBatchRepository,
+		ProvideBatchWorker,
+	)
+	return nil, nil // This will be replaced by Wire
+}
+
+// InitializeDailyReportService initializes the daily report job, which queues its emails in the outbox
+func InitializeDailyReportService() (*cronService.DailyReportService, error) {
+	wire.Build(
+		ProvideConfig,
+		ProvideDB,
+		ProvideBunDB,
+		emailRepo.NewOutboxRepository,
+		emailRepo.NewSuppressionRepository,
+		ProvideSuppressionService,
+		emailRepo.NewMessageLogRepository,
+		ProvideMessageLogService,
+		ProvideOutboxService,
+		ProvideEmailService,
+		cronService.NewDailyReportService,
+	)
+	return nil, nil // This will be replaced by Wire
+}
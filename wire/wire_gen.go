@@ -8,14 +8,21 @@ package wire
 
 import (
 	"base-code-go-gin-clean/internal/config"
+	"base-code-go-gin-clean/internal/domain/httplog"
 	"base-code-go-gin-clean/internal/handler"
 	"base-code-go-gin-clean/internal/handler/auth"
 	"base-code-go-gin-clean/internal/pkg/redis"
 	"base-code-go-gin-clean/internal/pkg/telemetry"
 	"base-code-go-gin-clean/internal/pkg/token"
+	email2 "base-code-go-gin-clean/internal/repository/email"
+	"base-code-go-gin-clean/internal/repository/preference"
+	privacy2 "base-code-go-gin-clean/internal/repository/privacy"
 	"base-code-go-gin-clean/internal/repository/user"
 	"base-code-go-gin-clean/internal/server"
 	"base-code-go-gin-clean/internal/service"
+	"base-code-go-gin-clean/internal/service/cron"
+	"base-code-go-gin-clean/internal/service/email"
+	"base-code-go-gin-clean/internal/service/privacy"
 	"base-code-go-gin-clean/pkg/logger"
 	"context"
 	"fmt"
@@ -48,9 +55,25 @@ func InitializeServer() (*server.Server, func(), error) {
 		return nil, nil, err
 	}
 	repository := ProvideRedisRepository(client)
-	userServiceConfig := ProvideUserServiceConfig(userRepository, repository)
+	outboxRepository := email2.NewOutboxRepository(bunDB)
+	suppressionRepository := email2.NewSuppressionRepository(bunDB)
+	suppressionService := ProvideSuppressionService(configConfig, suppressionRepository)
+	messageLogRepository := email2.NewMessageLogRepository(bunDB)
+	messageLogService := ProvideMessageLogService(configConfig, messageLogRepository)
+	outboxService := ProvideOutboxService(configConfig, outboxRepository, suppressionService, messageLogService)
+	emailService := ProvideEmailService(outboxService)
+	blobStore, err := ProvideBlobStore(configConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	preferenceRepository := preference.NewPreferenceRepository(bunDB)
+	preferenceService := ProvidePreferenceService(preferenceRepository, repository)
+	userServiceConfig := ProvideUserServiceConfig(configConfig, userRepository, repository, emailService, preferenceService, blobStore)
 	userService := service.NewUserService(userServiceConfig)
 	userHandler := handler.NewUserHandler(userService)
+	bulkRepository := user.NewUserBulkRepository(bunDB)
+	userBulkService := ProvideUserBulkService(bulkRepository, repository)
+	userBulkHandler := handler.NewUserBulkHandler(userBulkService)
 	tokenService, err := ProvideTokenService(configConfig)
 	if err != nil {
 		return nil, nil, err
@@ -58,21 +81,52 @@ func InitializeServer() (*server.Server, func(), error) {
 	serviceConfig := ProvideServiceConfig(configConfig)
 	authService := service.NewAuthService(userRepository, tokenService, repository, serviceConfig)
 	authHandler := auth.NewAuthHandler(authService)
-	emailService := ProvideEmailService(configConfig)
-	emailHandler := ProvideEmailHandler(emailService)
+	templateRepository := email2.NewTemplateRepository(bunDB)
+	templateService := ProvideTemplateService(outboxService, templateRepository, userRepository, preferenceService)
+	emailHandler := ProvideEmailHandler(outboxService, templateService)
+	emailTemplateHandler := handler.NewEmailTemplateHandler(templateService)
+	emailSuppressionHandler := ProvideEmailSuppressionHandler(configConfig, suppressionService)
+	emailMessageLogHandler := handler.NewEmailMessageLogHandler(messageLogService)
+	batchRepository := email2.NewBatchRepository(bunDB)
+	batchService := email.NewBatchService(batchRepository)
+	emailBatchHandler := handler.NewEmailBatchHandler(batchService)
+	httplogRepository := httplog.NewRepository(bunDB)
+	httplogService := httplog.NewService(httplogRepository)
+	httpLogHandler := handler.NewHTTPLogHandler(httplogService)
+	deletionRequestRepository := privacy2.NewDeletionRequestRepository(bunDB)
+	privacyService := ProvidePrivacyService(configConfig, userRepository, deletionRequestRepository, httplogRepository, repository, emailService, blobStore, preferenceRepository)
+	privacyHandler := handler.NewPrivacyHandler(privacyService)
+	avatarService := ProvideAvatarService(configConfig, userRepository, repository, blobStore)
+	avatarHandler := handler.NewAvatarHandler(avatarService)
+	preferenceHandler := handler.NewPreferenceHandler(preferenceService)
+	fileHandler := ProvideFileHandler(blobStore)
 	tokenConfig := config.NewTokenConfig(configConfig)
+	statusChecker := ProvideStatusChecker(userService)
+	roleChecker := ProvideRoleChecker(userService)
 	tracerProvider, cleanup, err := ProvideTracerProvider(configConfig)
 	if err != nil {
 		return nil, nil, err
 	}
 	serverOptions := &server.ServerOptions{
-		UserHandler:    userHandler,
-		AuthHandler:    authHandler,
-		EmailHandler:   emailHandler,
-		TokenConfig:    tokenConfig,
-		DB:             bunDB,
-		RedisRepo:      repository,
-		TracerProvider: tracerProvider,
+		UserHandler:             userHandler,
+		UserBulkHandler:         userBulkHandler,
+		AuthHandler:             authHandler,
+		EmailHandler:            emailHandler,
+		EmailTemplateHandler:    emailTemplateHandler,
+		EmailSuppressionHandler: emailSuppressionHandler,
+		EmailMessageLogHandler:  emailMessageLogHandler,
+		EmailBatchHandler:       emailBatchHandler,
+		HTTPLogHandler:          httpLogHandler,
+		PrivacyHandler:          privacyHandler,
+		AvatarHandler:           avatarHandler,
+		PreferenceHandler:       preferenceHandler,
+		FileHandler:             fileHandler,
+		TokenConfig:             tokenConfig,
+		StatusChecker:           statusChecker,
+		RoleChecker:             roleChecker,
+		DB:                      bunDB,
+		RedisRepo:               repository,
+		TracerProvider:          tracerProvider,
 	}
 	serverServer := server.New(configConfig, slogLogger, serverOptions)
 	return serverServer, func() {
@@ -80,6 +134,167 @@ func InitializeServer() (*server.Server, func(), error) {
 	}, nil
 }
 
+// InitializePrivacyService initializes the privacy service for background jobs
+func InitializePrivacyService() (privacy.PrivacyService, error) {
+	configConfig, err := ProvideConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := ProvideDB(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	bunDB := ProvideBunDB(db)
+	userRepository := user.NewUserRepository(bunDB)
+	deletionRequestRepository := privacy2.NewDeletionRequestRepository(bunDB)
+	httplogRepository := httplog.NewRepository(bunDB)
+	client, err := ProvideRedisClient(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	repository := ProvideRedisRepository(client)
+	outboxRepository := email2.NewOutboxRepository(bunDB)
+	suppressionRepository := email2.NewSuppressionRepository(bunDB)
+	suppressionService := ProvideSuppressionService(configConfig, suppressionRepository)
+	messageLogRepository := email2.NewMessageLogRepository(bunDB)
+	messageLogService := ProvideMessageLogService(configConfig, messageLogRepository)
+	outboxService := ProvideOutboxService(configConfig, outboxRepository, suppressionService, messageLogService)
+	emailService := ProvideEmailService(outboxService)
+	blobStore, err := ProvideBlobStore(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	preferenceRepository := preference.NewPreferenceRepository(bunDB)
+	privacyService := ProvidePrivacyService(configConfig, userRepository, deletionRequestRepository, httplogRepository, repository, emailService, blobStore, preferenceRepository)
+	return privacyService, nil
+}
+
+// InitializeHTTPLogRetention initializes the job that partitions and expires the HTTP logs
+func InitializeHTTPLogRetention() (httplog.RetentionService, error) {
+	configConfig, err := ProvideConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := ProvideDB(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	bunDB := ProvideBunDB(db)
+	httplogRepository := httplog.NewRepository(bunDB)
+	partitionRepository := httplog.NewPartitionRepository(bunDB)
+	archiveRepository := httplog.NewArchiveRepository(bunDB)
+	archiveService, err := ProvideHTTPLogArchive(configConfig, archiveRepository)
+	if err != nil {
+		return nil, err
+	}
+	retentionService := ProvideHTTPLogRetention(configConfig, httplogRepository, partitionRepository, archiveService)
+	return retentionService, nil
+}
+
+// InitializeHTTPLogArchive initializes the archive of the HTTP logs, nil when logs are not archived
+func InitializeHTTPLogArchive() (httplog.ArchiveService, error) {
+	configConfig, err := ProvideConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := ProvideDB(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	bunDB := ProvideBunDB(db)
+	archiveRepository := httplog.NewArchiveRepository(bunDB)
+	archiveService, err := ProvideHTTPLogArchive(configConfig, archiveRepository)
+	if err != nil {
+		return nil, err
+	}
+	return archiveService, nil
+}
+
+// InitializeEmailWorker initializes the worker that delivers queued emails
+func InitializeEmailWorker() (*email.OutboxWorker, func(), error) {
+	configConfig, err := ProvideConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := ProvideDB(configConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	bunDB := ProvideBunDB(db)
+	outboxRepository := email2.NewOutboxRepository(bunDB)
+	transport, cleanup, err := ProvideMailTransport(configConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	suppressionRepository := email2.NewSuppressionRepository(bunDB)
+	suppressionService := ProvideSuppressionService(configConfig, suppressionRepository)
+	messageLogRepository := email2.NewMessageLogRepository(bunDB)
+	messageLogService := ProvideMessageLogService(configConfig, messageLogRepository)
+	dkimSigner, err := ProvideDKIMSigner(configConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	outboxWorker := ProvideOutboxWorker(configConfig, outboxRepository, transport, suppressionService, messageLogService, dkimSigner)
+	return outboxWorker, func() {
+		cleanup()
+	}, nil
+}
+
+// InitializeEmailBatchWorker initializes the worker that fans scheduled batches out into the outbox
+func InitializeEmailBatchWorker() (*email.BatchWorker, error) {
+	configConfig, err := ProvideConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := ProvideDB(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	bunDB := ProvideBunDB(db)
+	batchRepository := email2.NewBatchRepository(bunDB)
+	outboxRepository := email2.NewOutboxRepository(bunDB)
+	suppressionRepository := email2.NewSuppressionRepository(bunDB)
+	suppressionService := ProvideSuppressionService(configConfig, suppressionRepository)
+	messageLogRepository := email2.NewMessageLogRepository(bunDB)
+	messageLogService := ProvideMessageLogService(configConfig, messageLogRepository)
+	outboxService := ProvideOutboxService(configConfig, outboxRepository, suppressionService, messageLogService)
+	templateRepository := email2.NewTemplateRepository(bunDB)
+	userRepository := user.NewUserRepository(bunDB)
+	preferenceRepository := preference.NewPreferenceRepository(bunDB)
+	client, err := ProvideRedisClient(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	repository := ProvideRedisRepository(client)
+	preferenceService := ProvidePreferenceService(preferenceRepository, repository)
+	templateService := ProvideTemplateService(outboxService, templateRepository, userRepository, preferenceService)
+	batchWorker := ProvideBatchWorker(configConfig, batchRepository, templateService)
+	return batchWorker, nil
+}
+
+// InitializeDailyReportService initializes the daily report job, which queues its emails in the outbox
+func InitializeDailyReportService() (*cron.DailyReportService, error) {
+	configConfig, err := ProvideConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := ProvideDB(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	bunDB := ProvideBunDB(db)
+	outboxRepository := email2.NewOutboxRepository(bunDB)
+	suppressionRepository := email2.NewSuppressionRepository(bunDB)
+	suppressionService := ProvideSuppressionService(configConfig, suppressionRepository)
+	messageLogRepository := email2.NewMessageLogRepository(bunDB)
+	messageLogService := ProvideMessageLogService(configConfig, messageLogRepository)
+	outboxService := ProvideOutboxService(configConfig, outboxRepository, suppressionService, messageLogService)
+	emailService := ProvideEmailService(outboxService)
+	dailyReportService := cron.NewDailyReportService(emailService)
+	return dailyReportService, nil
+}
+
 // wire.go:
 
 func ProvideBunDB(db *config.DB) *bun.DB {